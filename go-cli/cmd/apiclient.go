@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiRetries is how many times a rate-limited request is retried.
+const apiRetries = 5
+
+// apiClient makes authenticated requests to the REST APIs of sources such
+// as Confluence, backing off when the API rate-limits it.
+type apiClient struct {
+	client *http.Client
+	header http.Header
+}
+
+func newAPIClient(header http.Header) *apiClient {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("User-Agent", userAgent())
+	header.Set("Accept", "application/json")
+	return &apiClient{client: &http.Client{Timeout: time.Minute}, header: header}
+}
+
+// sourceToken reads a source's API token from the environment variable
+// named env.
+func sourceToken(env string) (string, error) {
+	if env == "" {
+		return "", fmt.Errorf("token_env is not set")
+	}
+	token := os.Getenv(env)
+	if token == "" {
+		return "", fmt.Errorf("%s is not set", env)
+	}
+	return token, nil
+}
+
+// getJSON fetches link and decodes the JSON response into v.
+func (c *apiClient) getJSON(link string, v interface{}) error {
+	return c.doJSON(http.MethodGet, link, nil, v)
+}
+
+// postJSON posts body as JSON to link and decodes the response into v.
+func (c *apiClient) postJSON(link string, body, v interface{}) error {
+	return c.doJSON(http.MethodPost, link, body, v)
+}
+
+func (c *apiClient) doJSON(method, link string, body, v interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	data, err := c.do(method, link, payload)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding %s: %w", link, err)
+	}
+	return nil
+}
+
+// download fetches link and returns the response body.
+func (c *apiClient) download(link string) ([]byte, error) {
+	return c.do(http.MethodGet, link, nil)
+}
+
+// deleteJSON deletes link and decodes the response into v.
+func (c *apiClient) deleteJSON(link string, v interface{}) error {
+	return c.doJSON(http.MethodDelete, link, nil, v)
+}
+
+// postForm posts a multipart form to link and decodes the response into v.
+func (c *apiClient) postForm(link string, form []byte, contentType string, v interface{}) error {
+	data, err := c.send(http.MethodPost, link, form, contentType)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding %s: %w", link, err)
+	}
+	return nil
+}
+
+// isNotFound reports whether err is an API response of 404 Not Found.
+func isNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), ": 404 ")
+}
+
+func (c *apiClient) do(method, link string, payload []byte) ([]byte, error) {
+	return c.send(method, link, payload, "application/json")
+}
+
+// send makes a request with payload, sent as contentType when not nil.
+func (c *apiClient) send(method, link string, payload []byte, contentType string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, link, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header = c.header.Clone()
+		if payload != nil {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < apiRetries {
+			wait := time.Duration(attempt+1) * time.Second
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(secs) * time.Second
+			}
+			time.Sleep(wait)
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, fmt.Errorf("%s %s: %s: %s", method, link, resp.Status, strings.TrimSpace(string(data)))
+		}
+		return data, nil
+	}
+}
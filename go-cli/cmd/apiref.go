@@ -0,0 +1,242 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// apiMember is one documented symbol on an API reference page.
+type apiMember struct {
+	Name        string
+	Signature   string
+	Description string
+	Fields      []apiField
+}
+
+// apiField is a labelled group of definitions (Parameters, Returns, ...).
+type apiField struct {
+	Label string
+	Items []apiItem
+}
+
+// apiItem is a single term, its optional type, and its definition.
+type apiItem struct {
+	Term       string
+	Type       string
+	Definition string
+}
+
+var whitespaceRe = regexp.MustCompile(`\s+`)
+
+// squash collapses runs of whitespace (including non-breaking spaces)
+// and trims the result.
+func squash(s string) string {
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	return strings.TrimSpace(whitespaceRe.ReplaceAllString(s, " "))
+}
+
+// extractAPIReference recognizes Sphinx autodoc, Javadoc and Dartdoc
+// markup and renders each member as compact markdown with definition
+// lists. It returns "" when the page has no recognizable API markup so
+// the caller can fall back to prose conversion.
+func extractAPIReference(doc *goquery.Document, converter markdownConverter) string {
+	var members []apiMember
+	members = append(members, sphinxMembers(doc, converter)...)
+	members = append(members, javadocMembers(doc, converter)...)
+	members = append(members, dartdocMembers(doc, converter)...)
+	if len(members) == 0 {
+		return ""
+	}
+	return renderAPIMembers(members)
+}
+
+// describe converts a selection to markdown for use as a description.
+func describe(sel *goquery.Selection, converter markdownConverter) string {
+	var parts []string
+	sel.Each(func(_ int, s *goquery.Selection) {
+		html, err := goquery.OuterHtml(s)
+		if err != nil {
+			return
+		}
+		text, err := converter.ConvertString(html)
+		if err != nil {
+			text = s.Text()
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	})
+	return strings.Join(parts, "\n\n")
+}
+
+// sphinxMembers handles Sphinx autodoc (<dl class="py function"> etc.).
+func sphinxMembers(doc *goquery.Document, converter markdownConverter) []apiMember {
+	var members []apiMember
+	doc.Find("dl.py, dl.cpp, dl.c, dl.js").Each(func(_ int, dl *goquery.Selection) {
+		dt := dl.ChildrenFiltered("dt.sig, dt").First()
+		if dt.Length() == 0 {
+			return
+		}
+		dt.Find("a.headerlink, .viewcode-link").Remove()
+
+		name := squash(dt.Find(".sig-prename").Text() + dt.Find(".sig-name").Text())
+		if name == "" {
+			name = squash(dt.AttrOr("id", ""))
+		}
+		m := apiMember{Name: name, Signature: squash(dt.Text())}
+
+		dd := dl.ChildrenFiltered("dd").First()
+		m.Description = describe(dd.ChildrenFiltered("p, div.admonition"), converter)
+
+		dd.ChildrenFiltered("dl.field-list").Each(func(_ int, fl *goquery.Selection) {
+			fl.ChildrenFiltered("dt").Each(func(_ int, fdt *goquery.Selection) {
+				field := apiField{Label: strings.TrimSuffix(squash(fdt.Text()), ":")}
+				fdd := fdt.NextFiltered("dd")
+				items := fdd.Find("li")
+				if items.Length() == 0 {
+					items = fdd
+				}
+				items.Each(func(_ int, li *goquery.Selection) {
+					term := squash(li.Find("strong").First().Text())
+					def := squash(li.Text())
+					if term != "" {
+						def = strings.TrimSpace(strings.TrimPrefix(def, term))
+					}
+					var typ string
+					if term != "" && strings.HasPrefix(def, "(") {
+						if end := strings.Index(def, ")"); end > 0 {
+							typ, def = def[1:end], def[end+1:]
+						}
+					}
+					def = strings.TrimLeft(def, " –-")
+					field.Items = append(field.Items, apiItem{Term: term, Type: typ, Definition: def})
+				})
+				m.Fields = append(m.Fields, field)
+			})
+		})
+		members = append(members, m)
+	})
+	return members
+}
+
+// javadocMembers handles modern (section.detail) and legacy
+// (li.blockList) Javadoc member details.
+func javadocMembers(doc *goquery.Document, converter markdownConverter) []apiMember {
+	var members []apiMember
+	details := doc.Find("section.detail").AddSelection(
+		doc.Find("ul.blockList > li.blockList").FilterFunction(func(_ int, s *goquery.Selection) bool {
+			return s.ChildrenFiltered("h4").Length() > 0
+		}))
+	details.Each(func(_ int, s *goquery.Selection) {
+		name := squash(s.ChildrenFiltered("h3, h4").First().Text())
+		sig := s.Find(".member-signature").First()
+		if sig.Length() == 0 {
+			sig = s.ChildrenFiltered("pre").First()
+		}
+		if name == "" || sig.Length() == 0 {
+			return
+		}
+
+		m := apiMember{
+			Name:        name,
+			Signature:   squash(sig.Text()),
+			Description: describe(s.Find("div.block").First(), converter),
+		}
+
+		var field *apiField
+		s.Find("dl").First().Children().Each(func(_ int, c *goquery.Selection) {
+			switch goquery.NodeName(c) {
+			case "dt":
+				m.Fields = append(m.Fields, apiField{Label: strings.TrimSuffix(squash(c.Text()), ":")})
+				field = &m.Fields[len(m.Fields)-1]
+			case "dd":
+				if field == nil {
+					return
+				}
+				term := squash(c.Find("code").First().Text())
+				def := squash(c.Text())
+				if term != "" && strings.HasPrefix(def, term) {
+					def = strings.TrimLeft(strings.TrimPrefix(def, term), " -")
+				} else {
+					term = ""
+				}
+				field.Items = append(field.Items, apiItem{Term: term, Definition: def})
+			}
+		})
+		members = append(members, m)
+	})
+	return members
+}
+
+// dartdocMembers handles Dartdoc class summaries (dl.properties,
+// dl.callables, dl.constants) and member pages (.multi-line-signature).
+func dartdocMembers(doc *goquery.Document, converter markdownConverter) []apiMember {
+	var members []apiMember
+	doc.Find("dl.properties > dt, dl.callables > dt, dl.constants > dt").Each(func(_ int, dt *goquery.Selection) {
+		name := squash(dt.Find(".name").First().Text())
+		if name == "" {
+			return
+		}
+		m := apiMember{Name: name, Signature: squash(dt.Text())}
+		dd := dt.NextFiltered("dd")
+		dd.Find(".features").Remove()
+		m.Description = describe(dd.Find("p").First(), converter)
+		members = append(members, m)
+	})
+
+	doc.Find("section.multi-line-signature").Each(func(_ int, s *goquery.Selection) {
+		name := squash(doc.Find("h1").First().Text())
+		m := apiMember{Name: name, Signature: squash(s.Text())}
+		m.Description = describe(s.NextAllFiltered("section.desc").First(), converter)
+		members = append(members, m)
+	})
+	return members
+}
+
+// renderAPIMembers renders members as markdown with definition lists.
+func renderAPIMembers(members []apiMember) string {
+	var b strings.Builder
+	for _, m := range members {
+		b.WriteString("## `" + m.Name + "`\n\n")
+		if m.Signature != "" && m.Signature != m.Name {
+			b.WriteString("```\n" + m.Signature + "\n```\n\n")
+		}
+		if m.Description != "" {
+			b.WriteString(m.Description + "\n\n")
+		}
+		for _, f := range m.Fields {
+			if len(f.Items) == 0 {
+				continue
+			}
+			b.WriteString("**" + f.Label + "**\n\n")
+			for _, item := range f.Items {
+				if item.Term != "" {
+					term := "`" + item.Term + "`"
+					if item.Type != "" {
+						term += " (" + item.Type + ")"
+					}
+					b.WriteString(term + "\n: " + item.Definition + "\n\n")
+				} else {
+					b.WriteString(item.Definition + "\n\n")
+				}
+			}
+		}
+	}
+	return strings.TrimSpace(b.String()) + "\n"
+}
@@ -0,0 +1,467 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// adocConverter converts AsciiDoc to markdown: the document header and
+// attributes, sections, delimited blocks, admonitions, lists, tables, and
+// inline macros.
+type adocConverter struct {
+	attrs       map[string]string
+	title       string
+	description string
+}
+
+var (
+	adocSection     = regexp.MustCompile(`^(={1,6}|#{1,6})\s+(.+?)\s*=*$`)
+	adocAttrEntry   = regexp.MustCompile(`^:(!?[\w-]+!?):\s*(.*)$`)
+	adocBlockAttr   = regexp.MustCompile(`^\[.*\]$`)
+	adocBlockTitle  = regexp.MustCompile(`^\.([^.\s].*)$`)
+	adocDelimiter   = regexp.MustCompile(`^(-{4,}|\.{4,}|={4,}|\*{4,}|_{4,}|\+{4,}|/{4,}|--|\|===)$`)
+	adocAdmonition  = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION):\s+(.*)$`)
+	adocListItem    = regexp.MustCompile(`^(\*{1,5}|-|\.{1,5}|\d+\.)\s+(.*)$`)
+	adocDescription = regexp.MustCompile(`^(.+?)(::|;;|:::|::::)(?:\s+(.*))?$`)
+	adocBlockMacro  = regexp.MustCompile(`^(image|video|audio)::([^\[]*)\[([^\]]*)\]$`)
+	adocSkipMacro   = regexp.MustCompile(`^(toc|include|ifdef|ifndef|ifeval|endif)::`)
+	adocCols        = regexp.MustCompile(`cols="?([^"\]]+)"?`)
+	adocCallout     = regexp.MustCompile(`\s*(//\s*|#\s*)?<\d+>\s*$`)
+	adocCode        = regexp.MustCompile("`([^`]+)`|\\+\\+\\+(.+?)\\+\\+\\+|\\+([^+\\s](?:[^+]*[^+\\s])?)\\+|pass:\\[([^\\]]*)\\]")
+	adocAttrRef     = regexp.MustCompile(`\\?\{([\w-]+)\}`)
+	adocLink        = regexp.MustCompile(`(?:link:|mailto:)?((?:https?://|mailto:)?[^\s\[\]<>]+)\[([^\]]*)\]`)
+	adocXrefAngle   = regexp.MustCompile(`<<([^,>]+)(?:,\s*([^>]+))?>>`)
+	adocXref        = regexp.MustCompile(`xref:([^\[\s]+)\[([^\]]*)\]`)
+	adocImage       = regexp.MustCompile(`image:([^\[\s:][^\[\s]*)\[([^\],]*)[^\]]*\]`)
+	adocInlineMacro = regexp.MustCompile(`(kbd|btn|menu|footnote|footnoteref):([^\[\s]*)\[([^\]]*)\]`)
+	adocStrong      = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*($|[^\w*])`)
+	adocEmphasis    = regexp.MustCompile(`(^|[^\w_])_([^_\s](?:[^_]*[^_\s])?)_($|[^\w_])`)
+	adocUnconEmph   = regexp.MustCompile(`__(.+?)__`)
+	adocHighlight   = regexp.MustCompile(`(^|[^\w#])#([^#\s](?:[^#]*[^#\s])?)#($|[^\w#])`)
+)
+
+// adocAdmonitionLabels maps admonition styles to their label.
+var adocAdmonitionLabels = map[string]string{
+	"NOTE":      "Note",
+	"TIP":       "Tip",
+	"IMPORTANT": "Important",
+	"WARNING":   "Warning",
+	"CAUTION":   "Caution",
+}
+
+// convertAsciiDoc converts an AsciiDoc file in a git source.
+func convertAsciiDoc(data []byte, opts *CrawlOptions) (sourceDoc, error) {
+	c := &adocConverter{attrs: map[string]string{}}
+	text := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\t", "        ")
+	body := strings.Join(c.blocks(strings.Split(text, "\n")), "\n\n")
+	return sourceDoc{Title: c.title, Description: c.description, Body: strings.TrimSpace(body) + "\n"}, nil
+}
+
+// blocks converts a sequence of AsciiDoc lines to markdown blocks. Block
+// attribute lines ([source,go]) and block titles (.Title) apply to the
+// block after them.
+func (c *adocConverter) blocks(lines []string) []string {
+	var out []string
+	attr, title := "", ""
+	emit := func(blocks ...string) {
+		if title != "" {
+			out = append(out, "**"+c.inline(title)+"**")
+		}
+		out = append(out, blocks...)
+		attr, title = "", ""
+	}
+
+	for i := 0; i < len(lines); {
+		line := strings.TrimRight(lines[i], " ")
+		switch {
+		case line == "":
+			i++
+
+		case strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "////"):
+			i++
+
+		case adocDelimiter.MatchString(line):
+			j := i + 1
+			for j < len(lines) && strings.TrimRight(lines[j], " ") != line {
+				j++
+			}
+			content := lines[i+1 : min(j, len(lines))]
+			i = j + 1
+			if block, ok := c.delimited(line, content, attr); ok {
+				emit(block...)
+			} else {
+				attr, title = "", ""
+			}
+
+		case adocSection.MatchString(line):
+			m := adocSection.FindStringSubmatch(line)
+			level := len(m[1])
+			i++
+			if level == 1 && c.title == "" {
+				c.title = c.inline(m[2])
+				i = c.header(lines, i)
+				continue
+			}
+			emit("#" + strings.Repeat("#", min(level-1, 5)) + " " + c.inline(m[2]))
+
+		case adocAttrEntry.MatchString(line):
+			m := adocAttrEntry.FindStringSubmatch(line)
+			c.setAttr(m[1], m[2])
+			i++
+
+		case adocBlockAttr.MatchString(line):
+			if !strings.HasPrefix(line, "[[") && !strings.HasPrefix(line, "[#") {
+				attr = line[1 : len(line)-1]
+			}
+			i++
+
+		case adocBlockTitle.MatchString(line) && !adocListItem.MatchString(line):
+			title = adocBlockTitle.FindStringSubmatch(line)[1]
+			i++
+
+		case adocSkipMacro.MatchString(line):
+			i++
+
+		case adocBlockMacro.MatchString(line):
+			m := adocBlockMacro.FindStringSubmatch(line)
+			alt, _, _ := strings.Cut(m[3], ",")
+			if m[1] == "image" {
+				emit("![" + alt + "](" + m[2] + ")")
+			} else {
+				emit("[" + m[1] + ": " + m[2] + "](" + m[2] + ")")
+			}
+			i++
+
+		case adocListItem.MatchString(line):
+			emit(c.list(lines, &i))
+
+		case adocDescription.MatchString(line) && !strings.Contains(line, "://"):
+			emit(c.descriptions(lines, &i)...)
+
+		default:
+			j := i
+			for j < len(lines) && strings.TrimSpace(lines[j]) != "" && !adocDelimiter.MatchString(strings.TrimRight(lines[j], " ")) {
+				j++
+			}
+			para := lines[i:j]
+			i = j
+			emit(c.paragraph(para, attr))
+		}
+	}
+	return out
+}
+
+// header skips the author and revision lines after the document title and
+// reads the header attributes, returning the index of the first body line.
+func (c *adocConverter) header(lines []string, i int) int {
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		if m := adocAttrEntry.FindStringSubmatch(lines[i]); m != nil {
+			c.setAttr(m[1], m[2])
+		}
+	}
+	return i
+}
+
+func (c *adocConverter) setAttr(name, value string) {
+	if strings.HasPrefix(name, "!") || strings.HasSuffix(name, "!") {
+		delete(c.attrs, strings.Trim(name, "!"))
+		return
+	}
+	c.attrs[name] = value
+	if name == "description" {
+		c.description = value
+	}
+}
+
+// paragraph converts a paragraph, honoring a style set by its block
+// attribute line and NOTE:-style admonition labels.
+func (c *adocConverter) paragraph(para []string, attr string) string {
+	style, _, _ := strings.Cut(attr, ",")
+	switch {
+	case indentOf(para[0]) > 0 || style == "literal":
+		return fence("", dedent(para))
+	case style == "source" || style == "listing":
+		return fence(adocSourceLang(attr), para)
+	}
+
+	text := c.inline(strings.Join(para, "\n"))
+	// A trailing " +" is a hard line break.
+	text = strings.ReplaceAll(text, " +\n", "\\\n")
+
+	if label := adocAdmonitionLabels[style]; label != "" {
+		return admonitionMarkdown(label, []string{text})
+	}
+	if m := adocAdmonition.FindStringSubmatch(text); m != nil {
+		return admonitionMarkdown(adocAdmonitionLabels[m[1]], []string{strings.TrimPrefix(text, m[1]+": ")})
+	}
+	if style == "quote" || style == "verse" {
+		return quoteMarkdown(text + adocAttribution(attr))
+	}
+	return text
+}
+
+// delimited converts a delimited block. Comment blocks report false.
+func (c *adocConverter) delimited(delim string, content []string, attr string) ([]string, bool) {
+	style, _, _ := strings.Cut(attr, ",")
+	switch delim[0] {
+	case '/':
+		return nil, false
+	case '-':
+		if delim == "--" {
+			break
+		}
+		code := make([]string, len(content))
+		for k, line := range content {
+			code[k] = adocCallout.ReplaceAllString(line, "")
+		}
+		return []string{fence(adocSourceLang(attr), code)}, true
+	case '.':
+		return []string{fence("", content)}, true
+	case '+':
+		return []string{strings.Join(content, "\n")}, true
+	case '|':
+		return []string{c.table(content, attr)}, true
+	case '_':
+		inner := strings.Join(c.blocks(content), "\n\n")
+		return []string{quoteMarkdown(inner + adocAttribution(attr))}, true
+	}
+	inner := c.blocks(content)
+	if label := adocAdmonitionLabels[style]; label != "" {
+		return []string{admonitionMarkdown(label, inner)}, true
+	}
+	return inner, true
+}
+
+// list converts consecutive list items. Nesting follows the marker depth
+// (** or ..); a lone "+" attaches the next paragraph to the item.
+func (c *adocConverter) list(lines []string, i *int) string {
+	var items []string
+	kind := ""
+	for *i < len(lines) {
+		m := adocListItem.FindStringSubmatch(strings.TrimSpace(lines[*i]))
+		if m == nil {
+			break
+		}
+		depth, bullet := len(m[1]), "- "
+		switch {
+		case m[1] == "-":
+			depth = 1
+		case m[1][0] == '.' || m[1][0] >= '0' && m[1][0] <= '9':
+			bullet = "1. "
+			if m[1][0] != '.' {
+				depth = 1
+			}
+		}
+		if depth == 1 {
+			if kind != "" && bullet != kind {
+				break
+			}
+			kind = bullet
+		}
+		text := []string{m[2]}
+		*i++
+		for *i < len(lines) {
+			next := strings.TrimSpace(lines[*i])
+			if next == "+" && *i+1 < len(lines) {
+				text = append(text, "")
+				*i++
+				continue
+			}
+			if next == "" || adocListItem.MatchString(next) || adocDelimiter.MatchString(next) || adocBlockAttr.MatchString(next) {
+				break
+			}
+			text = append(text, next)
+			*i++
+		}
+		for *i < len(lines) && strings.TrimSpace(lines[*i]) == "" {
+			if *i+1 < len(lines) && !adocListItem.MatchString(strings.TrimSpace(lines[*i+1])) {
+				break
+			}
+			*i++
+		}
+		indent := strings.Repeat("   ", depth-1)
+		item := c.inline(strings.Join(text, "\n"))
+		items = append(items, indent+bullet+indentMarkdown(item, len(indent)+len(bullet)))
+	}
+	return strings.Join(items, "\n")
+}
+
+// descriptions converts a description list (term:: definition).
+func (c *adocConverter) descriptions(lines []string, i *int) []string {
+	var out []string
+	for *i < len(lines) {
+		m := adocDescription.FindStringSubmatch(strings.TrimSpace(lines[*i]))
+		if m == nil || strings.Contains(lines[*i], "://") {
+			break
+		}
+		def := []string{m[3]}
+		*i++
+		for *i < len(lines) && strings.TrimSpace(lines[*i]) != "" && !adocDescription.MatchString(strings.TrimSpace(lines[*i])) {
+			def = append(def, strings.TrimSpace(lines[*i]))
+			*i++
+		}
+		for *i < len(lines) && strings.TrimSpace(lines[*i]) == "" {
+			*i++
+		}
+		term := "**" + c.inline(m[1]) + "**"
+		if d := strings.TrimSpace(strings.Join(def, " ")); d != "" {
+			term += ": " + c.inline(d)
+		}
+		out = append(out, term)
+	}
+	return out
+}
+
+// table converts a |=== table. The column count comes from the cols
+// attribute or the first row; the first row is the header.
+func (c *adocConverter) table(content []string, attr string) string {
+	cols := 0
+	if m := adocCols.FindStringSubmatch(attr); m != nil {
+		spec := m[1]
+		if n, _, ok := strings.Cut(spec, "*"); ok && !strings.Contains(n, ",") {
+			cols, _ = strconv.Atoi(n)
+		} else {
+			cols = len(strings.Split(spec, ","))
+		}
+	}
+	var cells []string
+	for _, line := range content {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "|") {
+			if len(cells) > 0 {
+				cells[len(cells)-1] += " " + line
+			}
+			continue
+		}
+		parts := strings.Split(strings.ReplaceAll(line[1:], `\|`, "\x00"), "|")
+		if cols == 0 {
+			cols = len(parts)
+		}
+		for _, part := range parts {
+			cells = append(cells, strings.TrimSpace(strings.ReplaceAll(part, "\x00", "|")))
+		}
+	}
+	if cols == 0 {
+		return ""
+	}
+	var rows [][]string
+	for k := 0; k < len(cells); k += cols {
+		row := cells[k:min(k+cols, len(cells))]
+		for n := range row {
+			row[n] = c.inline(row[n])
+		}
+		rows = append(rows, row)
+	}
+	return markdownTable(rows)
+}
+
+// inline converts inline markup to markdown.
+func (c *adocConverter) inline(s string) string {
+	s = adocAttrRef.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, `\`) {
+			return m[1:]
+		}
+		if v, ok := c.attrs[m[1:len(m)-1]]; ok {
+			return v
+		}
+		return m
+	})
+
+	var literals []string
+	s = adocCode.ReplaceAllStringFunc(s, func(m string) string {
+		sub := adocCode.FindStringSubmatch(m)
+		literals = append(literals, sub[1]+sub[2]+sub[3]+sub[4])
+		return fmt.Sprintf("\x00%d\x00", len(literals)-1)
+	})
+
+	s = adocImage.ReplaceAllString(s, "![$2]($1)")
+	s = adocXrefAngle.ReplaceAllStringFunc(s, func(m string) string {
+		sub := adocXrefAngle.FindStringSubmatch(m)
+		if sub[2] != "" {
+			return sub[2]
+		}
+		return sub[1]
+	})
+	s = adocXref.ReplaceAllStringFunc(s, func(m string) string {
+		sub := adocXref.FindStringSubmatch(m)
+		if sub[2] != "" {
+			return sub[2]
+		}
+		return sub[1]
+	})
+	s = adocInlineMacro.ReplaceAllStringFunc(s, func(m string) string {
+		sub := adocInlineMacro.FindStringSubmatch(m)
+		switch sub[1] {
+		case "kbd":
+			return codeSpan(sub[3])
+		case "btn":
+			return "**" + sub[3] + "**"
+		case "menu":
+			return strings.Join(append([]string{sub[2]}, strings.Split(sub[3], ">")...), " > ")
+		}
+		if sub[3] == "" {
+			return ""
+		}
+		return " (" + sub[3] + ")"
+	})
+	s = adocLink.ReplaceAllStringFunc(s, func(m string) string {
+		sub := adocLink.FindStringSubmatch(m)
+		target := sub[1]
+		if !strings.Contains(target, "://") && !strings.HasPrefix(m, "link:") && !strings.HasPrefix(target, "mailto:") {
+			return m
+		}
+		text, _, _ := strings.Cut(sub[2], ",")
+		text = strings.Trim(text, `"`)
+		if text == "" {
+			text = target
+		}
+		return "[" + text + "](" + target + ")"
+	})
+
+	s = adocUnconEmph.ReplaceAllString(s, "*$1*")
+	s = adocStrong.ReplaceAllString(s, "$1**$2**$3")
+	s = adocEmphasis.ReplaceAllString(s, "$1*$2*$3")
+	s = adocHighlight.ReplaceAllString(s, "$1$2$3")
+
+	for k, lit := range literals {
+		s = strings.Replace(s, fmt.Sprintf("\x00%d\x00", k), codeSpan(lit), 1)
+	}
+	return s
+}
+
+// adocSourceLang returns the language of a [source,lang] attribute.
+func adocSourceLang(attr string) string {
+	parts := strings.Split(attr, ",")
+	if len(parts) > 1 && (parts[0] == "source" || parts[0] == "") {
+		return strings.TrimSpace(parts[1])
+	}
+	return ""
+}
+
+// adocAttribution returns the attribution line of a [quote, author] block.
+func adocAttribution(attr string) string {
+	parts := strings.Split(attr, ",")
+	if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+		return ""
+	}
+	return "\n\n— " + strings.Trim(strings.TrimSpace(parts[1]), `"`)
+}
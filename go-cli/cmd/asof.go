@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultCDXEndpoint is the Wayback Machine host serving the CDX API and
+// the snapshots it lists.
+const defaultCDXEndpoint = "https://web.archive.org"
+
+// waybackTimestamp is the layout of Wayback Machine snapshot timestamps.
+const waybackTimestamp = "20060102150405"
+
+// asOfLayouts are the accepted spellings of --as-of.
+var asOfLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02", waybackTimestamp, "20060102"}
+
+// parseAsOf parses the time a crawl is pinned to.
+func parseAsOf(s string) (time.Time, error) {
+	for _, layout := range asOfLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid as_of %q: want a date such as 2023-06-01", s)
+}
+
+// asOfFetcher fetches the Wayback Machine snapshot of each page nearest
+// to a point in time instead of the live page, so a crawl can capture
+// docs as they were for an older product version. Snapshots are looked
+// up with the CDX API and recorded under the page's own URL, so links
+// resolve against the original site. Pages without a snapshot answer 404.
+type asOfFetcher struct {
+	next     Fetcher
+	at       time.Time
+	endpoint string
+}
+
+func (f *asOfFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	timestamp, err := f.closest(req)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp == "" {
+		fmt.Printf("No snapshot of %s as of %s\n", req.URL, f.at.Format("2006-01-02"))
+		return localResponse(req, http.StatusNotFound, nil, "", time.Time{}), nil
+	}
+
+	// The id_ flag serves the page as archived, without the Wayback
+	// Machine's toolbar and rewritten links.
+	snapshot := f.endpoint + "/web/" + timestamp + "/" + req.URL.String()
+	u, err := url.Parse(f.endpoint + "/web/" + timestamp + "id_/" + req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	var resp *http.Response
+	for redirects := 0; ; redirects++ {
+		archived := req.Clone(req.Context())
+		archived.URL, archived.Host = u, u.Host
+		archived.Header.Del("If-Modified-Since")
+		archived.Header.Del("If-None-Match")
+		if resp, err = f.next.Fetch(archived); err != nil {
+			return nil, err
+		}
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" || redirects == 5 {
+			break
+		}
+		// The archive redirects to the snapshot it holds when the
+		// timestamp is not exact.
+		resp.Body.Close()
+		if u, err = u.Parse(location); err != nil {
+			return nil, err
+		}
+	}
+
+	resp.Request = req
+	if resp.StatusCode == http.StatusOK {
+		archivedAt := timestamp
+		if t, err := time.Parse(waybackTimestamp, timestamp); err == nil {
+			archivedAt = t.UTC().Format(http.TimeFormat)
+		}
+		resp.Header.Set("Last-Modified", archivedAt)
+		resp.Header.Set(archivedHeader, snapshot)
+	}
+	return resp, nil
+}
+
+// closest returns the timestamp of the snapshot of req's URL nearest to
+// f.at that was archived with status 200, or "" when there is none.
+func (f *asOfFetcher) closest(req *http.Request) (string, error) {
+	query := url.Values{
+		"url":     {req.URL.String()},
+		"closest": {f.at.Format(waybackTimestamp)},
+		"sort":    {"closest"},
+		"filter":  {"statuscode:200"},
+		"fl":      {"timestamp"},
+		"output":  {"json"},
+		"limit":   {"1"},
+	}
+	u, err := url.Parse(f.endpoint + "/cdx/search/cdx?" + query.Encode())
+	if err != nil {
+		return "", err
+	}
+	lookup, err := http.NewRequestWithContext(req.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	lookup.Header.Set("User-Agent", req.Header.Get("User-Agent"))
+	resp, err := f.next.Fetch(lookup)
+	if err != nil {
+		return "", fmt.Errorf("looking up snapshots of %s: %w", req.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("looking up snapshots of %s: CDX API answered %s", req.URL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	// The first row names the fields.
+	var rows [][]string
+	if strings.TrimSpace(string(data)) != "" {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return "", fmt.Errorf("reading snapshots of %s: %w", req.URL, err)
+		}
+	}
+	if len(rows) < 2 || len(rows[1]) == 0 {
+		return "", nil
+	}
+	return rows[1][0], nil
+}
@@ -0,0 +1,327 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags for the bench command.
+var (
+	benchPages    int
+	benchRender   bool
+	benchOut      string
+	benchBaseline string
+	benchMaxSlow  float64
+)
+
+// BenchResult is one measurement. Benchmarks report NsPerOp, BytesPerSec,
+// and AllocsPerOp; the crawl reports PagesPerSec.
+type BenchResult struct {
+	Name        string  `json:"name"`
+	NsPerOp     int64   `json:"ns_per_op,omitempty"`
+	BytesPerSec float64 `json:"bytes_per_sec,omitempty"`
+	AllocsPerOp int64   `json:"allocs_per_op,omitempty"`
+	PagesPerSec float64 `json:"pages_per_sec,omitempty"`
+}
+
+// BenchReport is the output of the bench command, saved with --out and
+// compared against with --baseline.
+type BenchReport struct {
+	Version string        `json:"version"`
+	RanAt   time.Time     `json:"ran_at"`
+	Options string        `json:"options,omitempty"`
+	Results []BenchResult `json:"results"`
+}
+
+var benchCmd = &cobra.Command{
+	Use:    "bench",
+	Short:  "Measure extraction, conversion, path generation, and crawl speed",
+	Hidden: true,
+	Long: `Runs benchmarks of content extraction, markdown conversion, and output
+path generation on representative generated pages, then crawls a generated
+site of --pages pages from a local server and reports pages per second.
+
+--render fetches the crawl through the headless browser. --out saves the
+results as JSON; --baseline compares them with a saved run and exits
+non-zero when a measurement is more than --max-slowdown percent slower.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		report := BenchReport{Version: buildInfo().Version, RanAt: time.Now().UTC()}
+		if benchRender {
+			report.Options = "render"
+		}
+		report.Results = runMicroBenchmarks()
+		crawl, err := benchCrawl(benchPages, benchRender)
+		if err != nil {
+			fmt.Printf("Error benchmarking crawl: %v\n", err)
+			os.Exit(1)
+		}
+		report.Results = append(report.Results, crawl)
+
+		for _, r := range report.Results {
+			fmt.Println(r.String())
+		}
+		if benchOut != "" {
+			data, _ := json.MarshalIndent(report, "", "  ")
+			if err := os.WriteFile(benchOut, append(data, '\n'), 0644); err != nil {
+				fmt.Printf("Error writing %s: %v\n", benchOut, err)
+				os.Exit(1)
+			}
+		}
+		if benchBaseline != "" && !compareBench(report, benchBaseline, benchMaxSlow) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().IntVar(&benchPages, "pages", 200, "pages in the generated site crawled for pages per second")
+	benchCmd.Flags().BoolVar(&benchRender, "render", false, "fetch the crawl through the headless browser")
+	benchCmd.Flags().StringVar(&benchOut, "out", "", "write the results as JSON to this file")
+	benchCmd.Flags().StringVar(&benchBaseline, "baseline", "", "compare with results saved by an earlier --out")
+	benchCmd.Flags().Float64Var(&benchMaxSlow, "max-slowdown", 20, "percent slower than the baseline that counts as a regression")
+}
+
+// String formats a result for the terminal.
+func (r BenchResult) String() string {
+	if r.PagesPerSec > 0 {
+		return fmt.Sprintf("%-28s %10.1f pages/s", r.Name, r.PagesPerSec)
+	}
+	line := fmt.Sprintf("%-28s %12d ns/op %6d allocs/op", r.Name, r.NsPerOp, r.AllocsPerOp)
+	if r.BytesPerSec > 0 {
+		line += fmt.Sprintf(" %8.2f MB/s", r.BytesPerSec/1e6)
+	}
+	return line
+}
+
+// slowdown returns how much slower r is than base, in percent.
+func (r BenchResult) slowdown(base BenchResult) float64 {
+	if r.PagesPerSec > 0 && base.PagesPerSec > 0 {
+		return (base.PagesPerSec/r.PagesPerSec - 1) * 100
+	}
+	if r.NsPerOp > 0 && base.NsPerOp > 0 {
+		return (float64(r.NsPerOp)/float64(base.NsPerOp) - 1) * 100
+	}
+	return 0
+}
+
+// compareBench prints the change of every result against the baseline
+// file and reports whether none is slower by more than maxSlowdown percent.
+func compareBench(report BenchReport, path string, maxSlowdown float64) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error reading baseline: %v\n", err)
+		return false
+	}
+	var baseline BenchReport
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		fmt.Printf("Error parsing baseline %s: %v\n", path, err)
+		return false
+	}
+	base := map[string]BenchResult{}
+	for _, r := range baseline.Results {
+		base[r.Name] = r
+	}
+
+	fmt.Printf("\nChange from %s (version %s), positive is slower:\n", path, baseline.Version)
+	ok := true
+	for _, r := range report.Results {
+		b, found := base[r.Name]
+		if !found {
+			continue
+		}
+		change := r.slowdown(b)
+		status := ""
+		if change > maxSlowdown {
+			status = "  REGRESSION"
+			ok = false
+		}
+		fmt.Printf("%-28s %+7.1f%%%s\n", r.Name, change, status)
+	}
+	return ok
+}
+
+// runMicroBenchmarks measures the per-page steps of the pipeline.
+func runMicroBenchmarks() []BenchResult {
+	page := []byte(benchDocPage(0, 12))
+	reference := []byte(benchReferencePage(400))
+	content, _ := extractContent(page, ExtractionConfig{})
+	refContent, _ := extractContent(reference, ExtractionConfig{})
+	converter := newMarkdownConverter(ConverterConfig{}, nil)
+
+	var links []*url.URL
+	for i := 0; i < 100; i++ {
+		u, _ := url.Parse(fmt.Sprintf("https://docs.example.com/guide/section-%d/page-%d.html", i%7, i))
+		links = append(links, u)
+	}
+
+	benchmarks := []struct {
+		name  string
+		bytes int
+		fn    func()
+	}{
+		{"extract/doc-page", len(page), func() { extractContent(page, ExtractionConfig{}) }},
+		{"extract/reference-page", len(reference), func() { extractContent(reference, ExtractionConfig{}) }},
+		{"metadata/doc-page", len(page), func() { extractMetadata(page) }},
+		{"convert/doc-page", len(content), func() { converter.ConvertString(content) }},
+		{"convert/reference-page", len(refContent), func() { converter.ConvertString(refContent) }},
+		{"path/nested", 0, func() {
+			for _, u := range links {
+				getOutputPath(u, ".skillscache", false, "")
+			}
+		}},
+		{"path/flat-rename", 0, func() {
+			for _, u := range links {
+				getOutputPath(u, ".skillscache", true, "SKILL.md")
+			}
+		}},
+	}
+
+	var results []BenchResult
+	for _, bm := range benchmarks {
+		r := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(bm.bytes))
+			for i := 0; i < b.N; i++ {
+				bm.fn()
+			}
+		})
+		result := BenchResult{Name: bm.name, NsPerOp: r.NsPerOp(), AllocsPerOp: r.AllocsPerOp()}
+		if bm.bytes > 0 && r.T > 0 {
+			result.BytesPerSec = float64(r.Bytes) * float64(r.N) / r.T.Seconds()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// benchCrawl crawls a generated site of pages pages from a local server
+// and returns the pages saved per second.
+func benchCrawl(pages int, render bool) (BenchResult, error) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Path == "/docs/" {
+			fmt.Fprint(w, benchIndexPage(pages))
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/docs/page-"), ".html"))
+		if err != nil || n < 0 || n >= pages {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, benchDocPage(n, 12))
+	}))
+	defer server.Close()
+
+	outDir, err := os.MkdirTemp("", "agent-skills-bench")
+	if err != nil {
+		return BenchResult{}, err
+	}
+	defer os.RemoveAll(outDir)
+
+	cfg := Config{Output: outDir, Patterns: []string{server.URL + "/docs/**"}}
+	name := fmt.Sprintf("crawl/%d-pages", pages)
+	if render {
+		cfg.Fetcher = "browser"
+		name += "-render"
+	}
+	cfg.profile = "bench"
+
+	restore := quietStdout()
+	start := time.Now()
+	crawlSite(cfg, "", nil)
+	elapsed := time.Since(start)
+	restore()
+
+	m, err := loadManifest(outDir)
+	if err != nil {
+		return BenchResult{}, err
+	}
+	if len(m.Pages) == 0 {
+		return BenchResult{}, fmt.Errorf("no pages were saved; run with the same options outside bench to see why")
+	}
+	return BenchResult{Name: name, PagesPerSec: float64(len(m.Pages)) / elapsed.Seconds()}, nil
+}
+
+// benchIndexPage links to every page of the generated site.
+func benchIndexPage(pages int) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><head><title>Bench Docs</title></head><body><article><h1>Bench Docs</h1><ul>")
+	for i := 0; i < pages; i++ {
+		fmt.Fprintf(&b, `<li><a href="/docs/page-%d.html">Page %d</a></li>`, i, i)
+	}
+	b.WriteString("</ul></article></body></html>")
+	return b.String()
+}
+
+// benchDocPage returns a documentation page with site chrome around an
+// article of sections, each with prose, a list, a code sample, and a table.
+func benchDocPage(n, sections int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html><html><head><title>Guide Page %d</title>
+<meta name="description" content="Generated guide page %d for benchmarks.">
+<link rel="stylesheet" href="/site.css"><script src="/site.js"></script></head><body>
+<header class="site-header"><nav>`, n, n)
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&b, `<a class="nav-link" href="/docs/page-%d.html">Nav %d</a>`, i, i)
+	}
+	fmt.Fprintf(&b, `</nav></header><main><article><h1>Guide Page %d</h1>`, n)
+	for s := 0; s < sections; s++ {
+		fmt.Fprintf(&b, `<h2 id="section-%d">Section %d</h2>`, s, s)
+		for p := 0; p < 3; p++ {
+			b.WriteString(`<p>Configure the <code>client</code> before making requests. The <a href="/docs/page-1.html">client options</a> control retries, timeouts, and <strong>authentication</strong>; see the reference for every field.</p>`)
+		}
+		b.WriteString(`<ul><li>Create a client.</li><li>Set the <em>region</em>.</li><li>Send the request.</li></ul>`)
+		b.WriteString(`<pre><code class="language-go">client, err := fixture.NewClient(ctx, fixture.WithRegion("us-east1"))
+if err != nil {
+	return err
+}
+defer client.Close()</code></pre>`)
+		b.WriteString(`<table><thead><tr><th>Option</th><th>Default</th><th>Description</th></tr></thead><tbody>`)
+		for r := 0; r < 5; r++ {
+			fmt.Fprintf(&b, `<tr><td><code>option_%d</code></td><td>%d</td><td>Controls behaviour %d.</td></tr>`, r, r*10, r)
+		}
+		b.WriteString(`</tbody></table>`)
+	}
+	b.WriteString(`</article></main><footer><p>Copyright Bench Authors</p><div class="feedback">Was this page helpful?</div></footer></body></html>`)
+	return b.String()
+}
+
+// benchReferencePage returns a large generated API reference page with
+// members entries, the kind of page that dominates extraction cost.
+func benchReferencePage(members int) string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html><html><head><title>API Reference</title></head><body><article><h1>fixture package</h1>`)
+	for i := 0; i < members; i++ {
+		fmt.Fprintf(&b, `<dl class="py method"><dt class="sig sig-object py" id="fixture.Client.method_%d"><span class="sig-name descname">method_%d</span>(<em class="sig-param">name</em>, <em class="sig-param">timeout=None</em>)</dt>
+<dd><p>Calls method %d on the service and returns the response.</p>
+<dl class="field-list"><dt>Parameters</dt><dd><ul><li><strong>name</strong> (<em>str</em>) – Resource name.</li><li><strong>timeout</strong> (<em>float</em>) – Seconds to wait.</li></ul></dd>
+<dt>Returns</dt><dd><p>The response.</p></dd></dl></dd></dl>`, i, i, i)
+	}
+	b.WriteString(`</article></body></html>`)
+	return b.String()
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+// benchFixtures are the fixture pages the benchmarks run on, as the bench
+// command measures them.
+var benchFixtures = []struct {
+	name string
+	html []byte
+}{
+	{"doc-page", []byte(benchDocPage(0, 12))},
+	{"reference-page", []byte(benchReferencePage(400))},
+}
+
+func BenchmarkExtract(b *testing.B) {
+	for _, page := range benchFixtures {
+		b.Run(page.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(page.html)))
+			for i := 0; i < b.N; i++ {
+				extractContent(page.html, ExtractionConfig{})
+			}
+		})
+	}
+}
+
+func BenchmarkConvert(b *testing.B) {
+	for _, engine := range []string{engineV1, engineV2} {
+		converter := newMarkdownConverter(ConverterConfig{Engine: engine}, nil)
+		for _, page := range benchFixtures {
+			content, err := extractContent(page.html, ExtractionConfig{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(engine+"/"+page.name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(content)))
+				for i := 0; i < b.N; i++ {
+					converter.ConvertString(content)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkOutputPath(b *testing.B) {
+	var links []*url.URL
+	for i := 0; i < 100; i++ {
+		u, _ := url.Parse(fmt.Sprintf("https://docs.example.com/guide/section-%d/page-%d.html", i%7, i))
+		links = append(links, u)
+	}
+	layouts := []struct {
+		name   string
+		flat   bool
+		rename string
+	}{
+		{"nested", false, ""},
+		{"flat-rename", true, "SKILL.md"},
+	}
+	for _, layout := range layouts {
+		b.Run(layout.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, u := range links {
+					getOutputPath(u, ".skillscache", layout.flat, layout.rename)
+				}
+			}
+		})
+	}
+}
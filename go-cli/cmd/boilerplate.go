@@ -0,0 +1,216 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// shingleSize is the number of words per shingle.
+const shingleSize = 4
+
+// boilerplateShare is the fraction of a block's shingles that must be
+// common across the site for the block to count as boilerplate.
+const boilerplateShare = 0.6
+
+// stripBoilerplate removes text blocks that repeat across a large share
+// of the pages on each host (footers, promos, "Was this page helpful?")
+// from every page in the manifest.
+//
+// Blocks are compared by word shingles rather than exact text so small
+// per-page variations (dates, page names) still match. Headings and code
+// blocks are never removed.
+func stripBoilerplate(opts *CrawlOptions, m *Manifest, cfg BoilerplateConfig) {
+	threshold := cfg.Threshold
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+	minPages := cfg.MinPages
+	if minPages <= 0 {
+		minPages = 5
+	}
+
+	byHost := map[string][]*ManifestEntry{}
+	for _, entry := range m.Pages {
+		u, err := url.Parse(entry.URL)
+		if err != nil {
+			continue
+		}
+		byHost[u.Host] = append(byHost[u.Host], entry)
+	}
+
+	for host, entries := range byHost {
+		if len(entries) < minPages {
+			continue
+		}
+
+		type page struct {
+			entry  *ManifestEntry
+			path   string
+			fm     string
+			blocks []string
+		}
+		var pages []page
+		df := map[uint64]int{}
+
+		for _, entry := range entries {
+			path := filepath.Join(opts.Output, filepath.FromSlash(entry.Path))
+			data, err := readOutputFile(path)
+			if err != nil {
+				continue
+			}
+			fm, body := splitFrontmatter(string(data))
+			p := page{entry: entry, path: path, fm: fm, blocks: splitBlocks(body)}
+			seen := map[uint64]bool{}
+			for _, b := range p.blocks {
+				if !strippable(b) {
+					continue
+				}
+				for _, s := range shingles(b) {
+					if !seen[s] {
+						seen[s] = true
+						df[s]++
+					}
+				}
+			}
+			pages = append(pages, p)
+		}
+
+		cutoff := int(math.Ceil(threshold * float64(len(pages))))
+		if cutoff < 2 {
+			cutoff = 2
+		}
+
+		removed, touched := 0, 0
+		for _, p := range pages {
+			var kept []string
+			for _, b := range p.blocks {
+				if strippable(b) && isBoilerplate(b, df, cutoff) {
+					removed++
+					continue
+				}
+				kept = append(kept, b)
+			}
+			if len(kept) == len(p.blocks) {
+				continue
+			}
+
+			content := p.fm + "\n" + strings.Join(kept, "\n\n") + "\n"
+			if err := writeFileIfChanged(p.path, []byte(content)); err != nil {
+				fmt.Printf("Error writing %s: %v\n", p.path, err)
+				continue
+			}
+			m.mu.Lock()
+			p.entry.FileHash = contentHash(content)
+			m.touch(p.entry.URL)
+			m.mu.Unlock()
+			touched++
+		}
+
+		if removed > 0 {
+			fmt.Printf("Removed %d boilerplate blocks from %d pages on %s\n", removed, touched, host)
+		}
+	}
+}
+
+// splitBlocks splits markdown into blank-line separated blocks, keeping
+// fenced code blocks intact.
+func splitBlocks(body string) []string {
+	var blocks []string
+	var cur []string
+	inFence := false
+	flush := func() {
+		if len(cur) > 0 {
+			blocks = append(blocks, strings.Join(cur, "\n"))
+			cur = nil
+		}
+	}
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if trimmed == "" && !inFence {
+			flush()
+			continue
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return blocks
+}
+
+// strippable reports whether a block may be removed as boilerplate.
+func strippable(block string) bool {
+	trimmed := strings.TrimSpace(block)
+	return !strings.HasPrefix(trimmed, "#") &&
+		!strings.HasPrefix(trimmed, "```") &&
+		!strings.HasPrefix(trimmed, "~~~")
+}
+
+// isBoilerplate reports whether most of the block's shingles appear on at
+// least cutoff pages.
+func isBoilerplate(block string, df map[uint64]int, cutoff int) bool {
+	sh := shingles(block)
+	if len(sh) == 0 {
+		return false
+	}
+	common := 0
+	for _, s := range sh {
+		if df[s] >= cutoff {
+			common++
+		}
+	}
+	return float64(common)/float64(len(sh)) >= boilerplateShare
+}
+
+// shingles returns hashes of the overlapping word n-grams in a block.
+// Blocks shorter than one shingle hash as a whole.
+func shingles(block string) []uint64 {
+	words := textWords(block)
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < shingleSize {
+		return []uint64{hashWords(words)}
+	}
+	out := make([]uint64, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		out = append(out, hashWords(words[i:i+shingleSize]))
+	}
+	return out
+}
+
+// textWords returns the lower-cased words of s, ignoring punctuation and
+// markdown syntax.
+func textWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	})
+}
+
+func hashWords(words []string) uint64 {
+	h := fnv.New64a()
+	for _, w := range words {
+		h.Write([]byte(w))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,584 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Defaults for the bot config section.
+const (
+	defaultBotResults      = 3
+	defaultBotExcerptChars = 600
+	defaultSlackSecretEnv  = "SLACK_SIGNING_SECRET"
+	defaultSlackTokenEnv   = "SLACK_BOT_TOKEN"
+	// slackMaxAge is how old a signed Slack request may be, against replays.
+	slackMaxAge = 5 * time.Minute
+	// discordMaxChars is the longest message Discord accepts.
+	discordMaxChars = 2000
+	// botMaxBody caps the size of a request body.
+	botMaxBody = 1 << 20
+	// slackPostMessageURL is the Slack Web API method replies to mentions
+	// are posted with.
+	slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+)
+
+// botAddr is the address the bot listens on.
+var botAddr string
+
+var botCmd = &cobra.Command{
+	Use:   "bot",
+	Short: "Answer Slack and Discord questions from the output directory",
+	Long: `Starts a web server that answers questions asked in Slack or Discord by
+searching the pages in the output directory and replying with the best
+matching section and a link to its source page.
+
+Endpoints:
+  POST /slack/commands        Slack slash command (such as /docs how do I install)
+  POST /slack/events          Slack Events API; answers @mentions in a thread
+  POST /discord/interactions  Discord interactions endpoint for a slash command
+  GET  /search?q=...          the matches as JSON
+
+Slack requests are checked against the signing secret in
+SLACK_SIGNING_SECRET and Discord requests against bot.discord.public_key;
+endpoints whose secret is not configured are not served. The index is
+rebuilt when the manifest changes, so crawls are picked up without a
+restart.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		b := &botServer{cfg: cfg.Bot, opts: newCrawlOptions(&cfg)}
+		if _, err := b.searchIndex(); err != nil {
+			fmt.Printf("Error indexing %s: %v\n", b.opts.Output, err)
+			os.Exit(1)
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/search", b.handleSearch)
+		secretEnv := cfg.Bot.Slack.SecretEnv
+		if secretEnv == "" {
+			secretEnv = defaultSlackSecretEnv
+		}
+		if b.slackSecret = os.Getenv(secretEnv); b.slackSecret != "" {
+			tokenEnv := cfg.Bot.Slack.TokenEnv
+			if tokenEnv == "" {
+				tokenEnv = defaultSlackTokenEnv
+			}
+			b.slackToken = os.Getenv(tokenEnv)
+			mux.HandleFunc("/slack/commands", b.handleSlackCommand)
+			mux.HandleFunc("/slack/events", b.handleSlackEvent)
+			if b.slackToken == "" {
+				fmt.Printf("Warning: %s is not set, so @mentions are not answered\n", tokenEnv)
+			}
+		} else {
+			fmt.Printf("Warning: %s is not set, so Slack is not served\n", secretEnv)
+		}
+		if key := cfg.Bot.Discord.PublicKey; key != "" {
+			decoded, err := hex.DecodeString(key)
+			if err != nil || len(decoded) != ed25519.PublicKeySize {
+				fmt.Printf("Error: bot.discord.public_key is not a hex Ed25519 public key\n")
+				os.Exit(1)
+			}
+			b.discordKey = ed25519.PublicKey(decoded)
+			mux.HandleFunc("/discord/interactions", b.handleDiscord)
+		} else {
+			fmt.Printf("Warning: bot.discord.public_key is not set, so Discord is not served\n")
+		}
+
+		fmt.Printf("Answering questions from %s at http://%s/\n", b.opts.Output, botAddr)
+		if err := http.ListenAndServe(botAddr, mux); err != nil {
+			fmt.Printf("Error serving bot: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(botCmd)
+	botCmd.Flags().StringVar(&botAddr, "addr", "localhost:3000", "address to serve the bot on")
+}
+
+// botServer answers questions from the index of the output directory,
+// rebuilding it when the manifest changes.
+type botServer struct {
+	cfg         BotConfig
+	opts        *CrawlOptions
+	slackSecret string
+	slackToken  string
+	discordKey  ed25519.PublicKey
+
+	mu      sync.Mutex
+	index   *botIndex
+	indexed time.Time
+}
+
+// searchIndex returns the index, rebuilding it if the manifest changed.
+func (b *botServer) searchIndex() (*botIndex, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	info, err := os.Stat(filepath.Join(b.opts.Output, manifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	if b.index != nil && info.ModTime().Equal(b.indexed) {
+		return b.index, nil
+	}
+	m, err := loadManifest(b.opts.Output)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := datasetRows(b.opts, m, true)
+	if err != nil {
+		return nil, err
+	}
+	b.index, b.indexed = newBotIndex(rows), info.ModTime()
+	return b.index, nil
+}
+
+// answer searches for question and returns the hits to reply with.
+func (b *botServer) answer(question string) ([]botHit, error) {
+	index, err := b.searchIndex()
+	if err != nil {
+		return nil, err
+	}
+	results := b.cfg.Results
+	if results <= 0 {
+		results = defaultBotResults
+	}
+	return index.search(question, results), nil
+}
+
+// excerptChars returns the most characters of a section quoted in a reply.
+func (b *botServer) excerptChars() int {
+	if b.cfg.ExcerptChars > 0 {
+		return b.cfg.ExcerptChars
+	}
+	return defaultBotExcerptChars
+}
+
+// botIndex ranks the sections of the pages by BM25.
+type botIndex struct {
+	docs   []botDoc
+	df     map[string]int
+	avgLen float64
+}
+
+type botDoc struct {
+	row    datasetRow
+	terms  map[string]int
+	length int
+}
+
+// botHit is a section matching a question.
+type botHit struct {
+	Title   string  `json:"title"`
+	Section string  `json:"section,omitempty"`
+	URL     string  `json:"url"`
+	Path    string  `json:"path"`
+	Score   float64 `json:"score"`
+	text    string
+}
+
+// newBotIndex indexes rows. Title and section words count twice, so a
+// section about a topic outranks one that mentions it.
+func newBotIndex(rows []datasetRow) *botIndex {
+	x := &botIndex{df: map[string]int{}}
+	total := 0
+	for _, row := range rows {
+		doc := botDoc{row: row, terms: map[string]int{}}
+		heading := keywordTerms(row.Title + "\n" + row.Section)
+		for _, terms := range [][]string{keywordTerms(row.Text), heading, heading} {
+			for _, t := range terms {
+				doc.terms[t]++
+				doc.length++
+			}
+		}
+		for t := range doc.terms {
+			x.df[t]++
+		}
+		total += doc.length
+		x.docs = append(x.docs, doc)
+	}
+	if len(x.docs) > 0 {
+		x.avgLen = float64(total) / float64(len(x.docs))
+	}
+	return x
+}
+
+// search returns the n best sections for query, at most one per page.
+func (x *botIndex) search(query string, n int) []botHit {
+	const k1, b = 1.2, 0.75
+	terms := keywordTerms(query)
+	var hits []botHit
+	for _, doc := range x.docs {
+		score := 0.0
+		for _, t := range terms {
+			tf := float64(doc.terms[t])
+			if tf == 0 {
+				continue
+			}
+			df := float64(x.df[t])
+			idf := math.Log(1 + (float64(len(x.docs))-df+0.5)/(df+0.5))
+			score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(doc.length)/x.avgLen))
+		}
+		if score > 0 {
+			hits = append(hits, botHit{Title: doc.row.Title, Section: doc.row.Section, URL: doc.row.URL, Path: doc.row.Path, Score: score, text: doc.row.Text})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	var best []botHit
+	seen := map[string]bool{}
+	for _, hit := range hits {
+		if len(best) == n {
+			break
+		}
+		if !seen[hit.Path] {
+			seen[hit.Path] = true
+			best = append(best, hit)
+		}
+	}
+	return best
+}
+
+// heading returns the page title and section of a hit.
+func (h botHit) heading() string {
+	if h.Section == "" {
+		return h.Title
+	}
+	return h.Title + " › " + h.Section
+}
+
+// excerpt returns the start of text, cut at a paragraph or line break
+// (or failing that a space) before limit characters, with open code
+// fences closed.
+func excerpt(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := text[:limit]
+	if i := strings.LastIndex(cut, "\n\n"); i > limit/2 {
+		cut = cut[:i]
+	} else if i := strings.LastIndex(cut, "\n"); i > limit/2 {
+		cut = cut[:i]
+	} else if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	cut = strings.TrimSpace(cut) + " …"
+	fences := 0
+	for _, line := range strings.Split(cut, "\n") {
+		if isFence(line) {
+			fences++
+		}
+	}
+	if fences%2 == 1 {
+		cut += "\n```"
+	}
+	return cut
+}
+
+// noAnswer is the reply when nothing matches.
+const noAnswer = "I could not find anything about that in the docs."
+
+// botMarkdownLinkRe matches markdown links and images.
+var botMarkdownLinkRe = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+
+// slackText converts markdown to Slack mrkdwn: escaped, with links in
+// Slack's form (relative ones as plain text), bold with single asterisks,
+// and headings in bold.
+func slackText(s string) string {
+	s = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+	s = botMarkdownLinkRe.ReplaceAllStringFunc(s, func(link string) string {
+		m := botMarkdownLinkRe.FindStringSubmatch(link)
+		if u, err := url.Parse(m[2]); err == nil && u.IsAbs() {
+			return "<" + m[2] + "|" + m[1] + ">"
+		}
+		return m[1]
+	})
+	s = strings.ReplaceAll(s, "**", "*")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if _, heading, ok := atxHeading(line); ok {
+			lines[i] = "*" + heading + "*"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slackReply formats hits as a Slack message.
+func (b *botServer) slackReply(hits []botHit) string {
+	if len(hits) == 0 {
+		return noAnswer
+	}
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "|", "¦").Replace
+	var reply strings.Builder
+	fmt.Fprintf(&reply, "*<%s|%s>*\n%s", hits[0].URL, escape(hits[0].heading()), slackText(excerpt(hits[0].text, b.excerptChars())))
+	if len(hits) > 1 {
+		reply.WriteString("\n\nAlso see: ")
+		for i, hit := range hits[1:] {
+			if i > 0 {
+				reply.WriteString(", ")
+			}
+			fmt.Fprintf(&reply, "<%s|%s>", hit.URL, escape(hit.heading()))
+		}
+	}
+	return reply.String()
+}
+
+// discordReply formats hits as a Discord message, within its length
+// limit. Links are in angle brackets so Discord does not embed them.
+func (b *botServer) discordReply(hits []botHit) string {
+	if len(hits) == 0 {
+		return noAnswer
+	}
+	var more strings.Builder
+	if len(hits) > 1 {
+		more.WriteString("\n\nAlso see: ")
+		for i, hit := range hits[1:] {
+			if i > 0 {
+				more.WriteString(", ")
+			}
+			fmt.Fprintf(&more, "[%s](<%s>)", hit.heading(), hit.URL)
+		}
+	}
+	head := fmt.Sprintf("**%s**\n", hits[0].heading())
+	source := fmt.Sprintf("\nSource: <%s>", hits[0].URL)
+	limit := min(b.excerptChars(), discordMaxChars-len(head)-len(source)-more.Len()-8)
+	return head + excerpt(hits[0].text, max(limit, 0)) + source + more.String()
+}
+
+// readBody reads a request body of at most botMaxBody bytes.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, botMaxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
+// verifySlack checks a request's Slack signature: an HMAC-SHA256 of its
+// timestamp and body keyed with the signing secret.
+func (b *botServer) verifySlack(r *http.Request, body []byte) bool {
+	ts := r.Header.Get("X-Slack-Request-Timestamp")
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || time.Since(time.Unix(secs, 0)).Abs() > slackMaxAge {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(b.slackSecret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(want), []byte(r.Header.Get("X-Slack-Signature")))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// handleSlackCommand answers a slash command in the channel.
+func (b *botServer) handleSlackCommand(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if !b.verifySlack(r, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	question := strings.TrimSpace(form.Get("text"))
+	if question == "" {
+		writeJSON(w, map[string]string{"response_type": "ephemeral", "text": "Ask a question, such as `" + form.Get("command") + " how do I install`."})
+		return
+	}
+	hits, err := b.answer(question)
+	if err != nil {
+		fmt.Printf("Error searching for %q: %v\n", question, err)
+		http.Error(w, "search failed", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]string{"response_type": "in_channel", "text": b.slackReply(hits)})
+}
+
+// slackMentionRe matches user mentions in Slack messages.
+var slackMentionRe = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+// handleSlackEvent answers @mentions in their thread, posting the reply
+// after acknowledging the event as Slack requires.
+func (b *botServer) handleSlackEvent(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if !b.verifySlack(r, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var payload struct {
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+		Event     struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			Channel  string `json:"channel"`
+			TS       string `json:"ts"`
+			ThreadTS string `json:"thread_ts"`
+			BotID    string `json:"bot_id"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if payload.Type == "url_verification" {
+		writeJSON(w, map[string]string{"challenge": payload.Challenge})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	// Slack retries events it thinks were not delivered; they were.
+	event := payload.Event
+	if r.Header.Get("X-Slack-Retry-Num") != "" || event.Type != "app_mention" || event.BotID != "" || b.slackToken == "" {
+		return
+	}
+	question := strings.TrimSpace(slackMentionRe.ReplaceAllString(event.Text, ""))
+	thread := event.ThreadTS
+	if thread == "" {
+		thread = event.TS
+	}
+	go func() {
+		hits, err := b.answer(question)
+		if err != nil {
+			fmt.Printf("Error searching for %q: %v\n", question, err)
+			return
+		}
+		header := http.Header{}
+		header.Set("Authorization", "Bearer "+b.slackToken)
+		var resp struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		message := map[string]string{"channel": event.Channel, "thread_ts": thread, "text": b.slackReply(hits)}
+		if err := newAPIClient(header).postJSON(slackPostMessageURL, message, &resp); err != nil {
+			fmt.Printf("Error replying in Slack: %v\n", err)
+		} else if !resp.OK {
+			fmt.Printf("Error replying in Slack: %s\n", resp.Error)
+		}
+	}()
+}
+
+// Discord interaction and response types.
+const (
+	discordPing           = 1
+	discordCommand        = 2
+	discordPong           = 1
+	discordChannelMessage = 4
+)
+
+// handleDiscord answers a slash command given the question as its first
+// string option.
+func (b *botServer) handleDiscord(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	sig, err := hex.DecodeString(r.Header.Get("X-Signature-Ed25519"))
+	if err != nil || !ed25519.Verify(b.discordKey, append([]byte(r.Header.Get("X-Signature-Timestamp")), body...), sig) {
+		http.Error(w, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+	var interaction struct {
+		Type int `json:"type"`
+		Data struct {
+			Options []struct {
+				Value interface{} `json:"value"`
+			} `json:"options"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch interaction.Type {
+	case discordPing:
+		writeJSON(w, map[string]int{"type": discordPong})
+	case discordCommand:
+		question := ""
+		for _, option := range interaction.Data.Options {
+			if s, ok := option.Value.(string); ok {
+				question = s
+				break
+			}
+		}
+		hits, err := b.answer(question)
+		if err != nil {
+			fmt.Printf("Error searching for %q: %v\n", question, err)
+			http.Error(w, "search failed", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"type": discordChannelMessage, "data": map[string]string{"content": b.discordReply(hits)}})
+	default:
+		http.Error(w, "unsupported interaction", http.StatusBadRequest)
+	}
+}
+
+// handleSearch returns the matches for q as JSON, each with its excerpt.
+func (b *botServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	hits, err := b.answer(r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	type result struct {
+		botHit
+		Excerpt string `json:"excerpt"`
+	}
+	results := make([]result, len(hits))
+	for i, hit := range hits {
+		results[i] = result{hit, excerpt(hit.text, b.excerptChars())}
+	}
+	writeJSON(w, results)
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// Browser fetcher defaults.
+const (
+	defaultBrowserTimeout   = 60 * time.Second
+	defaultScreenshotWidth  = 1280
+	defaultScreenshotHeight = 10000
+	defaultScreenshotQual   = 80
+)
+
+// browserFetcher renders pages in headless Chrome, so sites that build
+// their content with JavaScript convert like static ones. It can also
+// capture a full-page screenshot of each page.
+type browserFetcher struct {
+	cfg     BrowserConfig
+	shots   *sync.Map
+	ctx     context.Context
+	cancels []context.CancelFunc
+}
+
+// newBrowserFetcher starts a headless Chrome for the crawl, connecting to
+// the hosts in overrides at their fixed addresses. Screenshots are kept in
+// shots, by URL, when it is not nil.
+func newBrowserFetcher(cfg BrowserConfig, overrides []hostOverride, shots *sync.Map) (*browserFetcher, error) {
+	if cfg.Screenshots.Width <= 0 {
+		cfg.Screenshots.Width = defaultScreenshotWidth
+	}
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.UserAgent(userAgent()),
+		chromedp.WindowSize(cfg.Screenshots.Width, 900),
+	)
+	if cfg.ExecPath != "" {
+		opts = append(opts, chromedp.ExecPath(cfg.ExecPath))
+	}
+	if len(overrides) > 0 {
+		opts = append(opts, chromedp.Flag("host-resolver-rules", browserHostRules(overrides)))
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	ctx, cancelBrowser := chromedp.NewContext(allocCtx)
+	f := &browserFetcher{cfg: cfg, shots: shots, ctx: ctx, cancels: []context.CancelFunc{cancelBrowser, cancelAlloc}}
+	if err := chromedp.Run(ctx); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("starting browser: %w", err)
+	}
+	return f, nil
+}
+
+// Close shuts the browser down.
+func (f *browserFetcher) Close() error {
+	for _, cancel := range f.cancels {
+		cancel()
+	}
+	return nil
+}
+
+func (f *browserFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("browser fetcher cannot send %s requests", req.Method)
+	}
+
+	timeout := defaultBrowserTimeout
+	if d, err := time.ParseDuration(f.cfg.Timeout); err == nil && d > 0 {
+		timeout = d
+	}
+	tab, cancelTab := chromedp.NewContext(f.ctx)
+	defer cancelTab()
+	tab, cancelTimeout := context.WithTimeout(tab, timeout)
+	defer cancelTimeout()
+
+	// The first document response is the page itself.
+	var mu sync.Mutex
+	var doc *network.Response
+	chromedp.ListenTarget(tab, func(ev interface{}) {
+		if e, ok := ev.(*network.EventResponseReceived); ok && e.Type == network.ResourceTypeDocument {
+			mu.Lock()
+			if doc == nil {
+				doc = e.Response
+			}
+			mu.Unlock()
+		}
+	})
+
+	var html string
+	var shot []byte
+	tasks := chromedp.Tasks{
+		network.Enable(),
+		chromedp.Navigate(req.URL.String()),
+	}
+	if f.cfg.WaitFor != "" {
+		tasks = append(tasks, chromedp.WaitVisible(f.cfg.WaitFor, chromedp.ByQuery))
+	}
+	tasks = append(tasks, chromedp.OuterHTML("html", &html, chromedp.ByQuery))
+	if f.cfg.Screenshots.Enabled {
+		tasks = append(tasks, f.screenshot(&shot))
+	}
+	if err := chromedp.Run(tab, tasks); err != nil {
+		return nil, err
+	}
+
+	status := http.StatusOK
+	header := http.Header{}
+	mu.Lock()
+	if doc != nil {
+		status = int(doc.Status)
+		for k, v := range doc.Headers {
+			header.Set(k, fmt.Sprint(v))
+		}
+	}
+	mu.Unlock()
+	// The DOM is serialized back as UTF-8 HTML whatever the original type.
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+
+	if shot != nil && f.shots != nil {
+		f.shots.Store(req.URL.String(), screenshot{data: shot, ext: screenshotExt(f.cfg.Screenshots.Format)})
+	}
+	resp := localResponse(req, status, []byte(html), "", time.Time{})
+	for k, v := range header {
+		resp.Header[k] = v
+	}
+	return resp, nil
+}
+
+// screenshot captures the whole page, up to the configured height, in the
+// configured format.
+func (f *browserFetcher) screenshot(res *[]byte) chromedp.Action {
+	cfg := f.cfg.Screenshots
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		_, _, _, _, _, size, err := page.GetLayoutMetrics().Do(ctx)
+		if err != nil {
+			return err
+		}
+		maxHeight := cfg.MaxHeight
+		if maxHeight <= 0 {
+			maxHeight = defaultScreenshotHeight
+		}
+		quality := cfg.Quality
+		if quality <= 0 {
+			quality = defaultScreenshotQual
+		}
+
+		format := page.CaptureScreenshotFormatPng
+		switch cfg.Format {
+		case "webp":
+			format = page.CaptureScreenshotFormatWebp
+		case "jpeg":
+			format = page.CaptureScreenshotFormatJpeg
+		}
+		capture := page.CaptureScreenshot().
+			WithFormat(format).
+			WithCaptureBeyondViewport(true).
+			WithFromSurface(true).
+			WithClip(&page.Viewport{
+				Width:  math.Ceil(size.Width),
+				Height: math.Min(math.Ceil(size.Height), float64(maxHeight)),
+				Scale:  1,
+			})
+		if format != page.CaptureScreenshotFormatPng {
+			capture = capture.WithQuality(int64(quality))
+		}
+		*res, err = capture.Do(ctx)
+		return err
+	})
+}
+
+// screenshot is an encoded page image waiting to be saved.
+type screenshot struct {
+	data []byte
+	ext  string
+}
+
+func screenshotExt(format string) string {
+	switch format {
+	case "webp", "jpeg":
+		return format
+	default:
+		return "png"
+	}
+}
+
+// saveScreenshot writes the screenshot captured for link next to mdPath
+// and returns the markdown image line referencing it, or "" if there is
+// none.
+func saveScreenshot(shots *sync.Map, link, mdPath, title string, renamed bool) string {
+	v, ok := shots.LoadAndDelete(link)
+	if !ok {
+		return ""
+	}
+	shot := v.(screenshot)
+	base := strings.TrimSuffix(filepath.Base(mdPath), filepath.Ext(mdPath))
+	if renamed {
+		base = "screenshot"
+	}
+	name := base + "." + shot.ext
+	if err := os.WriteFile(filepath.Join(filepath.Dir(mdPath), name), shot.data, 0644); err != nil {
+		fmt.Printf("Error writing screenshot %s: %v\n", name, err)
+		return ""
+	}
+	return fmt.Sprintf("![Screenshot of %s](%s)\n\n", title, name)
+}
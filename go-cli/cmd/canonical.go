@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// canonicalResponse returns r as if it had been fetched from the page's
+// canonical URL, so tracking-parameter and alias variants of a page are
+// saved to one file under one manifest entry. It returns r unchanged when
+// canonical naming is off or the page names no canonical URL on its site.
+// Links are still resolved against the fetched URL, since r itself is not
+// changed.
+func canonicalResponse(r *colly.Response, doc *goquery.Document, opts *CrawlOptions) *colly.Response {
+	if !opts.Canonical {
+		return r
+	}
+	canonical := canonicalURL(doc, r.Request.URL)
+	if canonical == nil {
+		return r
+	}
+	// A section saved on its own stays a section of the canonical page.
+	canonical.Fragment = r.Request.URL.Fragment
+	if canonical.String() == r.Request.URL.String() {
+		return r
+	}
+	req := *r.Request
+	req.URL = canonical
+	resp := *r
+	resp.Request = &req
+	return &resp
+}
+
+// canonicalURL returns the page's <link rel="canonical"> URL when it is on
+// the same site as fetched, ignoring a "www." prefix.
+func canonicalURL(doc *goquery.Document, fetched *url.URL) *url.URL {
+	href, ok := doc.Find(`link[rel="canonical"]`).First().Attr("href")
+	if !ok || strings.TrimSpace(href) == "" {
+		return nil
+	}
+	canonical, err := fetched.Parse(strings.TrimSpace(href))
+	if err != nil || canonical.Scheme != "http" && canonical.Scheme != "https" {
+		return nil
+	}
+	site := func(u *url.URL) string {
+		return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	if site(canonical) != site(fetched) {
+		return nil
+	}
+	canonical.Fragment = ""
+	return canonical
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gobwas/glob"
+)
+
+// categoryRule is a compiled category rule.
+type categoryRule struct {
+	name     string
+	globs    []glob.Glob
+	keywords []string
+}
+
+// compileCategories compiles the URL globs of every category rule.
+func compileCategories(rules []CategoryRule) ([]categoryRule, error) {
+	var compiled []categoryRule
+	for _, r := range rules {
+		if strings.TrimSpace(r.Name) == "" {
+			return nil, fmt.Errorf("category rule without a name")
+		}
+		cr := categoryRule{name: strings.TrimSpace(r.Name)}
+		for _, m := range r.Match {
+			g, err := glob.Compile(m)
+			if err != nil {
+				return nil, fmt.Errorf("invalid category glob %s: %w", m, err)
+			}
+			cr.globs = append(cr.globs, g)
+		}
+		for _, k := range r.Keywords {
+			if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+				cr.keywords = append(cr.keywords, k)
+			}
+		}
+		compiled = append(compiled, cr)
+	}
+	return compiled, nil
+}
+
+// matches reports whether the page is in the rule's category: its URL
+// matches one of the globs or its title, description, or text contains
+// one of the keywords.
+func (r categoryRule) matches(link, text string) bool {
+	for _, g := range r.globs {
+		if g.Match(link) {
+			return true
+		}
+	}
+	for _, k := range r.keywords {
+		if containsWord(text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// categorize returns the categories of a converted page in the order
+// rules are declared. Several rules may name the same category.
+func categorize(p convertedPage, rules []categoryRule) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+	text := strings.ToLower(p.Title + "\n" + p.Description + "\n" + p.Body)
+	var categories []string
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if !seen[r.name] && r.matches(p.URL, text) {
+			seen[r.name] = true
+			categories = append(categories, r.name)
+		}
+	}
+	return categories
+}
+
+// containsWord reports whether text contains word where it is not part
+// of a longer word.
+func containsWord(text, word string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// categoryPath returns where the markdown for a page in categories is
+// written: under a directory named after its first category when
+// opts.CategoryDirs is set, and at mdPath otherwise.
+func categoryPath(opts *CrawlOptions, mdPath string, categories []string) string {
+	if !opts.CategoryDirs || len(categories) == 0 {
+		return mdPath
+	}
+	dir := sanitizeName(categories[0])
+	if dir == "" {
+		return mdPath
+	}
+	rel, err := filepath.Rel(opts.Output, mdPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return mdPath
+	}
+	return filepath.Join(opts.Output, dir, rel)
+}
@@ -0,0 +1,332 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the change_summary config section.
+const (
+	defaultChangeSummaryFile = "changes.md"
+	changelogFileName        = "CHANGELOG.md"
+)
+
+// llmChangeChars caps how much of the changes of one skill is sent to the
+// LLM, and llmPageDiffChars how much of one page's diff.
+const (
+	llmChangeChars   = 8000 * charsPerToken
+	llmPageDiffChars = 500 * charsPerToken
+)
+
+// Kinds of page change.
+const (
+	pageAdded   = "added"
+	pageUpdated = "updated"
+	pageRemoved = "removed"
+)
+
+// pageChange is a page a crawl or replay added, updated, or removed.
+// Sections lists what changed in an updated page's sections, and Diff is
+// the start of its diff, kept for the LLM.
+type pageChange struct {
+	Kind     string
+	URL      string
+	Title    string
+	Path     string
+	Sections []string
+	Diff     string
+}
+
+// newPageChange describes the change from before to after of the page at
+// url. Diff is only kept when keepDiff is set.
+func newPageChange(kind, url, title, path, before, after string, keepDiff bool) pageChange {
+	c := pageChange{Kind: kind, URL: url, Title: title, Path: path}
+	if kind != pageUpdated {
+		return c
+	}
+	c.Sections = sectionChanges(before, after)
+	if keepDiff {
+		_, a := splitFrontmatter(before)
+		_, b := splitFrontmatter(after)
+		diff := unifiedDiff("a/"+path, "b/"+path, a, b)
+		if len(diff) > llmPageDiffChars {
+			diff = diff[:llmPageDiffChars] + "\n[...]\n"
+		}
+		c.Diff = diff
+	}
+	return c
+}
+
+// changeSet collects the page changes of one crawl.
+type changeSet struct {
+	mu       sync.Mutex
+	keepDiff bool
+	pages    []pageChange
+}
+
+// newChangeSet returns a collector for cfg, or nil when summaries are off.
+func newChangeSet(cfg ChangesConfig) *changeSet {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &changeSet{keepDiff: cfg.Method == "llm"}
+}
+
+// record notes a change to a page, unless only its frontmatter changed.
+// It does nothing on a nil changeSet.
+func (s *changeSet) record(kind, url, title, path, before, after string) {
+	if s == nil {
+		return
+	}
+	if kind == pageUpdated {
+		_, a := splitFrontmatter(before)
+		_, b := splitFrontmatter(after)
+		if a == b {
+			return
+		}
+	}
+	c := newPageChange(kind, url, title, path, before, after, s.keepDiff)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages = append(s.pages, c)
+}
+
+// list returns the recorded changes.
+func (s *changeSet) list() []pageChange {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]pageChange(nil), s.pages...)
+}
+
+// sectionChanges lists the sections of a page, by heading, that were
+// added, removed, or changed between before and after.
+func sectionChanges(before, after string) []string {
+	_, a := splitFrontmatter(before)
+	_, b := splitFrontmatter(after)
+	old, oldOrder := markdownSections(a)
+	cur, curOrder := markdownSections(b)
+	var out []string
+	for _, heading := range curOrder {
+		if body, ok := old[heading]; !ok {
+			out = append(out, "added "+quoteHeading(heading))
+		} else if body != cur[heading] {
+			out = append(out, "changed "+quoteHeading(heading))
+		}
+	}
+	for _, heading := range oldOrder {
+		if _, ok := cur[heading]; !ok {
+			out = append(out, "removed "+quoteHeading(heading))
+		}
+	}
+	return out
+}
+
+// markdownSections splits markdown at its H2 to H6 headings outside code
+// blocks and returns each section's text by heading, with the headings
+// in order. Text before the first of them is not a section.
+func markdownSections(text string) (map[string]string, []string) {
+	sections := map[string]string{}
+	var order []string
+	heading := ""
+	var body strings.Builder
+	flush := func() {
+		if heading != "" {
+			sections[heading] = body.String()
+		}
+		body.Reset()
+	}
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+		}
+		if level, title, ok := atxHeading(line); ok && !inFence && level >= 2 && title != "" {
+			flush()
+			heading = title
+			if _, dup := sections[heading]; !dup {
+				order = append(order, heading)
+			}
+			continue
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	flush()
+	return sections, order
+}
+
+// quoteHeading quotes a section heading for a summary.
+func quoteHeading(heading string) string {
+	return "“" + heading + "”"
+}
+
+// changeList describes a skill's changes as a markdown list, one page per
+// line.
+func changeList(changes []pageChange) string {
+	var b strings.Builder
+	for _, c := range changes {
+		title := c.Title
+		if title == "" {
+			title = c.Path
+		}
+		switch c.Kind {
+		case pageAdded:
+			fmt.Fprintf(&b, "- Added %s (`%s`)\n", title, c.Path)
+		case pageRemoved:
+			fmt.Fprintf(&b, "- Removed %s (`%s`)\n", title, c.Path)
+		default:
+			if len(c.Sections) > 0 {
+				fmt.Fprintf(&b, "- Updated %s: %s\n", title, strings.Join(c.Sections, ", "))
+			} else {
+				fmt.Fprintf(&b, "- Updated %s\n", title)
+			}
+		}
+	}
+	return b.String()
+}
+
+// summarizeSkill asks the LLM for a short prose summary of a skill's
+// changes.
+func summarizeSkill(llm *llmClient, skill string, changes []pageChange) (string, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Skill: %s\n\nPages changed:\n%s", skill, changeList(changes))
+	for _, c := range changes {
+		if c.Diff == "" || prompt.Len()+len(c.Diff) > llmChangeChars {
+			continue
+		}
+		prompt.WriteString("\n")
+		prompt.WriteString(c.Diff)
+	}
+	return llm.complete(
+		"You write release notes for a documentation set. Given the pages added, updated, and removed and excerpts of the diffs, summarize what changed for a reader in one to three short plain sentences. Name topics, not files, and reply with the summary only.",
+		prompt.String(),
+	)
+}
+
+// skillSummary is the summary of the changes to one skill.
+type skillSummary struct {
+	skill string
+	text  string
+}
+
+// summarizeChanges groups changes by skill and summarizes each skill's,
+// with prose from the LLM above the list of pages when cfg.Method is
+// "llm". Skills are in order.
+func summarizeChanges(opts *CrawlOptions, cfg ChangesConfig, llmCfg LLMConfig, changes []pageChange) []skillSummary {
+	if len(changes) == 0 {
+		return nil
+	}
+	var llm *llmClient
+	if cfg.Method == "llm" {
+		var err error
+		if llm, err = newLLMClient(llmCfg); err != nil {
+			fmt.Printf("Warning: could not configure LLM for change summaries, listing changes instead: %v\n", err)
+		}
+	}
+
+	// Several URLs can be saved to one file; list it once.
+	bySkill := map[string][]pageChange{}
+	listed := map[string]bool{}
+	for _, c := range changes {
+		if listed[c.Kind+" "+c.Path] {
+			continue
+		}
+		listed[c.Kind+" "+c.Path] = true
+		skill := opts.skillDirOf(&ManifestEntry{Path: c.Path})
+		bySkill[skill] = append(bySkill[skill], c)
+	}
+	var summaries []skillSummary
+	for _, skill := range sortedKeys(bySkill) {
+		list := bySkill[skill]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+		text := changeList(list)
+		if llm != nil {
+			prose, err := summarizeSkill(llm, skill, list)
+			if err != nil {
+				fmt.Printf("Warning: could not summarize changes to %s: %v\n", skill, err)
+			} else if prose != "" {
+				text = prose + "\n\n" + text
+			}
+		}
+		summaries = append(summaries, skillSummary{skill, text})
+	}
+	return summaries
+}
+
+// printChangeSummaries prints the summary of each skill.
+func printChangeSummaries(summaries []skillSummary) {
+	for _, s := range summaries {
+		fmt.Printf("Changes in %s:\n%s", s.skill, s.text)
+	}
+}
+
+// writeChangeSummaries writes the summaries to the summary file in the
+// output directory, which is removed when nothing changed. With
+// cfg.Changelog each summary is also added to the top of its skill's
+// CHANGELOG.md.
+func writeChangeSummaries(opts *CrawlOptions, m *Manifest, cfg ChangesConfig, summaries []skillSummary) {
+	file := cfg.File
+	if file == "" {
+		file = defaultChangeSummaryFile
+	}
+	summaryPath := filepath.Join(opts.Output, filepath.FromSlash(file))
+	if len(summaries) == 0 {
+		os.Remove(summaryPath)
+		return
+	}
+
+	date := time.Now().UTC().Format(time.DateOnly)
+	var all strings.Builder
+	fmt.Fprintf(&all, "# Changes on %s\n", date)
+	for _, s := range summaries {
+		fmt.Fprintf(&all, "\n## %s\n\n%s", s.skill, s.text)
+		if !cfg.Changelog {
+			continue
+		}
+		rel := filepath.ToSlash(filepath.Join(s.skill, changelogFileName))
+		path := filepath.Join(opts.Output, filepath.FromSlash(rel))
+		old, _ := os.ReadFile(path)
+		earlier := strings.TrimLeft(strings.TrimPrefix(string(old), "# Changelog\n"), "\n")
+		content := "# Changelog\n\n## " + date + "\n\n" + s.text
+		if earlier != "" {
+			content += "\n" + earlier
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fmt.Printf("Warning: could not write %s: %v\n", rel, err)
+		} else if err := writeFileIfChanged(path, []byte(content)); err != nil {
+			fmt.Printf("Warning: could not write %s: %v\n", rel, err)
+		} else {
+			m.recordGenerated(rel, content)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(summaryPath), 0755); err != nil {
+		fmt.Printf("Warning: could not write %s: %v\n", file, err)
+		return
+	}
+	if err := os.WriteFile(summaryPath, []byte(all.String()), 0644); err != nil {
+		fmt.Printf("Warning: could not write %s: %v\n", file, err)
+	}
+}
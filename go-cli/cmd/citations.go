@@ -0,0 +1,190 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Citation locates a section of a page's markdown file, so answers built
+// from it can cite the section on the source page. Sections are the text
+// before the first H2 heading and each H2 heading up to the next, as in
+// export --rows section. Start and End are byte offsets into the file and
+// Line and EndLine its first and last lines, counting from 1. Anchor is
+// the id of the heading on the source page, when it has one, and URL the
+// source URL with it as the fragment.
+type Citation struct {
+	Heading string `json:"heading,omitempty"`
+	Anchor  string `json:"anchor,omitempty"`
+	URL     string `json:"url"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Line    int    `json:"line"`
+	EndLine int    `json:"end_line"`
+}
+
+// headingID is the anchor of a heading on the source page.
+type headingID struct {
+	text string
+	id   string
+}
+
+// headingIDs returns the anchor of each heading in doc that has one: its
+// own id, the id or name of an anchor in or just before it, the target of
+// a permalink in it, or the id of a section it starts.
+func headingIDs(doc *goquery.Document) []headingID {
+	var ids []headingID
+	doc.Find(headingSelectors[6]).Each(func(_ int, h *goquery.Selection) {
+		id := h.AttrOr("id", "")
+		if id == "" {
+			h.Find("a[id], a[name]").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+				id = a.AttrOr("id", a.AttrOr("name", ""))
+				return id == ""
+			})
+		}
+		if prev := h.Prev(); id == "" && goquery.NodeName(prev) == "a" && strings.TrimSpace(prev.Text()) == "" {
+			id = prev.AttrOr("id", prev.AttrOr("name", ""))
+		}
+		if id == "" {
+			h.Find(`a[href^="#"]`).EachWithBreak(func(_ int, a *goquery.Selection) bool {
+				id = strings.TrimPrefix(a.AttrOr("href", ""), "#")
+				return id == ""
+			})
+		}
+		if parent := h.Parent(); id == "" && h.Prev().Length() == 0 && parent.Is("section, div") {
+			id = parent.AttrOr("id", "")
+		}
+		if id != "" {
+			ids = append(ids, headingID{text: squash(h.Text()), id: id})
+		}
+	})
+	return ids
+}
+
+// markdownAnchors returns the GitHub-style anchors of the headings in a
+// markdown body, as forges render them.
+func markdownAnchors(body string) []headingID {
+	var ids []headingID
+	anchors := headingAnchors{}
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if _, text, ok := atxHeading(line); ok && !inFence {
+			ids = append(ids, headingID{text: text, id: anchors.next(text)})
+		}
+	}
+	return ids
+}
+
+// linkTargetRe matches the target of a markdown link, "](...)".
+var linkTargetRe = regexp.MustCompile(`\]\([^)]*\)`)
+
+// headingKey reduces a heading to its lowercase letters and digits, so a
+// markdown heading matches the HTML heading it was converted from,
+// whatever the permalinks and formatting.
+func headingKey(heading string) string {
+	heading = linkTargetRe.ReplaceAllString(heading, "]")
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pageCitations returns the citations of the sections of a markdown file
+// converted from the page at pageURL, taking each heading's anchor from
+// ids, which are matched in order.
+func pageCitations(content, pageURL string, ids []headingID) []Citation {
+	base := stripFragment(pageURL)
+	fm, _ := splitFrontmatter(content)
+	var cites []Citation
+	current := Citation{URL: base, Start: len(fm)}
+	next := 0
+	flush := func(end int) {
+		text := strings.TrimRight(content[current.Start:end], " \t\n")
+		lead := len(text) - len(strings.TrimLeft(text, " \t\n"))
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		current.Start += lead
+		current.End = current.Start + len(text) - lead
+		current.Line = strings.Count(content[:current.Start], "\n") + 1
+		current.EndLine = current.Line + strings.Count(content[current.Start:current.End], "\n")
+		cites = append(cites, current)
+	}
+
+	offset := len(fm)
+	inFence := false
+	for _, line := range strings.SplitAfter(content[len(fm):], "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		if isFence(trimmed) {
+			inFence = !inFence
+		} else if level, heading, ok := atxHeading(trimmed); ok && level == 2 && !inFence {
+			flush(offset)
+			current = Citation{Heading: heading, URL: base, Start: offset}
+			key := headingKey(heading)
+			for i := next; i < len(ids); i++ {
+				if headingKey(ids[i].text) == key {
+					current.Anchor = ids[i].id
+					current.URL = base + "#" + ids[i].id
+					next = i + 1
+					break
+				}
+			}
+		}
+		offset += len(line)
+	}
+	flush(len(content))
+	return cites
+}
+
+// refreshCitations updates the citations of every page whose file was
+// rewritten after conversion, such as by boilerplate removal, keeping the
+// anchors found when it was converted. Inlined pages have none.
+func refreshCitations(opts *CrawlOptions, m *Manifest) {
+	for _, entry := range m.Pages {
+		var cites []Citation
+		if entry.InlinedIn == "" {
+			data, err := readOutputFile(opts.pageFile(entry))
+			if err != nil {
+				continue
+			}
+			var ids []headingID
+			for _, c := range entry.Citations {
+				if c.Anchor != "" {
+					ids = append(ids, headingID{text: c.Heading, id: c.Anchor})
+				}
+			}
+			cites = pageCitations(string(data), entry.URL, ids)
+		}
+		if slices.Equal(cites, entry.Citations) {
+			continue
+		}
+		m.mu.Lock()
+		entry.Citations = cites
+		m.touch(entry.URL)
+		m.mu.Unlock()
+	}
+}
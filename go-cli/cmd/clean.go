@@ -15,36 +15,269 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// cleanDryRun lists what clean would remove without removing it.
+// cleanYes skips the confirmation prompt.
+// cleanForce removes an output directory that has no manifest.
+// cleanDomain, cleanSkill, and cleanOlderThan limit clean to some pages.
+var (
+	cleanDryRun    bool
+	cleanYes       bool
+	cleanForce     bool
+	cleanDomain    string
+	cleanSkill     string
+	cleanOlderThan string
+)
+
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "Clean the output directory",
-	Long:  `Removes the output directory (default: .skillscache) and all its contents.`,
+	Long: `Removes the output directory (default: .skillscache) and all its contents,
+after asking for confirmation (--yes skips it).
+
+With --domain, --skill, or --older-than only the matching pages are removed,
+along with their manifest entries; --skill also removes the skill's
+generated files. Filters combine, so --domain example.com --older-than 30d
+removes pages from example.com crawled more than 30 days ago.
+
+A directory without a manifest.json is only removed with --force, and the
+root, home, and working directories are never removed.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Check viper for output dir override if flag not changed
-		if !cmd.Flags().Changed("output") && viper.IsSet("output") {
-			outputDir = viper.GetString("output")
+		outputDir := configuredOutput()
+		if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+			fmt.Printf("Nothing to clean: %s does not exist\n", outputDir)
+			return
 		}
 
-		fmt.Printf("Cleaning output directory: %s\n", outputDir)
-		err := os.RemoveAll(outputDir)
+		var err error
+		if cleanDomain != "" || cleanSkill != "" || cleanOlderThan != "" {
+			err = cleanPages(outputDir)
+		} else {
+			err = cleanAll(outputDir)
+		}
 		if err != nil {
 			fmt.Printf("Error cleaning directory: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Clean complete.")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(cleanCmd)
-	// Reuse outputDir variable from crawl.go since it's in the same package
-	cleanCmd.Flags().StringVar(&outputDir, "output", ".skillscache", "output directory to clean")
-	viper.BindPFlag("output", cleanCmd.Flags().Lookup("output"))
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "list what would be removed without removing it")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "do not ask for confirmation")
+	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "remove the output directory even without a manifest.json")
+	cleanCmd.Flags().StringVar(&cleanDomain, "domain", "", "only remove pages from this host and its subdomains")
+	cleanCmd.Flags().StringVar(&cleanSkill, "skill", "", "only remove this skill (a top-level directory of the output)")
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "", "only remove pages crawled longer ago than this (e.g. 72h or 30d)")
+}
+
+// cleanAll removes the whole output directory.
+func cleanAll(outputDir string) error {
+	if err := checkOutputDir(outputDir); err != nil {
+		return err
+	}
+	files, size := 0, int64(0)
+	filepath.WalkDir(outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				files++
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	if cleanDryRun {
+		fmt.Printf("Would remove %s (%d files, %s)\n", outputDir, files, formatSize(size))
+		return nil
+	}
+	if ok, err := confirmClean(fmt.Sprintf("Remove %s (%d files, %s)?", outputDir, files, formatSize(size))); err != nil || !ok {
+		return err
+	}
+	fmt.Printf("Cleaning output directory: %s\n", outputDir)
+	if err := os.RemoveAll(outputDir); err != nil {
+		return err
+	}
+	fmt.Println("Clean complete.")
+	return nil
+}
+
+// checkOutputDir refuses to remove directories that are unlikely to be a
+// crawl's output: the root, home, or working directory (or a parent of
+// it), or, without --force, a non-empty directory with no manifest.
+func checkOutputDir(outputDir string) error {
+	abs, err := filepath.Abs(outputDir)
+	if err != nil {
+		return err
+	}
+	protected := []string{string(filepath.Separator)}
+	if home, err := os.UserHomeDir(); err == nil {
+		protected = append(protected, home)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		for dir := wd; ; dir = filepath.Dir(dir) {
+			protected = append(protected, dir)
+			if dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	for _, dir := range protected {
+		if abs == filepath.Clean(dir) {
+			return fmt.Errorf("refusing to remove %s", abs)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, manifestFileName)); err == nil || cleanForce {
+		return nil
+	}
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%s has no %s, so it may not be an output directory; pass --force to remove it anyway", outputDir, manifestFileName)
+	}
+	return nil
+}
+
+// cleanPages removes the pages matching the scope flags.
+func cleanPages(outputDir string) error {
+	var cutoff time.Time
+	if cleanOlderThan != "" {
+		age, err := parseAge(cleanOlderThan)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+		cutoff = time.Now().Add(-age)
+	}
+	skill := strings.Trim(filepath.ToSlash(cleanSkill), "/")
+	domain := cleanDomain
+
+	m, err := loadManifest(outputDir)
+	if err != nil {
+		return err
+	}
+	var tombstones TombstoneConfig
+	if err := viper.UnmarshalKey("tombstones", &tombstones); err != nil {
+		return err
+	}
+	if err := m.keepTombstones(tombstones); err != nil {
+		return err
+	}
+	matched := map[string]string{}
+	for link, entry := range m.Pages {
+		var reasons []string
+		if domain != "" {
+			if !hostMatches(link, domain) {
+				continue
+			}
+			reasons = append(reasons, "on "+domain)
+		}
+		if skill != "" {
+			if strings.SplitN(entry.Path, "/", 2)[0] != skill {
+				continue
+			}
+			reasons = append(reasons, "in "+skill)
+		}
+		if !cutoff.IsZero() {
+			if entry.Provenance.CrawledAt.IsZero() || !entry.Provenance.CrawledAt.Before(cutoff) {
+				continue
+			}
+			reasons = append(reasons, "crawled "+entry.Provenance.CrawledAt.Format(time.DateOnly))
+		}
+		matched[link] = strings.Join(reasons, ", ")
+	}
+
+	opts := &CrawlOptions{Output: outputDir, Flat: viper.GetBool("flat"), Rename: viper.GetString("rename")}
+	preview := &garbageCollector{opts: opts, dryRun: true}
+	preview.removePages(m, matched)
+	if skill != "" {
+		preview.removeTree(filepath.Join(outputDir, filepath.FromSlash(skill)))
+	}
+	if preview.files == 0 && len(matched) == 0 {
+		fmt.Println("Nothing to clean")
+		return nil
+	}
+	summary := fmt.Sprintf("%d pages and %d files, %s", len(matched), preview.files, formatSize(preview.bytes))
+	if cleanDryRun {
+		fmt.Printf("Would remove %s\n", summary)
+		return nil
+	}
+	if ok, err := confirmClean("Remove " + summary + "?"); err != nil || !ok {
+		return err
+	}
+
+	gc := &garbageCollector{opts: opts, quiet: true}
+	gc.removePages(m, matched)
+	if skill != "" {
+		gc.removeTree(filepath.Join(outputDir, filepath.FromSlash(skill)))
+		for path := range m.Generated {
+			if strings.HasPrefix(path, skill+"/") {
+				m.removeGenerated(path)
+			}
+		}
+	}
+	removeEmptyDirs(outputDir)
+	syncTombstoneFiles(opts, m, tombstones)
+	if err := m.save(outputDir); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d pages and %d files, reclaiming %s\n", len(matched), gc.files, formatSize(gc.bytes))
+	return nil
+}
+
+// hostMatches reports whether link is on domain or one of its subdomains.
+func hostMatches(link, domain string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// parseAge parses a duration, also accepting whole days such as "30d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// confirmClean asks on the terminal whether to go ahead, unless --yes was
+// given. Without a terminal it refuses.
+func confirmClean(prompt string) (bool, error) {
+	if cleanYes {
+		return true, nil
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false, fmt.Errorf("confirmation needed but stdin is not a terminal; pass --yes")
+	}
+	fmt.Printf("%s [y/N] ", prompt)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	fmt.Println("Aborted.")
+	return false, nil
 }
@@ -0,0 +1,434 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gobwas/glob"
+	"go.yaml.in/yaml/v3"
+)
+
+// The command catalog written into each skill directory, as markdown for
+// reading and YAML for tools.
+const (
+	commandsFileName     = "COMMANDS.md"
+	commandsYAMLFileName = "commands.yaml"
+)
+
+// usageLineRe matches a usage line such as "Usage: widgets serve [flags]",
+// capturing the command line, which help output may put on the next line.
+var usageLineRe = regexp.MustCompile(`^\s*(?i:usage|synopsis):\s*(.*?)\s*$`)
+
+// usageHeadingRe matches the headings of usage sections, whose code
+// blocks start with the command line.
+var usageHeadingRe = regexp.MustCompile(`^(?i:usage|synopsis)$`)
+
+// flagLineRe matches a flag in help output, such as
+// "  -p, --port int   port to listen on (default 8080)".
+var flagLineRe = regexp.MustCompile(`^\s*((?:-[A-Za-z0-9?]|--[A-Za-z0-9][\w.-]*)(?:[ =][A-Za-z<\[{][^\s,]*)?(?:,\s*(?:-[A-Za-z0-9?]|--[A-Za-z0-9][\w.-]*)(?:[ =][A-Za-z<\[{][^\s,]*)?)*)(?:\s{2,}|\t+)(\S.*)$`)
+
+// flagNameRe matches one spelling of a flag and its argument.
+var flagNameRe = regexp.MustCompile(`^(-[A-Za-z0-9?]|--[A-Za-z0-9][\w.-]*)(?:[ =](\S+))?$`)
+
+// flagDefaultRe matches the default value given in a flag description.
+var flagDefaultRe = regexp.MustCompile(`\s*\((?i:default)(?::|\s+is)?\s+([^)]*)\)`)
+
+// catalogTool groups the commands of one documented tool.
+type catalogTool struct {
+	Name     string            `yaml:"name"`
+	Commands []*catalogCommand `yaml:"commands"`
+}
+
+// catalogCommand is a command found on a CLI reference page.
+type catalogCommand struct {
+	Name        string        `yaml:"name"`
+	Usage       string        `yaml:"usage"`
+	Description string        `yaml:"description,omitempty"`
+	Flags       []catalogFlag `yaml:"flags,omitempty"`
+	Source      string        `yaml:"source"`
+	// Page is the output path of the page the command was found on.
+	Page string `yaml:"-"`
+}
+
+// catalogFlag is a flag of a command.
+type catalogFlag struct {
+	Name        string `yaml:"name"`
+	Short       string `yaml:"short,omitempty"`
+	Arg         string `yaml:"arg,omitempty"`
+	Default     string `yaml:"default,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// writeCommandCatalogs writes a COMMANDS.md and commands.yaml into each
+// skill directory from the CLI reference pages among its pages: pages
+// with usage lines, whose flags are read from help output, flag tables,
+// and definition lists.
+func writeCommandCatalogs(opts *CrawlOptions, m *Manifest, cfg CommandsConfig) {
+	var pageGlobs []glob.Glob
+	for _, pattern := range cfg.Pages {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			fmt.Printf("Warning: invalid command page glob %s: %v\n", pattern, err)
+			continue
+		}
+		pageGlobs = append(pageGlobs, g)
+	}
+
+	entries := make([]*ManifestEntry, 0, len(m.Pages))
+	for _, entry := range m.Pages {
+		if len(pageGlobs) > 0 && !matchesAny(pageGlobs, entry.URL) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	bySkill := map[string]map[string]*catalogCommand{}
+	for _, entry := range entries {
+		html, err := opts.readSavedHTML(entry)
+		if err != nil {
+			continue
+		}
+		content, err := extractContent(html, opts.extractionFor(entry.URL))
+		if err != nil {
+			continue
+		}
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+		if err != nil {
+			continue
+		}
+		skill := opts.skillDirOf(entry)
+		commands := bySkill[skill]
+		if commands == nil {
+			commands = map[string]*catalogCommand{}
+			bySkill[skill] = commands
+		}
+		for _, c := range extractCommands(doc, entry) {
+			if old, ok := commands[c.Name]; ok {
+				old.Flags = mergeFlags(old.Flags, c.Flags)
+				continue
+			}
+			commands[c.Name] = c
+		}
+	}
+
+	for skill, commands := range bySkill {
+		if len(commands) == 0 {
+			continue
+		}
+		tools := catalogTools(commands)
+		data, err := renderCommandsYAML(tools)
+		if err != nil {
+			fmt.Printf("Error rendering %s: %v\n", commandsYAMLFileName, err)
+			continue
+		}
+		files := map[string]string{
+			path.Join(skill, commandsFileName):     renderCommands(tools, skill),
+			path.Join(skill, commandsYAMLFileName): data,
+		}
+		for _, rel := range sortedKeys(files) {
+			if err := writeFileIfChanged(filepath.Join(opts.Output, filepath.FromSlash(rel)), []byte(files[rel])); err != nil {
+				fmt.Printf("Error writing %s: %v\n", rel, err)
+				continue
+			}
+			m.recordGenerated(rel, files[rel])
+		}
+		fmt.Printf("Wrote %d commands of %d tools to %s\n", len(commands), len(tools), path.Join(skill, commandsFileName))
+	}
+}
+
+// matchesAny reports whether any of globs matches s.
+func matchesAny(globs []glob.Glob, s string) bool {
+	for _, g := range globs {
+		if g.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractCommands returns the commands documented on a page. Each usage
+// line starts a command, described by the first paragraph of its
+// section; flags found before the first command belong to it, and later
+// ones to the command before them.
+func extractCommands(doc *goquery.Document, entry *ManifestEntry) []*catalogCommand {
+	var commands []*catalogCommand
+	var pending []catalogFlag
+	var current *catalogCommand
+	section := ""
+	anchor := ""
+	usageSection := false
+	ids := map[string]string{}
+	for _, c := range entry.Citations {
+		if c.Anchor != "" {
+			ids[headingKey(c.Heading)] = c.URL
+		}
+	}
+	addFlags := func(flags []catalogFlag) {
+		if current == nil {
+			pending = append(pending, flags...)
+			return
+		}
+		current.Flags = mergeFlags(current.Flags, flags)
+	}
+
+	doc.Find("h1, h2, h3, h4, p, pre, table, dl").Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "h1", "h2", "h3", "h4":
+			section = ""
+			usageSection = usageHeadingRe.MatchString(squash(s.Text()))
+			if link, ok := ids[headingKey(s.Text())]; ok {
+				anchor = link
+			}
+		case "p":
+			if s.ParentsFiltered("table, dl, li").Length() > 0 {
+				return
+			}
+			text := squash(s.Text())
+			if section == "" {
+				section = text
+			}
+			if current != nil && current.Description == "" {
+				current.Description = text
+			}
+		case "pre":
+			expectUsage := usageSection
+			for _, line := range strings.Split(s.Text(), "\n") {
+				usage := ""
+				if match := usageLineRe.FindStringSubmatch(line); match != nil {
+					usage, expectUsage = match[1], match[1] == ""
+				} else if expectUsage && strings.TrimSpace(line) != "" {
+					usage, expectUsage = strings.TrimPrefix(strings.TrimSpace(line), "$ "), false
+				}
+				if usage != "" {
+					name := commandName(usage)
+					if name == "" || current != nil && current.Name == name {
+						continue
+					}
+					source := anchor
+					if source == "" {
+						source = entry.URL
+					}
+					current = &catalogCommand{Name: name, Usage: squash(usage), Description: section, Source: source, Page: entry.Path}
+					current.Flags = mergeFlags(current.Flags, pending)
+					pending = nil
+					commands = append(commands, current)
+				} else if flag, ok := parseFlagLine(line); ok {
+					addFlags([]catalogFlag{flag})
+				}
+			}
+		case "table":
+			addFlags(tableFlags(s))
+		case "dl":
+			var flags []catalogFlag
+			s.ChildrenFiltered("dt").Each(func(_ int, dt *goquery.Selection) {
+				dd := dt.NextFilteredUntil("dd", "dt").First()
+				if flag, ok := parseFlag(squash(dt.Text()), squash(dd.Text())); ok {
+					flags = append(flags, flag)
+				}
+			})
+			addFlags(flags)
+		}
+	})
+	return commands
+}
+
+// commandName returns the command a usage line invokes: its words up to
+// the first flag, argument placeholder, or option group.
+func commandName(usage string) string {
+	var words []string
+	for _, word := range strings.Fields(usage) {
+		if strings.ContainsAny(word[:1], "-[<{($|>\"'") || strings.ToUpper(word) == word && strings.ToLower(word) != word {
+			break
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// parseFlagLine parses a flag line of help output.
+func parseFlagLine(line string) (catalogFlag, bool) {
+	match := flagLineRe.FindStringSubmatch(line)
+	if match == nil {
+		return catalogFlag{}, false
+	}
+	return parseFlag(match[1], match[2])
+}
+
+// parseFlag parses the spellings of a flag, such as "-p, --port int",
+// and its description, which may give its default.
+func parseFlag(spellings, description string) (catalogFlag, bool) {
+	var flag catalogFlag
+	var long, short string
+	for _, spelling := range strings.Split(spellings, ",") {
+		match := flagNameRe.FindStringSubmatch(strings.TrimSpace(spelling))
+		if match == nil {
+			return catalogFlag{}, false
+		}
+		if strings.HasPrefix(match[1], "--") && long == "" {
+			long = match[1]
+		} else if !strings.HasPrefix(match[1], "--") && short == "" {
+			short = match[1]
+		}
+		if match[2] != "" {
+			flag.Arg = match[2]
+		}
+	}
+	flag.Name, flag.Short = long, short
+	if long == "" {
+		flag.Name, flag.Short = short, ""
+	}
+	if match := flagDefaultRe.FindStringSubmatch(description); match != nil {
+		flag.Default = strings.Trim(match[1], "\"'`")
+		description = strings.Replace(description, match[0], "", 1)
+	}
+	flag.Description = strings.TrimSpace(description)
+	return flag, true
+}
+
+// tableFlags returns the flags listed in a table whose first column
+// holds flags, taking their description, default, and argument type from
+// the columns named for them.
+func tableFlags(table *goquery.Selection) []catalogFlag {
+	var header []string
+	table.Find("tr").First().Find("th, td").Each(func(_ int, cell *goquery.Selection) {
+		header = append(header, strings.ToLower(squash(cell.Text())))
+	})
+	column := func(names ...string) int {
+		for i, h := range header {
+			for _, name := range names {
+				if strings.Contains(h, name) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	descCol := column("description", "meaning", "details")
+	if descCol < 0 {
+		descCol = len(header) - 1
+	}
+	defaultCol := column("default")
+	typeCol := column("type", "value", "argument")
+
+	var flags []catalogFlag
+	table.Find("tr").Slice(1, goquery.ToEnd).Each(func(_ int, row *goquery.Selection) {
+		var cells []string
+		row.Find("th, td").Each(func(_ int, cell *goquery.Selection) {
+			cells = append(cells, squash(cell.Text()))
+		})
+		if len(cells) < 2 || descCol >= len(cells) || descCol == 0 {
+			return
+		}
+		flag, ok := parseFlag(cells[0], cells[descCol])
+		if !ok {
+			return
+		}
+		if defaultCol > 0 && defaultCol < len(cells) && cells[defaultCol] != "" {
+			flag.Default = strings.Trim(cells[defaultCol], "\"'`")
+		}
+		if typeCol > 0 && typeCol < len(cells) && typeCol != descCol && flag.Arg == "" {
+			flag.Arg = cells[typeCol]
+		}
+		flags = append(flags, flag)
+	})
+	return flags
+}
+
+// mergeFlags adds the flags of more that flags does not have yet.
+func mergeFlags(flags, more []catalogFlag) []catalogFlag {
+	for _, flag := range more {
+		found := false
+		for _, f := range flags {
+			if f.Name == flag.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// catalogTools groups commands by the tool they run, the first word of
+// their name, sorted by name.
+func catalogTools(commands map[string]*catalogCommand) []*catalogTool {
+	byName := map[string]*catalogTool{}
+	for _, name := range sortedKeys(commands) {
+		tool := strings.Fields(name)[0]
+		if byName[tool] == nil {
+			byName[tool] = &catalogTool{Name: tool}
+		}
+		byName[tool].Commands = append(byName[tool].Commands, commands[name])
+	}
+	tools := make([]*catalogTool, 0, len(byName))
+	for _, name := range sortedKeys(byName) {
+		tools = append(tools, byName[name])
+	}
+	return tools
+}
+
+// renderCommandsYAML renders the catalog as YAML.
+func renderCommandsYAML(tools []*catalogTool) (string, error) {
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string][]*catalogTool{"tools": tools}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// renderCommands renders the catalog as markdown: one section per tool,
+// and for each command its usage, description, and flags.
+func renderCommands(tools []*catalogTool, skill string) string {
+	var b strings.Builder
+	b.WriteString("# Commands\n\n")
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "## %s\n\n", tool.Name)
+		for _, c := range tool.Commands {
+			link := c.Page
+			if skill != "." {
+				link = strings.TrimPrefix(link, skill+"/")
+			}
+			fmt.Fprintf(&b, "### %s\n\n%s\n\n", c.Name, fence("sh", []string{c.Usage}))
+			if c.Description != "" {
+				b.WriteString(c.Description + "\n\n")
+			}
+			if len(c.Flags) > 0 {
+				b.WriteString("| Flag | Argument | Default | Description |\n| --- | --- | --- | --- |\n")
+				for _, f := range c.Flags {
+					name := codeSpan(f.Name)
+					if f.Short != "" {
+						name = codeSpan(f.Short) + ", " + name
+					}
+					fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", name, tableCell(f.Arg), tableCell(f.Default), tableCell(f.Description))
+				}
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "[source](%s) ([page](%s))\n\n", c.Source, link)
+		}
+	}
+	return strings.TrimSpace(b.String()) + "\n"
+}
@@ -0,0 +1,319 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// tocFileName is the table of contents written into each compacted skill.
+const tocFileName = "TOC.md"
+
+// minTruncatedTokens is the smallest remaining budget worth filling with
+// a truncated page; below it lower-priority pages are omitted.
+const minTruncatedTokens = 200
+
+// compactOut holds the output directory for compacted skills.
+// compactBudget holds the token budget per skill.
+var (
+	compactOut    string
+	compactBudget int
+)
+
+var compactCmd = &cobra.Command{
+	Use:   "compact [skill-dir...]",
+	Short: "Fit skills into a token budget",
+	Long: `Copies each skill directory (default: every directory in the output
+directory) into --out, keeping the highest-priority pages whole until the
+token budget is reached, truncating the next page to fit, and omitting the
+rest. A TOC.md listing kept, truncated, and omitted pages is written to
+each compacted skill.
+
+Pages are prioritized by config weights (compact.weights), link
+centrality (how many pages in the skill link to them), and navigation
+depth (shallower pages first).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		if cmd.Flags().Changed("budget") || cfg.Compact.Budget == 0 {
+			cfg.Compact.Budget = compactBudget
+		}
+
+		weights, err := compileWeights(cfg.Compact.Weights)
+		if err != nil {
+			fmt.Printf("Error processing weights: %v\n", err)
+			os.Exit(1)
+		}
+
+		dirs := args
+		if len(dirs) == 0 {
+			entries, err := os.ReadDir(cfg.outputDir())
+			if err != nil {
+				fmt.Printf("Error reading output directory: %v\n", err)
+				os.Exit(1)
+			}
+			for _, e := range entries {
+				if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+					dirs = append(dirs, filepath.Join(cfg.outputDir(), e.Name()))
+				}
+			}
+		}
+
+		out := compactOut
+		if out == "" {
+			out = strings.TrimSuffix(cfg.outputDir(), string(filepath.Separator)) + "-compact"
+		}
+		for _, dir := range dirs {
+			dest := filepath.Join(out, filepath.Base(dir))
+			if err := compactSkill(dir, dest, cfg.Compact.Budget, weights); err != nil {
+				fmt.Printf("Error compacting %s: %v\n", dir, err)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compactCmd)
+	compactCmd.Flags().StringVar(&compactOut, "out", "", "directory for compacted skills (default: <output>-compact)")
+	compactCmd.Flags().IntVar(&compactBudget, "budget", 50000, "token budget per skill")
+}
+
+// weightRule is a compiled compact.weights entry.
+type weightRule struct {
+	g      glob.Glob
+	weight float64
+}
+
+func compileWeights(weights map[string]float64) ([]weightRule, error) {
+	var rules []weightRule
+	for pattern, w := range weights {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight glob %s: %w", pattern, err)
+		}
+		rules = append(rules, weightRule{g: g, weight: w})
+	}
+	return rules, nil
+}
+
+// compactPage is a page considered for a compacted skill.
+type compactPage struct {
+	rel      string
+	meta     pageMeta
+	content  string
+	tokens   int
+	inbound  int
+	depth    int
+	weight   float64
+	priority float64
+}
+
+var markdownLinkRe = regexp.MustCompile(`\]\(([^)\s]+)`)
+
+// compactSkill writes a budget-limited copy of the skill at dir to dest.
+func compactSkill(dir, dest string, budget int, weights []weightRule) error {
+	var pages []*compactPage
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		path = pagePath(path)
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".md") || filepath.Base(path) == tocFileName {
+			return err
+		}
+		data, err := readOutputFile(path)
+		if err != nil {
+			return err
+		}
+		meta, _, _ := parsePage(string(data))
+		rel, _ := filepath.Rel(dir, path)
+		pages = append(pages, &compactPage{
+			rel:     rel,
+			meta:    meta,
+			content: string(data),
+			tokens:  estimateTokens(string(data)),
+			depth:   strings.Count(filepath.ToSlash(rel), "/"),
+			weight:  1,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+
+	// Link centrality: count inbound links from other pages in the skill
+	byURL := map[string]*compactPage{}
+	for _, p := range pages {
+		if p.meta.URL != "" {
+			byURL[normalizeLink(p.meta.URL)] = p
+		}
+	}
+	for _, p := range pages {
+		base, err := url.Parse(p.meta.URL)
+		if err != nil || p.meta.URL == "" {
+			continue
+		}
+		linked := map[*compactPage]bool{}
+		for _, m := range markdownLinkRe.FindAllStringSubmatch(p.content, -1) {
+			ref, err := url.Parse(m[1])
+			if err != nil {
+				continue
+			}
+			if target, ok := byURL[normalizeLink(base.ResolveReference(ref).String())]; ok && target != p {
+				linked[target] = true
+			}
+		}
+		for target := range linked {
+			target.inbound++
+		}
+	}
+
+	for _, p := range pages {
+		for _, w := range weights {
+			if w.g.Match(p.meta.URL) {
+				p.weight = w.weight
+			}
+		}
+		p.priority = p.weight * float64(1+p.inbound) / float64(1+p.depth)
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		if pages[i].priority != pages[j].priority {
+			return pages[i].priority > pages[j].priority
+		}
+		return pages[i].rel < pages[j].rel
+	})
+
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+
+	var toc strings.Builder
+	var omitted []*compactPage
+	used, kept, truncated := 0, 0, 0
+	fmt.Fprintf(&toc, "# %s\n\nCompacted to a budget of %d tokens.\n\n## Pages\n\n", filepath.Base(dir), budget)
+
+	for _, p := range pages {
+		content := p.content
+		note := ""
+		remaining := budget - used
+		switch {
+		case p.tokens <= remaining:
+		case remaining >= minTruncatedTokens:
+			content = truncateToTokens(content, remaining, p.meta.URL)
+			note = " (truncated)"
+			truncated++
+		default:
+			omitted = append(omitted, p)
+			continue
+		}
+
+		target := filepath.Join(dest, p.rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return err
+		}
+		used += estimateTokens(content)
+		kept++
+		fmt.Fprintf(&toc, "- [%s](%s)%s — %d tokens\n", pageLabel(p), filepath.ToSlash(p.rel), note, estimateTokens(content))
+	}
+
+	if len(omitted) > 0 {
+		toc.WriteString("\n## Omitted\n\n")
+		for _, p := range omitted {
+			if p.meta.URL != "" {
+				fmt.Fprintf(&toc, "- [%s](%s) — %d tokens\n", pageLabel(p), p.meta.URL, p.tokens)
+			} else {
+				fmt.Fprintf(&toc, "- %s — %d tokens\n", pageLabel(p), p.tokens)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dest, tocFileName), []byte(toc.String()), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Compacted %s: %d pages kept (%d truncated), %d omitted, ~%d tokens\n",
+		dir, kept, truncated, len(omitted), used)
+	return nil
+}
+
+// pageLabel returns the display name of a page.
+func pageLabel(p *compactPage) string {
+	if p.meta.Title != "" {
+		return p.meta.Title
+	}
+	return p.rel
+}
+
+// truncateToTokens cuts content at a block boundary so it fits in budget
+// tokens, keeping the frontmatter and noting where the full page lives.
+func truncateToTokens(content string, budget int, source string) string {
+	notice := "\n\n> Truncated to fit the token budget."
+	if source != "" {
+		notice += " Full page: " + source
+	}
+	notice += "\n"
+
+	fm, body := splitFrontmatter(content)
+	limit := (budget - estimateTokens(fm+notice)) * charsPerToken
+	if limit <= 0 {
+		return fm + strings.TrimPrefix(notice, "\n")
+	}
+
+	var b strings.Builder
+	for _, block := range splitBlocks(body) {
+		if b.Len()+len(block)+2 > limit {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(block)
+	}
+	return fm + "\n" + b.String() + notice
+}
+
+// normalizeLink strips fragments, queries, and index/extension suffixes so
+// equivalent page URLs compare equal.
+func normalizeLink(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	u.Fragment = ""
+	u.RawQuery = ""
+	p := strings.TrimSuffix(u.Path, "index.html")
+	p = strings.TrimSuffix(p, ".html")
+	u.Path = strings.TrimSuffix(p, "/")
+	return u.String()
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// loadComposedConfig reads the config file at path and resolves its
+// `extends:` and `include:` keys into a single settings map.
+//
+// Precedence, lowest first: every extended base (in order), every
+// included fragment (in order), then the file itself. Nested maps are
+// merged key by key; lists and scalars are overridden unless the key
+// ends in "+", which appends the list to the inherited one.
+// Relative paths resolve against the directory of the file naming them.
+func loadComposedConfig(path string) (map[string]interface{}, error) {
+	settings, err := composeConfig(path, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	stripAppendMarkers(settings)
+	return settings, nil
+}
+
+func composeConfig(path string, seen map[string]bool) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if seen[abs] {
+		return nil, fmt.Errorf("config %s extends or includes itself", path)
+	}
+	seen[abs] = true
+	defer delete(seen, abs)
+
+	v := viper.New()
+	v.SetConfigFile(abs)
+	if filepath.Ext(abs) == "" {
+		v.SetConfigType("yaml")
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	self := v.AllSettings()
+
+	dir := filepath.Dir(abs)
+	merged := map[string]interface{}{}
+
+	for _, base := range cast.ToStringSlice(self["extends"]) {
+		settings, err := composeConfig(resolveConfigPath(dir, base), seen)
+		if err != nil {
+			return nil, err
+		}
+		mergeSettings(merged, settings)
+	}
+
+	for _, pattern := range cast.ToStringSlice(self["include"]) {
+		matches, err := filepath.Glob(resolveConfigPath(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("include %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("include %s matched no files", pattern)
+		}
+		for _, m := range matches {
+			settings, err := composeConfig(m, seen)
+			if err != nil {
+				return nil, err
+			}
+			mergeSettings(merged, settings)
+		}
+	}
+
+	delete(self, "extends")
+	delete(self, "include")
+	mergeSettings(merged, self)
+	return merged, nil
+}
+
+// resolveConfigPath resolves p relative to dir unless it is absolute.
+func resolveConfigPath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// mergeSettings merges src into dst in place. Maps merge recursively
+// and other values in src replace those in dst, lists included. A key
+// ending in "+" (such as `rules+`) appends its list to the inherited one
+// instead; with nothing to append to yet, the marker is kept so a file
+// further up the extends/include chain can still append.
+func mergeSettings(dst, src map[string]interface{}) {
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	// Sorted, so `rules` is applied before `rules+` in the same file.
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := src[key]
+		if list, ok := value.([]interface{}); ok && strings.HasSuffix(key, "+") {
+			base := strings.TrimSuffix(key, "+")
+			if el, ok := dst[base].([]interface{}); ok {
+				dst[base] = append(append([]interface{}{}, el...), list...)
+				continue
+			}
+			if el, ok := dst[key].([]interface{}); ok {
+				list = append(append([]interface{}{}, el...), list...)
+			}
+			dst[key] = list
+			continue
+		}
+		delete(dst, key+"+")
+		if v, ok := value.(map[string]interface{}); ok {
+			em, ok := dst[key].(map[string]interface{})
+			if !ok {
+				em = map[string]interface{}{}
+				dst[key] = em
+			}
+			mergeSettings(em, v)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// stripAppendMarkers renames the `key+` entries left over once a config
+// is fully composed to plain keys.
+func stripAppendMarkers(settings map[string]interface{}) {
+	for key, value := range settings {
+		if m, ok := value.(map[string]interface{}); ok {
+			stripAppendMarkers(m)
+		}
+		if base := strings.TrimSuffix(key, "+"); base != key {
+			delete(settings, key)
+			settings[base] = value
+		}
+	}
+}
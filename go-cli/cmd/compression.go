@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encodings the output's page files can be compressed with.
+const (
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+)
+
+// encodingExts maps each encoding to the extension appended to the files
+// it compresses.
+var encodingExts = map[string]string{
+	encodingGzip: ".gz",
+	encodingZstd: ".zst",
+}
+
+// checkCompression reports whether the compression setting is known.
+func checkCompression(encoding string) error {
+	if _, ok := encodingExts[encoding]; !ok && encoding != "" && encoding != "none" {
+		return fmt.Errorf("unknown compression %q: want none, gzip, or zstd", encoding)
+	}
+	return nil
+}
+
+// outputFile returns where the file a page keeps at path is on disk:
+// path itself, or path with an encoding's extension when only a
+// compressed copy exists.
+func outputFile(path string) string {
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	for _, encoding := range sortedKeys(encodingExts) {
+		if _, err := os.Stat(path + encodingExts[encoding]); err == nil {
+			return path + encodingExts[encoding]
+		}
+	}
+	return path
+}
+
+// plainPath returns path without the extension of an encoding.
+func plainPath(path string) string {
+	for _, ext := range encodingExts {
+		if strings.HasSuffix(path, ext) {
+			return strings.TrimSuffix(path, ext)
+		}
+	}
+	return path
+}
+
+// pagePath returns the plain path of a compressed page or saved HTML file
+// found on disk, and any other path unchanged.
+func pagePath(path string) string {
+	if plain := plainPath(path); strings.HasSuffix(plain, ".md") || strings.HasSuffix(plain, ".html") {
+		return plain
+	}
+	return path
+}
+
+// readOutputFile reads the file at path, decompressing it when only a
+// compressed copy is on disk, or when path itself names one.
+func readOutputFile(path string) ([]byte, error) {
+	actual := outputFile(path)
+	data, err := os.ReadFile(actual)
+	if err != nil {
+		return nil, err
+	}
+	for encoding, ext := range encodingExts {
+		if strings.HasSuffix(actual, ext) {
+			if data, err = decompress(encoding, data); err != nil {
+				return nil, fmt.Errorf("decompressing %s: %w", actual, err)
+			}
+			break
+		}
+	}
+	return data, nil
+}
+
+// statOutputFile is os.Stat of the file a page keeps at path, compressed
+// or not.
+func statOutputFile(path string) (os.FileInfo, error) {
+	return os.Stat(outputFile(path))
+}
+
+// removeOutputFile removes the file at path and its compressed copies.
+func removeOutputFile(path string) {
+	os.Remove(path)
+	for _, ext := range encodingExts {
+		os.Remove(path + ext)
+	}
+}
+
+// compress returns data compressed with encoding. The output depends only
+// on data, so unchanged files compress to the same bytes.
+func compress(encoding string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case encodingGzip:
+		w = gzip.NewWriter(&buf)
+	case encodingZstd:
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, err
+		}
+		w = zw
+	default:
+		return data, nil
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress returns data decompressed with encoding.
+func decompress(encoding string, data []byte) ([]byte, error) {
+	switch encoding {
+	case encodingGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case encodingZstd:
+		r, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+	return data, nil
+}
+
+// encodeFile stores the file a page keeps at path with encoding, or
+// uncompressed when encoding is empty, and removes its other copies.
+func encodeFile(path, encoding string) error {
+	target := path + encodingExts[encoding]
+	if outputFile(path) != target {
+		data, err := readOutputFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if data, err = compress(encoding, data); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+	}
+	if target != path {
+		os.Remove(path)
+	}
+	for _, ext := range encodingExts {
+		if path+ext != target {
+			os.Remove(path + ext)
+		}
+	}
+	return nil
+}
+
+// compressOutput stores the markdown and saved HTML of every page in m
+// with the compression setting, recording the encoding in the manifest.
+// Pages written since the last run are plain files; they are compressed
+// and their stale compressed copies replaced. With no compression,
+// compressed pages are expanded again.
+func compressOutput(opts *CrawlOptions, m *Manifest, encoding string) {
+	if encoding == "none" {
+		encoding = ""
+	}
+	for _, link := range sortedKeys(m.Pages) {
+		entry := m.Pages[link]
+		files := []string{opts.pageFile(entry)}
+		if u, err := url.Parse(link); err == nil && entry.InlinedIn == "" {
+			if _, raw := opts.outputPath(u); raw != opts.markdownPath(raw) {
+				files = append(files, raw)
+			}
+		}
+		failed := false
+		for _, path := range files {
+			if err := encodeFile(path, encoding); err != nil {
+				fmt.Printf("Error compressing %s: %v\n", path, err)
+				failed = true
+			}
+		}
+		if !failed && entry.Encoding != encoding {
+			m.mu.Lock()
+			entry.Encoding = encoding
+			m.touch(link)
+			m.mu.Unlock()
+		}
+	}
+}
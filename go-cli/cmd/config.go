@@ -16,17 +16,507 @@ package cmd
 
 // RuleConfig defines a verbose rule in the YAML config.
 type RuleConfig struct {
-	URL      string `mapstructure:"url"`
-	Subpaths bool   `mapstructure:"subpaths"`
-	Action   string `mapstructure:"action"` // "include" or "ignore"
+	URL           string `mapstructure:"url" schema:"desc=URL or glob pattern the rule applies to"`
+	Subpaths      bool   `mapstructure:"subpaths" schema:"desc=Also match every path below url"`
+	Action        string `mapstructure:"action" schema:"desc=Whether matching URLs are crawled or skipped;enum=include|ignore"` // "include" or "ignore"
+	Priority      int    `mapstructure:"priority" schema:"desc=Among matching rules the highest priority wins, before rule_precedence applies (default 0)"`
+	FetchPriority int    `mapstructure:"fetch_priority" schema:"desc=Fetch URLs this rule decides before those of rules with a lower fetch priority (default 0)"`
+	Convert       *bool  `mapstructure:"convert" schema:"desc=Save matching pages (default true). With false they are fetched only to discover links"`
+	Follow        *bool  `mapstructure:"follow" schema:"desc=Follow links on matching pages (default true)"`
+	Render        bool   `mapstructure:"render" schema:"desc=Fetch matching pages with the headless browser (see browser) whatever the fetcher"`
+}
+
+// ExtractionConfig controls how content is pulled from a page.
+type ExtractionConfig struct {
+	Mode            string            `mapstructure:"mode" schema:"desc=Extraction mode: article converts prose, api emits compact API reference markdown;enum=article|api"`
+	Profile         string            `mapstructure:"profile" schema:"desc=How much of each page is saved: mirror (the whole page), digest (lead paragraph and a summarized table of contents), or snippets (code blocks and tables only);enum=mirror|digest|snippets"`
+	ContentSelector string            `mapstructure:"content_selector" schema:"desc=CSS selector for the main content (default: article, falling back to body)"`
+	StripSelectors  []string          `mapstructure:"strip_selectors" schema:"desc=CSS selectors removed from the content before conversion"`
+	Frontmatter     map[string]string `mapstructure:"frontmatter" schema:"desc=Static fields added to the generated frontmatter"`
+	FrontmatterMap  map[string]string `mapstructure:"frontmatter_map" schema:"desc=Frontmatter fields read from each page: a CSS selector (meta tags give their content), selector@attr, or jsonld:path.to.field"`
+	HTMLFallback    bool              `mapstructure:"html_fallback" schema:"desc=Embed the source HTML of tables with merged or block cells, MathML, and custom elements in a collapsible block after their markdown"`
+	StopSections    bool              `mapstructure:"stop_sections" schema:"desc=Remove sections such as See also, Related articles, Feedback, and On this page, in the page language"`
+	StopHeadings    []string          `mapstructure:"stop_headings" schema:"desc=More headings (case-insensitive globs) whose sections are removed"`
+	MinWords        int               `mapstructure:"min_words" schema:"desc=Drop pages whose extracted content has fewer words than this"`
+	MinTokens       int               `mapstructure:"min_tokens" schema:"desc=Drop pages whose extracted content has fewer estimated tokens than this"`
+	MaxSimilarity   float64           `mapstructure:"max_similarity" schema:"desc=Drop pages whose text is at least this percent identical to a page already kept (0 disables)"`
+	Converter       ConverterConfig   `mapstructure:"converter" schema:"desc=HTML-to-markdown engine, plugins, and options"`
+}
+
+// ConverterConfig selects the HTML-to-markdown engine and how it writes
+// markdown. Empty options keep the engine's defaults.
+type ConverterConfig struct {
+	Engine          string   `mapstructure:"engine" schema:"desc=HTML-to-markdown engine: v1 or v2 of JohannesKaufmann/html-to-markdown (default v1);enum=v1|v2"`
+	Plugins         []string `mapstructure:"plugins" schema:"desc=Engine plugins: gfm (every GitHub Flavored Markdown plugin of the engine), tables, strikethrough, or task_lists (v1 only)"`
+	HeadingStyle    string   `mapstructure:"heading_style" schema:"desc=How headings are written;enum=atx|setext"`
+	BulletMarker    string   `mapstructure:"bullet_marker" schema:"desc=Marker of unordered list items;enum=-|+|*"`
+	CodeFence       string   `mapstructure:"code_fence" schema:"desc=Fence of code blocks;enum=backticks|tildes"`
+	EmDelimiter     string   `mapstructure:"em_delimiter" schema:"desc=Delimiter of emphasis;enum=_|*"`
+	StrongDelimiter string   `mapstructure:"strong_delimiter" schema:"desc=Delimiter of strong emphasis;enum=**|__"`
+	HorizontalRule  string   `mapstructure:"horizontal_rule" schema:"desc=Text of thematic breaks, e.g. --- or * * *"`
+}
+
+// ExtractionScope overrides extraction settings for URLs matching a glob.
+type ExtractionScope struct {
+	Match            []string `mapstructure:"match" schema:"desc=URL globs this scope applies to"`
+	ExtractionConfig `mapstructure:",squash"`
+}
+
+// CategoryRule assigns pages to a category by URL or by words in their
+// text.
+type CategoryRule struct {
+	Name     string   `mapstructure:"name" schema:"desc=Category recorded in the frontmatter and manifest of matching pages"`
+	Match    []string `mapstructure:"match" schema:"desc=URL globs of pages in the category"`
+	Keywords []string `mapstructure:"keywords" schema:"desc=Words or phrases that put pages whose title or text contains any of them in the category"`
+}
+
+// BoilerplateConfig controls site-wide removal of repeated text blocks.
+type BoilerplateConfig struct {
+	Enabled   bool    `mapstructure:"enabled" schema:"desc=Remove text blocks repeated across many pages of a host"`
+	Threshold float64 `mapstructure:"threshold" schema:"desc=Fraction of a host's pages a block must appear on (default 0.5)"`
+	MinPages  int     `mapstructure:"min_pages" schema:"desc=Minimum pages on a host before removal applies (default 5)"`
+}
+
+// LargePageConfig controls how very large pages are handled.
+type LargePageConfig struct {
+	SpoolMB     int `mapstructure:"spool_mb" schema:"desc=HTML bodies larger than this many MB are streamed to disk instead of held in memory (default 8)"`
+	MaxMB       int `mapstructure:"max_mb" schema:"desc=Most MB of a streamed page that are parsed and converted (default 256)"`
+	Parallelism int `mapstructure:"parallelism" schema:"desc=Streamed pages parsed at once (default 1)"`
+}
+
+// QualityConfig controls conversion quality scoring.
+type QualityConfig struct {
+	Enabled    bool    `mapstructure:"enabled" schema:"desc=Score every page and write quality-review.md listing the lowest-scoring ones"`
+	ReviewSize int     `mapstructure:"review_size" schema:"desc=Most pages listed in the review (default 50)"`
+	Threshold  float64 `mapstructure:"threshold" schema:"desc=Pages scoring below this are listed, from 0 to 1 (default 0.8)"`
+}
+
+// CompactConfig controls the compact command.
+type CompactConfig struct {
+	Budget  int                `mapstructure:"budget" schema:"desc=Token budget per skill"`
+	Weights map[string]float64 `mapstructure:"weights" schema:"desc=Priority multipliers keyed by URL glob"`
+}
+
+// WaybackConfig controls converting the latest Wayback Machine snapshot of
+// pages that now answer 404 or 410 but were saved by an earlier crawl.
+type WaybackConfig struct {
+	Enabled     bool   `mapstructure:"enabled" schema:"desc=Convert archived copies of saved pages that now answer 404 or 410"`
+	Endpoint    string `mapstructure:"endpoint" schema:"desc=Availability API base URL (default https://archive.org)"`
+	CDXEndpoint string `mapstructure:"cdx_endpoint" schema:"desc=Base URL of the CDX API and snapshots used by as_of (default https://web.archive.org)"`
+}
+
+// ThrottleConfig controls the pacing of requests to each host, which slows
+// down when a host answers 429 or 503 and speeds up again after a cool-down.
+type ThrottleConfig struct {
+	Delay    string `mapstructure:"delay" schema:"desc=Time between requests to a host that is not slowed down (default 0)"`
+	MaxDelay string `mapstructure:"max_delay" schema:"desc=Longest time between requests to a slowed host, and longest Retry-After honored (default 1m)"`
+	Cooldown string `mapstructure:"cooldown" schema:"desc=How long a slowed host must answer normally before its delay halves (default 30s)"`
+	Retries  int    `mapstructure:"retries" schema:"desc=Times a throttled request is retried (default 3, -1 for none)"`
+}
+
+// FetchErrorConfig controls how fetches that fail with network errors,
+// timeouts, 408 or 5xx are retried, and what happens once retries run out.
+type FetchErrorConfig struct {
+	Retries        int    `mapstructure:"retries" schema:"desc=Times a failed fetch is retried (default 2, -1 for none)"`
+	Delay          string `mapstructure:"delay" schema:"desc=Time to wait before retrying a failed fetch (default 1s)"`
+	Policy         string `mapstructure:"policy" schema:"desc=What to do with a page whose retries ran out when not asking: skip (default) or abort;enum=skip|abort"`
+	NonInteractive bool   `mapstructure:"non_interactive" schema:"desc=Never ask on the terminal; always apply the policy"`
+}
+
+// TrapConfig controls skipping links into search results, filtered
+// listings, calendars, and other URL spaces without end.
+type TrapConfig struct {
+	Disabled      bool     `mapstructure:"disabled" schema:"desc=Follow search, filter, and calendar links instead of skipping them"`
+	Params        []string `mapstructure:"params" schema:"desc=Query parameters that also mark a search or filter URL, besides built-in ones such as q, sort, and month"`
+	Allow         []string `mapstructure:"allow" schema:"desc=URL globs never treated as traps"`
+	MaxParams     int      `mapstructure:"max_params" schema:"desc=Skip links with more query parameters than this (default 3, -1 for no limit)"`
+	MaxVariants   int      `mapstructure:"max_variants" schema:"desc=Skip links once this many URLs differing only in query values or numeric path segments were followed (default 50, -1 for no limit)"`
+	MaxSimilarity float64  `mapstructure:"max_similarity" schema:"desc=Treat such URLs as a trap once a fetched one is at least this percent identical to another (default 90, -1 disables)"`
+}
+
+// TombstoneConfig controls the tombstones recorded for pages removed
+// from the output.
+type TombstoneConfig struct {
+	Enabled   bool   `mapstructure:"enabled" schema:"desc=Record a tombstone in the manifest for each page removed from the output"`
+	Retention string `mapstructure:"retention" schema:"desc=How long tombstones are kept (default 720h)"`
+	Files     bool   `mapstructure:"files" schema:"desc=Also write each tombstone to a .tombstone.json file where the page was"`
+}
+
+// DNSConfig selects the name servers used to look up hosts.
+type DNSConfig struct {
+	Servers []string `mapstructure:"servers" schema:"desc=DNS servers (host or host:port) used instead of the system resolver, tried in order"`
+}
+
+// TracingConfig controls exporting OpenTelemetry traces of the crawl.
+type TracingConfig struct {
+	Endpoint    string            `mapstructure:"endpoint" schema:"desc=OTLP/HTTP collector URL, e.g. http://localhost:4318 (default: OTEL_EXPORTER_OTLP_ENDPOINT)"`
+	Headers     map[string]string `mapstructure:"headers" schema:"desc=HTTP headers sent with each export, such as an API key"`
+	ServiceName string            `mapstructure:"service_name" schema:"desc=service.name of the exported spans (default: OTEL_SERVICE_NAME or agent-skills-generator)"`
+}
+
+// CrawlWindow limits crawling of some hosts to times of day. Requests to
+// them wait while no window is open.
+type CrawlWindow struct {
+	Hosts    []string `mapstructure:"hosts" schema:"desc=Host globs the windows apply to, e.g. *.example.com"`
+	Windows  []string `mapstructure:"windows" schema:"desc=Times of day crawling is allowed, as HH:MM-HH:MM (a window may run past midnight)"`
+	Timezone string   `mapstructure:"timezone" schema:"desc=IANA time zone of the windows, e.g. Europe/Berlin (default: local time)"`
+}
+
+// StatusConfig detects pages served with a success status that are not
+// content: soft 404s and login walls. Empty lists use built-in defaults.
+type StatusConfig struct {
+	NotFoundPhrases   []string `mapstructure:"not_found_phrases" schema:"desc=Title or heading phrases that mark a soft 404 page"`
+	CanonicalMismatch bool     `mapstructure:"canonical_mismatch" schema:"desc=Treat pages whose canonical URL has a different path as soft 404s"`
+	LoginPhrases      []string `mapstructure:"login_phrases" schema:"desc=Title or heading phrases that mark a login form"`
+	LoginURLs         []string `mapstructure:"login_urls" schema:"desc=URL globs of login pages that protected pages redirect to"`
+}
+
+// SkillEntryConfig controls writing a SKILL.md entry point for each site
+// section from its landing page.
+type SkillEntryConfig struct {
+	Enabled  bool     `mapstructure:"enabled" schema:"desc=Write a SKILL.md entry point for each section with a landing page"`
+	Sections []string `mapstructure:"sections" schema:"desc=URL globs of section landing pages (default: directory index pages)"`
+	Inline   int      `mapstructure:"inline_tokens" schema:"desc=Pages whose content is under this many tokens become sections of their SKILL.md instead of separate files (default 0: never)"`
+	UseWhen  string   `mapstructure:"use_when" schema:"desc=How the use_when field is written: from page titles and keywords (default), by the LLM, or not at all;enum=titles|llm|none"`
+}
+
+// KeywordsConfig controls keyword extraction into page frontmatter.
+type KeywordsConfig struct {
+	Enabled bool   `mapstructure:"enabled" schema:"desc=Add keywords to the frontmatter of every page"`
+	Method  string `mapstructure:"method" schema:"desc=How keywords are chosen (default tfidf);enum=tfidf|rake|llm"`
+	Count   int    `mapstructure:"count" schema:"desc=Keywords per page (default 8)"`
+	Field   string `mapstructure:"field" schema:"desc=Frontmatter key to write (default keywords)"`
+}
+
+// ChangesConfig controls the per-skill summaries of what a crawl or
+// replay changed.
+type ChangesConfig struct {
+	Enabled   bool   `mapstructure:"enabled" schema:"desc=Summarize the pages added, updated, and removed in each skill"`
+	Method    string `mapstructure:"method" schema:"desc=How summaries are written: a list of changed pages and sections (default), or prose from the LLM above the list;enum=list|llm"`
+	File      string `mapstructure:"file" schema:"desc=File in the output directory the summaries are written to (default changes.md)"`
+	Changelog bool   `mapstructure:"changelog" schema:"desc=Also add each summary to the top of a CHANGELOG.md in the skill"`
+}
+
+// VectorStoreConfig controls syncing the output to an OpenAI vector store.
+type VectorStoreConfig struct {
+	Enabled   bool   `mapstructure:"enabled" schema:"desc=Sync the output to the vector store after every crawl"`
+	ID        string `mapstructure:"id" schema:"desc=ID of the OpenAI vector store (vs_...)"`
+	Endpoint  string `mapstructure:"endpoint" schema:"desc=OpenAI API base URL (default https://api.openai.com/v1)"`
+	APIKeyEnv string `mapstructure:"api_key_env" schema:"desc=Environment variable holding the API key (default OPENAI_API_KEY)"`
+}
+
+// GeminiConfig controls syncing the output to the Gemini Files API or a
+// Vertex AI RAG corpus.
+type GeminiConfig struct {
+	Enabled      bool   `mapstructure:"enabled" schema:"desc=Sync the output to the target after every crawl"`
+	Target       string `mapstructure:"target" schema:"desc=Where files are uploaded (default files);enum=files|rag_corpus"`
+	Corpus       string `mapstructure:"corpus" schema:"desc=RAG corpus name: projects/PROJECT/locations/LOCATION/ragCorpora/ID"`
+	Endpoint     string `mapstructure:"endpoint" schema:"desc=API base URL (default the Gemini API, or the corpus location's Vertex AI endpoint)"`
+	APIKeyEnv    string `mapstructure:"api_key_env" schema:"desc=Environment variable holding the API key, or for rag_corpus an access token (default GEMINI_API_KEY or GOOGLE_ACCESS_TOKEN)"`
+	MaxMB        int    `mapstructure:"max_mb" schema:"desc=Files larger than this are uploaded in parts (default and at most 2048 for files, 25 for rag_corpus)"`
+	ChunkSize    int    `mapstructure:"chunk_size" schema:"desc=Tokens per chunk the corpus splits files into (default the corpus default)"`
+	ChunkOverlap int    `mapstructure:"chunk_overlap" schema:"desc=Tokens shared by consecutive corpus chunks"`
+}
+
+// BotConfig controls the Slack and Discord bot served by the bot command.
+type BotConfig struct {
+	Results      int              `mapstructure:"results" schema:"desc=Pages linked in each answer (default 3)"`
+	ExcerptChars int              `mapstructure:"excerpt_chars" schema:"desc=Most characters of the best section quoted in an answer (default 600)"`
+	Slack        SlackBotConfig   `mapstructure:"slack" schema:"desc=Slack app credentials"`
+	Discord      DiscordBotConfig `mapstructure:"discord" schema:"desc=Discord application settings"`
+}
+
+// SlackBotConfig names the environment variables holding a Slack app's
+// credentials.
+type SlackBotConfig struct {
+	SecretEnv string `mapstructure:"signing_secret_env" schema:"desc=Environment variable holding the signing secret (default SLACK_SIGNING_SECRET)"`
+	TokenEnv  string `mapstructure:"bot_token_env" schema:"desc=Environment variable holding the bot token used to answer mentions (default SLACK_BOT_TOKEN)"`
+}
+
+// DiscordBotConfig identifies a Discord application.
+type DiscordBotConfig struct {
+	PublicKey string `mapstructure:"public_key" schema:"desc=Hex public key of the Discord application, used to verify interactions"`
+}
+
+// PublishConfig controls the publish command.
+type PublishConfig struct {
+	To      string     `mapstructure:"to" schema:"desc=Directory the skills are published to"`
+	SignKey string     `mapstructure:"sign_key" schema:"desc=Secret key publish signs the manifest with, publishing the manifest and its signature along with the skills"`
+	Gate    GateConfig `mapstructure:"gate" schema:"desc=Checks publish --gate runs before publishing"`
+}
+
+// GateConfig is the policy publish --gate enforces.
+type GateConfig struct {
+	MaxPageTokens  int      `mapstructure:"max_page_tokens" schema:"desc=Most estimated tokens in one markdown file (0 for no limit)"`
+	MaxSkillTokens int      `mapstructure:"max_skill_tokens" schema:"desc=Most estimated tokens in the markdown files of one skill (0 for no limit)"`
+	Secrets        *bool    `mapstructure:"secrets" schema:"desc=Scan for API keys, tokens, and private keys (default true)"`
+	AllowSecrets   []string `mapstructure:"allow_secrets" schema:"desc=Regular expressions of matches the secret scan accepts, such as documented example keys"`
+	Licenses       []string `mapstructure:"licenses" schema:"desc=Licenses pages may have; when set, every page needs one of them"`
+	LicenseField   string   `mapstructure:"license_field" schema:"desc=Frontmatter field holding a page's license (default license)"`
+	Links          *bool    `mapstructure:"links" schema:"desc=Check that relative links point to published files (default true)"`
+}
+
+// ValidateConfig chooses the ruleset of the validate command and defines
+// custom ones.
+type ValidateConfig struct {
+	Ruleset  string                   `mapstructure:"ruleset" schema:"desc=Ruleset validate applies: agentskills, anthropic, or a custom one (default anthropic)"`
+	Rulesets map[string]RulesetConfig `mapstructure:"rulesets" schema:"desc=Custom rulesets by name, such as house rules"`
+}
+
+// RulesetConfig is a custom validation ruleset.
+type RulesetConfig struct {
+	Extends string                 `mapstructure:"extends" schema:"desc=Ruleset whose rules this one starts from (default: none)"`
+	Rules   map[string]RuleSetting `mapstructure:"rules" schema:"desc=Settings by rule ID; validate --list-rules shows the rules"`
+}
+
+// RuleSetting enables a validation rule. Settings left empty are taken
+// from the extended ruleset, then from the rule's defaults.
+type RuleSetting struct {
+	Severity string   `mapstructure:"severity" schema:"desc=How a failure is reported (default error);enum=error|warning|info|off"`
+	Limit    int      `mapstructure:"limit" schema:"desc=Limit of rules that have one, such as name-length or body-lines"`
+	Fields   []string `mapstructure:"fields" schema:"desc=Frontmatter fields the required-fields rule asks for"`
+}
+
+// GlossaryConfig controls writing a GLOSSARY.md into each skill.
+type GlossaryConfig struct {
+	Enabled bool     `mapstructure:"enabled" schema:"desc=Compile a GLOSSARY.md per skill from definitions found on its pages"`
+	Pages   []string `mapstructure:"pages" schema:"desc=URL globs of glossary pages (default: glossary in the URL or title)"`
+}
+
+// SnippetsConfig controls writing a SNIPPETS.md into each skill.
+type SnippetsConfig struct {
+	Enabled   bool     `mapstructure:"enabled" schema:"desc=Compile a SNIPPETS.md per skill from the code blocks on its pages, with their headings and source links"`
+	Languages []string `mapstructure:"languages" schema:"desc=Only collect code blocks in these languages (default: all)"`
+	MinLines  int      `mapstructure:"min_lines" schema:"desc=Skip code blocks with fewer lines than this (default 1)"`
+}
+
+// CommandsConfig controls writing a command catalog into each skill.
+type CommandsConfig struct {
+	Enabled bool     `mapstructure:"enabled" schema:"desc=Compile a COMMANDS.md and commands.yaml per skill from the usage lines and flags of its CLI reference pages"`
+	Pages   []string `mapstructure:"pages" schema:"desc=URL globs of CLI reference pages (default: every page with a usage line)"`
+}
+
+// FAQConfig controls writing a FAQ.md into each skill.
+type FAQConfig struct {
+	Enabled bool `mapstructure:"enabled" schema:"desc=Compile a FAQ.md per skill from FAQ structured data and accordions"`
+}
+
+// NavOrderConfig controls recording each page's position in the site
+// navigation.
+type NavOrderConfig struct {
+	Enabled  bool   `mapstructure:"enabled" schema:"desc=Record each page's position in the site navigation as order in its frontmatter"`
+	Selector string `mapstructure:"selector" schema:"desc=CSS selector of the navigation menus (default: nav elements and common sidebars)"`
+}
+
+// TranscriptConfig controls fetching captions for embedded videos.
+type TranscriptConfig struct {
+	Enabled   bool     `mapstructure:"enabled" schema:"desc=Embed transcripts of YouTube and Vimeo videos in the markdown"`
+	Languages []string `mapstructure:"languages" schema:"desc=Preferred caption languages in order (default en)"`
+}
+
+// NotebookConfig controls converting Jupyter notebooks.
+type NotebookConfig struct {
+	SkipOutputs    bool `mapstructure:"skip_outputs" schema:"desc=Leave cell outputs out of converted notebooks"`
+	MaxOutputLines int  `mapstructure:"max_output_lines" schema:"desc=Lines kept from each cell output (default 20)"`
+}
+
+// MDXConfig controls converting MDX files from git sources.
+type MDXConfig struct {
+	Components map[string]string `mapstructure:"components" schema:"desc=Action per JSX component name: unwrap (keep children), drop, tab, admonition, code, or details"`
+}
+
+// ConfluenceSource exports a Confluence space or page tree.
+type ConfluenceSource struct {
+	URL         string `mapstructure:"url" schema:"desc=Confluence base URL (e.g. https://example.atlassian.net/wiki)"`
+	Space       string `mapstructure:"space" schema:"desc=Key of the space to export"`
+	PageID      string `mapstructure:"page_id" schema:"desc=Export only this page and the pages below it"`
+	User        string `mapstructure:"user" schema:"desc=Account email for Confluence Cloud (omit to send the token as a bearer token)"`
+	TokenEnv    string `mapstructure:"token_env" schema:"desc=Environment variable holding the API or personal access token"`
+	Attachments bool   `mapstructure:"attachments" schema:"desc=Download page attachments next to the markdown"`
+}
+
+// NotionSource converts Notion pages and databases.
+type NotionSource struct {
+	TokenEnv  string   `mapstructure:"token_env" schema:"desc=Environment variable holding the integration token"`
+	Pages     []string `mapstructure:"pages" schema:"desc=IDs of pages to convert with their child pages (default: everything shared with the integration)"`
+	Databases []string `mapstructure:"databases" schema:"desc=IDs of databases to render as tables"`
+}
+
+// DriveSource exports the Google Docs in a Drive folder. It authenticates
+// with a service account key, or with an OAuth client and refresh token.
+type DriveSource struct {
+	FolderID        string `mapstructure:"folder_id" schema:"desc=ID of the Drive folder to export (subfolders are included)"`
+	CredentialsFile string `mapstructure:"credentials_file" schema:"desc=Service account key file (JSON)"`
+	Subject         string `mapstructure:"subject" schema:"desc=User the service account impersonates under domain-wide delegation"`
+	ClientID        string `mapstructure:"client_id" schema:"desc=OAuth client ID (when not using a service account)"`
+	ClientSecretEnv string `mapstructure:"client_secret_env" schema:"desc=Environment variable holding the OAuth client secret"`
+	RefreshTokenEnv string `mapstructure:"refresh_token_env" schema:"desc=Environment variable holding the OAuth refresh token"`
+}
+
+// ZendeskSource converts the articles of a Zendesk Guide help center.
+type ZendeskSource struct {
+	URL        string   `mapstructure:"url" schema:"desc=Help center URL (e.g. https://example.zendesk.com)"`
+	Locale     string   `mapstructure:"locale" schema:"desc=Locale of the articles to convert (default: the help center's default locale)"`
+	Email      string   `mapstructure:"email" schema:"desc=Agent email for API token authentication (omit for a public help center)"`
+	TokenEnv   string   `mapstructure:"token_env" schema:"desc=Environment variable holding the API token"`
+	Categories []string `mapstructure:"categories" schema:"desc=Names or IDs of the categories to convert (default: all)"`
+}
+
+// IntercomSource converts the articles of an Intercom help center.
+type IntercomSource struct {
+	TokenEnv    string   `mapstructure:"token_env" schema:"desc=Environment variable holding the access token"`
+	Collections []string `mapstructure:"collections" schema:"desc=Names or IDs of the collections to convert (default: all)"`
+}
+
+// DiscourseSource converts topics from categories of a Discourse forum.
+type DiscourseSource struct {
+	URL         string   `mapstructure:"url" schema:"desc=Forum URL (e.g. https://forum.example.com)"`
+	Categories  []string `mapstructure:"categories" schema:"desc=Slugs, names, or IDs of the categories to convert (default: all)"`
+	APIKeyEnv   string   `mapstructure:"api_key_env" schema:"desc=Environment variable holding an API key (omit for a public forum)"`
+	APIUsername string   `mapstructure:"api_username" schema:"desc=User the API key acts as"`
+	SolvedOnly  bool     `mapstructure:"solved_only" schema:"desc=Only convert topics with an accepted answer"`
+	MinLikes    int      `mapstructure:"min_likes" schema:"desc=Also keep replies with at least this many likes (default: accepted answers only)"`
+	MaxTopics   int      `mapstructure:"max_topics" schema:"desc=Most recent topics to convert per category (default: all)"`
+}
+
+// MboxSource converts the threads of an mbox mailing list archive.
+type MboxSource struct {
+	Path        string `mapstructure:"path" schema:"desc=Path to the mbox file"`
+	Skill       string `mapstructure:"skill" schema:"desc=Skill directory the threads are written to (default: the file name)"`
+	Since       string `mapstructure:"since" schema:"desc=Skip threads started before this date (YYYY-MM-DD)"`
+	SkipReplies bool   `mapstructure:"skip_replies" schema:"desc=Keep only the first message of each thread"`
+}
+
+// SourceConfig is a documentation source converted into the output
+// alongside crawled pages: a git repository, or one of the API connectors.
+type SourceConfig struct {
+	Git        string           `mapstructure:"git" schema:"desc=Repository to clone (URL or local path)"`
+	Ref        string           `mapstructure:"ref" schema:"desc=Branch or tag to check out (default: the remote HEAD)"`
+	Path       string           `mapstructure:"path" schema:"desc=Directory within the repository to convert (default: the whole repository)"`
+	Include    []string         `mapstructure:"include" schema:"desc=File globs relative to path to convert (default: every supported file)"`
+	Confluence ConfluenceSource `mapstructure:"confluence" schema:"desc=Confluence space or page tree to export"`
+	Notion     NotionSource     `mapstructure:"notion" schema:"desc=Notion pages and databases to convert"`
+	Drive      DriveSource      `mapstructure:"drive" schema:"desc=Google Drive folder of Docs to export"`
+	Zendesk    ZendeskSource    `mapstructure:"zendesk" schema:"desc=Zendesk Guide help center to convert"`
+	Intercom   IntercomSource   `mapstructure:"intercom" schema:"desc=Intercom help center to convert"`
+	Discourse  DiscourseSource  `mapstructure:"discourse" schema:"desc=Discourse forum categories to convert"`
+	Mbox       MboxSource       `mapstructure:"mbox" schema:"desc=Mailing list archive to convert"`
+}
+
+// LLMConfig locates an OpenAI-compatible chat completions endpoint.
+type LLMConfig struct {
+	Endpoint  string `mapstructure:"endpoint" schema:"desc=Chat completions URL (default OpenAI)"`
+	Model     string `mapstructure:"model" schema:"desc=Model name"`
+	APIKeyEnv string `mapstructure:"api_key_env" schema:"desc=Environment variable holding the API key (default OPENAI_API_KEY)"`
+}
+
+// BrowserConfig configures the headless Chrome fetcher.
+type BrowserConfig struct {
+	ExecPath    string           `mapstructure:"exec_path" schema:"desc=Chrome or Chromium binary (default: found on PATH)"`
+	WaitFor     string           `mapstructure:"wait_for" schema:"desc=CSS selector to wait for before reading the page"`
+	Timeout     string           `mapstructure:"timeout" schema:"desc=Per-page load timeout (default 60s)"`
+	Screenshots ScreenshotConfig `mapstructure:"screenshots" schema:"desc=Full-page screenshots saved next to each page"`
+}
+
+// ScreenshotConfig controls full-page screenshots taken by the browser
+// fetcher.
+type ScreenshotConfig struct {
+	Enabled   bool   `mapstructure:"enabled" schema:"desc=Capture a screenshot of every page and reference it from the markdown"`
+	Format    string `mapstructure:"format" schema:"desc=Image format (default png);enum=png|webp|jpeg"`
+	Quality   int    `mapstructure:"quality" schema:"desc=Compression quality for webp and jpeg (default 80)"`
+	Width     int    `mapstructure:"width" schema:"desc=Viewport width in pixels (default 1280)"`
+	MaxHeight int    `mapstructure:"max_height" schema:"desc=Pages taller than this are cut off (default 10000)"`
+}
+
+// RedisConfig locates the Redis server used by the redis queue.
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr" schema:"desc=Redis address (default localhost:6379)"`
+	Password string `mapstructure:"password" schema:"desc=Redis password"`
+	DB       int    `mapstructure:"db" schema:"desc=Redis database number"`
+	Prefix   string `mapstructure:"prefix" schema:"desc=Key prefix (workers sharing a crawl use the same prefix)"`
+}
+
+// StorageConfig selects where colly's visited set and cookies are kept.
+type StorageConfig struct {
+	Backend      string `mapstructure:"backend" schema:"desc=Keep the visited set and cookies between runs on disk or in Redis (default: with the queue, for one crawl);enum=memory|disk|redis"`
+	Path         string `mapstructure:"path" schema:"desc=Disk storage database path (default: <output>/.crawl-visited.db)"`
+	RevisitAfter string `mapstructure:"revisit_after" schema:"desc=Fetch pages visited by an earlier run again after this long (e.g. 168h; default: never)"`
 }
 
 // Config defines the top-level configuration structure.
 type Config struct {
-	Output     string       `mapstructure:"output"`
-	Flat       bool         `mapstructure:"flat"`
-	ConfigFile string       `mapstructure:"config"`
-	FileRename string       `mapstructure:"file_rename"`
-	Patterns   []string     `mapstructure:"patterns"`
-	Rules      []RuleConfig `mapstructure:"rules"`
+	Output        string            `mapstructure:"output" schema:"desc=Output directory"`
+	Namespace     string            `mapstructure:"namespace" schema:"desc=Tenant name; output, manifests, locks, crawl state, and Redis keys are kept apart under it"`
+	Flat          bool              `mapstructure:"flat" schema:"desc=Save files in a flat directory structure"`
+	ConfigFiles   []string          `mapstructure:"config" schema:"desc=Pattern files read in order: line-based, or .yaml, .json, or .toml"`
+	FileRename    string            `mapstructure:"file_rename" schema:"desc=Rename output markdown files (e.g. SKILL.md)"`
+	Compression   string            `mapstructure:"compression" schema:"desc=Keep each page's markdown and saved HTML compressed, for large caches; the manifest records the encoding;enum=none|gzip|zstd"`
+	SignKey       string            `mapstructure:"sign_key" schema:"desc=Secret key used to sign the manifest after crawling"`
+	Fetcher       string            `mapstructure:"fetcher" schema:"desc=Where pages are fetched from;enum=http|local|warc|browser"`
+	AsOf          string            `mapstructure:"as_of" schema:"desc=Crawl the Wayback Machine snapshots nearest to this date (such as 2023-06-01) instead of the live site"`
+	LocalRoot     string            `mapstructure:"local_root" schema:"desc=Directory of saved HTML served by the local fetcher"`
+	Browser       BrowserConfig     `mapstructure:"browser" schema:"desc=Headless Chrome settings for the browser fetcher"`
+	WARCFiles     []string          `mapstructure:"warc_files" schema:"desc=WARC files (globs allowed) served by the warc fetcher"`
+	Queue         string            `mapstructure:"queue" schema:"desc=Where the crawl frontier and visited set are kept;enum=memory|disk|redis"`
+	Redis         RedisConfig       `mapstructure:"redis" schema:"desc=Redis server for the redis queue (distributed crawls)"`
+	StateFile     string            `mapstructure:"state_file" schema:"desc=Disk queue database path (default: <output>/.crawl-state.db)"`
+	Storage       StorageConfig     `mapstructure:"storage" schema:"desc=Visited set and cookies kept between runs"`
+	WARCOut       string            `mapstructure:"warc_out" schema:"desc=Record every fetched response to this WARC file (.warc or .warc.gz)"`
+	EventLog      string            `mapstructure:"event_log" schema:"desc=Append every URL considered, rule decision, fetch, and output path to this JSONL file"`
+	ErrorsOut     string            `mapstructure:"errors_out" schema:"desc=Write the crawl's errors, classified by kind, to this JSON file"`
+	Tracing       TracingConfig     `mapstructure:"tracing" schema:"desc=OpenTelemetry traces of the crawl pipeline, exported with OTLP"`
+	URLs          string            `mapstructure:"urls" schema:"desc=File listing the URLs to crawl, one per line (# starts a comment); only they are fetched and no links are followed"`
+	MaxDuration   string            `mapstructure:"max_duration" schema:"desc=Stop the crawl after this long (e.g. 30m) and save the pending frontier"`
+	Resolve       []string          `mapstructure:"resolve" schema:"desc=Host overrides in curl --resolve form host:port:address, e.g. docs.example.com:443:10.0.0.5"`
+	DNS           DNSConfig         `mapstructure:"dns" schema:"desc=Custom DNS resolution for fetching pages"`
+	Throttle      ThrottleConfig    `mapstructure:"throttle" schema:"desc=Per-host pacing that backs off on 429 and 503 responses"`
+	FetchErrors   FetchErrorConfig  `mapstructure:"fetch_errors" schema:"desc=Retries of failed fetches, and the prompt or policy once they run out"`
+	Traps         TrapConfig        `mapstructure:"traps" schema:"desc=Detection of search, filter, and calendar pages that would make the crawl endless"`
+	Schedule      []CrawlWindow     `mapstructure:"schedule" schema:"desc=Times of day some hosts may be crawled"`
+	Sources       []SourceConfig    `mapstructure:"sources" schema:"desc=Git repositories whose docs are converted alongside the crawl"`
+	Patterns      []string          `mapstructure:"patterns" schema:"desc=Glob patterns to crawl (prefix with ! to ignore)"`
+	Rules         []RuleConfig      `mapstructure:"rules" schema:"desc=Verbose crawl rules"`
+	Precedence    string            `mapstructure:"rule_precedence" schema:"desc=Which matching rule wins: any ignore rule (default), the most specific rule, or the last rule in order;enum=ignore|specific|order"`
+	Extraction    ExtractionConfig  `mapstructure:"extraction" schema:"desc=Default content extraction settings"`
+	Scopes        []ExtractionScope `mapstructure:"scopes" schema:"desc=Extraction overrides scoped by URL glob"`
+	Disambiguate  bool              `mapstructure:"disambiguate_titles" schema:"desc=Qualify duplicate page titles in a skill with their section, e.g. Installation (Android)"`
+	NavOrder      NavOrderConfig    `mapstructure:"nav_order" schema:"desc=Reading order of pages from the site navigation"`
+	IgnoreNoindex bool              `mapstructure:"ignore_noindex" schema:"desc=Save pages marked noindex by a robots meta tag or X-Robots-Tag header"`
+	RawMarkdown   bool              `mapstructure:"raw_markdown" schema:"desc=Send Accept: text/markdown and save pages served as markdown (by negotiation, or as text/plain from .md paths) as is instead of converting HTML"`
+	ExcludeNoAI   bool              `mapstructure:"exclude_noai" schema:"desc=Leave out pages whose robots meta tag or X-Robots-Tag header says noai or noimageai, instead of marking them restricted"`
+	Tombstones    TombstoneConfig   `mapstructure:"tombstones" schema:"desc=Deletion records for pages removed from the output"`
+	Wayback       WaybackConfig     `mapstructure:"wayback" schema:"desc=Wayback Machine fallback for pages that disappeared upstream"`
+	Categories    []CategoryRule    `mapstructure:"categories" schema:"desc=Rules assigning pages to categories by URL or keyword"`
+	CategoryDirs  bool              `mapstructure:"category_dirs" schema:"desc=Save each page under a directory named after its first category"`
+	Canonical     bool              `mapstructure:"canonical_names" schema:"desc=Save pages under their canonical URL on the same site, so tracking and alias URLs share one file"`
+	Status        StatusConfig      `mapstructure:"status" schema:"desc=Detection of soft 404s and login walls"`
+	Keywords      KeywordsConfig    `mapstructure:"keywords" schema:"desc=Keyword extraction into page frontmatter"`
+	ChangeSummary ChangesConfig     `mapstructure:"change_summary" schema:"desc=Per-skill summaries of what each crawl or replay changed"`
+	Glossary      GlossaryConfig    `mapstructure:"glossary" schema:"desc=Per-skill glossary extraction"`
+	Snippets      SnippetsConfig    `mapstructure:"snippets" schema:"desc=Per-skill code snippet collection"`
+	Commands      CommandsConfig    `mapstructure:"commands" schema:"desc=Per-skill catalog of the commands and flags of documented CLIs"`
+	Transcripts   TranscriptConfig  `mapstructure:"transcripts" schema:"desc=Video transcript ingestion"`
+	Notebooks     NotebookConfig    `mapstructure:"notebooks" schema:"desc=Jupyter notebook conversion"`
+	MDX           MDXConfig         `mapstructure:"mdx" schema:"desc=MDX component handling for git sources"`
+	FAQ           FAQConfig         `mapstructure:"faq" schema:"desc=Per-skill FAQ extraction"`
+	VectorStore   VectorStoreConfig `mapstructure:"vector_store" schema:"desc=OpenAI vector store the output is uploaded to"`
+	Gemini        GeminiConfig      `mapstructure:"gemini" schema:"desc=Gemini Files API or Vertex AI RAG corpus the output is uploaded to"`
+	Bot           BotConfig         `mapstructure:"bot" schema:"desc=Slack and Discord bot answering questions from the output"`
+	Validate      ValidateConfig    `mapstructure:"validate" schema:"desc=Rulesets the validate command checks skills against"`
+	Publish       PublishConfig     `mapstructure:"publish" schema:"desc=Where publish copies the skills and the checks it gates on"`
+	LLM           LLMConfig         `mapstructure:"llm" schema:"desc=LLM endpoint for features that can call one"`
+	SkillEntry    SkillEntryConfig  `mapstructure:"skill_entry" schema:"desc=Section entry points built from landing pages"`
+	Boilerplate   BoilerplateConfig `mapstructure:"boilerplate" schema:"desc=Site-wide boilerplate removal"`
+	Quality       QualityConfig     `mapstructure:"quality" schema:"desc=Conversion quality scoring and the review list of low-scoring pages"`
+	LargePages    LargePageConfig   `mapstructure:"large_pages" schema:"desc=Streaming of very large pages to disk"`
+	Compact       CompactConfig     `mapstructure:"compact" schema:"desc=Settings for the compact command"`
+	Extends       []string          `mapstructure:"extends" schema:"desc=Base config files this file builds on"`
+	Include       []string          `mapstructure:"include" schema:"desc=Config fragments (globs allowed) merged into this file"`
+
+	// profile identifies the configuration in provenance, and attended
+	// is set when the crawl runs with a terminal on stdin and stdout, so
+	// a page that keeps failing can be put to the user. Both are set by
+	// the command running the crawl rather than read from config.
+	profile  string
+	attended bool
 }
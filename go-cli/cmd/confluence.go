@@ -0,0 +1,258 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// confluenceExpand requests the rendered body, position, and metadata of
+// each page.
+const confluenceExpand = "body.export_view,ancestors,version,space"
+
+type confluencePage struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Ancestors []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"ancestors"`
+	Body struct {
+		ExportView struct {
+			Value string `json:"value"`
+		} `json:"export_view"`
+	} `json:"body"`
+	Version struct {
+		When string `json:"when"`
+	} `json:"version"`
+	Space struct {
+		Key string `json:"key"`
+	} `json:"space"`
+	Links struct {
+		WebUI    string `json:"webui"`
+		Download string `json:"download"`
+	} `json:"_links"`
+}
+
+type confluenceResults struct {
+	Results []confluencePage `json:"results"`
+	Links   struct {
+		Base string `json:"base"`
+		Next string `json:"next"`
+	} `json:"_links"`
+}
+
+// confluenceSource exports a Confluence space or page tree through the
+// REST API.
+type confluenceSource struct {
+	cfg  ConfluenceSource
+	base string
+	api  *apiClient
+}
+
+// ingestConfluence writes every page of a space, or of the tree below a
+// page, into the output. Pages are nested by their position in the page
+// tree: <output>/<site>/<space>/<parent>/<page>.
+func ingestConfluence(cfg ConfluenceSource, opts *CrawlOptions) error {
+	if cfg.Space == "" && cfg.PageID == "" {
+		return fmt.Errorf("confluence source needs a space or page_id")
+	}
+	token, err := sourceToken(cfg.TokenEnv)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if cfg.User != "" {
+		// Confluence Cloud: an account email and API token.
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cfg.User+":"+token)))
+	} else {
+		// Server and Data Center: a personal access token.
+		header.Set("Authorization", "Bearer "+token)
+	}
+	s := &confluenceSource{cfg: cfg, base: strings.TrimSuffix(cfg.URL, "/"), api: newAPIClient(header)}
+
+	var pages []confluencePage
+	if cfg.PageID != "" {
+		var root confluencePage
+		if err := s.api.getJSON(s.base+"/rest/api/content/"+url.PathEscape(cfg.PageID)+"?expand="+confluenceExpand, &root); err != nil {
+			return err
+		}
+		pages = append(pages, root)
+		descendants, err := s.list("/rest/api/content/" + url.PathEscape(cfg.PageID) + "/descendant/page?limit=50&expand=" + confluenceExpand)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, descendants...)
+	} else {
+		pages, err = s.list("/rest/api/content?type=page&limit=50&spaceKey=" + url.QueryEscape(cfg.Space) + "&expand=" + confluenceExpand)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, page := range pages {
+		if err := s.writePage(page, opts); err != nil {
+			fmt.Printf("Error writing Confluence page %s: %v\n", page.Title, err)
+		}
+	}
+	fmt.Printf("Converted %d pages from %s\n", len(pages), cfg.URL)
+	return nil
+}
+
+// list follows the pagination links of a content listing.
+func (s *confluenceSource) list(link string) ([]confluencePage, error) {
+	var pages []confluencePage
+	next := s.base + link
+	for next != "" {
+		var res confluenceResults
+		if err := s.api.getJSON(next, &res); err != nil {
+			return nil, err
+		}
+		pages = append(pages, res.Results...)
+		next = ""
+		if res.Links.Next != "" {
+			base := res.Links.Base
+			if base == "" {
+				base = s.base
+			}
+			next = base + res.Links.Next
+		}
+	}
+	return pages, nil
+}
+
+// treePath returns the page's path segments below the export root.
+func (s *confluenceSource) treePath(page confluencePage) []string {
+	if page.ID == s.cfg.PageID {
+		return []string{toPathCase(page.Title)}
+	}
+	var segments []string
+	for _, a := range page.Ancestors {
+		if a.ID == s.cfg.PageID {
+			// Pages above the exported tree are not part of the skill.
+			segments = nil
+		}
+		segments = append(segments, toPathCase(a.Title))
+	}
+	return append(segments, toPathCase(page.Title))
+}
+
+func (s *confluenceSource) writePage(page confluencePage, opts *CrawlOptions) error {
+	attachments := map[string]string{}
+	if s.cfg.Attachments {
+		var err error
+		if attachments, err = s.attachments(page); err != nil {
+			fmt.Printf("Warning: could not list attachments of %s: %v\n", page.Title, err)
+		}
+	}
+	html := localizeAttachments(page.Body.ExportView.Value, attachments)
+	body, err := htmlToMarkdown(html, opts)
+	if err != nil {
+		return err
+	}
+
+	site, _ := url.Parse(s.base)
+	space := page.Space.Key
+	if space == "" {
+		space = s.cfg.Space
+	}
+	out := &url.URL{Scheme: "https", Host: site.Hostname(), Path: "/" + path.Join(append([]string{strings.ToLower(space)}, s.treePath(page)...)...)}
+	dir, err := writeSourcePage(opts, out, convertedPage{
+		URL:          s.base + page.Links.WebUI,
+		Title:        page.Title,
+		Body:         body,
+		LastModified: page.Version.When,
+	})
+	if err != nil {
+		return err
+	}
+
+	for name, link := range attachments {
+		data, err := s.api.download(link)
+		if err != nil {
+			fmt.Printf("Warning: could not download attachment %s: %v\n", name, err)
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachments lists a page's attachments, mapping each saved file name to
+// its download URL.
+func (s *confluenceSource) attachments(page confluencePage) (map[string]string, error) {
+	res, err := s.list("/rest/api/content/" + url.PathEscape(page.ID) + "/child/attachment?limit=50")
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	for _, a := range res {
+		name := attachmentName(a.Title)
+		if name != "" && a.Links.Download != "" {
+			files[name] = s.base + a.Links.Download
+		}
+	}
+	return files, nil
+}
+
+// attachmentName returns the file name an attachment is saved under.
+func attachmentName(title string) string {
+	name := filepath.Base(strings.ReplaceAll(title, "\\", "/"))
+	if name == "." || name == "/" || strings.HasPrefix(name, ".") {
+		return ""
+	}
+	return name
+}
+
+// localizeAttachments points links and images that reference downloaded
+// attachments at the saved files.
+func localizeAttachments(html string, attachments map[string]string) string {
+	if len(attachments) == 0 {
+		return html
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return html
+	}
+	doc.Find("a[href], img[src]").Each(func(_ int, el *goquery.Selection) {
+		attr := "href"
+		if goquery.NodeName(el) == "img" {
+			attr = "src"
+		}
+		u, err := url.Parse(el.AttrOr(attr, ""))
+		if err != nil || !strings.Contains(u.Path, "/attachments/") {
+			return
+		}
+		if name := attachmentName(path.Base(u.Path)); attachments[name] != "" {
+			el.SetAttr(attr, name)
+		}
+	})
+	out, err := doc.Find("body").Html()
+	if err != nil {
+		return html
+	}
+	return out
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// convertURL is the page to fetch; convertBase is the address of HTML
+// read from a file or stdin; convertNoFrontmatter prints only the body.
+// convertAll re-converts every profile below convertProfilesDir,
+// convertJobs at a time; convertDryRun only lists what would change, and
+// convertReport names the JSON report written.
+var (
+	convertURL           string
+	convertBase          string
+	convertNoFrontmatter bool
+	convertAll           bool
+	convertProfilesDir   string
+	convertJobs          int
+	convertDryRun        bool
+	convertReport        string
+)
+
+// defaultConvertBase resolves the relative links of HTML given without an
+// address. It is not recorded as the page's address.
+const defaultConvertBase = "https://localhost/index.html"
+
+var convertCmd = &cobra.Command{
+	Use:   "convert [file.html]",
+	Short: "Convert one page to markdown on stdout",
+	Long: `Converts a single page with the crawl's extraction pipeline and prints
+the markdown to stdout, without a crawl or an output directory. The HTML
+is read from the file, or from stdin when there is none (or it is -);
+with --url and no file, the page is fetched instead.
+
+--base-url gives HTML read from a file or stdin the address it came from,
+which resolves relative links and selects extraction scopes. A --url with
+a fragment converts only that section. Messages go to stderr.
+
+--all-profiles instead re-converts the saved responses of every cached
+site: each directory below --profiles-dir with a skills.yaml is replayed
+and applied with its own configuration, --jobs at a time, and a table of
+what changed is printed (and written as JSON with --report).`,
+	Example: `  curl -s https://docs.example.com/install.html | agent-skills-generator convert --base-url https://docs.example.com/install.html
+  agent-skills-generator convert --url https://docs.example.com/api/#auth --no-frontmatter
+  agent-skills-generator convert --all-profiles --profiles-dir skills --jobs 8 --report convert-report.json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if convertAll {
+			if len(args) > 0 || convertURL != "" {
+				fmt.Println("Error: --all-profiles converts saved responses and takes no file or --url")
+				os.Exit(1)
+			}
+			if !convertAllProfiles(convertProfilesDir, convertJobs, convertDryRun, convertReport) {
+				os.Exit(1)
+			}
+			return
+		}
+
+		// Progress and warnings printed by the pipeline go to stderr, so
+		// stdout holds only the markdown.
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		link := convertBase
+		var body []byte
+		var err error
+		switch {
+		case len(args) == 0 && convertURL != "":
+			link = convertURL
+			body, err = fetchPage(convertURL)
+		case len(args) == 0 || args[0] == "-":
+			body, err = io.ReadAll(os.Stdin)
+		default:
+			body, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			fmt.Printf("Error reading page: %v\n", err)
+			os.Exit(1)
+		}
+		if link == "" {
+			link = convertURL
+		}
+		anonymous := link == ""
+		if anonymous {
+			link = defaultConvertBase
+		}
+		markdown, err := convertPage(cfg, link, body)
+		if err != nil {
+			fmt.Printf("Error converting %s: %v\n", link, err)
+			os.Exit(1)
+		}
+		if anonymous {
+			markdown = clearAddress(markdown)
+		}
+		if convertNoFrontmatter {
+			_, markdown = splitFrontmatter(markdown)
+		}
+		fmt.Fprintln(stdout, strings.TrimRight(markdown, "\n"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.Flags().StringVar(&convertURL, "url", "", "fetch and convert this page when no file is given")
+	convertCmd.Flags().StringVar(&convertBase, "base-url", "", "address of the HTML read from a file or stdin (default --url; without one, relative links resolve against "+defaultConvertBase+")")
+	convertCmd.Flags().BoolVar(&convertNoFrontmatter, "no-frontmatter", false, "print the markdown without its frontmatter")
+	convertCmd.Flags().BoolVar(&convertAll, "all-profiles", false, "re-convert the saved responses of every profile (directory with a skills.yaml) below --profiles-dir")
+	convertCmd.Flags().StringVar(&convertProfilesDir, "profiles-dir", ".", "directory searched for profiles with --all-profiles")
+	convertCmd.Flags().IntVar(&convertJobs, "jobs", runtime.NumCPU(), "profiles converted at once with --all-profiles")
+	convertCmd.Flags().BoolVar(&convertDryRun, "dry-run", false, "with --all-profiles, list the files that would change without writing them")
+	convertCmd.Flags().StringVar(&convertReport, "report", "", "with --all-profiles, write the consolidated report to this JSON file")
+}
+
+// convertEvents records what happened to the converted page: its entry,
+// or the URL and reason it was skipped.
+type convertEvents struct {
+	NopEvents
+	entry  *ManifestEntry
+	url    string
+	reason string
+}
+
+func (e *convertEvents) OnPageConverted(entry ManifestEntry) { e.entry = &entry }
+func (e *convertEvents) OnSkip(url, reason string)           { e.url, e.reason = url, reason }
+func (e *convertEvents) OnError(_ string, err error)         { e.reason = err.Error() }
+
+// clearAddress blanks the url and provenance source_url of markdown
+// converted from HTML given without an address, which the placeholder
+// address only filled in.
+func clearAddress(markdown string) string {
+	fm, body := splitFrontmatter(markdown)
+	fields, err := parseFrontmatter(fm)
+	if err != nil {
+		return markdown
+	}
+	metadata, _ := fields.get("metadata")
+	meta, ok := metadata.(frontmatter)
+	if !ok {
+		return markdown
+	}
+	meta.set("url", "")
+	provenance, _ := meta.get("provenance")
+	if prov, ok := provenance.(frontmatter); ok {
+		prov.set("source_url", "")
+		meta.set("provenance", prov)
+	}
+	fields.set("metadata", meta)
+	return fields.render() + body
+}
+
+// convertPage converts the page at link with body as its HTML the way a
+// crawl would, in a scratch output directory, and returns the markdown.
+func convertPage(cfg Config, link string, body []byte) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("%q is not an absolute URL", link)
+	}
+	opts := newCrawlOptions(&cfg)
+	if err := opts.useConversionConfig(cfg); err != nil {
+		return "", err
+	}
+	scratch, err := os.MkdirTemp("", "convert-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(scratch)
+	opts.Output = scratch
+	events := &convertEvents{}
+	opts.Events = events
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "text/html; charset=utf-8")
+	if isNotebook(u, "") {
+		headers.Set("Content-Type", "application/x-ipynb+json")
+	}
+	saveResponse(&colly.Response{
+		StatusCode: http.StatusOK,
+		Body:       body,
+		Request:    &colly.Request{URL: u},
+		Headers:    &headers,
+	}, opts)
+	if events.entry == nil {
+		if events.reason != "" {
+			return "", fmt.Errorf("page left out: %s", events.reason)
+		}
+		return "", fmt.Errorf("no markdown produced")
+	}
+	data, err := os.ReadFile(filepath.Join(scratch, filepath.FromSlash(events.entry.Path)))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// profileConfigName is the config file that makes a directory a profile
+// for convert --all-profiles.
+const profileConfigName = "skills.yaml"
+
+// ConvertReport is the consolidated result of convert --all-profiles.
+type ConvertReport struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	Jobs        int          `json:"jobs"`
+	DryRun      bool         `json:"dry_run,omitempty"`
+	Profiles    []ProfileRun `json:"profiles"`
+}
+
+// ProfileRun is the result of re-converting one profile: the pages it
+// replayed and the files that changed, by replay --stat status (A, M, D,
+// R). Output holds what the run printed when it failed.
+type ProfileRun struct {
+	Profile  string         `json:"profile"`
+	Status   string         `json:"status"`
+	Pages    int            `json:"pages"`
+	Changes  map[string]int `json:"changes,omitempty"`
+	Files    []string       `json:"files,omitempty"`
+	Duration string         `json:"duration"`
+	Error    string         `json:"error,omitempty"`
+	Output   string         `json:"output,omitempty"`
+}
+
+// Profile run statuses.
+const (
+	profileConverted = "converted"
+	profileUnchanged = "unchanged"
+	profileSkipped   = "skipped"
+	profileFailed    = "failed"
+)
+
+// findProfiles returns the directories below root holding a skills.yaml,
+// skipping hidden directories such as output caches.
+func findProfiles(root string) ([]string, error) {
+	var profiles []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == profileConfigName {
+			profiles = append(profiles, filepath.Dir(path))
+		}
+		return nil
+	})
+	return profiles, err
+}
+
+// convertAllProfiles re-converts the saved responses of every profile
+// below root, jobs at a time, and prints a consolidated report. Each
+// profile runs replay --json in its own process, in its own directory, so
+// its relative paths resolve there and its options cannot leak into
+// another's. It reports whether every profile converted.
+func convertAllProfiles(root string, jobs int, dryRun bool, reportPath string) bool {
+	profiles, err := findProfiles(root)
+	if err != nil {
+		fmt.Printf("Error finding profiles: %v\n", err)
+		return false
+	}
+	if len(profiles) == 0 {
+		fmt.Printf("No %s found under %s\n", profileConfigName, root)
+		return false
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Printf("Error finding the executable: %v\n", err)
+		return false
+	}
+	jobs = max(1, min(jobs, len(profiles)))
+	fmt.Printf("Converting %d profiles, %d at a time\n", len(profiles), jobs)
+
+	runs := make([]ProfileRun, len(profiles))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				runs[i] = runProfile(exe, root, profiles[i], dryRun)
+				r := runs[i]
+				fmt.Printf("%s: %s, %d pages, %d files changed (%s)\n", r.Profile, r.Status, r.Pages, len(r.Files), r.Duration)
+			}
+		}()
+	}
+	for i := range profiles {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	ok := true
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tSTATUS\tPAGES\tADDED\tMODIFIED\tREMOVED\tRENAMED\tTIME")
+	for _, r := range runs {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n", r.Profile, r.Status, r.Pages, r.Changes["A"], r.Changes["M"], r.Changes["D"], r.Changes["R"], r.Duration)
+		if r.Status == profileFailed {
+			ok = false
+		}
+	}
+	w.Flush()
+	for _, r := range runs {
+		if r.Status == profileFailed {
+			fmt.Printf("Error converting %s: %s\n", r.Profile, r.Error)
+		}
+	}
+
+	if reportPath != "" {
+		report := ConvertReport{GeneratedAt: time.Now().UTC(), Jobs: jobs, DryRun: dryRun, Profiles: runs}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = os.WriteFile(reportPath, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			return false
+		}
+	}
+	return ok
+}
+
+// runProfile replays the saved responses of the profile in dir, applying
+// the result unless dryRun is set, and reads its outcome from the
+// replay --json result.
+func runProfile(exe, root, dir string, dryRun bool) ProfileRun {
+	name, err := filepath.Rel(root, dir)
+	if err != nil {
+		name = dir
+	}
+	run := ProfileRun{Profile: filepath.ToSlash(name), Changes: map[string]int{}}
+	args := []string{"replay", "--json"}
+	if !dryRun {
+		args = append(args, "--apply")
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "NO_COLOR=1")
+	var result, out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &result, &out
+	start := time.Now()
+	err = cmd.Run()
+	run.Duration = time.Since(start).Round(time.Millisecond).String()
+
+	if err == nil {
+		var replayed ReplayResult
+		if err = json.Unmarshal(result.Bytes(), &replayed); err != nil {
+			err = fmt.Errorf("reading replay result: %w", err)
+		}
+		run.Pages = replayed.Pages
+		for _, f := range replayed.Files {
+			run.Changes[f.Status]++
+			run.Files = append(run.Files, f.Status+" "+f.Path)
+		}
+	}
+	switch {
+	case len(run.Files) > 0:
+		run.Status = profileConverted
+	case run.Pages == 0:
+		run.Status = profileSkipped
+	default:
+		run.Status = profileUnchanged
+	}
+	if err != nil {
+		run.Status = profileFailed
+		run.Error = err.Error()
+		for _, line := range strings.Split(out.String(), "\n") {
+			if strings.HasPrefix(line, "Error") {
+				run.Error = line
+			}
+		}
+		run.Output = result.String() + out.String()
+	}
+	return run
+}
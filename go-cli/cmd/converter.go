@@ -0,0 +1,245 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/strikethrough"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/table"
+	"golang.org/x/net/html"
+)
+
+// markdownConverter converts HTML to markdown with one of the engines.
+type markdownConverter interface {
+	ConvertString(html string) (string, error)
+}
+
+// HTML-to-markdown engines: versions of JohannesKaufmann/html-to-markdown.
+const (
+	engineV1 = "v1"
+	engineV2 = "v2"
+)
+
+// Converter plugins. gfm stands for the GitHub Flavored Markdown ones the
+// engine has.
+const (
+	pluginGFM           = "gfm"
+	pluginTables        = "tables"
+	pluginStrikethrough = "strikethrough"
+	pluginTaskLists     = "task_lists"
+)
+
+// enginePlugins lists the plugins each engine has; v2 has no task lists.
+var enginePlugins = map[string][]string{
+	engineV1: {pluginTables, pluginStrikethrough, pluginTaskLists},
+	engineV2: {pluginTables, pluginStrikethrough},
+}
+
+// codeFences maps the code_fence setting to its fence.
+var codeFences = map[string]string{
+	"backticks": "```",
+	"tildes":    "~~~",
+}
+
+// checkConverter reports an unknown engine, plugin, or option.
+func checkConverter(cfg ConverterConfig) error {
+	engine := converterEngine(cfg)
+	available, ok := enginePlugins[engine]
+	if !ok {
+		return fmt.Errorf("unknown converter engine %q: want v1 or v2", cfg.Engine)
+	}
+	for _, name := range cfg.Plugins {
+		if name != pluginGFM && !slices.Contains(available, name) {
+			return fmt.Errorf("converter engine %s has no %q plugin", engine, name)
+		}
+	}
+	for _, option := range []struct{ name, value string }{
+		{"heading_style", cfg.HeadingStyle},
+		{"bullet_marker", cfg.BulletMarker},
+		{"code_fence", cfg.CodeFence},
+		{"em_delimiter", cfg.EmDelimiter},
+		{"strong_delimiter", cfg.StrongDelimiter},
+	} {
+		if option.value == "" {
+			continue
+		}
+		var valid []string
+		switch option.name {
+		case "heading_style":
+			valid = []string{"atx", "setext"}
+		case "bullet_marker":
+			valid = []string{"-", "+", "*"}
+		case "code_fence":
+			valid = sortedKeys(codeFences)
+		case "em_delimiter":
+			valid = []string{"_", "*"}
+		case "strong_delimiter":
+			valid = []string{"**", "__"}
+		}
+		if !slices.Contains(valid, option.value) {
+			return fmt.Errorf("invalid converter %s %q: want one of %v", option.name, option.value, valid)
+		}
+	}
+	return nil
+}
+
+// mergeConverter overlays the settings of scope on base: set options
+// win and plugins accumulate.
+func mergeConverter(base, scope ConverterConfig) ConverterConfig {
+	merged := base
+	merged.Plugins = append(append([]string{}, base.Plugins...), scope.Plugins...)
+	for _, option := range []struct {
+		dst *string
+		src string
+	}{
+		{&merged.Engine, scope.Engine},
+		{&merged.HeadingStyle, scope.HeadingStyle},
+		{&merged.BulletMarker, scope.BulletMarker},
+		{&merged.CodeFence, scope.CodeFence},
+		{&merged.EmDelimiter, scope.EmDelimiter},
+		{&merged.StrongDelimiter, scope.StrongDelimiter},
+		{&merged.HorizontalRule, scope.HorizontalRule},
+	} {
+		if option.src != "" {
+			*option.dst = option.src
+		}
+	}
+	return merged
+}
+
+// converterEngine returns the engine cfg selects.
+func converterEngine(cfg ConverterConfig) string {
+	if cfg.Engine == "" {
+		return engineV1
+	}
+	return cfg.Engine
+}
+
+// converterPlugins returns the plugins cfg enables, with gfm expanded to
+// those of its engine.
+func converterPlugins(cfg ConverterConfig) map[string]bool {
+	enabled := map[string]bool{}
+	for _, name := range cfg.Plugins {
+		if name == pluginGFM {
+			for _, p := range enginePlugins[converterEngine(cfg)] {
+				enabled[p] = true
+			}
+			continue
+		}
+		enabled[name] = true
+	}
+	return enabled
+}
+
+// newMarkdownConverter returns a converter with the engine, plugins, and
+// options of cfg, which checkConverter accepted. With transcripts, video
+// iframes become links followed by their transcripts.
+func newMarkdownConverter(cfg ConverterConfig, transcripts *transcriptClient) markdownConverter {
+	if cfg.Engine == engineV2 {
+		return newV2Converter(cfg, transcripts)
+	}
+	conv := md.NewConverter("", true, &md.Options{
+		HeadingStyle:     cfg.HeadingStyle,
+		HorizontalRule:   cfg.HorizontalRule,
+		BulletListMarker: cfg.BulletMarker,
+		Fence:            codeFences[cfg.CodeFence],
+		EmDelimiter:      cfg.EmDelimiter,
+		StrongDelimiter:  cfg.StrongDelimiter,
+	})
+	plugins := converterPlugins(cfg)
+	if plugins[pluginTables] {
+		conv.Use(plugin.Table())
+	}
+	if plugins[pluginStrikethrough] {
+		conv.Use(plugin.Strikethrough(""))
+	}
+	if plugins[pluginTaskLists] {
+		conv.Use(plugin.TaskListItems())
+	}
+	if transcripts != nil {
+		addTranscriptRule(conv, transcripts)
+	}
+	return conv
+}
+
+// v2Converter is the v2 engine, whose ConvertString takes options.
+type v2Converter struct {
+	conv *converter.Converter
+}
+
+func (c v2Converter) ConvertString(html string) (string, error) {
+	return c.conv.ConvertString(html)
+}
+
+// newV2Converter returns the v2 engine configured by cfg.
+func newV2Converter(cfg ConverterConfig, transcripts *transcriptClient) markdownConverter {
+	var options []commonmark.OptionFunc
+	if cfg.HeadingStyle == "setext" {
+		options = append(options, commonmark.WithHeadingStyle(commonmark.HeadingStyleSetext))
+	}
+	if cfg.HorizontalRule != "" {
+		options = append(options, commonmark.WithHorizontalRule(cfg.HorizontalRule))
+	}
+	if cfg.BulletMarker != "" {
+		options = append(options, commonmark.WithBulletListMarker(cfg.BulletMarker))
+	}
+	if fence := codeFences[cfg.CodeFence]; fence != "" {
+		options = append(options, commonmark.WithCodeBlockFence(fence))
+	}
+	if cfg.EmDelimiter != "" {
+		options = append(options, commonmark.WithEmDelimiter(cfg.EmDelimiter))
+	}
+	if cfg.StrongDelimiter != "" {
+		options = append(options, commonmark.WithStrongDelimiter(cfg.StrongDelimiter))
+	}
+
+	plugins := []converter.Plugin{base.NewBasePlugin(), commonmark.NewCommonmarkPlugin(options...)}
+	enabled := converterPlugins(cfg)
+	if enabled[pluginTables] {
+		plugins = append(plugins, table.NewTablePlugin())
+	}
+	if enabled[pluginStrikethrough] {
+		plugins = append(plugins, strikethrough.NewStrikethroughPlugin())
+	}
+	conv := converter.NewConverter(converter.WithPlugins(plugins...))
+	if transcripts != nil {
+		conv.Register.RendererFor("iframe", converter.TagTypeBlock, func(ctx converter.Context, w converter.Writer, n *html.Node) converter.RenderStatus {
+			out := transcripts.embed(nodeAttr(n, "src"), nodeAttr(n, "title"))
+			if out == "" {
+				return converter.RenderTryNext
+			}
+			w.WriteString(out)
+			return converter.RenderSuccess
+		}, converter.PriorityEarly)
+	}
+	return v2Converter{conv: conv}
+}
+
+// nodeAttr returns the value of an attribute of n, or "".
+func nodeAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
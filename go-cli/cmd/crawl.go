@@ -18,31 +18,27 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	"net/http"
 	"net/url"
 
-	md "github.com/JohannesKaufmann/html-to-markdown"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gobwas/glob"
 	"github.com/gocolly/colly/v2"
+	"github.com/gocolly/colly/v2/queue"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
-// configFile holds the path to the configuration file.
-// outputDir holds the path to the output directory.
-// flatOutput indicates whether to use a flat directory structure.
-// fileRename holds the optional filename to rename the output file to.
-var (
-	configFile string
-	outputDir  string
-	flatOutput bool
-	fileRename string
-)
+// crawlParallelism is the number of requests in flight at once.
+const crawlParallelism = 4
 
 // crawlCmd represents the crawl command.
 var crawlCmd = &cobra.Command{
@@ -54,13 +50,48 @@ var crawlCmd = &cobra.Command{
 	},
 }
 
+// noDescription is the description given to pages without one.
+const noDescription = "No description available."
+
+// crawlFlags holds flags that only apply to crawling. The same flags are
+// registered on the root command so a bare invocation accepts them too.
+var crawlFlags = pflag.NewFlagSet("crawl", pflag.ExitOnError)
+
 func init() {
 	rootCmd.AddCommand(crawlCmd)
-	// Flags are now on rootCmd
+	// Global flags are on rootCmd
+
+	crawlFlags.String("queue", "memory", "where the crawl frontier is kept: memory, disk, or redis")
+	crawlFlags.Bool("distributed", false, "share the crawl with other workers through Redis (same as --queue redis)")
+	crawlFlags.String("redis", "", "Redis address for distributed crawls (default localhost:6379)")
+	crawlFlags.String("storage", "", "keep the visited set and cookies between runs: disk or redis")
+	crawlFlags.String("state-file", "", "disk queue database (default: <output>/"+defaultStateFile+")")
+	crawlFlags.Bool("ignore-noindex", false, "save pages marked noindex instead of skipping them")
+	crawlFlags.Bool("exclude-noai", false, "skip pages marked noai or noimageai instead of saving them as restricted")
+	crawlFlags.Bool("non-interactive", false, "never ask whether to retry, skip, or abort a failing page; apply fetch_errors.policy")
+	crawlFlags.Bool("raw-markdown", false, "ask for markdown with Accept: text/markdown and save markdown responses without converting HTML")
+	crawlFlags.Bool("dedup-boilerplate", false, "remove text blocks repeated across many pages of a site")
+	crawlFlags.String("compression", "", "keep each page's markdown and saved HTML compressed: none, gzip, or zstd")
+	crawlFlags.String("sign-key", "", "sign the manifest with this secret key after crawling")
+	crawlFlags.String("fetcher", "http", "where pages are fetched from: http, local, warc, or browser")
+	crawlFlags.String("as-of", "", "crawl the Wayback Machine snapshots nearest to this date (e.g. 2023-06-01) instead of the live site")
+	crawlFlags.String("local-root", "", "directory of saved HTML for the local fetcher (e.g. a wget mirror)")
+	crawlFlags.StringSlice("warc-file", nil, "WARC file to read pages from with --fetcher warc (repeatable)")
+	crawlFlags.String("warc-out", "", "record every fetched response to this WARC file (.warc or .warc.gz)")
+	crawlFlags.StringArray("resolve", nil, "connect to host:port at address instead of its DNS address, as host:port:address (repeatable)")
+	crawlFlags.StringSlice("dns-server", nil, "DNS server used instead of the system resolver (repeatable)")
+	crawlFlags.String("event-log", "", "append every URL considered, rule decision, fetch, and output path to this JSONL file")
+	crawlFlags.String("errors-out", "", "write the crawl's errors, classified by kind, to this JSON file")
+	crawlFlags.String("max-duration", "", "stop the crawl after this long (e.g. 30m) and save the pending frontier")
+	crawlFlags.String("profile", "", "how much of each page is saved: mirror (default), digest, or snippets")
+	crawlFlags.Bool("html-fallback", false, "embed the source HTML of elements that convert poorly, such as tables with merged cells")
+	crawlFlags.Bool("stop-sections", false, "remove sections such as See also, Related articles, and Feedback")
+	crawlFlags.String("urls", "", "crawl exactly the URLs listed in this file (one per line, - for stdin) without following links")
+	crawlFlags.String("resume", "", "continue a partial crawl from the token in its manifest")
+	crawlCmd.Flags().AddFlagSet(crawlFlags)
 }
 
-// runCrawl executes the crawler logic.
-// It loads the configuration, sets up the collector, and starts the crawl.
+// runCrawl loads the configuration and crawls the site it describes.
 func runCrawl(cmd *cobra.Command) {
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
@@ -68,10 +99,76 @@ func runCrawl(cmd *cobra.Command) {
 		return
 	}
 
-	outputDir = cfg.Output
-	flatOutput = cfg.Flat
-	configFile = cfg.ConfigFile
-	fileRename = cfg.FileRename
+	if viper.GetBool("distributed") {
+		cfg.Queue = "redis"
+	}
+
+	cfg.profile = configProfile(cfg)
+	cfg.attended = !cfg.FetchErrors.NonInteractive && cfg.URLs != "-" && isTerminal(os.Stdin) && isTerminal(os.Stdout)
+
+	crawlSite(cfg, viper.GetString("resume"), nil)
+}
+
+// configProfile returns what identifies cfg in provenance: the config
+// file read, or else its pattern files.
+func configProfile(cfg Config) string {
+	if used := viper.ConfigFileUsed(); used != "" {
+		return used
+	}
+	return strings.Join(cfg.ConfigFiles, ",")
+}
+
+// crawlSite sets up the collector and crawls the site described by cfg,
+// continuing the partial crawl identified by resumeToken when it is set.
+// Progress is reported to events when it is not nil.
+func crawlSite(cfg Config, resumeToken string, events CrawlEvents) {
+	if err := checkNamespace(cfg.Namespace); err != nil {
+		fmt.Printf("Config error: %v\n", err)
+		return
+	}
+	opts := newCrawlOptions(&cfg)
+	if events != nil {
+		opts.Events = events
+	}
+
+	manifest, err := loadManifest(opts.Output)
+	if err != nil {
+		fmt.Printf("Warning: could not read manifest, starting fresh: %v\n", err)
+		manifest = &Manifest{Pages: map[string]*ManifestEntry{}}
+	}
+	opts.manifest = manifest
+	manifest.Config = opts.profile
+	manifest.Namespace = cfg.Namespace
+	if err := manifest.keepTombstones(cfg.Tombstones); err != nil {
+		fmt.Printf("Error processing tombstone settings: %v\n", err)
+		return
+	}
+	manifest.Partial = false
+	manifest.ResumeToken = ""
+
+	var maxDuration time.Duration
+	if cfg.MaxDuration != "" {
+		maxDuration, err = time.ParseDuration(cfg.MaxDuration)
+		if err != nil {
+			fmt.Printf("Error parsing max duration: %v\n", err)
+			return
+		}
+	}
+
+	pacing, err := newThrottle(cfg.Throttle)
+	if err != nil {
+		fmt.Printf("Error processing throttle settings: %v\n", err)
+		return
+	}
+
+	var resume *resumeState
+	if resumeToken != "" {
+		resume, err = loadResumeState(opts.Output, resumeToken)
+		if err != nil {
+			fmt.Printf("Error loading resume state: %v\n", err)
+			return
+		}
+	}
 
 	allowedGlobs, ignoredGlobs, err := loadRules(&cfg)
 	if err != nil {
@@ -81,30 +178,194 @@ func runCrawl(cmd *cobra.Command) {
 
 	fmt.Printf("Loaded %d allowed patterns and %d ignored patterns\n", len(allowedGlobs), len(ignoredGlobs))
 
+	// A URL list replaces the seeds and link following: exactly the
+	// listed URLs are crawled, whether or not an allow rule matches them.
+	var urlList []string
+	var listed map[string]bool
+	if cfg.URLs != "" {
+		urlList, err = readURLList(cfg.URLs)
+		if err != nil {
+			fmt.Printf("Error reading URL list: %v\n", err)
+			return
+		}
+		listed = map[string]bool{}
+		for _, link := range urlList {
+			listed[link] = true
+		}
+		fmt.Printf("Crawling %d URLs from %s without following links\n", len(urlList), cfg.URLs)
+	}
+
+	if cfg.EventLog != "" {
+		opts.log, err = openEventLog(cfg.EventLog, opts)
+		if err != nil {
+			fmt.Printf("Error opening event log: %v\n", err)
+			return
+		}
+		opts.Events = multiEvents{opts.Events, opts.log}
+		opts.log.record(LogEvent{Event: logCrawlStarted, Config: opts.profile})
+		defer func() {
+			opts.log.record(LogEvent{Event: logCrawlFinished})
+			opts.log.Close()
+		}()
+	}
+
+	opts.tracer = newTracer(cfg.Tracing)
+	if opts.tracer != nil {
+		opts.tracer.start("crawl", stringAttr("skills.config", opts.profile), stringAttr("skills.output", opts.Output), stringAttr("skills.namespace", cfg.Namespace))
+		defer opts.tracer.Close()
+	}
+
+	if err := opts.useConversionConfig(cfg); err != nil {
+		fmt.Printf("Error processing conversion settings: %v\n", err)
+		return
+	}
+
+	checker, err := newPageChecker(cfg.Status)
+	if err != nil {
+		fmt.Printf("Error processing status policies: %v\n", err)
+		return
+	}
+	traps, err := newTrapDetector(cfg.Traps)
+	if err != nil {
+		fmt.Printf("Error processing trap settings: %v\n", err)
+		return
+	}
+	schedule, err := compileSchedule(cfg.Schedule)
+	if err != nil {
+		fmt.Printf("Error processing schedule: %v\n", err)
+		return
+	}
+	opts.changes = newChangeSet(cfg.ChangeSummary)
+
 	c := colly.NewCollector(
-		colly.Async(true),
+		colly.UserAgent(userAgent()),
 	)
 
+	store, err := openCrawlStore(&cfg)
+	if err != nil {
+		fmt.Printf("Error opening crawl state: %v\n", err)
+		return
+	}
+	visits, err := openVisitStore(&cfg)
+	if err != nil {
+		fmt.Printf("Error opening crawl storage: %v\n", err)
+		store.Close()
+		return
+	}
+	if visits != nil {
+		defer visits.Close()
+		err = c.SetStorage(visits)
+	} else {
+		err = c.SetStorage(store)
+	}
+	if err != nil {
+		fmt.Printf("Error initializing crawl state: %v\n", err)
+		store.Close()
+		return
+	}
+	frontier := &stoppableQueue{Storage: store, schedule: schedule}
+	q, err := queue.New(crawlParallelism, frontier)
+	if err != nil {
+		fmt.Printf("Error initializing crawl queue: %v\n", err)
+		store.Close()
+		return
+	}
+	retries, err := newFetchRetries(cfg.FetchErrors, cfg.attended, frontier.stop)
+	if err != nil {
+		fmt.Printf("Error processing fetch error settings: %v\n", err)
+		store.Close()
+		return
+	}
+	if size, _ := q.Size(); size > 0 {
+		fmt.Printf("Resuming crawl with %d queued URLs\n", size)
+	}
+
+	// enqueue adds link to the frontier unless it was enqueued before,
+	// and reports whether it was added. Links with a higher fetch
+	// priority are fetched first.
+	enqueue := func(link string) bool {
+		added, err := store.MarkQueued(link)
+		if err != nil {
+			fmt.Printf("Error recording %s: %v\n", link, err)
+			return false
+		}
+		if !added {
+			return false
+		}
+		if priority := fetchPriority(link, allowedGlobs); priority != 0 {
+			err = queueWithPriority(q, link, priority)
+		} else {
+			err = q.AddURL(link)
+		}
+		if err != nil {
+			fmt.Printf("Error queueing %s: %v\n", link, err)
+			return false
+		}
+		return true
+	}
+
+	// Only rules that ask for rendering start the browser.
+	var render func(string) bool
+	for _, g := range allowedGlobs {
+		if g.render {
+			render = func(link string) bool {
+				winner := matchRule(link, allowedGlobs, ignoredGlobs, cfg.Precedence)
+				return winner != nil && winner.render
+			}
+			break
+		}
+	}
+	fetcher, err := newFetcher(&cfg, render, opts.screenshots)
+	if err != nil {
+		fmt.Printf("Error configuring fetcher: %v\n", err)
+		store.Close()
+		return
+	}
+	if closer, ok := fetcher.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if cfg.WARCOut != "" {
+		w, err := newWARCWriter(cfg.WARCOut)
+		if err != nil {
+			fmt.Printf("Error creating WARC file: %v\n", err)
+			store.Close()
+			return
+		}
+		defer w.Close()
+		fetcher = &warcRecordingFetcher{next: fetcher, w: w}
+	}
+	pages := newLargePages(cfg.LargePages, opts.Output)
+	defer pages.cleanup()
+	fetcher = &spoolFetcher{next: fetcher, pages: pages}
+	c.WithTransport(fetcherTransport{fetcher: fetcher})
+
 	c.Limit(&colly.LimitRule{
 		DomainGlob:  "*",
-		Parallelism: 4,
+		Parallelism: crawlParallelism,
 	})
 
 	c.OnRequest(func(r *colly.Request) {
-		_, fullPath := getOutputPath(r.URL, outputDir, flatOutput, fileRename)
+		pacing.wait(r)
+		opts.tracer.fetch(r)
 
-		var mdPath string
-		if fileRename != "" {
-			mdPath = filepath.Join(filepath.Dir(fullPath), fileRename)
-		} else {
-			if strings.HasSuffix(fullPath, ".html") {
-				mdPath = strings.TrimSuffix(fullPath, ".html") + ".md"
-			} else {
-				mdPath = fullPath + ".md"
-			}
+		// Redirects replace r.URL, so keep the URL that was asked for.
+		r.Ctx.Put("requested_url", r.URL.String())
+		if cfg.RawMarkdown {
+			r.Headers.Set("Accept", markdownAccept)
 		}
 
-		if info, err := os.Stat(mdPath); err == nil && !info.IsDir() {
+		_, fullPath := opts.outputPath(r.URL)
+
+		mdPath := opts.markdownPath(fullPath)
+		if r.URL.Fragment != "" {
+			mdPath = opts.sectionPath(mdPath, r.URL.Fragment)
+		}
+
+		// Unchanged conversions update the manifest but not the file, so
+		// the manifest has the latest Last-Modified.
+		if entry, ok := manifest.lookup(r.URL.String()); ok && entry.LastModified != "" {
+			r.Headers.Set("If-Modified-Since", entry.LastModified)
+		} else if info, err := os.Stat(mdPath); err == nil && !info.IsDir() {
 			f, err := os.Open(mdPath)
 			if err == nil {
 				defer f.Close()
@@ -114,7 +375,7 @@ func runCrawl(cmd *cobra.Command) {
 					if strings.Contains(line, "last_modified:") {
 						parts := strings.SplitN(line, ":", 2)
 						if len(parts) == 2 {
-							dateStr := strings.TrimSpace(parts[1])
+							dateStr := strings.Trim(strings.TrimSpace(parts[1]), `"`)
 							if dateStr != "" {
 								r.Headers.Set("If-Modified-Since", dateStr)
 							}
@@ -126,61 +387,426 @@ func runCrawl(cmd *cobra.Command) {
 		}
 	})
 
-	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
-		link := e.Attr("href")
-		absLink := e.Request.AbsoluteURL(link)
+	// follow enqueues a link found on the page fetched by req.
+	follow := func(req *colly.Request, link string) {
+		absLink := req.AbsoluteURL(link)
 		if absLink == "" {
 			return
 		}
+		// Fragments only name a page's sections; a link keeps its fragment
+		// only when a rule asks for that section.
+		if !targetsSection(absLink, allowedGlobs) {
+			absLink = stripFragment(absLink)
+		}
+		from := req.URL.String()
+		if listed != nil {
+			opts.log.considered(absLink, from, nil, "url list")
+			return
+		}
+		if source := matchRule(from, allowedGlobs, ignoredGlobs, cfg.Precedence); source != nil && source.noFollow {
+			opts.log.considered(absLink, from, source, "source not followed")
+			return
+		}
 
-		if shouldVisit(absLink, allowedGlobs, ignoredGlobs) {
-			e.Request.Visit(absLink)
+		winner := matchRule(absLink, allowedGlobs, ignoredGlobs, cfg.Precedence)
+		trap := ""
+		if winner != nil && !winner.ignore {
+			trap = traps.check(absLink)
+		}
+		switch {
+		case winner == nil:
+			opts.log.considered(absLink, from, nil, "no rule")
+		case winner.ignore:
+			opts.log.considered(absLink, from, winner, "ignored")
+		case trap != "":
+			opts.log.considered(absLink, from, winner, "trap")
+			if u, err := url.Parse(absLink); err == nil {
+				traps.exclude(opts, u, u, trap)
+			}
+		case enqueue(absLink):
+			opts.log.considered(absLink, from, winner, "queued")
+		default:
+			opts.log.considered(absLink, from, winner, "duplicate")
 		}
+	}
+
+	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		follow(e.Request, e.Attr("href"))
 	})
 
 	c.OnResponse(func(r *colly.Response) {
+		opts.tracer.fetched(r, nil)
+		pacing.succeeded(r.Request.URL.Host)
 		if r.StatusCode == 304 {
 			fmt.Printf("Skipping %s (Not Modified)\n", r.Request.URL)
+			opts.Events.OnSkip(r.Request.URL.String(), "not modified")
 			return
 		}
 
-		fmt.Printf("Visited: %s\n", r.Request.URL)
+		requested := r.Request.URL
+		if u, err := url.Parse(r.Ctx.Get("requested_url")); err == nil && u.Host != "" {
+			requested = u
+		}
+
+		// Large pages were streamed to disk, so colly has no body to
+		// parse; parse the file once and follow its links here.
+		spool := r.Headers.Get(spoolHeader)
+		var doc *goquery.Document
+		if spool != "" {
+			defer os.Remove(spool)
+			release := pages.acquire()
+			defer release()
+			var err error
+			if doc, err = pages.parse(spool, r); err != nil {
+				failPage(opts, ErrorExtraction, r.Request.URL.String(), 0, fmt.Errorf("parsing HTML: %w", err))
+				return
+			}
+			doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+				follow(r.Request, s.AttrOr("href", ""))
+			})
+		}
+
+		if convert, ok := sourceConverter(r, opts); ok {
+			if doc, err := convert(r.Body, opts); err == nil {
+				for _, link := range markdownLinks(doc.Body) {
+					follow(r.Request, link)
+				}
+			}
+		}
+
+		kind, reason := "", ""
+		if doc != nil {
+			kind, reason = checker.classify(requested, r.Request.URL, doc)
+		} else {
+			kind, reason = checker.checkResponse(requested, r)
+		}
+		if kind != "" {
+			fmt.Printf("Excluding %s: %s\n", requested, reason)
+			excludePage(opts, kind, requested, r.Request.URL, reason)
+			return
+		}
+		// Links on noindex pages are still followed by the a[href] handler.
+		if !cfg.IgnoreNoindex && (isNoindex(r.Headers, r.Body) || doc != nil && metaNoindex(doc)) {
+			fmt.Printf("Excluding %s: noindex\n", requested)
+			excludePage(opts, pageNoindex, requested, r.Request.URL, "robots noindex")
+			return
+		}
+		if cfg.ExcludeNoAI {
+			var found []string
+			if doc != nil {
+				found = aiDirectives(r.Headers, doc)
+			} else {
+				found = restrictedDirectives(r.Headers, r.Body)
+			}
+			if len(found) > 0 {
+				fmt.Printf("Excluding %s: %s\n", requested, strings.Join(found, ", "))
+				excludePage(opts, pageRestricted, requested, r.Request.URL, "robots "+strings.Join(found, ", "))
+				return
+			}
+		}
+
+		if reason := traps.checkResponse(requested, r, doc, opts); reason != "" {
+			traps.exclude(opts, requested, r.Request.URL, reason)
+			return
+		}
 
-		if !shouldVisit(r.Request.URL.String(), allowedGlobs, ignoredGlobs) {
+		fmt.Printf("Visited: %s\n", r.Request.URL)
+		opts.Events.OnPageFetched(FetchedPage{
+			URL:          r.Request.URL.String(),
+			RequestedURL: requested.String(),
+			StatusCode:   r.StatusCode,
+			Headers:      *r.Headers,
+		})
+
+		winner := matchRule(r.Request.URL.String(), allowedGlobs, ignoredGlobs, cfg.Precedence)
+		if winner == nil && !listed[requested.String()] && !listed[r.Request.URL.String()] || winner != nil && winner.ignore {
 			fmt.Printf("Skipping (not allowed/ignored): %s\n", r.Request.URL)
+			reason := "no allow rule matches"
+			if winner != nil {
+				reason = "ignored by " + winner.pattern
+			}
+			entry := ReportEntry{URL: requested.String(), Kind: ErrorRuleExclusion, Reason: reason}
+			if r.Request.URL.String() != entry.URL {
+				entry.FinalURL = r.Request.URL.String()
+			}
+			opts.report.add("error", entry)
+			opts.Events.OnSkip(r.Request.URL.String(), "not allowed")
+			return
+		}
+		if winner != nil && winner.noConvert {
+			fmt.Printf("Not converting (links only): %s\n", r.Request.URL)
+			opts.Events.OnSkip(r.Request.URL.String(), "links only")
 			return
 		}
 
-		saveResponse(r, outputDir)
+		if doc != nil {
+			saveSpooledResponse(r, doc, spool, opts)
+			return
+		}
+		saveResponse(r, opts)
 	})
 
 	c.OnError(func(r *colly.Response, err error) {
-		fmt.Printf("Error visiting %s: %v\n", r.Request.URL, err)
+		opts.tracer.fetched(r, err)
+		if pacing.retry(r) || retries.retry(r, err) {
+			return
+		}
+		page := opts.tracer.pageSpan(r.Request)
+		defer page.end()
+		// colly reports non-2xx statuses, including 304, as errors.
+		if r.StatusCode == 304 {
+			fmt.Printf("Skipping %s (Not Modified)\n", r.Request.URL)
+			opts.Events.OnSkip(r.Request.URL.String(), "not modified")
+			return
+		}
+		if (r.StatusCode == http.StatusNotFound || r.StatusCode == http.StatusGone) && cfg.AsOf == "" && waybackFallback(cfg.Wayback, opts, r.Request.URL) {
+			return
+		}
+		if r.StatusCode != 0 {
+			err = fmt.Errorf("HTTP %d: %w", r.StatusCode, err)
+		}
+		page.fail(err)
+		failPage(opts, ErrorNetwork, r.Request.URL.String(), r.StatusCode, err)
 	})
 
-	for _, g := range allowedGlobs {
-		seed := getSeedURL(g.pattern)
-		if seed != "" {
-			fmt.Printf("Seeding: %s\n", seed)
-			c.Visit(seed)
+	c.OnScraped(func(r *colly.Response) {
+		opts.tracer.pageSpan(r.Request).end()
+	})
+
+	if resume != nil {
+		fmt.Printf("Resuming partial crawl with %d saved URLs\n", len(resume.Pending))
+		for _, link := range resume.Pending {
+			if enqueue(link) {
+				opts.log.considered(link, "", nil, "resumed")
+			}
+		}
+	} else if listed != nil {
+		for _, link := range urlList {
+			winner := matchRule(link, allowedGlobs, ignoredGlobs, cfg.Precedence)
+			if winner != nil && winner.ignore {
+				fmt.Printf("Skipping (ignored): %s\n", link)
+				opts.log.considered(link, "", winner, "ignored")
+				continue
+			}
+			if visits != nil {
+				if err := visits.forgetURL(c, link); err != nil {
+					fmt.Printf("Warning: could not revisit %s: %v\n", link, err)
+				}
+			}
+			if enqueue(link) {
+				opts.log.considered(link, "", winner, "listed")
+			}
+		}
+	} else {
+		for _, g := range allowedGlobs {
+			seed := getSeedURL(g.pattern)
+			if seed != "" {
+				fmt.Printf("Seeding: %s\n", seed)
+				if visits != nil {
+					if err := visits.forgetURL(c, seed); err != nil {
+						fmt.Printf("Warning: could not revisit %s: %v\n", seed, err)
+					}
+				}
+				if enqueue(seed) {
+					opts.log.considered(seed, "", &g, "seed")
+				}
+			}
+		}
+	}
+
+	if maxDuration > 0 {
+		timer := time.AfterFunc(maxDuration-stopMargin(maxDuration), func() {
+			fmt.Println("Time limit near, finishing in-flight requests")
+			frontier.stop()
+		})
+		defer timer.Stop()
+	}
+
+	if err := q.Run(c); err != nil {
+		fmt.Printf("Error running crawl: %v\n", err)
+		store.Close()
+		return
+	}
+
+	if size, _ := store.QueueSize(); frontier.stopped.Load() && size > 0 {
+		state := &resumeState{
+			Token:   newResumeToken(),
+			SavedAt: time.Now().UTC(),
+			Queue:   cfg.Queue,
+		}
+		if cfg.Queue == "" || cfg.Queue == "memory" {
+			state.Queue = "memory"
+			state.Pending, err = drainQueue(c, store)
+			if err != nil {
+				fmt.Printf("Warning: could not save pending URLs: %v\n", err)
+			}
+		}
+		store.Close()
+		if err := saveResumeState(opts.Output, state); err != nil {
+			fmt.Printf("Error saving resume state: %v\n", err)
+		}
+		manifest.Partial = true
+		manifest.ResumeToken = state.Token
+		fmt.Printf("Crawl stopped with %d URLs pending; continue with --resume %s\n", size, state.Token)
+	} else {
+		if err := store.Finish(); err != nil {
+			fmt.Printf("Warning: could not clear crawl state: %v\n", err)
+		}
+		clearResumeState(opts.Output)
+	}
+
+	if len(cfg.Sources) > 0 {
+		ingestSources(cfg.Sources, opts)
+	}
+
+	processSite(cfg, opts, manifest)
+	compressOutput(opts, manifest, cfg.Compression)
+	syncTombstoneFiles(opts, manifest, cfg.Tombstones)
+	if cfg.ChangeSummary.Enabled {
+		summaries := summarizeChanges(opts, cfg.ChangeSummary, cfg.LLM, opts.changes.list())
+		printChangeSummaries(summaries)
+		writeChangeSummaries(opts, manifest, cfg.ChangeSummary, summaries)
+	}
+
+	if err := manifest.save(opts.Output); err != nil {
+		fmt.Printf("Error writing manifest: %v\n", err)
+		return
+	}
+	if err := opts.report.save(opts.Output); err != nil {
+		fmt.Printf("Error writing crawl report: %v\n", err)
+	}
+	if cfg.ErrorsOut != "" {
+		if err := opts.report.saveErrors(cfg.ErrorsOut); err != nil {
+			fmt.Printf("Error writing errors file: %v\n", err)
+		}
+	}
+
+	if cfg.SignKey != "" {
+		if err := signManifest(opts.Output, cfg.SignKey); err != nil {
+			fmt.Printf("Error signing manifest: %v\n", err)
+		}
+	}
+	if cfg.VectorStore.Enabled {
+		if err := syncVectorStore(opts, manifest, cfg.VectorStore, false); err != nil {
+			fmt.Printf("Error syncing vector store: %v\n", err)
+		}
+	}
+	if cfg.Gemini.Enabled {
+		if err := syncGemini(opts, manifest, cfg.Gemini, false); err != nil {
+			fmt.Printf("Error syncing to Gemini: %v\n", err)
+		}
+	}
+}
+
+// useConversionConfig sets the conversion settings of cfg used while
+// pages are saved.
+func (o *CrawlOptions) useConversionConfig(cfg Config) error {
+	o.extraction = cfg.Extraction
+	o.notebooks = cfg.Notebooks
+	o.rawMarkdown = cfg.RawMarkdown
+	o.mdx = cfg.MDX
+	o.transcripts = nil
+	if cfg.Transcripts.Enabled {
+		o.transcripts = newTranscriptClient(cfg.Transcripts)
+	}
+	if err := checkProfile(cfg.Extraction.Profile); err != nil {
+		return err
+	}
+	if err := checkFrontmatterMap(cfg.Extraction.FrontmatterMap); err != nil {
+		return err
+	}
+	if err := checkCompression(cfg.Compression); err != nil {
+		return err
+	}
+	if err := checkConverter(cfg.Extraction.Converter); err != nil {
+		return err
+	}
+	for _, s := range cfg.Scopes {
+		if err := checkConverter(mergeConverter(cfg.Extraction.Converter, s.Converter)); err != nil {
+			return err
 		}
 	}
+	var err error
+	if o.scopes, err = compileScopes(cfg.Scopes); err != nil {
+		return err
+	}
+	o.categories, err = compileCategories(cfg.Categories)
+	return err
+}
 
-	c.Wait()
+// processSite runs the steps that look at every page of the output once
+// the pages are saved, such as boilerplate removal and keywords.
+func processSite(cfg Config, opts *CrawlOptions, m *Manifest) {
+	if cfg.Boilerplate.Enabled {
+		stripBoilerplate(opts, m, cfg.Boilerplate)
+	}
+	if cfg.Disambiguate {
+		disambiguateTitles(opts, m)
+	}
+	if cfg.NavOrder.Enabled {
+		recordNavOrder(opts, m, cfg.NavOrder)
+	}
+	if cfg.Keywords.Enabled {
+		addKeywords(opts, m, cfg.Keywords, cfg.LLM)
+	}
+	if cfg.Glossary.Enabled {
+		writeGlossaries(opts, m, cfg.Glossary)
+	}
+	if cfg.FAQ.Enabled {
+		writeFAQs(opts, m)
+	}
+	if cfg.Snippets.Enabled {
+		writeSnippets(opts, m, cfg.Snippets)
+	}
+	if cfg.Commands.Enabled {
+		writeCommandCatalogs(opts, m, cfg.Commands)
+	}
+	if cfg.SkillEntry.Enabled {
+		keywordField := ""
+		if cfg.Keywords.Enabled {
+			keywordField = cfg.Keywords.Field
+			if keywordField == "" {
+				keywordField = "keywords"
+			}
+		}
+		writeSkillEntries(opts, m, cfg.SkillEntry, keywordField, cfg.LLM)
+	}
+	if cfg.Quality.Enabled {
+		writeQualityReview(opts, m, cfg.Quality)
+	}
+	refreshCitations(opts, m)
 }
 
-// globRule represents a compiled glob pattern.
+// globRule represents a compiled glob pattern. source names where it was
+// defined, such as "rules.yaml:3". noConvert, noFollow, render, and
+// fetchPriority are the rule's convert, follow, render, and
+// fetch_priority options; see fetchPriority.
 type globRule struct {
-	pattern string
-	g       glob.Glob
+	pattern       string
+	source        string
+	ignore        bool
+	priority      int
+	order         int
+	fetchPriority int
+	noConvert     bool
+	noFollow      bool
+	render        bool
+	g             glob.Glob
 }
 
-// loadRules merges rules from the external file and the config struct.
+// loadRules merges rules from the pattern files, in order, and the config
+// struct, and checks the precedence used to choose between them.
 func loadRules(cfg *Config) ([]globRule, []globRule, error) {
 	var allowed []globRule
 	var ignored []globRule
 
-	processPattern := func(pattern string) {
+	switch cfg.Precedence {
+	case "", "ignore", "specific", "order":
+	default:
+		return nil, nil, fmt.Errorf("unknown rule_precedence %q", cfg.Precedence)
+	}
+
+	processPattern := func(pattern, source string, opts *RuleConfig) {
 		pattern = strings.TrimSpace(pattern)
 		if pattern == "" || strings.HasPrefix(pattern, "#") {
 			return
@@ -198,7 +824,14 @@ func loadRules(cfg *Config) ([]globRule, []globRule, error) {
 			return
 		}
 
-		rule := globRule{pattern: pattern, g: g}
+		rule := globRule{pattern: pattern, source: source, ignore: isIgnore, order: len(allowed) + len(ignored), g: g}
+		if opts != nil {
+			rule.priority = opts.Priority
+			rule.fetchPriority = opts.FetchPriority
+			rule.noConvert = opts.Convert != nil && !*opts.Convert
+			rule.noFollow = opts.Follow != nil && !*opts.Follow
+			rule.render = opts.Render
+		}
 		if isIgnore {
 			ignored = append(ignored, rule)
 		} else {
@@ -206,60 +839,29 @@ func loadRules(cfg *Config) ([]globRule, []globRule, error) {
 		}
 	}
 
-	if cfg.ConfigFile != "" {
-		f, err := os.Open(cfg.ConfigFile)
-		if err == nil {
-			defer f.Close()
-			scanner := bufio.NewScanner(f)
-			for scanner.Scan() {
-				processPattern(scanner.Text())
-			}
-			if err := scanner.Err(); err != nil {
-				fmt.Printf("Warning reading config file: %v\n", err)
-			}
-		} else if !os.IsNotExist(err) {
-			fmt.Printf("Warning opening config file: %v\n", err)
+	for _, path := range cfg.ConfigFiles {
+		patterns, err := readPatternFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range patterns {
+			processPattern(p.pattern, p.source, p.rule)
 		}
 	}
 
-	for _, p := range cfg.Patterns {
-		processPattern(p)
+	config := filepath.Base(viper.ConfigFileUsed())
+	if viper.ConfigFileUsed() == "" {
+		config = "config"
 	}
-
-	for _, r := range cfg.Rules {
-		pat := r.URL
-		if r.Subpaths {
-			if !strings.HasSuffix(pat, "*") {
-				if !strings.HasSuffix(pat, "/") {
-					pat += "/"
-				}
-				pat += "*"
-			}
-		}
-
-		if r.Action == "ignore" {
-			pat = "!" + pat
-		}
-		processPattern(pat)
+	for i, p := range cfg.Patterns {
+		processPattern(p, fmt.Sprintf("%s patterns[%d]", config, i), nil)
 	}
 
-	return allowed, ignored, nil
-}
-
-// shouldVisit checks if a link should be visited based on allowed and ignored rules.
-func shouldVisit(link string, allowed, ignored []globRule) bool {
-	for _, rule := range ignored {
-		if rule.g.Match(link) {
-			return false
-		}
+	for i := range cfg.Rules {
+		processPattern(rulePattern(cfg.Rules[i]), fmt.Sprintf("%s rules[%d]", config, i), &cfg.Rules[i])
 	}
 
-	for _, rule := range allowed {
-		if rule.g.Match(link) {
-			return true
-		}
-	}
-	return false
+	return allowed, ignored, nil
 }
 
 // getOutputPath determines the directory and file path for the URL
@@ -295,7 +897,7 @@ func getOutputPath(u *url.URL, outDir string, flat bool, rename string) (string,
 		segment = strings.ReplaceAll(segment, "/", "_")
 
 		// Clean domian: replace dots with _
-		cleanDomain := strings.ReplaceAll(u.Hostname(), ".", "_")
+		cleanDomain := strings.ReplaceAll(outputHost(u), ".", "_")
 
 		// Construct directory name: domain_path
 		var dirName string
@@ -309,88 +911,338 @@ func getOutputPath(u *url.URL, outDir string, flat bool, rename string) (string,
 		fullPath = filepath.Join(outDir, dirName, "index.html")
 	} else {
 		// Hierarchical structure: .skillscache/<hostname>/<path>
-		fullPath = filepath.Join(outDir, u.Hostname(), path)
+		fullPath = filepath.Join(outDir, outputHost(u), path)
 	}
 
 	dir := filepath.Dir(fullPath)
 	return dir, fullPath
 }
 
+// outputHost returns the host segment used in output paths.
+// file:// URLs have no host, so they are grouped under "local".
+func outputHost(u *url.URL) string {
+	if u.Hostname() == "" && u.Scheme == "file" {
+		return "local"
+	}
+	return u.Hostname()
+}
+
 // saveResponse saves the response body to a file and converts it to markdown.
-func saveResponse(r *colly.Response, outDir string) {
+func saveResponse(r *colly.Response, opts *CrawlOptions) {
 	contentType := r.Headers.Get("Content-Type")
-	if !strings.Contains(strings.ToLower(contentType), "text/html") {
+	if convert, ok := sourceConverter(r, opts); ok {
+		saveSourceResponse(r, opts, convert)
 		return
 	}
+	notebookPage := isNotebook(r.Request.URL, contentType)
+	if !notebookPage && !strings.Contains(strings.ToLower(contentType), "text/html") {
+		return
+	}
+
+	var doc *goquery.Document
+	if !notebookPage {
+		var err error
+		doc, err = goquery.NewDocumentFromReader(bytes.NewReader(r.Body))
+		if err != nil {
+			failPage(opts, ErrorExtraction, r.Request.URL.String(), 0, fmt.Errorf("parsing HTML: %w", err))
+			return
+		}
+		r = canonicalResponse(r, doc, opts)
+	}
 
-	dirName, fullPath := getOutputPath(r.Request.URL, outDir, flatOutput, fileRename)
+	dirName, fullPath := opts.outputPath(r.Request.URL)
+	ext := opts.extractionFor(r.Request.URL.String())
 
 	if err := os.MkdirAll(dirName, 0755); err != nil {
-		fmt.Printf("Error creating dir %s: %v\n", dirName, err)
+		failPage(opts, ErrorWrite, r.Request.URL.String(), 0, fmt.Errorf("creating dir %s: %w", dirName, err))
 		return
 	}
 
-	if err := os.WriteFile(fullPath, r.Body, 0644); err != nil {
-		fmt.Printf("Error writing html file %s: %v\n", fullPath, err)
+	// Pages at .md paths are written over their own path, so their HTML
+	// is not kept.
+	if fullPath != opts.markdownPath(fullPath) {
+		if err := writeFileIfChanged(fullPath, r.Body); err != nil {
+			failPage(opts, ErrorWrite, r.Request.URL.String(), 0, fmt.Errorf("writing html file %s: %w", fullPath, err))
+		}
 	}
 
-	title, description, err := extractMetadata(r.Body)
-	if err != nil {
-		fmt.Printf("Error extracting metadata for %s: %v\n", fullPath, err)
+	if notebookPage {
+		convert := opts.tracer.stageSpan(r.Request, "convert", stringAttr("skills.format", "notebook"))
+		saveNotebook(r, opts, dirName, fullPath, ext)
+		convert.end()
+		return
 	}
+	convertDocument(r, doc, opts, dirName, fullPath, ext)
+}
+
+// convertDocument converts a parsed page saved at fullPath to markdown
+// and writes it. The document is parsed once for metadata, API reference
+// mode, and content extraction, which removes stripped elements from it.
+func convertDocument(r *colly.Response, doc *goquery.Document, opts *CrawlOptions, dirName, fullPath string, ext ExtractionConfig) {
+	extract := opts.tracer.stageSpan(r.Request, "extract")
+	defer extract.end()
+	mdPath := opts.markdownPath(fullPath)
+	restricted := len(aiDirectives(r.Headers, doc)) > 0
+	fields := mappedFrontmatter(doc, ext)
+	if fragment := r.Request.URL.Fragment; fragment != "" {
+		section, ok := sectionDocument(doc, fragment)
+		if !ok {
+			fmt.Printf("Warning: no section #%s on %s\n", fragment, r.Request.URL)
+			opts.Events.OnSkip(r.Request.URL.String(), "section not found")
+			return
+		}
+		doc = section
+		ext.ContentSelector = ""
+		mdPath = opts.sectionPath(mdPath, fragment)
+	}
+
+	title, description := documentMetadata(doc)
 	if title == "" {
 		title = "Untitled"
 	}
+	anchors := headingIDs(doc)
 	if description == "" {
-		description = "No description available."
+		description = noDescription
 	}
 
-	cleanHTML, err := extractContent(r.Body)
-	if err != nil {
-		fmt.Printf("Error extracting content for %s: %v\n", fullPath, err)
-		return
+	converter := newMarkdownConverter(ext.Converter, opts.transcripts)
+
+	var markdownBody string
+	var warnings []string
+	if ext.Mode == "api" {
+		// API reference mode extracts and converts in one pass.
+		extract.end()
+		convert := opts.tracer.stageSpan(r.Request, "convert", stringAttr("skills.mode", "api"))
+		markdownBody = extractAPIReference(doc, converter)
+		convert.end()
+	}
+	if markdownBody == "" {
+		cleanHTML, err := documentContent(doc, ext)
+		if err != nil {
+			failPage(opts, ErrorExtraction, r.Request.URL.String(), 0, fmt.Errorf("extracting content: %w", err))
+			extract.fail(err)
+			return
+		}
+		warnings = conversionWarnings(cleanHTML, ext, opts.transcripts)
+		var excerpts []string
+		if ext.HTMLFallback {
+			if cleanHTML, excerpts, err = markLowConfidence(cleanHTML); err != nil {
+				failPage(opts, ErrorConversion, r.Request.URL.String(), 0, fmt.Errorf("checking conversion: %w", err))
+				extract.fail(err)
+				return
+			}
+		}
+		extract.end()
+		convert := opts.tracer.stageSpan(r.Request, "convert")
+		markdownBody, err = converter.ConvertString(cleanHTML)
+		if err != nil {
+			failPage(opts, ErrorConversion, r.Request.URL.String(), 0, fmt.Errorf("converting to markdown: %w", err))
+			convert.fail(err)
+			convert.end()
+			return
+		}
+		markdownBody = insertHTMLFallbacks(markdownBody, excerpts)
+		convert.end()
+	}
+	if limit := r.Headers.Get(truncatedHeader); limit != "" {
+		warnings = append(warnings, fmt.Sprintf("%s: only the first %s MB were converted", warningPage, limit))
 	}
 
-	converter := md.NewConverter("", true, nil)
-	markdownBody, err := converter.ConvertString(cleanHTML)
-	if err != nil {
-		fmt.Printf("Error converting to markdown for %s: %v\n", fullPath, err)
-		return
+	write := opts.tracer.stageSpan(r.Request, "write")
+	defer write.end()
+	writePage(convertedPage{
+		URL:          r.Request.URL.String(),
+		Title:        title,
+		Description:  description,
+		Body:         markdownBody,
+		LastModified: responseLastModified(r),
+		Archived:     r.Headers.Get(archivedHeader),
+		CrawledAt:    responseCrawledAt(r),
+		Language:     documentLanguage(doc),
+		Extraction:   ext,
+		Restricted:   restricted,
+		Anchors:      anchors,
+		Fields:       fields,
+		Warnings:     warnings,
+	}, opts, dirName, mdPath)
+}
+
+// responseLastModified returns the Last-Modified header, falling back to
+// the response date.
+func responseLastModified(r *colly.Response) string {
+	if lastModified := r.Headers.Get("Last-Modified"); lastModified != "" {
+		return lastModified
 	}
+	return r.Headers.Get("Date")
+}
+
+// convertedPage is a page converted to markdown, ready to be written.
+// CrawledAt is zero for pages fetched now; replayed pages keep the time
+// their response was crawled.
+type convertedPage struct {
+	URL          string
+	Title        string
+	Description  string
+	Body         string
+	LastModified string
+	Archived     string
+	Language     string
+	CrawledAt    time.Time
+	Extraction   ExtractionConfig
+	// Restricted is set for pages marked noai or noimageai.
+	Restricted bool
+	// Anchors are the ids of the page's headings on the source.
+	Anchors []headingID
+	// Fields are the frontmatter fields read from the page by
+	// frontmatter_map.
+	Fields frontmatter
+	// Warnings describe what the conversion lost.
+	Warnings []string
+}
 
+// writePage writes a converted page with its frontmatter to mdPath and
+// records it in the manifest. dirName names the page in flat output.
+func writePage(p convertedPage, opts *CrawlOptions, dirName, mdPath string) {
 	var name string
-	if flatOutput {
+	if opts.Flat {
 		name = filepath.Base(dirName)
 	} else {
-		name = toPathCase(title)
+		name = toPathCase(p.Title)
 	}
 	name = sanitizeName(name)
 
-	metaUrl := r.Request.URL.String()
-	lastModified := r.Headers.Get("Last-Modified")
-	if lastModified == "" {
-		lastModified = r.Headers.Get("Date")
+	description := sanitizeDescription(p.Description)
+	p.Body = removeStopSections(p.Body, p.Extraction, p.Language)
+	if reason := thinReason(p, opts); reason != "" {
+		excludeThinPage(opts, p.URL, reason)
+		return
 	}
+	var ok bool
+	if p.Body, ok = applyProfile(p); !ok {
+		excludeThinPage(opts, p.URL, "no code blocks or tables for the snippets profile")
+		return
+	}
+	categories := categorize(p, opts.categories)
+	mdPath = categoryPath(opts, mdPath, categories)
+	opts.report.warn(p.URL, p.Warnings)
 
-	description = sanitizeDescription(description)
+	crawledAt := p.CrawledAt
+	if crawledAt.IsZero() {
+		crawledAt = time.Now().UTC().Truncate(time.Second)
+	}
+	provenance := Provenance{
+		Tool:        toolName,
+		Version:     buildInfo().Version,
+		CrawledAt:   crawledAt,
+		SourceURL:   p.URL,
+		ContentHash: contentHash(p.Body),
+		Config:      opts.profile,
+	}
 
-	frontmatter := fmt.Sprintf("---\nname: %s\ndescription: %s\nmetadata:\n  url: %s\n  last_modified: %s\n---\n\n# %s\n\n", name, description, metaUrl, lastModified, title)
+	shot := saveScreenshot(opts.screenshots, p.URL, mdPath, p.Title, opts.Rename != "")
+	render := func(provenance Provenance) string {
+		fm := frontmatter{
+			{Key: "name", Value: name},
+			{Key: "description", Value: description},
+			{Key: "categories", Value: categories},
+		}
+		if p.Restricted {
+			fm = append(fm, fmField{Key: "restricted", Value: true})
+		}
+		fm = append(fm, pageFrontmatter(p.Extraction, p.Fields)...)
+		if len(p.Warnings) > 0 {
+			fm = append(fm, fmField{Key: "warnings", Value: p.Warnings})
+		}
+		metadata := frontmatter{
+			{Key: "url", Value: p.URL},
+			{Key: "last_modified", Value: p.LastModified},
+		}
+		if p.Archived != "" {
+			metadata = append(metadata, fmField{Key: "archived_url", Value: p.Archived})
+		}
+		metadata = append(metadata, fmField{Key: "provenance", Value: provenanceFields(provenance)})
+		fm = append(fm, fmField{Key: "metadata", Value: metadata})
+		return fm.render() + fmt.Sprintf("\n# %s\n\n", p.Title) + shot + p.Body
+	}
 
-	finalMarkdown := frontmatter + markdownBody
+	relPath, err := filepath.Rel(opts.Output, mdPath)
+	if err != nil {
+		relPath = mdPath
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	// A page that converts to the same output as last time, apart from
+	// when it was crawled and last modified, keeps its file, so the file's
+	// modification time only changes when its content does.
+	timeless := provenance
+	timeless.CrawledAt = time.Time{}
+	lastModified := p.LastModified
+	p.LastModified = ""
+	outputHash := contentHash(render(timeless))
+	p.LastModified = lastModified
+	if old, ok := opts.manifest.lookup(p.URL); ok && old.OutputHash == outputHash && old.Path == relPath {
+		if _, err := statOutputFile(mdPath); err == nil {
+			fmt.Printf("Unchanged: %s\n", p.URL)
+			unchanged := *old
+			unchanged.LastModified = p.LastModified
+			opts.manifest.record(&unchanged)
+			opts.Events.OnSkip(p.URL, "unchanged")
+			return
+		}
+	}
 
-	var mdPath string
-	if fileRename != "" {
-		mdPath = filepath.Join(filepath.Dir(fullPath), fileRename)
-	} else {
-		if strings.HasSuffix(fullPath, ".html") {
-			mdPath = strings.TrimSuffix(fullPath, ".html") + ".md"
+	finalMarkdown := render(provenance)
+	if opts.changes != nil {
+		previous := mdPath
+		if old, ok := opts.manifest.lookup(p.URL); ok {
+			previous = opts.pageFile(old)
+		}
+		if before, err := readOutputFile(previous); err == nil {
+			opts.changes.record(pageUpdated, p.URL, p.Title, relPath, string(before), finalMarkdown)
 		} else {
-			mdPath = fullPath + ".md"
+			opts.changes.record(pageAdded, p.URL, p.Title, relPath, "", finalMarkdown)
 		}
 	}
-
+	if err := os.MkdirAll(filepath.Dir(mdPath), 0755); err != nil {
+		failPage(opts, ErrorWrite, p.URL, 0, fmt.Errorf("creating dir %s: %w", filepath.Dir(mdPath), err))
+		return
+	}
 	if err := os.WriteFile(mdPath, []byte(finalMarkdown), 0644); err != nil {
-		fmt.Printf("Error writing markdown file %s: %v\n", mdPath, err)
+		failPage(opts, ErrorWrite, p.URL, 0, fmt.Errorf("writing markdown file %s: %w", mdPath, err))
+		return
+	}
+
+	// A page whose category moved it leaves nothing at its old path.
+	if old, ok := opts.manifest.lookup(p.URL); ok && old.Path != relPath && old.InlinedIn == "" {
+		removeOutputFile(filepath.Join(opts.Output, filepath.FromSlash(old.Path)))
+	}
+
+	entry := &ManifestEntry{
+		URL:          p.URL,
+		Path:         relPath,
+		Name:         name,
+		Title:        p.Title,
+		LastModified: p.LastModified,
+		Categories:   categories,
+		Restricted:   p.Restricted,
+		FileHash:     contentHash(finalMarkdown),
+		OutputHash:   outputHash,
+		Citations:    pageCitations(finalMarkdown, p.URL, p.Anchors),
+		Provenance:   provenance,
+	}
+	opts.manifest.record(entry)
+	opts.Events.OnPageConverted(*entry)
+}
+
+// provenanceFields returns the provenance block for the frontmatter.
+func provenanceFields(p Provenance) frontmatter {
+	return frontmatter{
+		{Key: "tool", Value: p.Tool},
+		{Key: "version", Value: p.Version},
+		{Key: "crawled_at", Value: p.CrawledAt.Format(time.RFC3339)},
+		{Key: "source_url", Value: p.SourceURL},
+		{Key: "content_hash", Value: p.ContentHash},
+		{Key: "config", Value: p.Config},
 	}
 }
 
@@ -400,7 +1252,12 @@ func extractMetadata(body []byte) (string, string, error) {
 	if err != nil {
 		return "", "", err
 	}
+	title, description := documentMetadata(doc)
+	return title, description, nil
+}
 
+// documentMetadata returns the title and description of a parsed page.
+func documentMetadata(doc *goquery.Document) (string, string) {
 	title := doc.Find("meta[property='og:title']").AttrOr("content", "")
 	if title == "" {
 		title = doc.Find("title").Text()
@@ -411,26 +1268,40 @@ func extractMetadata(body []byte) (string, string, error) {
 		description = doc.Find("meta[name='description']").AttrOr("content", "")
 	}
 
-	return strings.TrimSpace(title), strings.TrimSpace(description), nil
+	return strings.TrimSpace(title), strings.TrimSpace(description)
 }
 
 // extractContent extracts the main content from the HTML body.
-func extractContent(body []byte) (string, error) {
+func extractContent(body []byte, ext ExtractionConfig) (string, error) {
 	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		return "", err
 	}
+	return documentContent(doc, ext)
+}
 
+// documentContent extracts the main content from a parsed page. It removes
+// the stripped elements from doc.
+func documentContent(doc *goquery.Document, ext ExtractionConfig) (string, error) {
 	selection := doc.Find("body")
 
-	article := doc.Find("article")
-	if article.Length() > 0 {
-		selection = article
+	contentSelector := ext.ContentSelector
+	if contentSelector == "" {
+		contentSelector = "article"
+	}
+	content := doc.Find(contentSelector)
+	if content.Length() > 0 {
+		selection = content
 	}
 
-	selection.Find("header#site-content-title").Remove()
-	selection.Find(".toc").Remove()
+	for _, sel := range append(defaultStripSelectors, ext.StripSelectors...) {
+		selection.Find(sel).Remove()
+	}
 
+	if ext.ContentSelector != "" {
+		// Keep the selected element itself (e.g. a <table>) in the output
+		return goquery.OuterHtml(selection.First())
+	}
 	return selection.Html()
 }
 
@@ -470,7 +1341,7 @@ func sanitizeName(s string) string {
 func sanitizeDescription(s string) string {
 	s = strings.TrimSpace(s)
 	if s == "" {
-		return "No description available."
+		return noDescription
 	}
 	if len(s) > 1024 {
 		s = s[:1024] + "..."
@@ -482,7 +1353,7 @@ func sanitizeDescription(s string) string {
 func getSeedURL(pattern string) string {
 	idx := strings.Index(pattern, "*")
 	if idx != -1 {
-		return pattern[:idx]
+		return strings.TrimSuffix(pattern[:idx], "#")
 	}
 	return pattern
 }
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrorKind classifies why a page, or a source, is missing from the
+// output, so automation can branch on it.
+type ErrorKind string
+
+// Error kinds recorded in the crawl report.
+const (
+	// ErrorNetwork is a failed fetch: a connection error or an HTTP error
+	// status.
+	ErrorNetwork ErrorKind = "network"
+	// ErrorRuleExclusion is a fetched page, such as a redirect target, that
+	// no allow rule matches or an ignore rule matches.
+	ErrorRuleExclusion ErrorKind = "rule_exclusion"
+	// ErrorExtraction is a page whose HTML could not be parsed or whose
+	// content could not be extracted.
+	ErrorExtraction ErrorKind = "extraction"
+	// ErrorConversion is a page whose content could not be converted to
+	// markdown.
+	ErrorConversion ErrorKind = "conversion"
+	// ErrorWrite is a page whose files could not be written.
+	ErrorWrite ErrorKind = "write"
+	// ErrorSource is a source under sources that could not be read.
+	ErrorSource ErrorKind = "source"
+)
+
+// PageError is the error passed to CrawlEvents.OnError. Status is the
+// HTTP status of network errors, when there was a response.
+type PageError struct {
+	Kind   ErrorKind
+	URL    string
+	Status int
+	Err    error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("%s error: %v", e.Kind, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// errorKind returns the kind of err, or "" when it is not a PageError.
+func errorKind(err error) ErrorKind {
+	var pe *PageError
+	if errors.As(err, &pe) {
+		return pe.Kind
+	}
+	return ""
+}
+
+// failPage reports a page (or source) that failed: it prints the error,
+// adds it to the crawl report, and passes it to the crawl events.
+func failPage(opts *CrawlOptions, kind ErrorKind, link string, status int, err error) {
+	fmt.Printf("Error (%s) %s: %v\n", kind, link, err)
+	opts.report.add("error", ReportEntry{URL: link, Kind: kind, Status: status, Reason: err.Error()})
+	opts.Events.OnError(link, &PageError{Kind: kind, URL: link, Status: status, Err: err})
+}
+
+// errorsFile is the file written by --errors-out.
+type errorsFile struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Counts      map[ErrorKind]int `json:"counts"`
+	Errors      []ReportEntry     `json:"errors"`
+}
+
+// saveErrors writes the errors of the crawl, with a count of each kind,
+// to path.
+func (r *CrawlReport) saveErrors(path string) error {
+	r.mu.Lock()
+	out := errorsFile{GeneratedAt: time.Now().UTC(), Counts: map[ErrorKind]int{}, Errors: append([]ReportEntry{}, r.Errors...)}
+	r.mu.Unlock()
+	for _, entry := range out.Errors {
+		out.Counts[entry.Kind]++
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
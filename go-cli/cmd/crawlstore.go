@@ -0,0 +1,245 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gocolly/colly/v2/queue"
+	"github.com/gocolly/colly/v2/storage"
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultStateFile is the disk store location inside the output directory.
+const defaultStateFile = ".crawl-state.db"
+
+// crawlStore holds the crawl frontier (pending requests), the set of URLs
+// already enqueued, and colly's visited set and cookies.
+type crawlStore interface {
+	storage.Storage
+	queue.Storage
+
+	// MarkQueued records link as enqueued. It returns false if link was
+	// enqueued before, so each URL enters the frontier once.
+	MarkQueued(link string) (bool, error)
+
+	// Finish is called after a crawl completes; stores that persist state
+	// for resuming discard it here.
+	Finish() error
+
+	// Close releases the store without discarding state.
+	Close() error
+}
+
+// openCrawlStore opens the store selected by the config.
+func openCrawlStore(cfg *Config) (crawlStore, error) {
+	switch cfg.Queue {
+	case "", "memory":
+		return newMemoryStore(), nil
+	case "disk":
+		return openBoltStore(cfg.statePath(cfg.StateFile, defaultStateFile))
+	case "redis":
+		return openRedisStore(cfg.redisConfig())
+	default:
+		return nil, fmt.Errorf("unknown queue %q", cfg.Queue)
+	}
+}
+
+// memoryStore keeps everything in memory, as colly does by default.
+type memoryStore struct {
+	*storage.InMemoryStorage
+	requests *priorityQueue
+
+	mu     sync.Mutex
+	queued map[string]bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		InMemoryStorage: &storage.InMemoryStorage{},
+		requests:        newPriorityQueue(),
+		queued:          map[string]bool{},
+	}
+}
+
+func (s *memoryStore) Init() error {
+	return s.InMemoryStorage.Init()
+}
+
+func (s *memoryStore) AddRequest(r []byte) error   { s.requests.add(r); return nil }
+func (s *memoryStore) GetRequest() ([]byte, error) { return s.requests.next(), nil }
+func (s *memoryStore) QueueSize() (int, error)     { return s.requests.len(), nil }
+func (s *memoryStore) Finish() error               { return nil }
+func (s *memoryStore) Close() error                { return s.InMemoryStorage.Close() }
+
+func (s *memoryStore) MarkQueued(link string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queued[link] {
+		return false, nil
+	}
+	s.queued[link] = true
+	return true, nil
+}
+
+var (
+	boltVisited  = []byte("visited")
+	boltCookies  = []byte("cookies")
+	boltRequests = []byte("requests")
+	boltQueued   = []byte("queued")
+)
+
+// boltStore keeps the crawl state in a bbolt database so memory stays
+// flat regardless of frontier size and an interrupted crawl resumes
+// where it stopped.
+type boltStore struct {
+	path string
+	db   *bolt.DB
+
+	mu   sync.Mutex
+	size int
+}
+
+func openBoltStore(path string) (*boltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0644, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &boltStore{path: path, db: db}, nil
+}
+
+func (s *boltStore) Init() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltVisited, boltCookies, boltRequests, boltQueued} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		s.mu.Lock()
+		s.size = tx.Bucket(boltRequests).Stats().KeyN
+		s.mu.Unlock()
+		return nil
+	})
+}
+
+func u64Key(n uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	return b[:]
+}
+
+func (s *boltStore) Visited(requestID uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltVisited).Put(u64Key(requestID), []byte{1})
+	})
+}
+
+func (s *boltStore) IsVisited(requestID uint64) (bool, error) {
+	var visited bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		visited = tx.Bucket(boltVisited).Get(u64Key(requestID)) != nil
+		return nil
+	})
+	return visited, err
+}
+
+func (s *boltStore) Cookies(u *url.URL) string {
+	var cookies string
+	s.db.View(func(tx *bolt.Tx) error {
+		cookies = string(tx.Bucket(boltCookies).Get([]byte(u.Host)))
+		return nil
+	})
+	return cookies
+}
+
+func (s *boltStore) SetCookies(u *url.URL, cookies string) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCookies).Put([]byte(u.Host), []byte(cookies))
+	})
+}
+
+func (s *boltStore) AddRequest(r []byte) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltRequests)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(priorityKey(requestPriority(r), seq), r)
+	})
+	if err == nil {
+		s.mu.Lock()
+		s.size++
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *boltStore) GetRequest() ([]byte, error) {
+	var r []byte
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltRequests).Cursor()
+		k, v := c.First()
+		if k == nil {
+			return nil
+		}
+		r = append([]byte(nil), v...)
+		return c.Delete()
+	})
+	if err == nil && r != nil {
+		s.mu.Lock()
+		s.size--
+		s.mu.Unlock()
+	}
+	return r, err
+}
+
+func (s *boltStore) QueueSize() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size, nil
+}
+
+func (s *boltStore) MarkQueued(link string) (bool, error) {
+	added := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltQueued)
+		if b.Get([]byte(link)) != nil {
+			return nil
+		}
+		added = true
+		return b.Put([]byte(link), []byte{1})
+	})
+	return added, err
+}
+
+func (s *boltStore) Finish() error {
+	if err := s.db.Close(); err != nil {
+		return err
+	}
+	return os.Remove(s.path)
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
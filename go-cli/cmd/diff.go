@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// maxDiffCells bounds the table diffLines builds. Files whose changed
+// region is larger are shown as the old lines removed and the new added.
+const maxDiffCells = 4 << 20
+
+// diffOp is one line of a diff: kept (' '), removed ('-'), or added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines returns the edits that turn a into b, from their longest
+// common subsequence.
+func diffLines(a, b []string) []diffOp {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:pre] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	n, m := len(ma), len(mb)
+	i, j := 0, 0
+	if (n+1)*(m+1) <= maxDiffCells {
+		// lcs[i*(m+1)+j] is the common subsequence length of ma[i:] and mb[j:].
+		lcs := make([]int32, (n+1)*(m+1))
+		at := func(i, j int) int32 { return lcs[i*(m+1)+j] }
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i*(m+1)+j] = at(i+1, j+1) + 1
+				} else {
+					lcs[i*(m+1)+j] = max(at(i+1, j), at(i, j+1))
+				}
+			}
+		}
+		for i < n && j < m {
+			switch {
+			case ma[i] == mb[j]:
+				ops = append(ops, diffOp{' ', ma[i]})
+				i++
+				j++
+			case at(i+1, j) >= at(i, j+1):
+				ops = append(ops, diffOp{'-', ma[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', mb[j]})
+				j++
+			}
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', ma[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', mb[j]})
+	}
+	for _, line := range a[len(a)-suf:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// noNewline marks a last line that has no newline, so it differs from
+// the same line followed by one.
+const noNewline = "\x00"
+
+// splitLines splits text into lines without their newlines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += noNewline
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// unifiedDiff returns a unified diff from a (named aName) to b (named
+// bName), or "" when they are equal.
+func unifiedDiff(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	// aLines[k] and bLines[k] count the lines of a and b before ops[k].
+	aLines := make([]int, len(ops)+1)
+	bLines := make([]int, len(ops)+1)
+	for k, op := range ops {
+		aLines[k+1], bLines[k+1] = aLines[k], bLines[k]
+		if op.kind != '+' {
+			aLines[k+1]++
+		}
+		if op.kind != '-' {
+			bLines[k+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		// Changes separated by few unchanged lines share a hunk.
+		end := start
+		for {
+			for end < len(ops) && ops[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		lo, hi := max(start-diffContext, 0), min(end+diffContext, len(ops))
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(aLines[lo], aLines[hi]-aLines[lo]),
+			hunkRange(bLines[lo], bLines[hi]-bLines[lo]))
+		for _, op := range ops[lo:hi] {
+			out.WriteByte(op.kind)
+			out.WriteString(strings.TrimSuffix(op.line, noNewline))
+			out.WriteByte('\n')
+			if strings.HasSuffix(op.line, noNewline) {
+				out.WriteString("\\ No newline at end of file\n")
+			}
+		}
+		start = hi
+	}
+	return out.String()
+}
+
+// hunkRange formats the start line and length of one side of a hunk
+// that follows the first before lines.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
@@ -0,0 +1,302 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// discoursePostBatch is how many posts are requested at once.
+const discoursePostBatch = 20
+
+type discourseCategory struct {
+	ID            int                 `json:"id"`
+	Name          string              `json:"name"`
+	Slug          string              `json:"slug"`
+	TopicID       int                 `json:"topic_id"`
+	Subcategories []discourseCategory `json:"subcategory_list"`
+}
+
+type discourseTopic struct {
+	ID           int    `json:"id"`
+	CategoryID   int    `json:"category_id"`
+	Title        string `json:"title"`
+	Slug         string `json:"slug"`
+	LastPostedAt string `json:"last_posted_at"`
+	HasAccepted  bool   `json:"has_accepted_answer"`
+	PostStream   struct {
+		Posts  []discoursePost `json:"posts"`
+		Stream []int           `json:"stream"`
+	} `json:"post_stream"`
+	AcceptedAnswer struct {
+		PostNumber int `json:"post_number"`
+	} `json:"accepted_answer"`
+}
+
+type discoursePost struct {
+	ID             int    `json:"id"`
+	PostNumber     int    `json:"post_number"`
+	Username       string `json:"username"`
+	Cooked         string `json:"cooked"`
+	LikeCount      int    `json:"like_count"`
+	AcceptedAnswer bool   `json:"accepted_answer"`
+	// Actions carries like counts on versions that omit like_count.
+	Actions []struct {
+		ID    int `json:"id"`
+		Count int `json:"count"`
+	} `json:"actions_summary"`
+}
+
+// likes returns how many likes the post has.
+func (p discoursePost) likes() int {
+	if p.LikeCount > 0 {
+		return p.LikeCount
+	}
+	for _, a := range p.Actions {
+		// Action 2 is a like.
+		if a.ID == 2 {
+			return a.Count
+		}
+	}
+	return 0
+}
+
+// discourseSource converts topics from a Discourse forum's JSON API.
+type discourseSource struct {
+	cfg  DiscourseSource
+	base *url.URL
+	api  *apiClient
+}
+
+// ingestDiscourse converts the topics of the configured categories. Each
+// topic becomes a page with its first post and its accepted answer, plus
+// any reply with at least min_likes likes; the rest of the thread is left
+// out.
+func ingestDiscourse(cfg DiscourseSource, opts *CrawlOptions) error {
+	base, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if cfg.APIKeyEnv != "" {
+		key, err := sourceToken(cfg.APIKeyEnv)
+		if err != nil {
+			return err
+		}
+		header.Set("Api-Key", key)
+		header.Set("Api-Username", cfg.APIUsername)
+	}
+	s := &discourseSource{cfg: cfg, base: base, api: newAPIClient(header)}
+
+	var res struct {
+		CategoryList struct {
+			Categories []discourseCategory `json:"categories"`
+		} `json:"category_list"`
+	}
+	if err := s.api.getJSON(s.link("/categories.json?include_subcategories=true"), &res); err != nil {
+		return err
+	}
+	var categories []discourseCategory
+	for _, c := range res.CategoryList.Categories {
+		categories = append(categories, c)
+		categories = append(categories, c.Subcategories...)
+	}
+
+	count := 0
+	for _, c := range categories {
+		if !selectedCategory(cfg.Categories, strconv.Itoa(c.ID), c.Name) && !selectedCategory(cfg.Categories, "", c.Slug) {
+			continue
+		}
+		n, err := s.category(c, opts)
+		if err != nil {
+			return err
+		}
+		count += n
+	}
+	fmt.Printf("Converted %d Discourse topics\n", count)
+	return nil
+}
+
+// link returns the absolute URL of an API path on the forum.
+func (s *discourseSource) link(p string) string {
+	return s.base.String() + p
+}
+
+// category converts the topics in a category, returning how many were
+// written.
+func (s *discourseSource) category(c discourseCategory, opts *CrawlOptions) (int, error) {
+	count := 0
+	for page := 0; ; page++ {
+		var res struct {
+			TopicList struct {
+				Topics        []discourseTopic `json:"topics"`
+				MoreTopicsURL string           `json:"more_topics_url"`
+			} `json:"topic_list"`
+		}
+		link := s.link(fmt.Sprintf("/c/%s/%d/l/latest.json?page=%d", url.PathEscape(c.Slug), c.ID, page))
+		if err := s.api.getJSON(link, &res); err != nil {
+			return count, err
+		}
+		for _, t := range res.TopicList.Topics {
+			// Each category has an "About" topic holding its description.
+			// Topics of subcategories are listed too; they are converted
+			// with their own category.
+			if t.ID == c.TopicID || t.CategoryID != c.ID || (s.cfg.SolvedOnly && !t.HasAccepted) {
+				continue
+			}
+			if s.cfg.MaxTopics > 0 && count >= s.cfg.MaxTopics {
+				return count, nil
+			}
+			if err := s.topic(t.ID, c, opts); err != nil {
+				fmt.Printf("Error converting Discourse topic %s: %v\n", t.Title, err)
+				continue
+			}
+			count++
+		}
+		if len(res.TopicList.Topics) == 0 || res.TopicList.MoreTopicsURL == "" {
+			return count, nil
+		}
+	}
+}
+
+// topic converts a topic to <output>/<host>/<category>/<topic>.
+func (s *discourseSource) topic(id int, c discourseCategory, opts *CrawlOptions) error {
+	var t discourseTopic
+	if err := s.api.getJSON(s.link(fmt.Sprintf("/t/%d.json", id)), &t); err != nil {
+		return err
+	}
+	posts, err := s.posts(t)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		return fmt.Errorf("topic has no posts")
+	}
+
+	first, err := s.postMarkdown(posts[0], opts)
+	if err != nil {
+		return err
+	}
+	sections := []string{first}
+	for _, p := range posts[1:] {
+		accepted := p.AcceptedAnswer || (t.AcceptedAnswer.PostNumber != 0 && p.PostNumber == t.AcceptedAnswer.PostNumber)
+		var heading string
+		switch {
+		case accepted:
+			heading = "## Accepted Answer"
+		case s.cfg.MinLikes > 0 && p.likes() >= s.cfg.MinLikes:
+			heading = "## Reply"
+		default:
+			continue
+		}
+		body, err := s.postMarkdown(p, opts)
+		if err != nil {
+			return err
+		}
+		if p.Username != "" {
+			heading += " from @" + p.Username
+		}
+		sections = append(sections, heading+"\n\n"+body)
+	}
+
+	slug := t.Slug
+	if slug == "" {
+		slug = toPathCase(t.Title)
+	}
+	source := s.link(fmt.Sprintf("/t/%s/%d", slug, t.ID))
+	out := &url.URL{Scheme: "https", Host: s.base.Hostname(), Path: "/" + toPathCase(c.Slug) + "/" + slug}
+	_, err = writeSourcePage(opts, out, convertedPage{
+		URL:          source,
+		Title:        t.Title,
+		Body:         strings.Join(sections, "\n\n") + "\n",
+		LastModified: t.LastPostedAt,
+	})
+	return err
+}
+
+// posts returns the posts of a topic that may be rendered: the first
+// post, the accepted answer, and (with min_likes) every reply. The topic
+// response only carries the first posts of long threads, so any others
+// are requested by ID.
+func (s *discourseSource) posts(t discourseTopic) ([]discoursePost, error) {
+	posts := t.PostStream.Posts
+	loaded := map[int]bool{}
+	acceptedLoaded := t.AcceptedAnswer.PostNumber == 0
+	for _, p := range posts {
+		loaded[p.ID] = true
+		acceptedLoaded = acceptedLoaded || p.PostNumber == t.AcceptedAnswer.PostNumber
+	}
+	if s.cfg.MinLikes == 0 && acceptedLoaded {
+		return posts, nil
+	}
+	var missing []int
+	for _, id := range t.PostStream.Stream {
+		if !loaded[id] {
+			missing = append(missing, id)
+		}
+	}
+	for len(missing) > 0 {
+		batch := missing[:min(len(missing), discoursePostBatch)]
+		missing = missing[len(batch):]
+		query := url.Values{}
+		for _, id := range batch {
+			query.Add("post_ids[]", strconv.Itoa(id))
+		}
+		var res struct {
+			PostStream struct {
+				Posts []discoursePost `json:"posts"`
+			} `json:"post_stream"`
+		}
+		if err := s.api.getJSON(s.link(fmt.Sprintf("/t/%d/posts.json?%s", t.ID, query.Encode())), &res); err != nil {
+			return nil, err
+		}
+		posts = append(posts, res.PostStream.Posts...)
+	}
+	return posts, nil
+}
+
+// postMarkdown converts a post's rendered HTML, dropping forum chrome
+// (image size captions, quote headers) and resolving relative links
+// against the forum.
+func (s *discourseSource) postMarkdown(p discoursePost, opts *CrawlOptions) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(p.Cooked))
+	if err != nil {
+		return "", err
+	}
+	doc.Find(".lightbox-wrapper .meta, aside.quote > .title, .onebox .source").Remove()
+	for attr, selector := range map[string]string{"href": "a[href]", "src": "img[src]"} {
+		doc.Find(selector).Each(func(_ int, el *goquery.Selection) {
+			value, _ := el.Attr(attr)
+			if ref, err := url.Parse(value); err == nil && !strings.HasPrefix(value, "#") {
+				el.SetAttr(attr, s.base.ResolveReference(ref).String())
+			}
+		})
+	}
+	html, err := doc.Find("body").Html()
+	if err != nil {
+		return "", err
+	}
+	body, err := htmlToMarkdown(html, opts)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(body), nil
+}
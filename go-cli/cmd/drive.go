@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Google endpoints used by the Drive source.
+var (
+	driveAPI       = "https://www.googleapis.com/drive/v3"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+const (
+	driveScope        = "https://www.googleapis.com/auth/drive.readonly"
+	driveFolderType   = "application/vnd.google-apps.folder"
+	driveDocumentType = "application/vnd.google-apps.document"
+)
+
+// driveImage matches the base64 images a markdown export appends as
+// reference definitions.
+var driveImage = regexp.MustCompile(`(?m)^\[([^\]]+)\]:\s*<data:image/(\w+);base64,([^>]+)>\s*$`)
+
+type driveFile struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	MimeType     string `json:"mimeType"`
+	ModifiedTime string `json:"modifiedTime"`
+	WebViewLink  string `json:"webViewLink"`
+	Description  string `json:"description"`
+}
+
+// driveSource exports the Google Docs in a Drive folder.
+type driveSource struct {
+	cfg DriveSource
+	api *apiClient
+}
+
+// ingestDrive exports every Google Doc in the folder, and in its
+// subfolders, as markdown nested like the folders:
+// <output>/drive.google.com/<folder>/<subfolder>/<doc>.
+func ingestDrive(cfg DriveSource, opts *CrawlOptions) error {
+	token, err := driveAccessToken(cfg)
+	if err != nil {
+		return fmt.Errorf("authenticating with Google: %w", err)
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	s := &driveSource{cfg: cfg, api: newAPIClient(header)}
+
+	var root driveFile
+	if err := s.api.getJSON(driveAPI+"/files/"+url.PathEscape(cfg.FolderID)+"?supportsAllDrives=true&fields=id,name", &root); err != nil {
+		return err
+	}
+	count, err := s.folder(root, []string{toPathCase(root.Name)}, opts)
+	fmt.Printf("Exported %d documents from Drive folder %s\n", count, root.Name)
+	return err
+}
+
+// folder exports the documents in a folder and its subfolders.
+func (s *driveSource) folder(folder driveFile, segments []string, opts *CrawlOptions) (int, error) {
+	files, err := s.list(folder.ID)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, f := range files {
+		switch f.MimeType {
+		case driveFolderType:
+			n, err := s.folder(f, append(append([]string{}, segments...), toPathCase(f.Name)), opts)
+			if err != nil {
+				fmt.Printf("Error reading Drive folder %s: %v\n", f.Name, err)
+			}
+			count += n
+		case driveDocumentType:
+			if err := s.document(f, segments, opts); err != nil {
+				fmt.Printf("Error exporting %s: %v\n", f.Name, err)
+				continue
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// list returns the files in a folder.
+func (s *driveSource) list(folderID string) ([]driveFile, error) {
+	var files []driveFile
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf("'%s' in parents and trashed = false", strings.ReplaceAll(folderID, "'", "")))
+	query.Set("fields", "nextPageToken,files(id,name,mimeType,modifiedTime,webViewLink,description)")
+	query.Set("pageSize", "100")
+	query.Set("supportsAllDrives", "true")
+	query.Set("includeItemsFromAllDrives", "true")
+	for {
+		var res struct {
+			Files         []driveFile `json:"files"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		if err := s.api.getJSON(driveAPI+"/files?"+query.Encode(), &res); err != nil {
+			return nil, err
+		}
+		files = append(files, res.Files...)
+		if res.NextPageToken == "" {
+			return files, nil
+		}
+		query.Set("pageToken", res.NextPageToken)
+	}
+}
+
+// document exports a Google Doc as markdown. Embedded images are saved
+// next to the markdown. Drives that cannot export markdown fall back to
+// converting the HTML export.
+func (s *driveSource) document(f driveFile, segments []string, opts *CrawlOptions) error {
+	export := driveAPI + "/files/" + url.PathEscape(f.ID) + "/export?mimeType="
+	var body string
+	data, err := s.api.download(export + url.QueryEscape("text/markdown"))
+	if err == nil {
+		body = string(data)
+	} else {
+		html, err := s.api.download(export + url.QueryEscape("text/html"))
+		if err != nil {
+			return err
+		}
+		if body, err = htmlToMarkdown(string(html), opts); err != nil {
+			return err
+		}
+	}
+
+	images := map[string][]byte{}
+	body = driveImage.ReplaceAllStringFunc(body, func(m string) string {
+		sub := driveImage.FindStringSubmatch(m)
+		img, err := base64.StdEncoding.DecodeString(sub[3])
+		if err != nil {
+			return ""
+		}
+		name := toPathCase(f.Name) + "-" + toPathCase(sub[1]) + "." + sub[2]
+		images[name] = img
+		return "[" + sub[1] + "]: " + name
+	})
+
+	// The export starts with the document title when it uses the Title
+	// style; the page title comes from the file name instead.
+	body = strings.TrimSpace(body)
+	if first, rest, _ := strings.Cut(body, "\n"); strings.TrimSpace(strings.TrimPrefix(first, "# ")) == f.Name {
+		body = strings.TrimSpace(rest)
+	}
+
+	out := &url.URL{Scheme: "https", Host: "drive.google.com", Path: "/" + path.Join(append(segments, toPathCase(f.Name))...)}
+	dir, err := writeSourcePage(opts, out, convertedPage{
+		URL:          f.WebViewLink,
+		Title:        f.Name,
+		Description:  f.Description,
+		Body:         body + "\n",
+		LastModified: f.ModifiedTime,
+	})
+	if err != nil {
+		return err
+	}
+	for name, img := range images {
+		if err := os.WriteFile(filepath.Join(dir, name), img, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// driveAccessToken obtains an OAuth access token, from a service account
+// key or from an OAuth client's refresh token.
+func driveAccessToken(cfg DriveSource) (string, error) {
+	form := url.Values{}
+	if cfg.CredentialsFile != "" {
+		assertion, err := serviceAccountAssertion(cfg.CredentialsFile, cfg.Subject)
+		if err != nil {
+			return "", err
+		}
+		form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+		form.Set("assertion", assertion)
+	} else {
+		secret, err := sourceToken(cfg.ClientSecretEnv)
+		if err != nil {
+			return "", err
+		}
+		refresh, err := sourceToken(cfg.RefreshTokenEnv)
+		if err != nil {
+			return "", err
+		}
+		form.Set("grant_type", "refresh_token")
+		form.Set("client_id", cfg.ClientID)
+		form.Set("client_secret", secret)
+		form.Set("refresh_token", refresh)
+	}
+
+	resp, err := http.PostForm(googleTokenURL, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// serviceAccountAssertion signs the JWT a service account exchanges for an
+// access token. subject impersonates a user under domain-wide delegation.
+func serviceAccountAssertion(credentialsFile, subject string) (string, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return "", err
+	}
+	var key struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return "", fmt.Errorf("reading %s: %w", credentialsFile, err)
+	}
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("%s has no private key", credentialsFile)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("%s: private key is not RSA", credentialsFile)
+	}
+
+	now := time.Now().Unix()
+	claims := map[string]interface{}{
+		"iss":   key.ClientEmail,
+		"scope": driveScope,
+		"aud":   googleTokenURL,
+		"iat":   now,
+		"exp":   now + 3600,
+	}
+	if subject != "" {
+		claims["sub"] = subject
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LogEvent is one line of the crawl event log.
+type LogEvent struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+	URL   string    `json:"url,omitempty"`
+	// From is the page a considered link was found on.
+	From string `json:"from,omitempty"`
+	// Rule is the rule that decided whether a link is crawled, with the
+	// file it came from.
+	Rule     string `json:"rule,omitempty"`
+	Decision string `json:"decision,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	FinalURL string `json:"final_url,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// Kind classifies errors; see ErrorKind.
+	Kind ErrorKind `json:"kind,omitempty"`
+	// Path and Raw are the markdown and the saved response, relative to
+	// the output directory.
+	Path   string `json:"path,omitempty"`
+	Raw    string `json:"raw,omitempty"`
+	Config string `json:"config,omitempty"`
+}
+
+// Event log event names.
+const (
+	logCrawlStarted  = "crawl_started"
+	logCrawlFinished = "crawl_finished"
+	logConsidered    = "considered"
+	logFetched       = "fetched"
+	logConverted     = "converted"
+	logSkipped       = "skipped"
+	logError         = "error"
+)
+
+// eventLog appends every crawl decision to a JSONL file: each link
+// considered and the rule that decided it, each fetch, and each page
+// converted, skipped, or failed. It implements CrawlEvents so the crawl's
+// progress events are logged too. A nil log records nothing.
+type eventLog struct {
+	mu   sync.Mutex
+	f    *os.File
+	enc  *json.Encoder
+	opts *CrawlOptions
+}
+
+// openEventLog opens path for appending.
+func openEventLog(path string, opts *CrawlOptions) (*eventLog, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &eventLog{f: f, enc: json.NewEncoder(f), opts: opts}, nil
+}
+
+// record appends e, stamped with the current time.
+func (l *eventLog) record(e LogEvent) {
+	if l == nil {
+		return
+	}
+	e.Time = time.Now().UTC()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enc.Encode(e)
+}
+
+// considered records the decision about a link found on from. winner is
+// the deciding rule, if any.
+func (l *eventLog) considered(link, from string, winner *globRule, decision string) {
+	if l == nil {
+		return
+	}
+	e := LogEvent{Event: logConsidered, URL: link, From: from, Decision: decision}
+	if winner != nil {
+		e.Rule = winner.pattern
+		if winner.ignore {
+			e.Rule = "!" + e.Rule
+		}
+		if winner.source != "" {
+			e.Rule += " (" + winner.source + ")"
+		}
+	}
+	l.record(e)
+}
+
+// Close closes the log file.
+func (l *eventLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+func (l *eventLog) OnPageFetched(page FetchedPage) {
+	e := LogEvent{Event: logFetched, URL: page.RequestedURL, Status: page.StatusCode}
+	if page.URL != page.RequestedURL {
+		e.FinalURL = page.URL
+	}
+	l.record(e)
+}
+
+func (l *eventLog) OnPageConverted(entry ManifestEntry) {
+	e := LogEvent{Event: logConverted, URL: entry.URL, Path: entry.Path}
+	if u, err := url.Parse(entry.URL); err == nil {
+		_, raw := l.opts.outputPath(u)
+		if raw != l.opts.markdownPath(raw) {
+			if rel, err := filepath.Rel(l.opts.Output, raw); err == nil {
+				e.Raw = filepath.ToSlash(rel)
+			}
+		}
+	}
+	l.record(e)
+}
+
+func (l *eventLog) OnError(url string, err error) {
+	e := LogEvent{Event: logError, URL: url, Reason: err.Error()}
+	var pe *PageError
+	if errors.As(err, &pe) {
+		e.Kind, e.Status, e.Reason = pe.Kind, pe.Status, pe.Err.Error()
+	}
+	l.record(e)
+}
+
+func (l *eventLog) OnSkip(url, reason string) {
+	l.record(LogEvent{Event: logSkipped, URL: url, Reason: reason})
+}
+
+// multiEvents sends every event to each of its receivers.
+type multiEvents []CrawlEvents
+
+func (m multiEvents) OnPageFetched(page FetchedPage) {
+	for _, e := range m {
+		e.OnPageFetched(page)
+	}
+}
+
+func (m multiEvents) OnPageConverted(entry ManifestEntry) {
+	for _, e := range m {
+		e.OnPageConverted(entry)
+	}
+}
+
+func (m multiEvents) OnError(url string, err error) {
+	for _, e := range m {
+		e.OnError(url, err)
+	}
+}
+
+func (m multiEvents) OnSkip(url, reason string) {
+	for _, e := range m {
+		e.OnSkip(url, reason)
+	}
+}
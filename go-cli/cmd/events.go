@@ -0,0 +1,66 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CrawlEvents receives progress from a crawl, so applications embedding
+// the crawler can show it in their own UI or store it without parsing
+// stdout. Methods may be called from several goroutines at once and
+// should return quickly; the crawl waits for them.
+type CrawlEvents interface {
+	// OnPageFetched is called for every page fetched successfully, before
+	// it is converted.
+	OnPageFetched(page FetchedPage)
+	// OnPageConverted is called after a page's markdown is written, with
+	// the entry recorded in the manifest.
+	OnPageConverted(entry ManifestEntry)
+	// OnError is called when a page or source fails. url names the source
+	// for errors outside the crawl.
+	OnError(url string, err error)
+	// OnSkip is called for pages fetched but left out of the output.
+	OnSkip(url, reason string)
+}
+
+// FetchedPage describes a fetched page.
+type FetchedPage struct {
+	// URL is the final URL, after redirects.
+	URL string
+	// RequestedURL is the URL that was enqueued.
+	RequestedURL string
+	StatusCode   int
+	Headers      http.Header
+}
+
+// NopEvents implements CrawlEvents by doing nothing. Embed it to handle
+// only some events.
+type NopEvents struct{}
+
+func (NopEvents) OnPageFetched(FetchedPage)     {}
+func (NopEvents) OnPageConverted(ManifestEntry) {}
+func (NopEvents) OnError(string, error)         {}
+func (NopEvents) OnSkip(string, string)         {}
+
+// Crawl crawls the site described by cfg, reporting progress to events.
+// cfg is used as given: flags and config files are not read, but pattern
+// files listed in cfg.ConfigFiles are. Output still goes to stdout as for
+// the crawl command.
+func Crawl(cfg Config, events CrawlEvents) {
+	cfg.profile = strings.Join(cfg.ConfigFiles, ",")
+	crawlSite(cfg, "", events)
+}
@@ -0,0 +1,464 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// exportFormat selects the export format.
+// exportOut is the directory the export is written to.
+// exportRows makes each row a page or a section of one.
+// exportName and exportLicense fill in the dataset card.
+// exportSince is the generation or time a patch bundle starts from.
+var (
+	exportFormat  string
+	exportOut     string
+	exportRows    string
+	exportName    string
+	exportLicense string
+	exportSince   string
+)
+
+// datasetShardBytes is the size at which dataset files are split.
+const datasetShardBytes = 256 << 20
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the crawled pages as a dataset or RAG documents",
+	Long: `Writes the pages in the output directory's manifest to --out in one of
+these formats:
+
+hf-dataset (default): the layout of a Hugging Face dataset repository in
+<output>-dataset:
+
+  README.md                             dataset card with the data files config
+  data/train-00000-of-00001.jsonl       one JSON row per page or section
+
+Each row has the markdown text (without frontmatter and title), url,
+title, section, skill, and path of a page. Files above 256 MiB are split
+into shards. Push the directory with:
+
+  huggingface-cli upload <user>/<dataset> <out> --repo-type dataset
+
+documents: <output>-documents.jsonl, one document per line in the shape
+LangChain and LlamaIndex documents take, {"page_content": ...,
+"metadata": {"source": ..., "title": ..., ...}}.
+
+patch: <output>-patch-<from>-<to>.tar.gz, the files added or changed
+since the generation named by --since (the default format when --since
+is given), the current manifest, and patch.json listing the added,
+changed, and deleted files. A generation is recorded each time a crawl
+or other command changes the output's files; --since takes its number
+from the manifest's generation field, or a time (RFC 3339 or a date) for
+the generation current then. Generation 0 exports everything.
+
+With --rows section every page is split at its H2 headings, one row per
+section, with the heading in section; the text before the first heading
+is a row with an empty section.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if exportSince != "" && !cmd.Flags().Changed("format") {
+			exportFormat = "patch"
+		}
+		if exportFormat != "hf-dataset" && exportFormat != "documents" && exportFormat != "patch" {
+			fmt.Printf("Error: unknown export format %q (want hf-dataset, documents, or patch)\n", exportFormat)
+			os.Exit(1)
+		}
+		if (exportFormat == "patch") != (exportSince != "") {
+			fmt.Println("Error: --since and the patch format go together")
+			os.Exit(1)
+		}
+		if exportRows != "page" && exportRows != "section" {
+			fmt.Printf("Error: --rows must be page or section, not %q\n", exportRows)
+			os.Exit(1)
+		}
+		outputDir := configuredOutput()
+		opts := &CrawlOptions{Output: outputDir, Flat: viper.GetBool("flat"), Rename: viper.GetString("rename")}
+		m, err := loadManifest(outputDir)
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+		if exportFormat == "patch" {
+			exportPatch(outputDir, m)
+			return
+		}
+		rows, err := datasetRows(opts, m, exportRows == "section")
+		if err != nil {
+			fmt.Printf("Error reading pages: %v\n", err)
+			os.Exit(1)
+		}
+		if len(rows) == 0 {
+			fmt.Println("Error: no pages to export")
+			os.Exit(1)
+		}
+
+		out := exportOut
+		if exportFormat == "documents" {
+			if out == "" {
+				out = strings.TrimSuffix(outputDir, string(filepath.Separator)) + "-documents.jsonl"
+			}
+			if err := writeDocuments(out, rows); err != nil {
+				fmt.Printf("Error writing documents: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Exported %d documents to %s\n", len(rows), out)
+			return
+		}
+		if out == "" {
+			out = strings.TrimSuffix(outputDir, string(filepath.Separator)) + "-dataset"
+		}
+		name := exportName
+		if name == "" {
+			abs, _ := filepath.Abs(outputDir)
+			name = strings.TrimLeft(filepath.Base(abs), ".")
+		}
+		files, err := writeDataset(out, rows)
+		if err != nil {
+			fmt.Printf("Error writing dataset: %v\n", err)
+			os.Exit(1)
+		}
+		if err := writeDatasetCard(out, name, exportLicense, exportRows, rows); err != nil {
+			fmt.Printf("Error writing dataset card: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported %d rows in %d files to %s\n", len(rows), files, out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", "hf-dataset", "export format: hf-dataset, documents, or patch")
+	exportCmd.Flags().StringVar(&exportOut, "out", "", "where to write (default: <output>-dataset, <output>-documents.jsonl, or <output>-patch-<from>-<to>.tar.gz)")
+	exportCmd.Flags().StringVar(&exportRows, "rows", "page", "one row per page or per section")
+	exportCmd.Flags().StringVar(&exportName, "name", "", "dataset name in the card (default: the output directory's name)")
+	exportCmd.Flags().StringVar(&exportLicense, "license", "", "license identifier for the card, e.g. cc-by-4.0")
+	exportCmd.Flags().StringVar(&exportSince, "since", "", "export a patch bundle of the changes since this generation or time")
+}
+
+// exportPatch writes the patch bundle of the changes since --since.
+func exportPatch(outputDir string, m *Manifest) {
+	base, err := sinceGeneration(outputDir, m, exportSince)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	p := diffGenerations(base, m.Generation, manifestFiles(outputDir, m))
+	if len(p.Added)+len(p.Changed)+len(p.Deleted) == 0 {
+		fmt.Printf("Nothing changed since generation %d\n", base.Generation)
+		return
+	}
+	out := exportOut
+	if out == "" {
+		out = fmt.Sprintf("%s-patch-%d-%d.tar.gz", strings.TrimSuffix(outputDir, string(filepath.Separator)), p.From, p.To)
+	}
+	if err := writePatch(out, outputDir, p); err != nil {
+		fmt.Printf("Error writing patch: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported generations %d to %d to %s: %d added, %d changed, %d deleted\n", p.From, p.To, out, len(p.Added), len(p.Changed), len(p.Deleted))
+}
+
+// datasetRow is one row of an exported dataset. The description is only
+// exported in document metadata.
+type datasetRow struct {
+	Text        string `json:"text"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Section     string `json:"section"`
+	Skill       string `json:"skill"`
+	Path        string `json:"path"`
+	description string
+}
+
+// datasetRows returns the rows for the pages in m, ordered by path. Each
+// file is exported once, however many URLs were saved to it.
+func datasetRows(opts *CrawlOptions, m *Manifest, bySection bool) ([]datasetRow, error) {
+	entries := make([]*ManifestEntry, 0, len(m.Pages))
+	for _, entry := range m.Pages {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return len(entries[i].URL) < len(entries[j].URL) || len(entries[i].URL) == len(entries[j].URL) && entries[i].URL < entries[j].URL
+	})
+
+	var rows []datasetRow
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if seen[entry.Path] {
+			continue
+		}
+		seen[entry.Path] = true
+		meta, body, err := readPage(opts.pageFile(entry))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		title := entry.Title
+		if title == "" {
+			title = meta.Title
+		}
+		row := datasetRow{URL: entry.URL, Title: title, Skill: opts.skillDirOf(entry), Path: entry.Path}
+		if meta.Description != noDescription {
+			row.description = meta.Description
+		}
+		text := stripPageTitle(body, meta.Title)
+		if !bySection {
+			row.Text = text
+			rows = append(rows, row)
+			continue
+		}
+		for _, s := range splitSections(text) {
+			row.Section, row.Text = s.heading, s.text
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// textSection is a part of a page under one H2 heading.
+type textSection struct {
+	heading string
+	text    string
+}
+
+// splitSections splits markdown at its H2 headings outside code fences.
+// Text before the first heading is a section without a heading; empty
+// sections are dropped.
+func splitSections(body string) []textSection {
+	var sections []textSection
+	current := textSection{}
+	var lines []string
+	flush := func() {
+		current.text = strings.TrimSpace(strings.Join(lines, "\n"))
+		if current.text != "" {
+			sections = append(sections, current)
+		}
+		lines = nil
+	}
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+		}
+		if level, heading, ok := atxHeading(line); ok && !inFence && level == 2 {
+			flush()
+			current = textSection{heading: heading}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return sections
+}
+
+// writeDataset writes rows as JSON lines to data/ under out, split into
+// shards of about datasetShardBytes, replacing earlier data files. It
+// returns the number of files written.
+func writeDataset(out string, rows []datasetRow) (int, error) {
+	dataDir := filepath.Join(out, "data")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return 0, err
+	}
+	old, _ := filepath.Glob(filepath.Join(dataDir, "train-*.jsonl"))
+	for _, path := range old {
+		os.Remove(path)
+	}
+
+	var shards [][]byte
+	var shard []byte
+	for _, row := range rows {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return 0, err
+		}
+		if len(shard) > 0 && len(shard)+len(line) > datasetShardBytes {
+			shards = append(shards, shard)
+			shard = nil
+		}
+		shard = append(append(shard, line...), '\n')
+	}
+	shards = append(shards, shard)
+
+	for i, data := range shards {
+		name := fmt.Sprintf("train-%05d-of-%05d.jsonl", i, len(shards))
+		f, err := os.Create(filepath.Join(dataDir, name))
+		if err != nil {
+			return 0, err
+		}
+		w := bufio.NewWriter(f)
+		w.Write(data)
+		if err := w.Flush(); err != nil {
+			f.Close()
+			return 0, err
+		}
+		if err := f.Close(); err != nil {
+			return 0, err
+		}
+	}
+	return len(shards), nil
+}
+
+// datasetCard is the YAML header of a dataset card.
+type datasetCard struct {
+	PrettyName     string          `yaml:"pretty_name"`
+	License        string          `yaml:"license,omitempty"`
+	TaskCategories []string        `yaml:"task_categories"`
+	Tags           []string        `yaml:"tags"`
+	SizeCategories []string        `yaml:"size_categories"`
+	Configs        []datasetConfig `yaml:"configs"`
+}
+
+type datasetConfig struct {
+	ConfigName string            `yaml:"config_name"`
+	DataFiles  []datasetDataFile `yaml:"data_files"`
+}
+
+type datasetDataFile struct {
+	Split string `yaml:"split"`
+	Path  string `yaml:"path"`
+}
+
+// sizeCategory returns the Hugging Face size category for n rows.
+func sizeCategory(n int) string {
+	switch {
+	case n < 1000:
+		return "n<1K"
+	case n < 10000:
+		return "1K<n<10K"
+	case n < 100000:
+		return "10K<n<100K"
+	case n < 1000000:
+		return "100K<n<1M"
+	}
+	return "1M<n<10M"
+}
+
+// writeDatasetCard writes README.md, the dataset card, to out.
+func writeDatasetCard(out, name, license, rowKind string, rows []datasetRow) error {
+	card := datasetCard{
+		PrettyName:     name,
+		License:        license,
+		TaskCategories: []string{"text-generation"},
+		Tags:           []string{"documentation", "markdown", "agent-skills"},
+		SizeCategories: []string{sizeCategory(len(rows))},
+		Configs: []datasetConfig{{
+			ConfigName: "default",
+			DataFiles:  []datasetDataFile{{Split: "train", Path: "data/train-*.jsonl"}},
+		}},
+	}
+	var header strings.Builder
+	enc := yaml.NewEncoder(&header)
+	enc.SetIndent(2)
+	if err := enc.Encode(card); err != nil {
+		return err
+	}
+
+	pages := map[string]bool{}
+	bySkill := map[string]int{}
+	for _, row := range rows {
+		pages[row.Path] = true
+		bySkill[row.Skill]++
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString(header.String())
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n\n", name)
+	fmt.Fprintf(&b, "Documentation pages converted to markdown by %s %s on %s: %d rows from %d pages, one row per %s.\n\n",
+		toolName, buildInfo().Version, time.Now().UTC().Format(time.DateOnly), len(rows), len(pages), rowKind)
+	b.WriteString("## Columns\n\n")
+	b.WriteString("| Column | Description |\n| --- | --- |\n")
+	b.WriteString("| `text` | Markdown content, without frontmatter and page title |\n")
+	b.WriteString("| `url` | Source URL of the page |\n")
+	b.WriteString("| `title` | Page title |\n")
+	b.WriteString("| `section` | H2 heading of the section (empty for whole pages and page introductions) |\n")
+	b.WriteString("| `skill` | Skill directory the page belongs to |\n")
+	b.WriteString("| `path` | Path of the page's markdown file in the skill cache |\n\n")
+	b.WriteString("## Skills\n\n| Skill | Rows |\n| --- | --- |\n")
+	for _, skill := range sortedKeys(bySkill) {
+		fmt.Fprintf(&b, "| %s | %d |\n", skill, bySkill[skill])
+	}
+	b.WriteString("\nThe pages keep the licenses of the sites they were crawled from.\n")
+	return os.WriteFile(filepath.Join(out, "README.md"), []byte(b.String()), 0644)
+}
+
+// ragDocument is a document in the shape LangChain's Document and
+// LlamaIndex's Document take.
+type ragDocument struct {
+	PageContent string      `json:"page_content"`
+	Metadata    ragMetadata `json:"metadata"`
+}
+
+type ragMetadata struct {
+	Source      string `json:"source"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Section     string `json:"section,omitempty"`
+	Skill       string `json:"skill"`
+	Path        string `json:"path"`
+}
+
+// writeDocuments writes rows to path as JSON lines of RAG documents.
+func writeDocuments(path string, rows []datasetRow) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, row := range rows {
+		doc := ragDocument{
+			PageContent: row.Text,
+			Metadata: ragMetadata{
+				Source:      row.URL,
+				Title:       row.Title,
+				Description: row.description,
+				Section:     row.Section,
+				Skill:       row.Skill,
+				Path:        row.Path,
+			},
+		}
+		if err := enc.Encode(doc); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
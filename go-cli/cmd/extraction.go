@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gobwas/glob"
+)
+
+// defaultStripSelectors are always removed from extracted content.
+var defaultStripSelectors = []string{"header#site-content-title", ".toc"}
+
+// compiledScope is an ExtractionScope with its globs compiled.
+type compiledScope struct {
+	globs []glob.Glob
+	ExtractionConfig
+}
+
+// compileScopes compiles the match globs of every scope.
+func compileScopes(scopes []ExtractionScope) ([]compiledScope, error) {
+	var compiled []compiledScope
+	for _, s := range scopes {
+		if err := checkProfile(s.Profile); err != nil {
+			return nil, err
+		}
+		if err := checkFrontmatterMap(s.FrontmatterMap); err != nil {
+			return nil, err
+		}
+		cs := compiledScope{ExtractionConfig: s.ExtractionConfig}
+		for _, m := range s.Match {
+			g, err := glob.Compile(m)
+			if err != nil {
+				return nil, fmt.Errorf("invalid scope glob %s: %w", m, err)
+			}
+			cs.globs = append(cs.globs, g)
+		}
+		compiled = append(compiled, cs)
+	}
+	return compiled, nil
+}
+
+// resolveExtraction returns the extraction settings for link: the base
+// settings overlaid with every matching scope in declaration order.
+// Later scalar values win, strip selectors and stop headings accumulate,
+// and frontmatter and frontmatter_map keys merge. Converter settings
+// follow the same rules, with plugins accumulating.
+func resolveExtraction(base ExtractionConfig, scopes []compiledScope, link string) ExtractionConfig {
+	resolved := base
+	resolved.StripSelectors = append([]string{}, base.StripSelectors...)
+	resolved.StopHeadings = append([]string{}, base.StopHeadings...)
+	resolved.Frontmatter = map[string]string{}
+	for k, v := range base.Frontmatter {
+		resolved.Frontmatter[k] = v
+	}
+	resolved.Converter.Plugins = append([]string{}, base.Converter.Plugins...)
+	resolved.FrontmatterMap = map[string]string{}
+	for k, v := range base.FrontmatterMap {
+		resolved.FrontmatterMap[k] = v
+	}
+
+	for _, s := range scopes {
+		if !s.matches(link) {
+			continue
+		}
+		if s.ContentSelector != "" {
+			resolved.ContentSelector = s.ContentSelector
+		}
+		if s.Mode != "" {
+			resolved.Mode = s.Mode
+		}
+		if s.Profile != "" {
+			resolved.Profile = s.Profile
+		}
+		if s.HTMLFallback {
+			resolved.HTMLFallback = true
+		}
+		if s.StopSections {
+			resolved.StopSections = true
+		}
+		if s.MinWords != 0 {
+			resolved.MinWords = s.MinWords
+		}
+		if s.MinTokens != 0 {
+			resolved.MinTokens = s.MinTokens
+		}
+		if s.MaxSimilarity != 0 {
+			resolved.MaxSimilarity = s.MaxSimilarity
+		}
+		resolved.Converter = mergeConverter(resolved.Converter, s.Converter)
+		resolved.StripSelectors = append(resolved.StripSelectors, s.StripSelectors...)
+		resolved.StopHeadings = append(resolved.StopHeadings, s.StopHeadings...)
+		for k, v := range s.Frontmatter {
+			resolved.Frontmatter[k] = v
+		}
+		for k, v := range s.FrontmatterMap {
+			resolved.FrontmatterMap[k] = v
+		}
+	}
+	return resolved
+}
+
+func (s compiledScope) matches(link string) bool {
+	for _, g := range s.globs {
+		if g.Match(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// extraFrontmatter returns the configured static frontmatter fields in
+// key order.
+func extraFrontmatter(ext ExtractionConfig) frontmatter {
+	keys := make([]string, 0, len(ext.Frontmatter))
+	for k := range ext.Frontmatter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var fm frontmatter
+	for _, k := range keys {
+		fm = append(fm, fmField{Key: k, Value: ext.Frontmatter[k]})
+	}
+	return fm
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// faqFileName is the FAQ written into each skill directory.
+const faqFileName = "FAQ.md"
+
+// faqEntry is a question and answer found on a page.
+type faqEntry struct {
+	Question string
+	Answer   string // markdown
+	Source   *ManifestEntry
+}
+
+// writeFAQs writes a FAQ.md into each skill directory from FAQPage JSON-LD,
+// schema.org Question microdata, and visible FAQ accordions on its pages.
+func writeFAQs(opts *CrawlOptions, m *Manifest) {
+	converter := newMarkdownConverter(opts.extraction.Converter, nil)
+	bySkill := map[string][]faqEntry{}
+	seen := map[string]bool{}
+
+	for _, entry := range m.Pages {
+		html, err := opts.readSavedHTML(entry)
+		if err != nil {
+			continue
+		}
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+		if err != nil {
+			continue
+		}
+		skill := opts.skillDirOf(entry)
+		for _, qa := range extractFAQ(doc, converter) {
+			key := skill + "\x00" + strings.ToLower(qa.Question)
+			if qa.Question == "" || qa.Answer == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			qa.Source = entry
+			bySkill[skill] = append(bySkill[skill], qa)
+		}
+	}
+
+	for skill, entries := range bySkill {
+		rel := path.Join(skill, faqFileName)
+		content := renderFAQ(entries, skill)
+		if err := writeFileIfChanged(filepath.Join(opts.Output, filepath.FromSlash(rel)), []byte(content)); err != nil {
+			fmt.Printf("Error writing %s: %v\n", rel, err)
+			continue
+		}
+		m.recordGenerated(rel, content)
+		fmt.Printf("Wrote %d questions to %s\n", len(entries), rel)
+	}
+}
+
+// extractFAQ returns the question/answer pairs on a page. Structured data
+// comes first; accordions are only read when the page has none.
+func extractFAQ(doc *goquery.Document, converter markdownConverter) []faqEntry {
+	toMarkdown := func(html string) string {
+		out, err := converter.ConvertString(html)
+		if err != nil {
+			return squash(html)
+		}
+		return strings.TrimSpace(out)
+	}
+
+	var out []faqEntry
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return
+		}
+		for _, q := range jsonLDQuestions(data) {
+			out = append(out, faqEntry{Question: squash(q[0]), Answer: toMarkdown(q[1])})
+		}
+	})
+
+	doc.Find(`[itemtype$="schema.org/Question"]`).Each(func(i int, s *goquery.Selection) {
+		answer, _ := s.Find(`[itemprop="acceptedAnswer"] [itemprop="text"]`).First().Html()
+		out = append(out, faqEntry{
+			Question: squash(s.Find(`[itemprop="name"]`).First().Text()),
+			Answer:   toMarkdown(answer),
+		})
+	})
+	if len(out) > 0 {
+		return out
+	}
+
+	doc.Find("details").Each(func(i int, s *goquery.Selection) {
+		summary := s.ChildrenFiltered("summary").First()
+		question := squash(summary.Text())
+		if !strings.HasSuffix(question, "?") {
+			return
+		}
+		answer := s.Clone()
+		answer.ChildrenFiltered("summary").Remove()
+		html, _ := answer.Html()
+		out = append(out, faqEntry{Question: question, Answer: toMarkdown(html)})
+	})
+
+	doc.Find("[aria-controls]").Each(func(i int, s *goquery.Selection) {
+		question := squash(s.Text())
+		id, _ := s.Attr("aria-controls")
+		if !strings.HasSuffix(question, "?") || id == "" {
+			return
+		}
+		panel := doc.Find("[id]").FilterFunction(func(i int, p *goquery.Selection) bool {
+			pid, _ := p.Attr("id")
+			return pid == id
+		}).First()
+		html, _ := panel.Html()
+		out = append(out, faqEntry{Question: question, Answer: toMarkdown(html)})
+	})
+	return out
+}
+
+// jsonLDQuestions returns the [question, answer HTML] pairs of any FAQPage
+// in a JSON-LD document, looking through arrays and @graph.
+func jsonLDQuestions(data interface{}) [][2]string {
+	var out [][2]string
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			out = append(out, jsonLDQuestions(item)...)
+		}
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok {
+			out = append(out, jsonLDQuestions(graph)...)
+		}
+		if !jsonLDHasType(v, "FAQPage") {
+			return out
+		}
+		entities, ok := v["mainEntity"].([]interface{})
+		if !ok {
+			entities = []interface{}{v["mainEntity"]}
+		}
+		for _, e := range entities {
+			q, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := q["name"].(string)
+			answer := q["acceptedAnswer"]
+			if list, ok := answer.([]interface{}); ok && len(list) > 0 {
+				answer = list[0]
+			}
+			if a, ok := answer.(map[string]interface{}); ok {
+				text, _ := a["text"].(string)
+				out = append(out, [2]string{name, text})
+			}
+		}
+	}
+	return out
+}
+
+// jsonLDHasType reports whether a JSON-LD node has the given @type.
+func jsonLDHasType(node map[string]interface{}, want string) bool {
+	switch t := node["@type"].(type) {
+	case string:
+		return t == want
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renderFAQ renders the questions of a skill grouped by source page.
+func renderFAQ(entries []faqEntry, skill string) string {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Source.Path < entries[j].Source.Path
+	})
+
+	var b strings.Builder
+	b.WriteString("# FAQ\n\n")
+	for _, qa := range entries {
+		link := qa.Source.Path
+		if skill != "." {
+			link = strings.TrimPrefix(link, skill+"/")
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s\n\nSource: [%s](%s)\n\n", qa.Question, qa.Answer, qa.Source.Title, link)
+	}
+	return strings.TrimSpace(b.String()) + "\n"
+}
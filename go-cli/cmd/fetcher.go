@@ -0,0 +1,261 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fetcher retrieves the response for a single request. Colly still drives
+// the crawl (rules, link following, limits); a Fetcher only decides where
+// the bytes come from.
+type Fetcher interface {
+	Fetch(req *http.Request) (*http.Response, error)
+}
+
+// fetcherTransport adapts a Fetcher to the http.RoundTripper colly expects.
+type fetcherTransport struct {
+	fetcher Fetcher
+}
+
+func (t fetcherTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.fetcher.Fetch(req)
+}
+
+// newFetcher builds the Fetcher selected by the config.
+// file:// URLs are always served from disk, whatever the backend. When
+// render is set, the URLs it reports are fetched with the headless browser
+// instead. Screenshots the browser takes are kept in shots.
+//
+// Requests over the network follow the resolve overrides and DNS servers
+// of the config; the browser only follows the overrides. Over HTTP, forge
+// blob pages are fetched as their raw files (see forgeFetcher).
+func newFetcher(cfg *Config, render func(link string) bool, shots *sync.Map) (Fetcher, error) {
+	local := &localFetcher{root: cfg.LocalRoot}
+	overrides, err := parseResolve(cfg.Resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AsOf != "" && cfg.Fetcher != "" && cfg.Fetcher != "http" {
+		return nil, fmt.Errorf("as_of requires the http fetcher, not %q", cfg.Fetcher)
+	}
+
+	var remote Fetcher
+	switch cfg.Fetcher {
+	case "", "http":
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if dial := newDialer(overrides, cfg.DNS); dial != nil {
+			transport.DialContext = dial
+		}
+		remote = &httpFetcher{transport: transport}
+		if cfg.AsOf != "" {
+			at, err := parseAsOf(cfg.AsOf)
+			if err != nil {
+				return nil, err
+			}
+			endpoint := strings.TrimSuffix(cfg.Wayback.CDXEndpoint, "/")
+			if endpoint == "" {
+				endpoint = defaultCDXEndpoint
+			}
+			remote = &asOfFetcher{next: remote, at: at, endpoint: endpoint}
+		}
+		remote = &forgeFetcher{next: remote}
+	case "local":
+		if cfg.LocalRoot == "" {
+			return nil, fmt.Errorf("fetcher %q requires local_root", cfg.Fetcher)
+		}
+		remote = local
+	case "warc":
+		w, err := newWARCFetcher(cfg.WARCFiles)
+		if err != nil {
+			return nil, err
+		}
+		remote = w
+	case "browser":
+		b, err := newBrowserFetcher(cfg.Browser, overrides, shots)
+		if err != nil {
+			return nil, err
+		}
+		remote = b
+	default:
+		return nil, fmt.Errorf("unknown fetcher %q", cfg.Fetcher)
+	}
+	if render != nil && cfg.Fetcher != "browser" {
+		remote = &renderFetcher{next: remote, render: render, cfg: cfg.Browser, overrides: overrides, shots: shots}
+	}
+
+	return schemeFetcher{"file": local, "": remote}, nil
+}
+
+// schemeFetcher dispatches requests by URL scheme.
+// The "" entry handles any scheme without its own entry.
+type schemeFetcher map[string]Fetcher
+
+func (s schemeFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	if f, ok := s[req.URL.Scheme]; ok {
+		return f.Fetch(req)
+	}
+	return s[""].Fetch(req)
+}
+
+// Close releases fetchers that hold resources, such as the browser.
+func (s schemeFetcher) Close() error {
+	for _, f := range s {
+		if c, ok := f.(io.Closer); ok {
+			c.Close()
+		}
+	}
+	return nil
+}
+
+// renderFetcher sends the requests render reports to a headless browser,
+// started on first use, and the rest to next.
+type renderFetcher struct {
+	next      Fetcher
+	render    func(link string) bool
+	cfg       BrowserConfig
+	overrides []hostOverride
+	shots     *sync.Map
+
+	once    sync.Once
+	browser *browserFetcher
+	err     error
+}
+
+func (f *renderFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	if !f.render(req.URL.String()) {
+		return f.next.Fetch(req)
+	}
+	f.once.Do(func() {
+		f.browser, f.err = newBrowserFetcher(f.cfg, f.overrides, f.shots)
+	})
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.browser.Fetch(req)
+}
+
+// Close shuts the browser down if it was started.
+func (f *renderFetcher) Close() error {
+	if f.browser != nil {
+		f.browser.Close()
+	}
+	if c, ok := f.next.(io.Closer); ok {
+		c.Close()
+	}
+	return nil
+}
+
+// httpFetcher fetches over the network.
+type httpFetcher struct {
+	transport http.RoundTripper
+}
+
+func (f *httpFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	return f.transport.RoundTrip(req)
+}
+
+// localFetcher serves responses from files on disk, so a saved HTML dump
+// (a wget mirror, an exported GitBook) converts without any network.
+//
+// file:// URLs map directly to their path. http(s) URLs map to
+// <root>/<host>/<path> (the wget --mirror layout), falling back to
+// <root>/<path> when there is no host directory.
+type localFetcher struct {
+	root string
+}
+
+func (f *localFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	path, ok := f.resolve(req)
+	if !ok {
+		return localResponse(req, http.StatusNotFound, nil, "", time.Time{}), nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if since, err := http.ParseTime(req.Header.Get("If-Modified-Since")); err == nil {
+		if !info.ModTime().Truncate(time.Second).After(since) {
+			return localResponse(req, http.StatusNotModified, nil, "", info.ModTime()), nil
+		}
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	return localResponse(req, http.StatusOK, body, contentType, info.ModTime()), nil
+}
+
+// resolve returns the file backing the request, if any.
+func (f *localFetcher) resolve(req *http.Request) (string, bool) {
+	var bases []string
+	if req.URL.Scheme == "file" {
+		bases = []string{filepath.FromSlash(req.URL.Path)}
+	} else {
+		rel := filepath.FromSlash(strings.TrimPrefix(req.URL.Path, "/"))
+		bases = []string{
+			filepath.Join(f.root, req.URL.Hostname(), rel),
+			filepath.Join(f.root, rel),
+		}
+	}
+
+	for _, base := range bases {
+		candidates := []string{base, base + ".html", filepath.Join(base, "index.html")}
+		for _, c := range candidates {
+			if info, err := os.Stat(c); err == nil && !info.IsDir() {
+				return c, true
+			}
+		}
+	}
+	return "", false
+}
+
+// localResponse builds an HTTP response for a file served from disk.
+func localResponse(req *http.Request, status int, body []byte, contentType string, modTime time.Time) *http.Response {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if !modTime.IsZero() {
+		header.Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Fetch retry defaults, used when the config leaves a setting empty.
+const (
+	defaultFetchRetries    = 2
+	defaultFetchRetryDelay = time.Second
+)
+
+// fetchAttemptsKey counts the retries of a failed request in its context.
+const fetchAttemptsKey = "fetch_attempts"
+
+// What happens to a page that still fails once its retries are used up.
+const (
+	fetchRetry = "retry"
+	fetchSkip  = "skip"
+	fetchAbort = "abort"
+)
+
+// fetchRetries retries pages whose fetch failed in a way that may pass:
+// network errors and timeouts, and 408 and 5xx statuses other than those
+// the throttle handles. Once a page's retries are used up, an attended
+// crawl asks whether to retry it again, skip it, or abort the crawl;
+// otherwise the policy decides.
+type fetchRetries struct {
+	retries int
+	delay   time.Duration
+	policy  string
+	// stop aborts the crawl, keeping the pending URLs for --resume.
+	stop func()
+
+	mu          sync.Mutex
+	interactive bool
+	in          *bufio.Reader
+	skipAll     bool
+	aborted     bool
+}
+
+// newFetchRetries returns the retry handling configured by cfg, asking
+// on the terminal when interactive is set.
+func newFetchRetries(cfg FetchErrorConfig, interactive bool, stop func()) (*fetchRetries, error) {
+	f := &fetchRetries{retries: defaultFetchRetries, delay: defaultFetchRetryDelay, policy: fetchSkip, stop: stop, interactive: interactive}
+	if cfg.Retries != 0 {
+		f.retries = max(cfg.Retries, 0)
+	}
+	if cfg.Delay != "" {
+		d, err := time.ParseDuration(cfg.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fetch_errors.delay: %w", err)
+		}
+		f.delay = d
+	}
+	switch cfg.Policy {
+	case "", fetchSkip:
+	case fetchAbort:
+		f.policy = fetchAbort
+	default:
+		return nil, fmt.Errorf("unknown fetch_errors.policy %q", cfg.Policy)
+	}
+	if interactive {
+		f.in = bufio.NewReader(os.Stdin)
+	}
+	return f, nil
+}
+
+// retryableFetch reports whether a fetch that failed with status (0 for
+// no response) may succeed when sent again.
+func retryableFetch(status int) bool {
+	return status == 0 || status == http.StatusRequestTimeout || status >= 500
+}
+
+// retry sends a failed request again while it has retries left, then
+// decides what to do with it. It reports whether the request was sent
+// again; otherwise the caller records the failure.
+func (f *fetchRetries) retry(r *colly.Response, err error) bool {
+	if !retryableFetch(r.StatusCode) {
+		return false
+	}
+	link := r.Request.URL.String()
+	attempts, _ := r.Ctx.GetAny(fetchAttemptsKey).(int)
+	// 429 and 503 were already retried by the throttle.
+	throttled := r.StatusCode == http.StatusTooManyRequests || r.StatusCode == http.StatusServiceUnavailable
+	if attempts < f.retries && !throttled {
+		r.Ctx.Put(fetchAttemptsKey, attempts+1)
+		fmt.Printf("Fetching %s failed (%v), retrying in %s (%d of %d)\n", link, err, f.delay, attempts+1, f.retries)
+		time.Sleep(f.delay)
+		return r.Request.Retry() != colly.ErrRetryBodyUnseekable
+	}
+	switch f.decide(link, attempts+1, err) {
+	case fetchRetry:
+		r.Ctx.Put(fetchAttemptsKey, attempts+1)
+		return r.Request.Retry() != colly.ErrRetryBodyUnseekable
+	case fetchAbort:
+		f.stop()
+	}
+	return false
+}
+
+// decide returns what to do with a page that failed attempts times:
+// the user's answer in an attended crawl, or the policy. Questions are
+// asked one at a time, and "skip all" stops asking.
+func (f *fetchRetries) decide(link string, attempts int, err error) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.aborted || f.skipAll {
+		return fetchSkip
+	}
+	for f.interactive {
+		fmt.Printf("Fetching %s failed %d times: %v\n[r]etry, [s]kip, skip [A]ll, or [a]bort? ", link, attempts, err)
+		line, readErr := f.in.ReadString('\n')
+		switch strings.TrimSpace(line) {
+		case "r", "retry":
+			return fetchRetry
+		case "s", "skip":
+			return fetchSkip
+		case "A", "all", "skip all":
+			f.skipAll = true
+			return fetchSkip
+		case "a", "abort":
+			fmt.Println("Aborting the crawl, finishing in-flight requests")
+			f.aborted = true
+			return fetchAbort
+		case "":
+			if readErr != nil {
+				// Input ended; the policy decides from now on.
+				fmt.Println()
+				f.interactive = false
+			}
+		}
+	}
+	if f.policy == fetchAbort {
+		fmt.Printf("Aborting the crawl: %s failed %d times\n", link, attempts)
+		f.aborted = true
+	}
+	return f.policy
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// jsonLDPrefix starts a frontmatter_map source read from the page's
+// JSON-LD, such as "jsonld:author.name".
+const jsonLDPrefix = "jsonld:"
+
+// reservedFrontmatterKeys are written by the crawler itself and cannot
+// be mapped.
+var reservedFrontmatterKeys = map[string]bool{
+	"name": true, "description": true, "categories": true, "restricted": true, "warnings": true, "metadata": true,
+}
+
+// checkFrontmatterMap reports an error for a frontmatter_map that maps a
+// key the crawler writes or has an empty source.
+func checkFrontmatterMap(fields map[string]string) error {
+	for _, key := range sortedKeys(fields) {
+		if reservedFrontmatterKeys[key] {
+			return fmt.Errorf("frontmatter_map cannot set %q, which the crawler writes", key)
+		}
+		if strings.TrimSpace(fields[key]) == "" || fields[key] == jsonLDPrefix {
+			return fmt.Errorf("frontmatter_map.%s has no source", key)
+		}
+	}
+	return nil
+}
+
+// mappedFrontmatter returns the frontmatter fields ext.FrontmatterMap
+// reads from doc, in key order. Each source is a JSON-LD path after
+// "jsonld:", a CSS selector and attribute as "selector@attr", or a CSS
+// selector whose first match gives its text (or content, for meta
+// tags). Sources that find nothing add no field.
+func mappedFrontmatter(doc *goquery.Document, ext ExtractionConfig) frontmatter {
+	if len(ext.FrontmatterMap) == 0 {
+		return nil
+	}
+	var jsonLD []interface{}
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err == nil {
+			jsonLD = append(jsonLD, data)
+		}
+	})
+
+	var fm frontmatter
+	for _, key := range sortedKeys(ext.FrontmatterMap) {
+		source := strings.TrimSpace(ext.FrontmatterMap[key])
+		var value interface{}
+		if path, ok := strings.CutPrefix(source, jsonLDPrefix); ok {
+			if found, ok := jsonLDValue(jsonLD, strings.Split(path, ".")); ok {
+				value = found
+			}
+		} else if text := selectedText(doc, source); text != "" {
+			value = text
+		}
+		if value != nil {
+			fm = append(fm, fmField{Key: key, Value: value})
+		}
+	}
+	return fm
+}
+
+// selectedText returns the text of the first element matching a
+// frontmatter_map selector, or the named attribute after "@".
+func selectedText(doc *goquery.Document, source string) string {
+	selector, attr := source, ""
+	if i := strings.LastIndex(source, "@"); i > 0 && !strings.ContainsAny(source[i:], "]) ") {
+		selector, attr = source[:i], source[i+1:]
+	}
+	s := doc.Find(selector).First()
+	switch {
+	case s.Length() == 0:
+		return ""
+	case attr != "":
+		return squash(s.AttrOr(attr, ""))
+	case goquery.NodeName(s) == "meta":
+		return squash(s.AttrOr("content", ""))
+	}
+	return squash(s.Text())
+}
+
+// jsonLDValue returns the value at path in JSON-LD data, looking through
+// arrays and @graph for the first object that has it. Objects such as
+// {"@type": "Person", "name": ...} give their name, and lists of values
+// give a list.
+func jsonLDValue(data interface{}, path []string) (interface{}, bool) {
+	switch v := data.(type) {
+	case []interface{}:
+		if len(path) == 0 {
+			var items []string
+			for _, item := range v {
+				if value, ok := jsonLDValue(item, nil); ok {
+					if s, ok := value.(string); ok {
+						items = append(items, s)
+					}
+				}
+			}
+			return items, len(items) > 0
+		}
+		for _, item := range v {
+			if value, ok := jsonLDValue(item, path); ok {
+				return value, true
+			}
+		}
+	case map[string]interface{}:
+		if len(path) == 0 {
+			if name, ok := v["name"]; ok {
+				return jsonLDValue(name, nil)
+			}
+			return nil, false
+		}
+		if next, ok := v[path[0]]; ok {
+			if value, ok := jsonLDValue(next, path[1:]); ok {
+				return value, true
+			}
+		}
+		if graph, ok := v["@graph"]; ok {
+			return jsonLDValue(graph, path)
+		}
+	case string:
+		if len(path) == 0 && strings.TrimSpace(v) != "" {
+			return squash(v), true
+		}
+	case float64:
+		if len(path) == 0 {
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		}
+	case bool:
+		if len(path) == 0 {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// pageFrontmatter returns the custom frontmatter of a page: the static
+// fields of its extraction settings, overridden by the fields mapped from
+// its metadata.
+func pageFrontmatter(ext ExtractionConfig, mapped frontmatter) frontmatter {
+	fm := extraFrontmatter(ext)
+	for _, f := range mapped {
+		fm.set(f.Key, f.Value)
+	}
+	sort.SliceStable(fm, func(i, j int) bool { return fm[i].Key < fm[j].Key })
+	return fm
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// forgeRawHeader carries the raw file URL a forge blob page was fetched
+// from, so the response is converted as a documentation source.
+const forgeRawHeader = "X-Agent-Skills-Raw-URL"
+
+// rawForgeURL returns the raw file URL of a GitHub or GitLab blob page
+// for a documentation file sourceConverters can convert: github.com/o/r/
+// blob/ref/file becomes raw.githubusercontent.com/o/r/ref/file, and a
+// GitLab /-/blob/ path becomes /-/raw/.
+func rawForgeURL(u *url.URL) (string, bool) {
+	if _, ok := sourceConverters[strings.ToLower(path.Ext(u.Path))]; !ok {
+		return "", false
+	}
+	if strings.EqualFold(u.Hostname(), "github.com") || strings.EqualFold(u.Hostname(), "www.github.com") {
+		parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 4)
+		if len(parts) < 4 || parts[2] != "blob" {
+			return "", false
+		}
+		return "https://raw.githubusercontent.com/" + parts[0] + "/" + parts[1] + "/" + parts[3], true
+	}
+	if before, after, ok := strings.Cut(u.Path, "/-/blob/"); ok && before != "" {
+		raw := *u
+		raw.Path, raw.RawPath, raw.RawQuery, raw.Fragment = before+"/-/raw/"+after, "", "", ""
+		return raw.String(), true
+	}
+	return "", false
+}
+
+// forgeFetcher fetches the raw file behind a forge blob page instead of
+// the page, whose converted UI chrome would bury the document. The
+// response is still recorded under the blob URL.
+type forgeFetcher struct {
+	next Fetcher
+}
+
+func (f *forgeFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	raw, ok := rawForgeURL(req.URL)
+	if !ok {
+		return f.next.Fetch(req)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return f.next.Fetch(req)
+	}
+	rawReq := req.Clone(req.Context())
+	rawReq.URL, rawReq.Host = u, u.Host
+	resp, err := f.next.Fetch(rawReq)
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req
+	resp.Header.Set(forgeRawHeader, raw)
+	return resp, nil
+}
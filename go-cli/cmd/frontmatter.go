@@ -0,0 +1,261 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// fmField is a single key/value pair in the frontmatter.
+// Value may be a string, bool, int, []string or frontmatter (nested map).
+type fmField struct {
+	Key   string
+	Value interface{}
+}
+
+// frontmatter is an ordered list of YAML frontmatter fields.
+type frontmatter []fmField
+
+// set replaces the value for key, or appends it if not present.
+func (f *frontmatter) set(key string, value interface{}) {
+	for i := range *f {
+		if (*f)[i].Key == key {
+			(*f)[i].Value = value
+			return
+		}
+	}
+	*f = append(*f, fmField{Key: key, Value: value})
+}
+
+// setAfter is like set, but a new key is inserted after the field named
+// after (or appended when that field is missing).
+func (f *frontmatter) setAfter(after, key string, value interface{}) {
+	if _, ok := f.get(key); ok {
+		f.set(key, value)
+		return
+	}
+	for i := range *f {
+		if (*f)[i].Key == after {
+			*f = append((*f)[:i+1], append(frontmatter{{Key: key, Value: value}}, (*f)[i+1:]...)...)
+			return
+		}
+	}
+	*f = append(*f, fmField{Key: key, Value: value})
+}
+
+// remove deletes the field named key, if present.
+func (f *frontmatter) remove(key string) {
+	for i := range *f {
+		if (*f)[i].Key == key {
+			*f = append((*f)[:i], (*f)[i+1:]...)
+			return
+		}
+	}
+}
+
+// get returns the value for key, if present.
+func (f frontmatter) get(key string) (interface{}, bool) {
+	for _, field := range f {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return nil, false
+}
+
+// render returns the frontmatter as a YAML document delimited by "---".
+func (f frontmatter) render() string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	writeYAMLFields(&b, f, 0)
+	b.WriteString("---\n")
+	return b.String()
+}
+
+// writeYAMLFields writes fields at the given indentation level.
+func writeYAMLFields(b *strings.Builder, fields frontmatter, indent int) {
+	pad := strings.Repeat("  ", indent)
+	for _, field := range fields {
+		switch v := field.Value.(type) {
+		case frontmatter:
+			if len(v) == 0 {
+				continue
+			}
+			fmt.Fprintf(b, "%s%s:\n", pad, field.Key)
+			writeYAMLFields(b, v, indent+1)
+		case []string:
+			if len(v) == 0 {
+				continue
+			}
+			fmt.Fprintf(b, "%s%s:\n", pad, field.Key)
+			for _, item := range v {
+				fmt.Fprintf(b, "%s  - %s\n", pad, yamlString(item))
+			}
+		case bool:
+			fmt.Fprintf(b, "%s%s: %t\n", pad, field.Key, v)
+		case int:
+			fmt.Fprintf(b, "%s%s: %d\n", pad, field.Key, v)
+		case string:
+			fmt.Fprintf(b, "%s%s: %s\n", pad, field.Key, yamlString(v))
+		default:
+			fmt.Fprintf(b, "%s%s: %s\n", pad, field.Key, yamlString(fmt.Sprint(v)))
+		}
+	}
+}
+
+// yamlString returns s as a YAML scalar, quoting it only when a plain
+// scalar would be misparsed.
+func yamlString(s string) string {
+	if s == "" {
+		return `""`
+	}
+	needsQuote := strings.ContainsAny(s, "\n\r\t") ||
+		strings.Contains(s, ": ") ||
+		strings.Contains(s, " #") ||
+		strings.HasSuffix(s, ":") ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		s != strings.TrimSpace(s)
+
+	if !needsQuote {
+		switch strings.ToLower(s) {
+		case "true", "false", "yes", "no", "on", "off", "null", "~":
+			needsQuote = true
+		}
+	}
+	if !needsQuote {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			needsQuote = true
+		}
+	}
+
+	if !needsQuote {
+		return s
+	}
+	// JSON strings are valid YAML double-quoted scalars.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// splitFrontmatter splits a markdown document into its frontmatter
+// (including the "---" delimiters and trailing newline) and body.
+// Documents without frontmatter return an empty frontmatter.
+func splitFrontmatter(content string) (string, string) {
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content
+	}
+	end := strings.Index(content[4:], "\n---\n")
+	if end == -1 {
+		return "", content
+	}
+	split := 4 + end + len("\n---\n")
+	return content[:split], content[split:]
+}
+
+// parseFrontmatter parses a frontmatter block (as returned by
+// splitFrontmatter) into ordered fields, so it can be edited and rendered
+// again without reordering keys.
+func parseFrontmatter(fm string) (frontmatter, error) {
+	raw := strings.TrimSuffix(strings.TrimPrefix(fm, "---\n"), "---\n")
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return frontmatter{}, nil
+	}
+	return frontmatterFromNode(doc.Content[0]), nil
+}
+
+// frontmatterFromNode converts a YAML mapping node to frontmatter.
+func frontmatterFromNode(n *yaml.Node) frontmatter {
+	var f frontmatter
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i].Value, n.Content[i+1]
+		switch value.Kind {
+		case yaml.MappingNode:
+			f = append(f, fmField{Key: key, Value: frontmatterFromNode(value)})
+		case yaml.SequenceNode:
+			var items []string
+			for _, item := range value.Content {
+				items = append(items, item.Value)
+			}
+			f = append(f, fmField{Key: key, Value: items})
+		default:
+			var v interface{} = value.Value
+			switch value.Tag {
+			case "!!bool":
+				v, _ = strconv.ParseBool(value.Value)
+			case "!!int":
+				if n, err := strconv.Atoi(value.Value); err == nil {
+					v = n
+				}
+			}
+			f = append(f, fmField{Key: key, Value: v})
+		}
+	}
+	return f
+}
+
+// pageMeta is the subset of generated frontmatter most commands need.
+type pageMeta struct {
+	Name        string
+	Description string
+	URL         string
+	Title       string
+	Fields      map[string]interface{}
+}
+
+// parsePage parses the frontmatter of a generated markdown document and
+// returns its metadata and body. The title comes from the first H1.
+func parsePage(content string) (pageMeta, string, error) {
+	fm, body := splitFrontmatter(content)
+	meta := pageMeta{Fields: map[string]interface{}{}}
+	if fm != "" {
+		raw := strings.TrimSuffix(strings.TrimPrefix(fm, "---\n"), "---\n")
+		if err := yaml.Unmarshal([]byte(raw), &meta.Fields); err != nil {
+			return meta, body, err
+		}
+	}
+	meta.Name, _ = meta.Fields["name"].(string)
+	meta.Description, _ = meta.Fields["description"].(string)
+	if m, ok := meta.Fields["metadata"].(map[string]interface{}); ok {
+		meta.URL, _ = m["url"].(string)
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			meta.Title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+			break
+		}
+	}
+	return meta, body, nil
+}
+
+// readPage reads and parses a generated markdown file.
+func readPage(path string) (pageMeta, string, error) {
+	data, err := readOutputFile(path)
+	if err != nil {
+		return pageMeta{}, "", err
+	}
+	return parsePage(string(data))
+}
@@ -0,0 +1,292 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// gateReportFileName is the default name of the gate report.
+const gateReportFileName = "gate-report.json"
+
+// Gate check names.
+const (
+	gateSize     = "size"
+	gateSecrets  = "secrets"
+	gateLicenses = "licenses"
+	gateLinks    = "links"
+)
+
+// GateReport is the result of the publish gate.
+type GateReport struct {
+	GeneratedAt time.Time   `json:"generated_at"`
+	Passed      bool        `json:"passed"`
+	Diff        publishDiff `json:"diff"`
+	Checks      []GateCheck `json:"checks"`
+}
+
+// GateCheck is the result of one check. Checks the policy does not
+// configure are skipped and pass.
+type GateCheck struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Failures []GateFailure `json:"failures"`
+}
+
+// GateFailure is a file that failed a check.
+type GateFailure struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// secretPatterns match credentials that must not be published. Each
+// yields its kind when it matches.
+var secretPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{50,})\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"OpenAI API key", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{32,}`)},
+	{"Anthropic API key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{32,}`)},
+	{"Stripe secret key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{20,}`)},
+	{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+}
+
+// placeholderRe matches the example credentials documentation is full of.
+var placeholderRe = regexp.MustCompile(`(?i)example|x{6,}|0{8,}|your[_-]|<[^>]*>|\.\.\.`)
+
+// runGate checks the files to publish against cfg.
+func runGate(opts *CrawlOptions, m *Manifest, files map[string]string, cfg GateConfig) *GateReport {
+	report := &GateReport{Passed: true}
+	for _, check := range []GateCheck{
+		gateSizeCheck(opts, files, cfg),
+		gateSecretCheck(opts, files, cfg),
+		gateLicenseCheck(opts, m, files, cfg),
+		gateLinkCheck(opts, files, cfg),
+	} {
+		check.Passed = len(check.Failures) == 0
+		if check.Failures == nil {
+			check.Failures = []GateFailure{}
+		}
+		report.Passed = report.Passed && check.Passed
+		report.Checks = append(report.Checks, check)
+	}
+	return report
+}
+
+// markdownFiles calls fn with the path and content of each markdown file
+// in files.
+func markdownFiles(opts *CrawlOptions, files map[string]string, fn func(path, content string)) {
+	for _, path := range sortedKeys(files) {
+		if !strings.HasSuffix(path, ".md") {
+			continue
+		}
+		data, err := readOutputFile(filepath.Join(opts.Output, filepath.FromSlash(path)))
+		if err != nil {
+			continue
+		}
+		fn(path, string(data))
+	}
+}
+
+// gateSizeCheck fails pages over max_page_tokens and skills over
+// max_skill_tokens.
+func gateSizeCheck(opts *CrawlOptions, files map[string]string, cfg GateConfig) GateCheck {
+	check := GateCheck{Name: gateSize}
+	if cfg.MaxPageTokens <= 0 && cfg.MaxSkillTokens <= 0 {
+		check.Skipped = true
+		return check
+	}
+	skills := map[string]int{}
+	markdownFiles(opts, files, func(path, content string) {
+		tokens := estimateTokens(content)
+		if cfg.MaxPageTokens > 0 && tokens > cfg.MaxPageTokens {
+			check.Failures = append(check.Failures, GateFailure{Path: path, Message: fmt.Sprintf("%d tokens, over the page budget of %d", tokens, cfg.MaxPageTokens)})
+		}
+		skill := "."
+		if !opts.Flat {
+			skill = strings.SplitN(path, "/", 2)[0]
+		}
+		skills[skill] += tokens
+	})
+	if cfg.MaxSkillTokens > 0 {
+		for _, skill := range sortedKeys(skills) {
+			if skills[skill] > cfg.MaxSkillTokens {
+				check.Failures = append(check.Failures, GateFailure{Path: skill, Message: fmt.Sprintf("skill has %d tokens, over the skill budget of %d", skills[skill], cfg.MaxSkillTokens)})
+			}
+		}
+	}
+	return check
+}
+
+// gateSecretCheck fails files containing credentials, other than
+// placeholders and matches of cfg.AllowSecrets. Reports show only the
+// start of each secret.
+func gateSecretCheck(opts *CrawlOptions, files map[string]string, cfg GateConfig) GateCheck {
+	check := GateCheck{Name: gateSecrets}
+	if cfg.Secrets != nil && !*cfg.Secrets {
+		check.Skipped = true
+		return check
+	}
+	var allowed []*regexp.Regexp
+	for _, pattern := range cfg.AllowSecrets {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			check.Failures = append(check.Failures, GateFailure{Path: "skills.yaml", Message: fmt.Sprintf("invalid allow_secrets pattern %q: %v", pattern, err)})
+			continue
+		}
+		allowed = append(allowed, re)
+	}
+	isAllowed := func(secret string) bool {
+		if placeholderRe.MatchString(secret) {
+			return true
+		}
+		for _, re := range allowed {
+			if re.MatchString(secret) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, path := range sortedKeys(files) {
+		data, err := readOutputFile(filepath.Join(opts.Output, filepath.FromSlash(path)))
+		if err != nil {
+			continue
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			for _, p := range secretPatterns {
+				for _, secret := range p.re.FindAllString(line, -1) {
+					if !isAllowed(secret) {
+						check.Failures = append(check.Failures, GateFailure{Path: path, Line: i + 1, Message: fmt.Sprintf("possible %s %s…", p.kind, secret[:min(len(secret), 8)])})
+					}
+				}
+			}
+		}
+	}
+	return check
+}
+
+// gateLicenseCheck fails pages whose license frontmatter field is missing
+// or not in cfg.Licenses.
+func gateLicenseCheck(opts *CrawlOptions, m *Manifest, files map[string]string, cfg GateConfig) GateCheck {
+	check := GateCheck{Name: gateLicenses}
+	if len(cfg.Licenses) == 0 {
+		check.Skipped = true
+		return check
+	}
+	field := cfg.LicenseField
+	if field == "" {
+		field = "license"
+	}
+	allowed := map[string]bool{}
+	for _, license := range cfg.Licenses {
+		allowed[strings.ToLower(license)] = true
+	}
+	pages := map[string]bool{}
+	for _, entry := range m.Pages {
+		if entry.InlinedIn == "" {
+			pages[entry.Path] = true
+		}
+	}
+	markdownFiles(opts, files, func(path, content string) {
+		if !pages[path] {
+			return
+		}
+		meta, _, err := parsePage(content)
+		if err != nil {
+			check.Failures = append(check.Failures, GateFailure{Path: path, Message: fmt.Sprintf("could not parse frontmatter: %v", err)})
+			return
+		}
+		license := strings.TrimSpace(fmt.Sprint(meta.Fields[field]))
+		switch {
+		case meta.Fields[field] == nil || license == "":
+			check.Failures = append(check.Failures, GateFailure{Path: path, Message: fmt.Sprintf("no %s in the frontmatter", field)})
+		case !allowed[strings.ToLower(license)]:
+			check.Failures = append(check.Failures, GateFailure{Path: path, Message: fmt.Sprintf("license %q is not allowed", license)})
+		}
+	})
+	return check
+}
+
+// gateLinkCheck fails relative links to files that are not published.
+func gateLinkCheck(opts *CrawlOptions, files map[string]string, cfg GateConfig) GateCheck {
+	check := GateCheck{Name: gateLinks}
+	if cfg.Links != nil && !*cfg.Links {
+		check.Skipped = true
+		return check
+	}
+	dirs := map[string]bool{}
+	for path := range files {
+		for dir := filepath.ToSlash(filepath.Dir(path)); dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+			dirs[dir] = true
+		}
+	}
+	markdownFiles(opts, files, func(path, content string) {
+		for _, link := range relativeLinks(path, content) {
+			if _, ok := files[link.path]; !ok && !dirs[link.path] {
+				check.Failures = append(check.Failures, GateFailure{Path: path, Line: link.line, Message: fmt.Sprintf("broken link to %s", link.ref)})
+			}
+		}
+	})
+	return check
+}
+
+// save writes the report to path.
+func (r *GateReport) save(path string) error {
+	r.GeneratedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// print summarizes the report, listing the first failures of each check.
+func (r *GateReport) print() {
+	const shown = 10
+	for _, check := range r.Checks {
+		switch {
+		case check.Skipped:
+			fmt.Printf("Gate %s: skipped\n", check.Name)
+		case check.Passed:
+			fmt.Printf("Gate %s: passed\n", check.Name)
+		default:
+			fmt.Printf("Gate %s: %d failures\n", check.Name, len(check.Failures))
+			for i, f := range check.Failures {
+				if i == shown {
+					fmt.Printf("  ... and %d more\n", len(check.Failures)-shown)
+					break
+				}
+				if f.Line > 0 {
+					fmt.Printf("  %s:%d: %s\n", f.Path, f.Line, f.Message)
+				} else {
+					fmt.Printf("  %s: %s\n", f.Path, f.Message)
+				}
+			}
+		}
+	}
+}
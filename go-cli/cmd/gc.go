@@ -0,0 +1,399 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// gcCheckers is how many pages are checked upstream at once.
+const gcCheckers = 8
+
+// gcDryRun lists what gc would remove without removing it.
+// gcKeepHTML keeps the raw HTML saved next to each page.
+// gcUpstream checks every page's URL before keeping it.
+var (
+	gcDryRun   bool
+	gcKeepHTML bool
+	gcUpstream bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove stale pages and files from the output directory",
+	Long: `Removes from the output directory:
+
+  - pages the rules no longer match, or match only to follow links
+  - pages the last crawl found missing upstream (HTTP 404 or 410), or with
+    --upstream, pages whose URL answers 404 or 410 now
+  - the raw HTML and notebooks saved next to pages (unless --keep-html)
+  - images and other files no page links to
+  - copies of inlined pages that are no longer inlined
+
+Removed pages are dropped from the manifest. Pages from sources other
+than the crawl are only checked upstream. Run with --dry-run to list
+what would be removed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		opts := newCrawlOptions(&cfg)
+
+		m, err := loadManifest(opts.Output)
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+		allowed, ignored, err := loadRules(&cfg)
+		if err != nil {
+			fmt.Printf("Error processing rules: %v\n", err)
+			os.Exit(1)
+		}
+		last, err := loadReport(opts.Output)
+		if err != nil {
+			fmt.Printf("Warning: could not read crawl report: %v\n", err)
+			last = &CrawlReport{}
+		}
+
+		if err := m.keepTombstones(cfg.Tombstones); err != nil {
+			fmt.Printf("Error processing tombstone settings: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Pages of the urls list are kept unless a rule ignores them.
+		listed := map[string]bool{}
+		if cfg.URLs != "" {
+			links, err := readURLList(cfg.URLs)
+			if err != nil {
+				fmt.Printf("Error reading URL list: %v\n", err)
+				os.Exit(1)
+			}
+			for _, link := range links {
+				listed[link] = true
+			}
+		}
+
+		gc := &garbageCollector{opts: opts, dryRun: gcDryRun}
+		stale := stalePages(m, allowed, ignored, cfg.Precedence, listed, last)
+		if gcUpstream {
+			for link, reason := range checkUpstream(m, stale) {
+				stale[link] = reason
+			}
+		}
+		gc.removePages(m, stale)
+		if err := gc.removeFiles(m, gcKeepHTML); err != nil {
+			fmt.Printf("Error removing files: %v\n", err)
+			os.Exit(1)
+		}
+
+		verb := "Removed"
+		if gcDryRun {
+			verb = "Would remove"
+		} else if len(stale) > 0 || cfg.Tombstones.Enabled || len(m.Tombstones) > 0 {
+			syncTombstoneFiles(opts, m, cfg.Tombstones)
+			if err := m.save(opts.Output); err != nil {
+				fmt.Printf("Error saving manifest: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Printf("%s %d pages and %d files, reclaiming %s\n", verb, len(stale), gc.files, formatSize(gc.bytes))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "list what would be removed without removing it")
+	gcCmd.Flags().BoolVar(&gcKeepHTML, "keep-html", false, "keep the raw HTML saved next to each page")
+	gcCmd.Flags().BoolVar(&gcUpstream, "upstream", false, "request every page and remove those that answer 404 or 410")
+}
+
+// stalePages returns the pages to remove, with the reason, from the
+// current rules, the listed URLs, and the last crawl report.
+func stalePages(m *Manifest, allowed, ignored []globRule, precedence string, listed map[string]bool, last *CrawlReport) map[string]string {
+	// Only pages on crawled hosts are held to the rules, so pages from
+	// git and other sources are kept.
+	hosts := map[string]bool{}
+	for _, g := range allowed {
+		if u, err := url.Parse(getSeedURL(g.pattern)); err == nil && u.Host != "" {
+			hosts[u.Host] = true
+		}
+	}
+
+	stale := map[string]string{}
+	for link := range m.Pages {
+		u, err := url.Parse(link)
+		if err != nil || !hosts[u.Host] {
+			continue
+		}
+		winner := matchRule(link, allowed, ignored, precedence)
+		switch {
+		case winner == nil && listed[link]:
+		case winner == nil || winner.ignore:
+			stale[link] = "no longer matched by the rules"
+		case winner.noConvert:
+			stale[link] = "only followed for links"
+		}
+	}
+	for _, e := range last.Errors {
+		if _, ok := m.Pages[e.URL]; ok && (strings.HasPrefix(e.Reason, "HTTP 404") || strings.HasPrefix(e.Reason, "HTTP 410")) {
+			stale[e.URL] = "missing upstream in the last crawl (" + e.Reason[:len("HTTP 404")] + ")"
+		}
+	}
+	return stale
+}
+
+// checkUpstream requests every page not already stale and returns those
+// that answer 404 or 410.
+func checkUpstream(m *Manifest, stale map[string]string) map[string]string {
+	client := &http.Client{Timeout: 30 * time.Second}
+	links := make(chan string)
+	var mu sync.Mutex
+	missing := map[string]string{}
+	var wg sync.WaitGroup
+	for i := 0; i < gcCheckers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range links {
+				req, err := http.NewRequest(http.MethodHead, link, nil)
+				if err != nil {
+					continue
+				}
+				req.Header.Set("User-Agent", userAgent())
+				resp, err := client.Do(req)
+				if err != nil {
+					fmt.Printf("Warning: could not check %s: %v\n", link, err)
+					continue
+				}
+				resp.Body.Close()
+				if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+					mu.Lock()
+					missing[link] = fmt.Sprintf("missing upstream (HTTP %d)", resp.StatusCode)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for link := range m.Pages {
+		if _, ok := stale[link]; !ok && (strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://")) {
+			links <- link
+		}
+	}
+	close(links)
+	wg.Wait()
+	return missing
+}
+
+// garbageCollector removes files from the output directory and counts
+// what it reclaims.
+type garbageCollector struct {
+	opts    *CrawlOptions
+	dryRun  bool
+	quiet   bool
+	files   int
+	bytes   int64
+	removed map[string]bool
+}
+
+// remove deletes path, printing why, and counts its size.
+func (g *garbageCollector) remove(path, reason string) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || g.removed[path] {
+		return
+	}
+	rel, err := filepath.Rel(g.opts.Output, path)
+	if err != nil {
+		rel = path
+	}
+	if g.dryRun {
+		if !g.quiet {
+			fmt.Printf("Would remove %s: %s\n", filepath.ToSlash(rel), reason)
+		}
+	} else if err := os.Remove(path); err != nil {
+		fmt.Printf("Error removing %s: %v\n", rel, err)
+		return
+	}
+	if g.removed == nil {
+		g.removed = map[string]bool{}
+	}
+	g.removed[path] = true
+	g.files++
+	g.bytes += info.Size()
+}
+
+// removePages removes the files and manifest entries of stale pages.
+func (g *garbageCollector) removePages(m *Manifest, stale map[string]string) {
+	links := make([]string, 0, len(stale))
+	for link := range stale {
+		links = append(links, link)
+	}
+	sort.Strings(links)
+	for _, link := range links {
+		entry := m.Pages[link]
+		if !g.quiet {
+			fmt.Printf("Page %s: %s\n", link, stale[link])
+		}
+		g.remove(outputFile(g.opts.pageFile(entry)), "stale page")
+		if u, err := url.Parse(link); err == nil {
+			_, raw := g.opts.outputPath(u)
+			g.remove(outputFile(raw), "stale page")
+		}
+		if !g.dryRun {
+			m.remove(link, stale[link])
+		}
+	}
+}
+
+// removeTree removes every file below dir.
+func (g *garbageCollector) removeTree(dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			g.remove(path, "in the skill")
+		}
+		return nil
+	})
+}
+
+// removeFiles removes raw HTML (unless keepHTML), files no page links to,
+// and copies of pages that are no longer inlined, then empty directories.
+func (g *garbageCollector) removeFiles(m *Manifest, keepHTML bool) error {
+	out := g.opts.Output
+	raw := map[string]bool{}
+	pages := map[string]bool{}
+	inlined := map[string]bool{}
+	for link, entry := range m.Pages {
+		if u, err := url.Parse(link); err == nil {
+			_, rawPath := g.opts.outputPath(u)
+			if rawPath != g.opts.markdownPath(rawPath) {
+				raw[filepath.Clean(rawPath)] = true
+			}
+		}
+		pages[filepath.Join(out, filepath.FromSlash(entry.Path))] = true
+		if entry.InlinedIn != "" {
+			inlined[filepath.Join(out, inlinedDirName, filepath.FromSlash(entry.Path))] = true
+		}
+	}
+	generated := map[string]bool{}
+	for path := range m.Generated {
+		generated[filepath.Join(out, filepath.FromSlash(path))] = true
+	}
+
+	// Every file linked from a markdown file in the output is in use.
+	linked := map[string]bool{}
+	var files []string
+	err := filepath.WalkDir(out, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != out && strings.HasPrefix(d.Name(), ".") && d.Name() != inlinedDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Dir(path) == out {
+			return nil // manifest, report, and other crawl files
+		}
+		files = append(files, path)
+		if strings.HasSuffix(pagePath(path), ".md") {
+			data, err := readOutputFile(path)
+			if err != nil {
+				return err
+			}
+			for _, m := range markdownLinkRe.FindAllStringSubmatch(string(data), -1) {
+				ref, err := url.Parse(m[1])
+				if err != nil || ref.IsAbs() || ref.Host != "" || ref.Path == "" {
+					continue
+				}
+				linked[filepath.Join(filepath.Dir(path), filepath.FromSlash(ref.Path))] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	inlinedDir := filepath.Join(out, inlinedDirName) + string(filepath.Separator)
+	for _, file := range files {
+		// Compressed pages and HTML are checked under their plain paths.
+		path := pagePath(file)
+		switch {
+		case strings.HasPrefix(path, inlinedDir):
+			if !inlined[path] {
+				g.remove(file, "no longer inlined")
+			}
+		case raw[path]:
+			if !keepHTML {
+				g.remove(file, "raw page source")
+			}
+		case strings.HasSuffix(path, ".html"):
+			if !keepHTML && !linked[path] {
+				g.remove(file, "raw HTML of a removed page")
+			}
+		case strings.HasSuffix(path, ".md") || pages[path] || generated[path] || linked[path]:
+		default:
+			g.remove(file, "not linked from any page")
+		}
+	}
+
+	if !g.dryRun {
+		removeEmptyDirs(out)
+	}
+	return nil
+}
+
+// removeEmptyDirs removes the empty directories below root, deepest first.
+func removeEmptyDirs(root string) {
+	var dirs []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != root {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i]) // fails, as intended, when not empty
+	}
+}
+
+// formatSize returns n bytes in the largest binary unit that keeps it at
+// least 1.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
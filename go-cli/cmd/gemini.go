@@ -0,0 +1,489 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Defaults for the gemini config section. Uploads are limited to 2 GB a
+// file by the Files API and 25 MB by RAG corpus imports.
+const (
+	defaultGeminiEndpoint  = "https://generativelanguage.googleapis.com"
+	defaultGeminiAPIKeyEnv = "GEMINI_API_KEY"
+	defaultGoogleTokenEnv  = "GOOGLE_ACCESS_TOKEN"
+	geminiStateFileName    = ".gemini.json"
+	geminiFilesMaxMB       = 2048
+	ragFileMaxMB           = 25
+	// geminiExpiryMargin re-uploads Files API files this long before they
+	// expire, so they do not lapse between syncs.
+	geminiExpiryMargin = 6 * time.Hour
+)
+
+// geminiDryRun lists what a sync would upload and remove.
+var geminiDryRun bool
+
+var geminiCmd = &cobra.Command{
+	Use:   "gemini",
+	Short: "Sync the output to the Gemini Files API or a Vertex AI RAG corpus",
+}
+
+var geminiSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Upload new and changed pages and delete removed ones",
+	Long: `Uploads the pages and generated markdown files in the output directory
+to the target of the gemini config section: the Gemini Files API
+(target: files) or the Vertex AI RAG corpus gemini.corpus
+(target: rag_corpus). Only files whose manifest hash changed since the
+last sync are uploaded, and the copies of changed and removed pages are
+deleted. Files larger than gemini.max_mb are uploaded in parts split at
+their H2 headings. Files API uploads expire after 48 hours and are
+uploaded again as they near expiry. What was uploaded is recorded in
+<output>/.gemini.json.
+
+With gemini.enabled set, every crawl syncs when it finishes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		opts := newCrawlOptions(&cfg)
+		m, err := loadManifest(opts.Output)
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+		if err := syncGemini(opts, m, cfg.Gemini, geminiDryRun); err != nil {
+			fmt.Printf("Error syncing to Gemini: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(geminiCmd)
+	geminiCmd.AddCommand(geminiSyncCmd)
+	geminiSyncCmd.Flags().BoolVar(&geminiDryRun, "dry-run", false, "list what would be uploaded and deleted without changing anything")
+}
+
+// geminiState records the files uploaded to a target, by path relative
+// to the output directory. Target is "files" or the corpus name.
+type geminiState struct {
+	Target string                `json:"target"`
+	Files  map[string]geminiFile `json:"files"`
+}
+
+// geminiFile lists the uploads holding one file, one per part.
+type geminiFile struct {
+	Names     []string   `json:"names"`
+	Hash      string     `json:"hash"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// loadGeminiState reads the sync state for target from outDir. The state
+// of another target, or none, yields an empty one.
+func loadGeminiState(outDir, target string) (*geminiState, error) {
+	state := &geminiState{Target: target, Files: map[string]geminiFile{}}
+	data, err := os.ReadFile(filepath.Join(outDir, geminiStateFileName))
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var saved geminiState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	if saved.Target != target {
+		fmt.Printf("Warning: %s recorded %s; syncing %s from scratch\n", geminiStateFileName, saved.Target, target)
+		return state, nil
+	}
+	if saved.Files != nil {
+		state.Files = saved.Files
+	}
+	return state, nil
+}
+
+func (s *geminiState) save(outDir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, geminiStateFileName), append(data, '\n'), 0644)
+}
+
+// geminiSink uploads files to a Gemini target and deletes them.
+type geminiSink interface {
+	// upload returns the resource name of the upload and when it
+	// expires, if it does.
+	upload(name string, content []byte) (string, time.Time, error)
+	remove(name string) error
+}
+
+// geminiFiles uploads to the Gemini Files API.
+type geminiFiles struct {
+	api      *apiClient
+	endpoint string
+}
+
+func (g *geminiFiles) upload(name string, content []byte) (string, time.Time, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=UTF-8"}})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	json.NewEncoder(part).Encode(map[string]interface{}{"file": map[string]string{"display_name": name}})
+	if part, err = w.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain"}}); err != nil {
+		return "", time.Time{}, err
+	}
+	part.Write(content)
+	if err := w.Close(); err != nil {
+		return "", time.Time{}, err
+	}
+	var resp struct {
+		File struct {
+			Name           string    `json:"name"`
+			ExpirationTime time.Time `json:"expirationTime"`
+		} `json:"file"`
+	}
+	link := g.endpoint + "/upload/v1beta/files?uploadType=multipart"
+	if err := g.api.postForm(link, body.Bytes(), "multipart/related; boundary="+w.Boundary(), &resp); err != nil {
+		return "", time.Time{}, err
+	}
+	return resp.File.Name, resp.File.ExpirationTime, nil
+}
+
+func (g *geminiFiles) remove(name string) error {
+	var deleted struct{}
+	return g.api.deleteJSON(g.endpoint+"/v1beta/"+name, &deleted)
+}
+
+// ragCorpus uploads to a Vertex AI RAG corpus, which chunks and embeds
+// each file.
+type ragCorpus struct {
+	api          *apiClient
+	endpoint     string
+	corpus       string
+	chunkSize    int
+	chunkOverlap int
+}
+
+func (r *ragCorpus) upload(name string, content []byte) (string, time.Time, error) {
+	metadata := map[string]interface{}{"rag_file": map[string]string{"display_name": name}}
+	if r.chunkSize > 0 {
+		metadata["upload_rag_file_config"] = map[string]interface{}{
+			"rag_file_transformation_config": map[string]interface{}{
+				"rag_file_chunking_config": map[string]interface{}{
+					"fixed_length_chunking": map[string]int{"chunk_size": r.chunkSize, "chunk_overlap": r.chunkOverlap},
+				},
+			},
+		}
+	}
+	meta, err := json.Marshal(metadata)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("metadata", string(meta))
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	part.Write(content)
+	if err := w.Close(); err != nil {
+		return "", time.Time{}, err
+	}
+	var resp struct {
+		RagFile struct {
+			Name string `json:"name"`
+		} `json:"ragFile"`
+	}
+	if err := r.api.postForm(r.endpoint+"/upload/v1/"+r.corpus+"/ragFiles:upload", body.Bytes(), w.FormDataContentType(), &resp); err != nil {
+		return "", time.Time{}, err
+	}
+	return resp.RagFile.Name, time.Time{}, nil
+}
+
+func (r *ragCorpus) remove(name string) error {
+	var operation struct{}
+	return r.api.deleteJSON(r.endpoint+"/v1/"+name, &operation)
+}
+
+// newGeminiSink configures the sink for cfg.Target, reading its
+// credentials from the environment variable named by api_key_env.
+func newGeminiSink(cfg GeminiConfig) (geminiSink, error) {
+	keyEnv := cfg.APIKeyEnv
+	header := http.Header{}
+	switch geminiTarget(cfg) {
+	case "files":
+		if keyEnv == "" {
+			keyEnv = defaultGeminiAPIKeyEnv
+		}
+		key := os.Getenv(keyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("%s is not set", keyEnv)
+		}
+		header.Set("x-goog-api-key", key)
+		endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+		if endpoint == "" {
+			endpoint = defaultGeminiEndpoint
+		}
+		return &geminiFiles{api: newAPIClient(header), endpoint: endpoint}, nil
+	default:
+		if keyEnv == "" {
+			keyEnv = defaultGoogleTokenEnv
+		}
+		token := os.Getenv(keyEnv)
+		if token == "" {
+			return nil, fmt.Errorf("%s is not set (try: export %s=$(gcloud auth print-access-token))", keyEnv, keyEnv)
+		}
+		header.Set("Authorization", "Bearer "+token)
+		endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+		if endpoint == "" {
+			endpoint = "https://" + corpusLocation(cfg.Corpus) + "-aiplatform.googleapis.com"
+		}
+		return &ragCorpus{api: newAPIClient(header), endpoint: endpoint, corpus: cfg.Corpus, chunkSize: cfg.ChunkSize, chunkOverlap: cfg.ChunkOverlap}, nil
+	}
+}
+
+// geminiTarget returns the configured target, "files" by default.
+func geminiTarget(cfg GeminiConfig) string {
+	if cfg.Target == "" {
+		return "files"
+	}
+	return cfg.Target
+}
+
+// corpusLocation returns the location of a corpus named
+// projects/P/locations/L/ragCorpora/C, or "".
+func corpusLocation(corpus string) string {
+	parts := strings.Split(corpus, "/")
+	if len(parts) != 6 || parts[0] != "projects" || parts[2] != "locations" || parts[4] != "ragCorpora" || parts[3] == "" {
+		return ""
+	}
+	return parts[3]
+}
+
+// splitUpload splits markdown into parts of at most limit bytes, breaking
+// at H2 headings where it can and between lines where it must.
+func splitUpload(content string, limit int) []string {
+	if len(content) <= limit {
+		return []string{content}
+	}
+	var sections []string
+	var current strings.Builder
+	inFence := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+		}
+		if level, _, ok := atxHeading(strings.TrimRight(line, "\n")); ok && !inFence && level == 2 && current.Len() > 0 {
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	sections = append(sections, current.String())
+
+	var parts []string
+	var part strings.Builder
+	add := func(text string) {
+		if part.Len()+len(text) > limit && part.Len() > 0 {
+			parts = append(parts, part.String())
+			part.Reset()
+		}
+		part.WriteString(text)
+	}
+	for _, section := range sections {
+		if len(section) <= limit {
+			add(section)
+			continue
+		}
+		for _, line := range strings.SplitAfter(section, "\n") {
+			for len(line) > limit {
+				add(line[:limit])
+				line = line[limit:]
+			}
+			add(line)
+		}
+	}
+	if part.Len() > 0 {
+		parts = append(parts, part.String())
+	}
+	return parts
+}
+
+// uploadName returns the display name of part i of n of the file at path.
+func uploadName(path string, i, n int) string {
+	name := strings.ReplaceAll(path, "/", "_")
+	if n == 1 {
+		return name
+	}
+	return fmt.Sprintf("%s-part%d-of-%d.md", strings.TrimSuffix(name, ".md"), i+1, n)
+}
+
+// syncGemini uploads the files whose hash changed since the last sync,
+// and the Files API uploads about to expire, to the Gemini target, and
+// deletes the replaced and removed ones. With dryRun it only lists what
+// it would do.
+func syncGemini(opts *CrawlOptions, m *Manifest, cfg GeminiConfig, dryRun bool) error {
+	target := geminiTarget(cfg)
+	limitMB := cfg.MaxMB
+	switch target {
+	case "files":
+		if limitMB <= 0 || limitMB > geminiFilesMaxMB {
+			limitMB = geminiFilesMaxMB
+		}
+	case "rag_corpus":
+		if corpusLocation(cfg.Corpus) == "" {
+			return fmt.Errorf("gemini.corpus %q is not a corpus name of the form projects/PROJECT/locations/LOCATION/ragCorpora/ID", cfg.Corpus)
+		}
+		if limitMB <= 0 || limitMB > ragFileMaxMB {
+			limitMB = ragFileMaxMB
+		}
+		target = cfg.Corpus
+	default:
+		return fmt.Errorf("unknown gemini.target %q (want files or rag_corpus)", cfg.Target)
+	}
+	limit := limitMB << 20
+
+	state, err := loadGeminiState(opts.Output, target)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", geminiStateFileName, err)
+	}
+	files := vectorFiles(opts, m)
+	renew := time.Now().Add(geminiExpiryMargin)
+	var upload, remove []string
+	expiring := 0
+	for _, path := range sortedKeys(files) {
+		saved, ok := state.Files[path]
+		if saved.Hash != files[path] {
+			upload = append(upload, path)
+		} else if ok && saved.ExpiresAt != nil && saved.ExpiresAt.Before(renew) {
+			upload = append(upload, path)
+			expiring++
+		}
+	}
+	for _, path := range sortedKeys(state.Files) {
+		if _, ok := files[path]; !ok {
+			remove = append(remove, path)
+		}
+	}
+	fmt.Printf("Gemini %s: %d files to upload (%d expiring), %d to delete, %d unchanged\n", target, len(upload), expiring, len(remove), len(files)-len(upload))
+	if dryRun {
+		for _, path := range upload {
+			fmt.Printf("Upload %s\n", path)
+		}
+		for _, path := range remove {
+			fmt.Printf("Delete %s\n", path)
+		}
+		return nil
+	}
+	if len(upload) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	sink, err := newGeminiSink(cfg)
+	if err != nil {
+		return err
+	}
+	// The state is saved whatever happens, so the next sync retries only
+	// what did not go through.
+	defer func() {
+		if err := state.save(opts.Output); err != nil {
+			fmt.Printf("Error writing %s: %v\n", geminiStateFileName, err)
+		}
+	}()
+	// removeAll deletes uploads, treating ones already gone (such as
+	// expired files) as deleted.
+	removeAll := func(names []string) error {
+		for _, name := range names {
+			if err := sink.remove(name); err != nil && !isNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	uploaded, failed := 0, 0
+	for _, path := range upload {
+		data, err := readOutputFile(filepath.Join(opts.Output, filepath.FromSlash(path)))
+		if err != nil {
+			fmt.Printf("Warning: could not read %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		parts := splitUpload(string(data), limit)
+		if len(parts) > 1 {
+			fmt.Printf("Uploading %s in %d parts of at most %d MB\n", path, len(parts), limitMB)
+		}
+		file := geminiFile{Hash: files[path]}
+		for i, part := range parts {
+			name, expires, err := sink.upload(uploadName(path, i, len(parts)), []byte(part))
+			if err != nil {
+				fmt.Printf("Warning: could not upload %s: %v\n", path, err)
+				break
+			}
+			file.Names = append(file.Names, name)
+			if !expires.IsZero() && (file.ExpiresAt == nil || expires.Before(*file.ExpiresAt)) {
+				file.ExpiresAt = &expires
+			}
+		}
+		if len(file.Names) < len(parts) {
+			// Keep the old copy rather than a partial new one.
+			removeAll(file.Names)
+			failed++
+			continue
+		}
+		if old, ok := state.Files[path]; ok {
+			if err := removeAll(old.Names); err != nil {
+				fmt.Printf("Warning: could not delete the old copy of %s: %v\n", path, err)
+			}
+		}
+		state.Files[path] = file
+		uploaded++
+	}
+
+	deleted := 0
+	for _, path := range remove {
+		if err := removeAll(state.Files[path].Names); err != nil {
+			fmt.Printf("Warning: could not delete %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		delete(state.Files, path)
+		deleted++
+	}
+	fmt.Printf("Uploaded %d files and deleted %d from Gemini %s\n", uploaded, deleted, target)
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be synced", failed)
+	}
+	return nil
+}
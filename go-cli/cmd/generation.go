@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// generationsDirName is the directory of the output holding the file
+// lists of earlier generations.
+const generationsDirName = ".generations"
+
+// keptGenerations is how many generations' file lists are kept.
+const keptGenerations = 50
+
+// generationSnapshot lists the files of the output, with their content
+// hashes, as of a generation. A generation is recorded each time the
+// manifest is saved with a different set of files.
+type generationSnapshot struct {
+	Generation int               `json:"generation"`
+	SavedAt    time.Time         `json:"saved_at"`
+	Files      map[string]string `json:"files"`
+}
+
+// manifestFiles returns the hash of every file the manifest lists, keyed
+// by path relative to outDir: the markdown of pages that are not inlined,
+// and the generated files.
+func manifestFiles(outDir string, m *Manifest) map[string]string {
+	files := map[string]string{}
+	for _, entry := range m.Pages {
+		if entry.InlinedIn != "" {
+			continue
+		}
+		hash := entry.FileHash
+		if hash == "" {
+			data, err := readOutputFile(filepath.Join(outDir, filepath.FromSlash(entry.Path)))
+			if err != nil {
+				continue
+			}
+			hash = contentHash(string(data))
+		}
+		files[entry.Path] = hash
+	}
+	maps.Copy(files, m.Generated)
+	return files
+}
+
+func generationPath(outDir string, generation int) string {
+	return filepath.Join(outDir, generationsDirName, strconv.Itoa(generation)+".json")
+}
+
+// loadGeneration reads the file list of a generation.
+func loadGeneration(outDir string, generation int) (*generationSnapshot, error) {
+	data, err := os.ReadFile(generationPath(outDir, generation))
+	if err != nil {
+		return nil, err
+	}
+	var s generationSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// recordGeneration starts a new generation when the files m lists differ
+// from the last generation's, and removes the file lists of generations
+// no longer kept. Callers hold m.mu and the manifest lock.
+func (m *Manifest) recordGeneration(outDir string) error {
+	files := manifestFiles(outDir, m)
+	if last, err := loadGeneration(outDir, m.Generation); err == nil && maps.Equal(last.Files, files) {
+		return nil
+	}
+	m.Generation++
+	data, err := json.MarshalIndent(generationSnapshot{Generation: m.Generation, SavedAt: time.Now().UTC(), Files: files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(outDir, generationsDirName), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(generationPath(outDir, m.Generation), append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	for old := m.Generation - keptGenerations; old > 0; old-- {
+		if err := os.Remove(generationPath(outDir, old)); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// sinceGeneration resolves --since, a generation number or a time (RFC
+// 3339 or a date), to the file list to compare with. Generation 0, and
+// times before the first generation, compare with an empty output.
+func sinceGeneration(outDir string, m *Manifest, since string) (*generationSnapshot, error) {
+	empty := &generationSnapshot{Files: map[string]string{}}
+	if generation, err := strconv.Atoi(since); err == nil {
+		if generation < 0 || generation > m.Generation {
+			return nil, fmt.Errorf("generation %d does not exist (latest: %d)", generation, m.Generation)
+		}
+		if generation == 0 {
+			return empty, nil
+		}
+		s, err := loadGeneration(outDir, generation)
+		if err != nil {
+			return nil, fmt.Errorf("generation %d is no longer kept: %w", generation, err)
+		}
+		return s, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", since); err != nil {
+			return nil, fmt.Errorf("--since must be a generation number, an RFC 3339 time, or a date, not %q", since)
+		}
+	}
+	// The generation current at t is the last one saved by then.
+	for generation := m.Generation; generation > 0; generation-- {
+		s, err := loadGeneration(outDir, generation)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("no generation kept from before %s (oldest: %d)", since, generation+1)
+			}
+			return nil, err
+		}
+		if !s.SavedAt.After(t) {
+			return s, nil
+		}
+		if generation == 1 {
+			return empty, nil
+		}
+	}
+	return empty, nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gobwas/glob"
+)
+
+// glossaryFileName is the glossary written into each skill directory.
+const glossaryFileName = "GLOSSARY.md"
+
+// maxDefinitionLen caps glossary definitions; longer ones are cut at a
+// sentence boundary.
+const maxDefinitionLen = 400
+
+// leadingDefinitionRe matches an opening sentence that defines a term,
+// such as "A widget is a reusable piece of UI."
+var leadingDefinitionRe = regexp.MustCompile(`^(?:(?:The|An|A)\s+)?([A-Za-z][\w .+#/-]{0,48}?)\s+(?:is|are|refers to)\s+(?:an?|the|one|any)\s+`)
+
+// glossaryTerm is a single defined term and where it was found.
+type glossaryTerm struct {
+	Term       string
+	Definition string
+	Source     *ManifestEntry
+	// fromList is set for definition lists and glossary pages, which take
+	// precedence over opening sentences.
+	fromList bool
+}
+
+// writeGlossaries writes a GLOSSARY.md into each skill directory (each
+// top-level directory of the output) from the definitions on its pages:
+// definition lists, the entries of glossary pages, and opening sentences
+// that define the page's subject.
+func writeGlossaries(opts *CrawlOptions, m *Manifest, cfg GlossaryConfig) {
+	var pageGlobs []glob.Glob
+	for _, pattern := range cfg.Pages {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			fmt.Printf("Warning: invalid glossary page glob %s: %v\n", pattern, err)
+			continue
+		}
+		pageGlobs = append(pageGlobs, g)
+	}
+
+	bySkill := map[string]map[string]*glossaryTerm{}
+	for _, entry := range m.Pages {
+		html, err := opts.readSavedHTML(entry)
+		if err != nil {
+			continue
+		}
+		content, err := extractContent(html, opts.extractionFor(entry.URL))
+		if err != nil {
+			continue
+		}
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+		if err != nil {
+			continue
+		}
+
+		skill := opts.skillDirOf(entry)
+		terms := bySkill[skill]
+		if terms == nil {
+			terms = map[string]*glossaryTerm{}
+			bySkill[skill] = terms
+		}
+		add := func(term, definition string, fromList bool) {
+			term, definition = squash(term), trimDefinition(squash(definition))
+			if term == "" || definition == "" || len(term) > 80 {
+				return
+			}
+			key := strings.ToLower(term)
+			if old, ok := terms[key]; ok && (old.fromList || !fromList) {
+				return
+			}
+			terms[key] = &glossaryTerm{Term: term, Definition: definition, Source: entry, fromList: fromList}
+		}
+
+		doc.Find("dl").Each(func(i int, dl *goquery.Selection) {
+			if !isGlossaryList(dl) {
+				return
+			}
+			dl.ChildrenFiltered("dt").Each(func(i int, dt *goquery.Selection) {
+				add(dt.Text(), dt.NextFilteredUntil("dd", "dt").First().Text(), true)
+			})
+		})
+
+		if isGlossaryPage(entry, doc, pageGlobs) {
+			doc.Find("h2, h3, h4").Each(func(i int, h *goquery.Selection) {
+				add(h.Text(), h.NextFiltered("p").Text(), true)
+			})
+		}
+
+		if subject := strings.ToLower(entry.Title); subject != "" {
+			first := squash(doc.Find("p").First().Text())
+			if match := leadingDefinitionRe.FindStringSubmatch(first); match != nil {
+				term := strings.TrimSpace(match[1])
+				if strings.Contains(subject, strings.ToLower(term)) {
+					add(capitalize(term), firstSentence(first), false)
+				}
+			}
+		}
+	}
+
+	for skill, terms := range bySkill {
+		if len(terms) == 0 {
+			continue
+		}
+		rel := path.Join(skill, glossaryFileName)
+		content := renderGlossary(terms, skill)
+		if err := writeFileIfChanged(filepath.Join(opts.Output, filepath.FromSlash(rel)), []byte(content)); err != nil {
+			fmt.Printf("Error writing %s: %v\n", rel, err)
+			continue
+		}
+		m.recordGenerated(rel, content)
+		fmt.Printf("Wrote %d glossary terms to %s\n", len(terms), rel)
+	}
+}
+
+// isGlossaryList reports whether dl is prose definitions rather than API
+// markup, which Sphinx and others also render as definition lists.
+func isGlossaryList(dl *goquery.Selection) bool {
+	class, _ := dl.Attr("class")
+	if class == "" || strings.Contains(class, "glossary") {
+		return dl.Find("dt code, dt .sig").Length() == 0
+	}
+	return false
+}
+
+// isGlossaryPage reports whether a page is a glossary, by the configured
+// globs or, by default, "glossary" in its URL or title.
+func isGlossaryPage(entry *ManifestEntry, doc *goquery.Document, globs []glob.Glob) bool {
+	if len(globs) > 0 {
+		for _, g := range globs {
+			if g.Match(entry.URL) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(strings.ToLower(entry.URL), "glossary") ||
+		strings.Contains(strings.ToLower(entry.Title), "glossary")
+}
+
+// trimDefinition shortens a definition to whole sentences within
+// maxDefinitionLen.
+func trimDefinition(s string) string {
+	if len(s) <= maxDefinitionLen {
+		return s
+	}
+	cut := strings.LastIndex(s[:maxDefinitionLen], ". ")
+	if cut <= 0 {
+		return strings.TrimSpace(s[:maxDefinitionLen]) + "…"
+	}
+	return s[:cut+1]
+}
+
+// firstSentence returns the text up to the first sentence break.
+func firstSentence(s string) string {
+	if i := strings.Index(s, ". "); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// renderGlossary renders terms alphabetically under letter headings, each
+// linking to the page that defines it.
+func renderGlossary(terms map[string]*glossaryTerm, skill string) string {
+	list := make([]*glossaryTerm, 0, len(terms))
+	for _, t := range terms {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Term) < strings.ToLower(list[j].Term)
+	})
+
+	var b strings.Builder
+	b.WriteString("# Glossary\n\n")
+	letter := ""
+	for _, t := range list {
+		first := strings.ToUpper(string([]rune(t.Term)[0]))
+		if !unicode.IsLetter([]rune(first)[0]) {
+			first = "#"
+		}
+		if first != letter {
+			letter = first
+			fmt.Fprintf(&b, "## %s\n\n", letter)
+		}
+		link := t.Source.Path
+		if skill != "." {
+			link = strings.TrimPrefix(link, skill+"/")
+		}
+		fmt.Fprintf(&b, "**%s**\n: %s ([source](%s))\n\n", t.Term, t.Definition, link)
+	}
+	return strings.TrimSpace(b.String()) + "\n"
+}
@@ -0,0 +1,315 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// intercomAPI is the Intercom REST API endpoint.
+var intercomAPI = "https://api.intercom.io"
+
+// intercomVersion is the Intercom API version requests are made against.
+const intercomVersion = "2.11"
+
+// helpArticle is a help center article, with the names of the
+// categories, sections, or collections it is filed under.
+type helpArticle struct {
+	Title        string
+	Description  string
+	HTML         string
+	URL          string
+	LastModified string
+	Path         []string
+}
+
+// writeHelpArticles converts articles to <output>/<host>/<path>/<title>
+// and returns how many were written.
+func writeHelpArticles(articles []helpArticle, host string, opts *CrawlOptions) int {
+	count := 0
+	for _, a := range articles {
+		body, err := htmlToMarkdown(a.HTML, opts)
+		if err != nil {
+			fmt.Printf("Error converting article %s: %v\n", a.Title, err)
+			continue
+		}
+		var segments []string
+		for _, name := range append(append([]string{}, a.Path...), a.Title) {
+			segments = append(segments, toPathCase(name))
+		}
+		out := &url.URL{Scheme: "https", Host: host, Path: "/" + path.Join(segments...)}
+		if _, err := writeSourcePage(opts, out, convertedPage{
+			URL:          a.URL,
+			Title:        a.Title,
+			Description:  a.Description,
+			Body:         strings.TrimSpace(body) + "\n",
+			LastModified: a.LastModified,
+		}); err != nil {
+			fmt.Printf("Error writing article %s: %v\n", a.Title, err)
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// selectedCategory reports whether a category, given by ID and name, is
+// in filter. An empty filter selects every category.
+func selectedCategory(filter []string, id, name string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == id || strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonID returns a JSON string or number ID as a string.
+func jsonID(raw json.RawMessage) string {
+	s := strings.Trim(string(raw), `"`)
+	if s == "null" {
+		return ""
+	}
+	return s
+}
+
+// zendeskItem is a category, section, or article from the Help Center API.
+type zendeskItem struct {
+	ID              int64  `json:"id"`
+	Name            string `json:"name"`
+	Title           string `json:"title"`
+	Body            string `json:"body"`
+	HTMLURL         string `json:"html_url"`
+	UpdatedAt       string `json:"updated_at"`
+	Draft           bool   `json:"draft"`
+	CategoryID      int64  `json:"category_id"`
+	SectionID       int64  `json:"section_id"`
+	ParentSectionID int64  `json:"parent_section_id"`
+}
+
+// ingestZendesk converts the published articles of a Zendesk Guide help
+// center, nested under their category and sections.
+func ingestZendesk(cfg ZendeskSource, opts *CrawlOptions) error {
+	base, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if cfg.Email != "" {
+		token, err := sourceToken(cfg.TokenEnv)
+		if err != nil {
+			return err
+		}
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cfg.Email+"/token:"+token)))
+	}
+	api := newAPIClient(header)
+	prefix := base.String() + "/api/v2/help_center"
+	if cfg.Locale != "" {
+		prefix += "/" + url.PathEscape(cfg.Locale)
+	}
+
+	// list follows next_page links through a listing such as "articles".
+	list := func(resource string) ([]zendeskItem, error) {
+		var items []zendeskItem
+		next := prefix + "/" + resource + ".json?per_page=100"
+		for next != "" {
+			var res map[string]json.RawMessage
+			if err := api.getJSON(next, &res); err != nil {
+				return nil, err
+			}
+			var page []zendeskItem
+			if err := json.Unmarshal(res[resource], &page); err != nil {
+				return nil, fmt.Errorf("decoding %s: %w", next, err)
+			}
+			items = append(items, page...)
+			next = ""
+			json.Unmarshal(res["next_page"], &next)
+		}
+		return items, nil
+	}
+
+	categories, err := list("categories")
+	if err != nil {
+		return err
+	}
+	sections, err := list("sections")
+	if err != nil {
+		return err
+	}
+	items, err := list("articles")
+	if err != nil {
+		return err
+	}
+	categoryNames := map[int64]string{}
+	for _, c := range categories {
+		categoryNames[c.ID] = c.Name
+	}
+	sectionsByID := map[int64]zendeskItem{}
+	for _, s := range sections {
+		sectionsByID[s.ID] = s
+	}
+
+	var articles []helpArticle
+	for _, a := range items {
+		if a.Draft {
+			continue
+		}
+		// Sections can nest; the outermost one carries the category.
+		var names []string
+		var categoryID int64
+		seen := map[int64]bool{}
+		for id := a.SectionID; id != 0 && !seen[id]; {
+			seen[id] = true
+			section, ok := sectionsByID[id]
+			if !ok {
+				break
+			}
+			names = append([]string{section.Name}, names...)
+			categoryID = section.CategoryID
+			id = section.ParentSectionID
+		}
+		category := categoryNames[categoryID]
+		if !selectedCategory(cfg.Categories, strconv.FormatInt(categoryID, 10), category) {
+			continue
+		}
+		if category != "" {
+			names = append([]string{category}, names...)
+		}
+		articles = append(articles, helpArticle{
+			Title:        a.Title,
+			HTML:         a.Body,
+			URL:          a.HTMLURL,
+			LastModified: a.UpdatedAt,
+			Path:         names,
+		})
+	}
+	fmt.Printf("Converted %d Zendesk articles\n", writeHelpArticles(articles, base.Hostname(), opts))
+	return nil
+}
+
+// intercomItem is a collection or article from the Intercom API.
+type intercomItem struct {
+	ID          json.RawMessage `json:"id"`
+	Name        string          `json:"name"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Body        string          `json:"body"`
+	URL         string          `json:"url"`
+	State       string          `json:"state"`
+	ParentID    json.RawMessage `json:"parent_id"`
+	UpdatedAt   int64           `json:"updated_at"`
+}
+
+// ingestIntercom converts the published articles of an Intercom help
+// center, nested under their collections.
+func ingestIntercom(cfg IntercomSource, opts *CrawlOptions) error {
+	token, err := sourceToken(cfg.TokenEnv)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	header.Set("Intercom-Version", intercomVersion)
+	api := newAPIClient(header)
+
+	// list pages through a listing such as "articles".
+	list := func(resource string) ([]intercomItem, error) {
+		var items []intercomItem
+		for page := 1; ; page++ {
+			var res struct {
+				Data  []intercomItem `json:"data"`
+				Pages struct {
+					Page       int `json:"page"`
+					TotalPages int `json:"total_pages"`
+				} `json:"pages"`
+			}
+			link := fmt.Sprintf("%s/%s?per_page=50&page=%d", intercomAPI, resource, page)
+			if err := api.getJSON(link, &res); err != nil {
+				return nil, err
+			}
+			items = append(items, res.Data...)
+			if len(res.Data) == 0 || page >= res.Pages.TotalPages {
+				return items, nil
+			}
+		}
+	}
+
+	collections, err := list("help_center/collections")
+	if err != nil {
+		return err
+	}
+	items, err := list("articles")
+	if err != nil {
+		return err
+	}
+	collectionsByID := map[string]intercomItem{}
+	for _, c := range collections {
+		collectionsByID[jsonID(c.ID)] = c
+	}
+
+	host := "intercom.help"
+	var articles []helpArticle
+	for _, a := range items {
+		if a.State != "published" {
+			continue
+		}
+		// Collections can nest; the article is selected when any
+		// collection above it is.
+		var names []string
+		selected := len(cfg.Collections) == 0
+		seen := map[string]bool{}
+		for id := jsonID(a.ParentID); id != "" && !seen[id]; {
+			seen[id] = true
+			collection, ok := collectionsByID[id]
+			if !ok {
+				break
+			}
+			names = append([]string{collection.Name}, names...)
+			selected = selected || selectedCategory(cfg.Collections, id, collection.Name)
+			id = jsonID(collection.ParentID)
+		}
+		if !selected {
+			continue
+		}
+		if u, err := url.Parse(a.URL); err == nil && u.Hostname() != "" {
+			host = u.Hostname()
+		}
+		var updated string
+		if a.UpdatedAt > 0 {
+			updated = time.Unix(a.UpdatedAt, 0).UTC().Format(time.RFC3339)
+		}
+		articles = append(articles, helpArticle{
+			Title:        a.Title,
+			Description:  a.Description,
+			HTML:         a.Body,
+			URL:          a.URL,
+			LastModified: updated,
+			Path:         names,
+		})
+	}
+	fmt.Printf("Converted %d Intercom articles\n", writeHelpArticles(articles, host, opts))
+	return nil
+}
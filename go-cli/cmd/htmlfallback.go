@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// htmlFallbackLimit caps the size of an embedded HTML excerpt; larger
+// elements are converted without one.
+const htmlFallbackLimit = 32 * 1024
+
+// htmlFallbackToken marks where an excerpt goes in the converted markdown.
+const htmlFallbackToken = "HTMLFALLBACK%dEND"
+
+// markLowConfidence finds elements in content HTML that markdown cannot
+// represent faithfully and puts a placeholder after each. It returns the
+// marked HTML and the original HTML of each element, to be restored with
+// insertHTMLFallbacks once the page is converted.
+func markLowConfidence(content string) (string, []string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return "", nil, err
+	}
+	var excerpts []string
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		if lowConfidenceReason(s) == "" || s.ParentsFiltered("[data-html-fallback]").Length() > 0 {
+			return
+		}
+		html, err := goquery.OuterHtml(s)
+		if err != nil || len(html) > htmlFallbackLimit {
+			return
+		}
+		s.SetAttr("data-html-fallback", "")
+		s.AfterHtml("<p>" + fmt.Sprintf(htmlFallbackToken, len(excerpts)) + "</p>")
+		excerpts = append(excerpts, html)
+	})
+	if len(excerpts) == 0 {
+		return content, nil, nil
+	}
+	doc.Find("[data-html-fallback]").RemoveAttr("data-html-fallback")
+	marked, err := doc.Find("body").Html()
+	return marked, excerpts, err
+}
+
+// lowConfidenceReason says why an element is likely to lose content or
+// structure in markdown, or returns "" when it converts cleanly: tables
+// with merged or block-level cells, MathML, and custom elements.
+func lowConfidenceReason(s *goquery.Selection) string {
+	tag := goquery.NodeName(s)
+	switch {
+	case tag == "table":
+		if s.Find("[colspan], [rowspan]").FilterFunction(func(i int, cell *goquery.Selection) bool {
+			colspan, _ := cell.Attr("colspan")
+			rowspan, _ := cell.Attr("rowspan")
+			return (colspan != "" && colspan != "1") || (rowspan != "" && rowspan != "1")
+		}).Length() > 0 {
+			return "merged cells"
+		}
+		if s.Find("td, th").Find("table, ul, ol, pre, blockquote, p + p").Length() > 0 {
+			return "block content in cells"
+		}
+	case tag == "math":
+		return "MathML"
+	case strings.Contains(tag, "-") && strings.TrimSpace(s.Text()) != "":
+		return "custom element"
+	}
+	return ""
+}
+
+// insertHTMLFallbacks replaces the placeholders left by markLowConfidence
+// with collapsible blocks holding the original HTML.
+func insertHTMLFallbacks(markdown string, excerpts []string) string {
+	for i, html := range excerpts {
+		block := "<details>\n<summary>Source HTML</summary>\n\n" + fence("html", strings.Split(html, "\n")) + "\n\n</details>"
+		markdown = strings.Replace(markdown, fmt.Sprintf(htmlFallbackToken, i), block, 1)
+	}
+	return markdown
+}
@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultKeywordCount is how many keywords each page gets by default.
+const defaultKeywordCount = 8
+
+// llmKeywordChars caps how much of a page is sent to the LLM.
+const llmKeywordChars = 12000 * charsPerToken
+
+// keywordWordRe matches words, keeping identifiers like "c++" or "go-redis".
+var keywordWordRe = regexp.MustCompile(`[a-z][a-z0-9_+#-]*[a-z0-9+#]|[a-z]`)
+
+// keywordBreakRe splits text into phrases at punctuation.
+var keywordBreakRe = regexp.MustCompile(`[.,;:!?()\[\]{}"|\n]+`)
+
+// keywordNoiseRe matches markdown that should not produce keywords: link
+// targets, inline code, and HTML tags.
+var keywordNoiseRe = regexp.MustCompile("\\]\\([^)]*\\)|`[^`]*`|<[^>]+>|https?://\\S+")
+
+// stopwords are skipped when extracting keywords.
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a about above after again all also an and any are as at be
+		because been before being below between both but by can could did do does doing down
+		during each else etc few for from further get gets got had has have having here how
+		however i if in into is it its itself just may me might more most must my no nor not
+		now of off on once only or other our out over own per same see should so some such
+		than that the their them then there these they this those through to too under until
+		up us use used uses using very via was we were what when where which while who whom
+		why will with within without would yes yet you your page pages default example
+		examples following new one two first next previous section note return returns
+		parameter parameters value values`) {
+		stopwords[w] = true
+	}
+}
+
+// addKeywords adds a keywords list (or the field named by cfg.Field) to the
+// frontmatter of every page in the manifest.
+func addKeywords(opts *CrawlOptions, m *Manifest, cfg KeywordsConfig, llmCfg LLMConfig) {
+	count := cfg.Count
+	if count <= 0 {
+		count = defaultKeywordCount
+	}
+	field := cfg.Field
+	if field == "" {
+		field = "keywords"
+	}
+
+	var llm *llmClient
+	if cfg.Method == "llm" {
+		var err error
+		llm, err = newLLMClient(llmCfg)
+		if err != nil {
+			fmt.Printf("Error configuring LLM for keywords: %v\n", err)
+			return
+		}
+	}
+
+	type page struct {
+		entry *ManifestEntry
+		path  string
+		fm    frontmatter
+		body  string
+		terms []string
+	}
+	var pages []*page
+	df := map[string]int{}
+	for _, entry := range m.Pages {
+		p := &page{entry: entry, path: filepath.Join(opts.Output, filepath.FromSlash(entry.Path))}
+		data, err := readOutputFile(p.path)
+		if err != nil {
+			continue
+		}
+		fmText, body := splitFrontmatter(string(data))
+		if p.fm, err = parseFrontmatter(fmText); err != nil {
+			fmt.Printf("Warning: could not parse frontmatter of %s: %v\n", entry.Path, err)
+			continue
+		}
+		p.body = body
+		if cfg.Method == "" || cfg.Method == "tfidf" {
+			p.terms = keywordTerms(body)
+			seen := map[string]bool{}
+			for _, t := range p.terms {
+				if !seen[t] {
+					seen[t] = true
+					df[t]++
+				}
+			}
+		}
+		pages = append(pages, p)
+	}
+
+	for _, p := range pages {
+		var keywords []string
+		switch cfg.Method {
+		case "", "tfidf":
+			keywords = tfidfKeywords(p.terms, df, len(pages), count)
+		case "rake":
+			keywords = rakeKeywords(p.body, count)
+		case "llm":
+			var err error
+			keywords, err = llmKeywords(llm, p.body, count)
+			if err != nil {
+				fmt.Printf("Error extracting keywords for %s: %v\n", p.entry.URL, err)
+				continue
+			}
+		default:
+			fmt.Printf("Error: unknown keyword method %q\n", cfg.Method)
+			return
+		}
+		if len(keywords) == 0 {
+			continue
+		}
+
+		p.fm.setAfter("description", field, keywords)
+		content := p.fm.render() + p.body
+		if err := writeFileIfChanged(p.path, []byte(content)); err != nil {
+			fmt.Printf("Error writing %s: %v\n", p.path, err)
+			continue
+		}
+		m.mu.Lock()
+		p.entry.FileHash = contentHash(content)
+		m.touch(p.entry.URL)
+		m.mu.Unlock()
+	}
+}
+
+// keywordText lowercases body and removes code blocks and markup.
+func keywordText(body string) string {
+	var b strings.Builder
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return keywordNoiseRe.ReplaceAllString(strings.ToLower(b.String()), " ")
+}
+
+// keywordTerms returns the candidate terms of body: non-stopword words and
+// pairs of adjacent non-stopword words within a phrase.
+func keywordTerms(body string) []string {
+	var terms []string
+	for _, phrase := range keywordBreakRe.Split(keywordText(body), -1) {
+		prev := ""
+		for _, w := range keywordWordRe.FindAllString(phrase, -1) {
+			if stopwords[w] || len(w) < 3 {
+				prev = ""
+				continue
+			}
+			terms = append(terms, w)
+			if prev != "" {
+				terms = append(terms, prev+" "+w)
+			}
+			prev = w
+		}
+	}
+	return terms
+}
+
+// tfidfKeywords ranks a page's terms by TF-IDF across the crawl.
+func tfidfKeywords(terms []string, df map[string]int, docs, count int) []string {
+	tf := map[string]int{}
+	for _, t := range terms {
+		tf[t]++
+	}
+	scores := map[string]float64{}
+	for t, n := range tf {
+		idf := math.Log(float64(1+docs)/float64(1+df[t])) + 1
+		scores[t] = float64(n) / float64(len(terms)) * idf
+	}
+	return topKeywords(scores, count)
+}
+
+// rakeKeywords ranks phrases with RAKE: each word scores its co-occurrence
+// degree over its frequency, and a phrase scores the sum of its words.
+func rakeKeywords(body string, count int) []string {
+	var phrases [][]string
+	for _, chunk := range keywordBreakRe.Split(keywordText(body), -1) {
+		var phrase []string
+		flush := func() {
+			if len(phrase) > 0 && len(phrase) <= 3 {
+				phrases = append(phrases, phrase)
+			}
+			phrase = nil
+		}
+		for _, w := range keywordWordRe.FindAllString(chunk, -1) {
+			if stopwords[w] || len(w) < 3 {
+				flush()
+				continue
+			}
+			phrase = append(phrase, w)
+		}
+		flush()
+	}
+
+	freq := map[string]float64{}
+	degree := map[string]float64{}
+	for _, phrase := range phrases {
+		for _, w := range phrase {
+			freq[w]++
+			degree[w] += float64(len(phrase))
+		}
+	}
+	scores := map[string]float64{}
+	for _, phrase := range phrases {
+		score := 0.0
+		for _, w := range phrase {
+			score += degree[w] / freq[w]
+		}
+		scores[strings.Join(phrase, " ")] = score
+	}
+	return topKeywords(scores, count)
+}
+
+// topKeywords returns up to count of the highest-scoring terms, skipping
+// words already covered by a higher-ranked phrase.
+func topKeywords(scores map[string]float64, count int) []string {
+	terms := make([]string, 0, len(scores))
+	for t := range scores {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if scores[terms[i]] != scores[terms[j]] {
+			return scores[terms[i]] > scores[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+
+	var out []string
+	covered := map[string]bool{}
+	for _, t := range terms {
+		if len(out) == count {
+			break
+		}
+		words := strings.Fields(t)
+		if len(words) == 1 && covered[t] {
+			continue
+		}
+		for _, w := range words {
+			covered[w] = true
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// llmKeywords asks the LLM for keywords describing body.
+func llmKeywords(llm *llmClient, body string, count int) ([]string, error) {
+	if len(body) > llmKeywordChars {
+		body = body[:llmKeywordChars]
+	}
+	reply, err := llm.complete(
+		fmt.Sprintf("You tag documentation pages for search. Reply with at most %d short, lowercase keywords or key phrases, separated by commas, and nothing else.", count),
+		body,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var keywords []string
+	for _, k := range strings.Split(reply, ",") {
+		k = strings.ToLower(strings.Trim(strings.TrimSpace(k), `."'`))
+		if k != "" && len(keywords) < count {
+			keywords = append(keywords, k)
+		}
+	}
+	return keywords, nil
+}
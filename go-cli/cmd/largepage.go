@@ -0,0 +1,207 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// spoolHeader carries the path of a spooled body from the fetcher to the
+// response handlers. It never leaves the process.
+const spoolHeader = "X-Agent-Skills-Spool"
+
+// truncatedHeader carries the parse limit in MB of a large page converted
+// only in part, for its conversion warnings. It never leaves the process.
+const truncatedHeader = "X-Agent-Skills-Truncated"
+
+// spoolDirPattern names the directory under the output directory that
+// holds a process's spooled bodies until they are saved. Each process has
+// its own, so distributed workers sharing the output do not remove each
+// other's bodies.
+const spoolDirPattern = ".spool-*"
+
+// Defaults for large page handling.
+const (
+	defaultSpoolMB     = 8
+	defaultMaxParseMB  = 256
+	defaultLargeAtOnce = 1
+)
+
+// largePages holds the resolved large page settings of a crawl.
+type largePages struct {
+	outDir    string
+	threshold int64
+	maxParse  int64
+	slots     chan struct{}
+
+	mu  sync.Mutex
+	dir string // created on first use
+}
+
+// newLargePages resolves cfg against outDir, applying defaults.
+func newLargePages(cfg LargePageConfig, outDir string) *largePages {
+	spool, maxParse, parallel := cfg.SpoolMB, cfg.MaxMB, cfg.Parallelism
+	if spool <= 0 {
+		spool = defaultSpoolMB
+	}
+	if maxParse <= 0 {
+		maxParse = defaultMaxParseMB
+	}
+	if parallel <= 0 {
+		parallel = defaultLargeAtOnce
+	}
+	return &largePages{
+		outDir:    outDir,
+		threshold: int64(spool) << 20,
+		maxParse:  int64(maxParse) << 20,
+		slots:     make(chan struct{}, parallel),
+	}
+}
+
+// spoolFetcher streams HTML bodies larger than the threshold to a file
+// instead of handing them to colly, which buffers every body in memory.
+// The response colly sees has an empty body and the file path in
+// spoolHeader, so links are not followed by the a[href] handler; the
+// crawl handles such pages with handleSpooled.
+type spoolFetcher struct {
+	next  Fetcher
+	pages *largePages
+}
+
+func (f *spoolFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	resp, err := f.next.Fetch(req)
+	if err != nil || resp.StatusCode != http.StatusOK ||
+		!strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/html") {
+		return resp, err
+	}
+
+	if resp.ContentLength >= 0 && resp.ContentLength <= f.pages.threshold {
+		return resp, nil
+	}
+	var head bytes.Buffer
+	if _, err := io.CopyN(&head, resp.Body, f.pages.threshold+1); err != nil {
+		resp.Body.Close()
+		if err != io.EOF {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(&head)
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	dir, err := f.pages.spoolDir()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp(dir, "page-*.html")
+	if err != nil {
+		return nil, err
+	}
+	_, err = head.WriteTo(file)
+	if err == nil {
+		_, err = io.Copy(file, resp.Body)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	resp.Header.Set(spoolHeader, file.Name())
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = 0
+	resp.Body = http.NoBody
+	return resp, nil
+}
+
+// spoolDir returns the spool directory of this process, creating it on
+// first use.
+func (p *largePages) spoolDir() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dir == "" {
+		if err := os.MkdirAll(p.outDir, 0755); err != nil {
+			return "", err
+		}
+		dir, err := os.MkdirTemp(p.outDir, spoolDirPattern)
+		if err != nil {
+			return "", err
+		}
+		p.dir = dir
+	}
+	return p.dir, nil
+}
+
+// cleanup removes the spool directory of this process, if it made one.
+func (p *largePages) cleanup() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dir != "" {
+		os.RemoveAll(p.dir)
+	}
+}
+
+// acquire waits for a free large page slot. Large pages are parsed a few
+// at a time so parallel workers do not each hold a large DOM.
+func (p *largePages) acquire() func() {
+	p.slots <- struct{}{}
+	return func() { <-p.slots }
+}
+
+// parse reads the spooled body of r, stopping at the parse limit. A
+// truncated body is marked with truncatedHeader.
+func (p *largePages) parse(path string, r *colly.Response) (*goquery.Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.Size() > p.maxParse {
+		fmt.Printf("Warning: %s is %d MB; converting only the first %d MB\n",
+			r.Request.URL, info.Size()>>20, p.maxParse>>20)
+		r.Headers.Set(truncatedHeader, strconv.FormatInt(p.maxParse>>20, 10))
+	}
+	return goquery.NewDocumentFromReader(io.LimitReader(f, p.maxParse))
+}
+
+// saveSpooledResponse moves a spooled body to its output path and
+// converts the already parsed document.
+func saveSpooledResponse(r *colly.Response, doc *goquery.Document, spool string, opts *CrawlOptions) {
+	r = canonicalResponse(r, doc, opts)
+	dirName, fullPath := opts.outputPath(r.Request.URL)
+	ext := opts.extractionFor(r.Request.URL.String())
+
+	if err := os.MkdirAll(dirName, 0755); err != nil {
+		failPage(opts, ErrorWrite, r.Request.URL.String(), 0, fmt.Errorf("creating dir %s: %w", dirName, err))
+		return
+	}
+	if err := os.Rename(spool, fullPath); err != nil {
+		failPage(opts, ErrorWrite, r.Request.URL.String(), 0, fmt.Errorf("writing html file %s: %w", fullPath, err))
+		return
+	}
+	convertDocument(r, doc, opts, dirName, fullPath, ext)
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// listFormat selects table or json output.
+// listSkills lists one row per skill instead of per page.
+// listDomain, listSkill, listOlderThan, and listMatch filter the pages.
+// listSort orders the rows by path, size, tokens, or age.
+var (
+	listFormat    string
+	listSkills    bool
+	listDomain    string
+	listSkill     string
+	listOlderThan string
+	listMatch     string
+	listSort      string
+)
+
+// listedPage is a page in the output of list.
+type listedPage struct {
+	Skill     string    `json:"skill"`
+	Path      string    `json:"path"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Size      int64     `json:"size"`
+	Tokens    int       `json:"tokens"`
+	CrawledAt time.Time `json:"crawled_at"`
+	InlinedIn string    `json:"inlined_in,omitempty"`
+}
+
+// listedSkill totals the pages of one skill for list --skills.
+type listedSkill struct {
+	Skill     string    `json:"skill"`
+	Pages     int       `json:"pages"`
+	Size      int64     `json:"size"`
+	Tokens    int       `json:"tokens"`
+	CrawledAt time.Time `json:"crawled_at"`
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the pages in the output directory",
+	Long: `Lists the pages recorded in the output directory's manifest with their
+title, source URL, size, estimated token count, and age.
+
+--skills prints one row per skill instead, with its page count, total size
+and tokens, and the age of its most recently crawled page. --domain, --skill,
+--older-than, and --match narrow the list, and --format json prints it for
+scripts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		outputDir := configuredOutput()
+		opts := &CrawlOptions{Output: outputDir, Flat: viper.GetBool("flat"), Rename: viper.GetString("rename")}
+		m, err := loadManifest(outputDir)
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+		pages, err := listPages(opts, m)
+		if err != nil {
+			fmt.Printf("Error listing pages: %v\n", err)
+			os.Exit(1)
+		}
+
+		var rows interface{} = pages
+		if listSkills {
+			rows = listSkillTotals(pages)
+		}
+		switch listFormat {
+		case "json":
+			data, _ := json.MarshalIndent(rows, "", "  ")
+			fmt.Println(string(data))
+		case "table":
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			if listSkills {
+				fmt.Fprintln(w, "SKILL\tPAGES\tSIZE\tTOKENS\tAGE")
+				for _, s := range rows.([]listedSkill) {
+					fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\n", s.Skill, s.Pages, formatSize(s.Size), s.Tokens, formatAge(s.CrawledAt))
+				}
+			} else {
+				fmt.Fprintln(w, "PATH\tTITLE\tURL\tSIZE\tTOKENS\tAGE")
+				for _, p := range pages {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", p.Path, p.Title, p.URL, formatSize(p.Size), p.Tokens, formatAge(p.CrawledAt))
+				}
+			}
+			w.Flush()
+		default:
+			fmt.Printf("Error: unknown format %q (want table or json)\n", listFormat)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&listFormat, "format", "table", "output format: table or json")
+	listCmd.Flags().BoolVar(&listSkills, "skills", false, "list skills instead of pages")
+	listCmd.Flags().StringVar(&listDomain, "domain", "", "only list pages from this host and its subdomains")
+	listCmd.Flags().StringVar(&listSkill, "skill", "", "only list pages of this skill (a top-level directory of the output)")
+	listCmd.Flags().StringVar(&listOlderThan, "older-than", "", "only list pages crawled longer ago than this (e.g. 72h or 30d)")
+	listCmd.Flags().StringVar(&listMatch, "match", "", "only list pages whose title, URL, or path contains this text")
+	listCmd.Flags().StringVar(&listSort, "sort", "path", "sort by path, size, tokens, or age")
+}
+
+// listPages returns the manifest's pages that match the filter flags,
+// sorted by --sort.
+func listPages(opts *CrawlOptions, m *Manifest) ([]listedPage, error) {
+	var cutoff time.Time
+	if listOlderThan != "" {
+		age, err := parseAge(listOlderThan)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --older-than: %w", err)
+		}
+		cutoff = time.Now().Add(-age)
+	}
+	skill := strings.Trim(listSkill, "/")
+	match := strings.ToLower(listMatch)
+
+	pages := []listedPage{}
+	for link, entry := range m.Pages {
+		p := listedPage{
+			Skill:     strings.SplitN(entry.Path, "/", 2)[0],
+			Path:      entry.Path,
+			URL:       link,
+			Title:     entry.Title,
+			CrawledAt: entry.Provenance.CrawledAt,
+			InlinedIn: entry.InlinedIn,
+		}
+		if listDomain != "" && !hostMatches(link, listDomain) {
+			continue
+		}
+		if skill != "" && p.Skill != skill {
+			continue
+		}
+		if !cutoff.IsZero() && (p.CrawledAt.IsZero() || !p.CrawledAt.Before(cutoff)) {
+			continue
+		}
+		if match != "" && !strings.Contains(strings.ToLower(p.Title+"\n"+p.URL+"\n"+p.Path), match) {
+			continue
+		}
+		if data, err := readOutputFile(opts.pageFile(entry)); err == nil {
+			p.Size = int64(len(data))
+			p.Tokens = estimateTokens(string(data))
+		}
+		pages = append(pages, p)
+	}
+
+	var less func(a, b listedPage) bool
+	switch listSort {
+	case "path":
+		less = func(a, b listedPage) bool { return a.Path < b.Path }
+	case "size":
+		less = func(a, b listedPage) bool { return a.Size > b.Size }
+	case "tokens":
+		less = func(a, b listedPage) bool { return a.Tokens > b.Tokens }
+	case "age":
+		less = func(a, b listedPage) bool { return a.CrawledAt.Before(b.CrawledAt) }
+	default:
+		return nil, fmt.Errorf("unknown sort %q (want path, size, tokens, or age)", listSort)
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		if less(pages[i], pages[j]) != less(pages[j], pages[i]) {
+			return less(pages[i], pages[j])
+		}
+		return pages[i].Path < pages[j].Path
+	})
+	return pages, nil
+}
+
+// listSkillTotals groups pages by skill. The skills keep the order in
+// which their first page appears.
+func listSkillTotals(pages []listedPage) []listedSkill {
+	skills := []listedSkill{}
+	index := map[string]int{}
+	for _, p := range pages {
+		i, ok := index[p.Skill]
+		if !ok {
+			i = len(skills)
+			index[p.Skill] = i
+			skills = append(skills, listedSkill{Skill: p.Skill})
+		}
+		s := &skills[i]
+		s.Pages++
+		s.Size += p.Size
+		s.Tokens += p.Tokens
+		if p.CrawledAt.After(s.CrawledAt) {
+			s.CrawledAt = p.CrawledAt
+		}
+	}
+	return skills
+}
+
+// formatAge formats the time since t using its largest unit, such as
+// "3d" or "5h".
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int(d/time.Second))
+}
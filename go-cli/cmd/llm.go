@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Defaults for the llm config section.
+const (
+	defaultLLMEndpoint  = "https://api.openai.com/v1/chat/completions"
+	defaultLLMModel     = "gpt-4o-mini"
+	defaultLLMAPIKeyEnv = "OPENAI_API_KEY"
+)
+
+// llmClient calls an OpenAI-compatible chat completions endpoint.
+type llmClient struct {
+	endpoint string
+	model    string
+	apiKey   string
+	client   *http.Client
+}
+
+// newLLMClient configures a client from the llm config section. The API
+// key is read from the environment variable named by api_key_env.
+func newLLMClient(cfg LLMConfig) (*llmClient, error) {
+	c := &llmClient{
+		endpoint: cfg.Endpoint,
+		model:    cfg.Model,
+		client:   &http.Client{Timeout: 2 * time.Minute},
+	}
+	if c.endpoint == "" {
+		c.endpoint = defaultLLMEndpoint
+	}
+	if c.model == "" {
+		c.model = defaultLLMModel
+	}
+	keyEnv := cfg.APIKeyEnv
+	if keyEnv == "" {
+		keyEnv = defaultLLMAPIKeyEnv
+	}
+	c.apiKey = os.Getenv(keyEnv)
+	if c.apiKey == "" && c.endpoint == defaultLLMEndpoint {
+		return nil, fmt.Errorf("%s is not set", keyEnv)
+	}
+	return c, nil
+}
+
+type llmMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// complete sends a system and user message and returns the reply text.
+func (c *llmClient) complete(system, prompt string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": c.model,
+		"messages": []llmMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+		"temperature": 0,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent())
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var out struct {
+		Choices []struct {
+			Message llmMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", err
+	}
+	if len(out.Choices) == 0 {
+		return "", fmt.Errorf("empty response from %s", c.endpoint)
+	}
+	return strings.TrimSpace(out.Choices[0].Message.Content), nil
+}
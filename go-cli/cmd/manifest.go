@@ -0,0 +1,290 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// manifestFileName is the name of the manifest written to the output directory.
+const manifestFileName = "manifest.json"
+
+// Provenance records where a generated page came from.
+type Provenance struct {
+	Tool        string    `json:"tool"`
+	Version     string    `json:"version"`
+	CrawledAt   time.Time `json:"crawled_at"`
+	SourceURL   string    `json:"source_url"`
+	ContentHash string    `json:"content_hash"`
+	Config      string    `json:"config,omitempty"`
+}
+
+// ManifestEntry describes a single generated markdown file. Pages
+// inlined into a SKILL.md have no file of their own; InlinedIn names the
+// SKILL.md instead. OutputHash hashes the converted page without its
+// crawl time, so unchanged conversions can leave the file alone.
+// Categories lists the categories the page's rules assigned. Restricted
+// marks pages that asked not to be used for AI (noai). Citations
+// locate the page's sections in its file. Encoding is gzip or zstd when
+// the page's markdown and saved HTML are kept compressed, with .gz or
+// .zst appended to their paths.
+type ManifestEntry struct {
+	URL          string     `json:"url"`
+	Path         string     `json:"path"`
+	Name         string     `json:"name"`
+	Title        string     `json:"title"`
+	LastModified string     `json:"last_modified,omitempty"`
+	Categories   []string   `json:"categories,omitempty"`
+	Restricted   bool       `json:"restricted,omitempty"`
+	FileHash     string     `json:"file_hash,omitempty"`
+	Quality      *PageScore `json:"quality,omitempty"`
+	InlinedIn    string     `json:"inlined_in,omitempty"`
+	OutputHash   string     `json:"output_hash,omitempty"`
+	Encoding     string     `json:"encoding,omitempty"`
+	Citations    []Citation `json:"citations,omitempty"`
+	Provenance   Provenance `json:"provenance"`
+}
+
+// Manifest lists every page in the output directory, keyed by source URL.
+// Namespace names the tenant whose output it is, if any.
+// Partial is set when the crawl stopped at its time limit; ResumeToken
+// continues it with --resume. Generation counts the saves that changed
+// the output's files; see recordGeneration.
+type Manifest struct {
+	Tool        string                    `json:"tool"`
+	Version     string                    `json:"version"`
+	Build       BuildInfo                 `json:"build"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+	Config      string                    `json:"config,omitempty"`
+	Namespace   string                    `json:"namespace,omitempty"`
+	Partial     bool                      `json:"partial,omitempty"`
+	ResumeToken string                    `json:"resume_token,omitempty"`
+	Generation  int                       `json:"generation,omitempty"`
+	Pages       map[string]*ManifestEntry `json:"pages"`
+	// Generated maps files derived from pages (such as section SKILL.md
+	// files) to their content hash.
+	Generated map[string]string `json:"generated,omitempty"`
+	// Tombstones lists pages removed from the output, keyed by URL.
+	Tombstones map[string]*Tombstone `json:"tombstones,omitempty"`
+
+	mu sync.Mutex
+	// touched and removed track this process's changes so save can
+	// merge them with entries written concurrently by other workers.
+	touched        map[string]bool
+	removed        map[string]bool
+	generatedFiles map[string]bool
+	// tombstoneTTL is how long tombstones of removed pages are kept;
+	// zero records none.
+	tombstoneTTL time.Duration
+}
+
+// loadManifest reads the manifest from outDir.
+// A missing manifest yields an empty one so crawls can start fresh.
+func loadManifest(outDir string) (*Manifest, error) {
+	m := &Manifest{Pages: map[string]*ManifestEntry{}}
+	data, err := os.ReadFile(filepath.Join(outDir, manifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Pages == nil {
+		m.Pages = map[string]*ManifestEntry{}
+	}
+	return m, nil
+}
+
+// record adds or replaces the entry for a page.
+func (m *Manifest) record(entry *ManifestEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Pages[entry.URL] = entry
+	m.touch(entry.URL)
+}
+
+// lookup returns the entry for a page, if any.
+func (m *Manifest) lookup(url string) (*ManifestEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.Pages[url]
+	return entry, ok
+}
+
+// touch marks an entry as changed by this process. Callers hold m.mu.
+func (m *Manifest) touch(url string) {
+	if m.touched == nil {
+		m.touched = map[string]bool{}
+	}
+	m.touched[url] = true
+	delete(m.removed, url)
+}
+
+// recordGenerated records a derived file written to path (relative to the
+// output directory) with the given content.
+func (m *Manifest) recordGenerated(path, content string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Generated == nil {
+		m.Generated = map[string]string{}
+	}
+	if m.generatedFiles == nil {
+		m.generatedFiles = map[string]bool{}
+	}
+	path = filepath.ToSlash(path)
+	m.Generated[path] = contentHash(content)
+	m.generatedFiles[path] = true
+}
+
+// removeGenerated forgets a derived file that was deleted.
+func (m *Manifest) removeGenerated(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.generatedFiles == nil {
+		m.generatedFiles = map[string]bool{}
+	}
+	delete(m.Generated, path)
+	m.generatedFiles[path] = true
+}
+
+// remove deletes the entry for a page, recording a tombstone saying why
+// when they are kept.
+func (m *Manifest) remove(url, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bury(url, reason)
+	delete(m.Pages, url)
+	delete(m.touched, url)
+	if m.removed == nil {
+		m.removed = map[string]bool{}
+	}
+	m.removed[url] = true
+}
+
+// save writes the manifest to outDir. Entries this process did not change
+// are taken from the manifest currently on disk, so workers sharing an
+// output directory do not overwrite each other's pages.
+func (m *Manifest) save(outDir string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(outDir, manifestFileName)
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if onDisk, err := loadManifest(outDir); err == nil {
+		for url, entry := range onDisk.Pages {
+			if !m.touched[url] && !m.removed[url] {
+				m.Pages[url] = entry
+			}
+		}
+		for path, hash := range onDisk.Generated {
+			if !m.generatedFiles[path] {
+				if m.Generated == nil {
+					m.Generated = map[string]string{}
+				}
+				m.Generated[path] = hash
+			}
+		}
+		for url, t := range onDisk.Tombstones {
+			if _, ok := m.Tombstones[url]; !ok && !m.touched[url] {
+				if m.Tombstones == nil {
+					m.Tombstones = map[string]*Tombstone{}
+				}
+				m.Tombstones[url] = t
+			}
+		}
+		m.Generation = max(m.Generation, onDisk.Generation)
+	}
+	m.pruneTombstones(time.Now())
+	if err := m.recordGeneration(outDir); err != nil {
+		fmt.Printf("Warning: could not record generation: %v\n", err)
+	}
+
+	m.Tool = toolName
+	m.Build = buildInfo()
+	m.Version = m.Build.Version
+	m.GeneratedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lockStale is how old a lock file must be before it is assumed abandoned.
+const lockStale = time.Minute
+
+// lockFile takes an exclusive lock by creating path, waiting for other
+// holders to release it. The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(2 * lockStale)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", path)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// contentHash returns a stable hash of the converted page content.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// writeFileIfChanged writes content to path unless the file already holds
+// exactly that, so unchanged files keep their modification time. A
+// compressed copy holding exactly that counts as unchanged too.
+func writeFileIfChanged(path string, content []byte) error {
+	if old, err := readOutputFile(path); err == nil && bytes.Equal(old, content) {
+		return nil
+	}
+	return os.WriteFile(path, content, 0644)
+}
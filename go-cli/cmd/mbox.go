@@ -0,0 +1,327 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// mboxSubjectPrefix matches reply and forward markers and list tags at
+// the start of a subject, such as "Re: [announce] ".
+var mboxSubjectPrefix = regexp.MustCompile(`(?i)^\s*((re|fwd?|aw)\s*(\[\d+\])?\s*:|\[[^\]]*\])\s*`)
+
+// mboxMessage is a message read from an mbox archive.
+type mboxMessage struct {
+	ID         string
+	References []string
+	Subject    string
+	From       string
+	Date       time.Time
+	ArchivedAt string
+	Body       string
+}
+
+// mboxThread is a thread of messages, oldest first.
+type mboxThread struct {
+	Messages []*mboxMessage
+}
+
+// ingestMbox converts the threads of an mbox archive into dated pages
+// that make up one skill: <output>/<skill>/<yyyy-mm-dd>-<subject>/.
+func ingestMbox(cfg MboxSource, opts *CrawlOptions) error {
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if cfg.Since != "" {
+		if since, err = time.Parse("2006-01-02", cfg.Since); err != nil {
+			return fmt.Errorf("since: %w", err)
+		}
+	}
+	skill := cfg.Skill
+	if skill == "" {
+		skill = strings.TrimSuffix(filepath.Base(cfg.Path), filepath.Ext(cfg.Path))
+	}
+	skill = toPathCase(skill)
+
+	var messages []*mboxMessage
+	for _, raw := range splitMbox(data) {
+		msg, err := parseMboxMessage(raw, opts)
+		if err != nil {
+			fmt.Printf("Error reading message in %s: %v\n", cfg.Path, err)
+			continue
+		}
+		messages = append(messages, msg)
+	}
+
+	count := 0
+	for _, thread := range threadMessages(messages) {
+		root := thread.Messages[0]
+		if root.Date.Before(since) {
+			continue
+		}
+		title := cleanSubject(root.Subject)
+		if title == "" {
+			title = "Untitled"
+		}
+		var sections []string
+		for i, msg := range thread.Messages {
+			body := cleanMailBody(msg.Body)
+			if i == 0 {
+				sections = append(sections, fmt.Sprintf("*%s, %s*\n\n%s", msg.From, msg.Date.Format("2006-01-02"), body))
+				continue
+			}
+			if cfg.SkipReplies || body == "" {
+				continue
+			}
+			sections = append(sections, fmt.Sprintf("## Reply from %s (%s)\n\n%s", msg.From, msg.Date.Format("2006-01-02"), body))
+		}
+
+		source := root.ArchivedAt
+		if source == "" {
+			source = "mid:" + url.PathEscape(root.ID)
+		}
+		last := thread.Messages[len(thread.Messages)-1].Date
+		out := &url.URL{Scheme: "https", Host: skill, Path: "/" + root.Date.Format("2006-01-02") + "-" + toPathCase(title)}
+		if _, err := writeSourcePage(opts, out, convertedPage{
+			URL:          source,
+			Title:        title,
+			Description:  firstParagraph(cleanMailBody(root.Body)),
+			Body:         strings.Join(sections, "\n\n") + "\n",
+			LastModified: last.UTC().Format(time.RFC3339),
+		}); err != nil {
+			fmt.Printf("Error writing thread %s: %v\n", title, err)
+			continue
+		}
+		count++
+	}
+	fmt.Printf("Converted %d threads from %s\n", count, cfg.Path)
+	return nil
+}
+
+// splitMbox splits an mbox archive into raw messages at its "From "
+// separator lines, undoing the ">From " quoting of body lines.
+func splitMbox(data []byte) [][]byte {
+	var messages [][]byte
+	var current *bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "From ") {
+			if current != nil {
+				messages = append(messages, current.Bytes())
+			}
+			current = &bytes.Buffer{}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if trimmed := strings.TrimLeft(line, ">"); strings.HasPrefix(trimmed, "From ") && trimmed != line {
+			line = line[1:]
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	if current != nil {
+		messages = append(messages, current.Bytes())
+	}
+	return messages
+}
+
+// parseMboxMessage parses a raw message, keeping its text body.
+func parseMboxMessage(raw []byte, opts *CrawlOptions) (*mboxMessage, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	decoder := new(mime.WordDecoder)
+	header := func(key string) string {
+		value := m.Header.Get(key)
+		if decoded, err := decoder.DecodeHeader(value); err == nil {
+			return decoded
+		}
+		return value
+	}
+	msg := &mboxMessage{
+		ID:         strings.Trim(m.Header.Get("Message-Id"), "<> "),
+		Subject:    header("Subject"),
+		ArchivedAt: strings.Trim(m.Header.Get("Archived-At"), "<> "),
+	}
+	for _, ref := range strings.Fields(m.Header.Get("References") + " " + m.Header.Get("In-Reply-To")) {
+		msg.References = append(msg.References, strings.Trim(ref, "<>"))
+	}
+	if from, err := mail.ParseAddress(header("From")); err == nil {
+		msg.From = from.Name
+		if msg.From == "" {
+			msg.From = from.Address
+		}
+	} else {
+		msg.From = header("From")
+	}
+	msg.Date, _ = m.Header.Date()
+	if msg.Body, err = mailText(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Body, opts); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// mailText returns the text of a message part: text/plain as is, text/html
+// converted to markdown, and for multipart messages the first part that
+// has text, preferring plain text in multipart/alternative.
+func mailText(contentType, encoding string, body io.Reader, opts *CrawlOptions) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var html string
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+				continue
+			}
+			partType := part.Header.Get("Content-Type")
+			text, err := mailText(partType, part.Header.Get("Content-Transfer-Encoding"), part, opts)
+			if err != nil || text == "" {
+				continue
+			}
+			if strings.HasPrefix(partType, "text/html") && mediaType == "multipart/alternative" {
+				html = text
+				continue
+			}
+			return text, nil
+		}
+		return html, nil
+	}
+
+	if label := params["charset"]; label != "" {
+		if body, err = charset.NewReaderLabel(label, body); err != nil {
+			return "", err
+		}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	switch mediaType {
+	case "text/plain":
+		return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+	case "text/html":
+		return htmlToMarkdown(string(data), opts)
+	}
+	return "", nil
+}
+
+// threadMessages groups messages into threads by their References and
+// In-Reply-To headers, falling back to the subject for replies whose
+// parent is not in the archive. Threads are sorted by their first message.
+func threadMessages(messages []*mboxMessage) []*mboxThread {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Date.Before(messages[j].Date)
+	})
+	byID := map[string]*mboxThread{}
+	bySubject := map[string]*mboxThread{}
+	var threads []*mboxThread
+	for _, msg := range messages {
+		var thread *mboxThread
+		for _, ref := range msg.References {
+			if t, ok := byID[ref]; ok {
+				thread = t
+				break
+			}
+		}
+		subject := strings.ToLower(cleanSubject(msg.Subject))
+		if thread == nil && len(msg.References) > 0 {
+			thread = bySubject[subject]
+		}
+		if thread == nil {
+			thread = &mboxThread{}
+			threads = append(threads, thread)
+			if _, ok := bySubject[subject]; !ok {
+				bySubject[subject] = thread
+			}
+		}
+		thread.Messages = append(thread.Messages, msg)
+		if msg.ID != "" {
+			byID[msg.ID] = thread
+		}
+	}
+	return threads
+}
+
+// cleanSubject strips reply markers and list tags from a subject.
+func cleanSubject(subject string) string {
+	for {
+		stripped := mboxSubjectPrefix.ReplaceAllString(subject, "")
+		if stripped == subject {
+			return strings.TrimSpace(subject)
+		}
+		subject = stripped
+	}
+}
+
+// cleanMailBody removes quoted text, the attribution line introducing
+// it, and the signature from a message body.
+func cleanMailBody(body string) string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if line == "-- " || line == "--" {
+			break
+		}
+		if strings.HasPrefix(line, ">") {
+			// Drop the "On <date>, <name> wrote:" line before a quote.
+			if n := len(lines); n > 0 && strings.HasSuffix(strings.TrimSpace(lines[n-1]), "wrote:") {
+				lines = lines[:n-1]
+			}
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(collapseBlankLines(strings.Join(lines, "\n")))
+}
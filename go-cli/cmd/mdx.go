@@ -0,0 +1,382 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MDX component actions, set per component name in mdx.components.
+const (
+	mdxUnwrap     = "unwrap"     // keep the children
+	mdxDrop       = "drop"       // remove the component and its children
+	mdxTab        = "tab"        // a bold label from label= or value=, then the children
+	mdxAdmonition = "admonition" // a blockquote labelled by type= or title=
+	mdxCode       = "code"       // a fenced block in language=
+	mdxDetails    = "details"    // a bold summary= label, then the children
+)
+
+// defaultMDXComponents maps the Docusaurus theme components. Components
+// that are not mapped are unwrapped.
+var defaultMDXComponents = map[string]string{
+	"Tabs":        mdxUnwrap,
+	"TabItem":     mdxTab,
+	"Admonition":  mdxAdmonition,
+	"CodeBlock":   mdxCode,
+	"Details":     mdxDetails,
+	"DocCardList": mdxDrop,
+	"TOCInline":   mdxDrop,
+}
+
+var (
+	mdxCodeSpan   = regexp.MustCompile("(?ms)^[ \t]*```.*?^[ \t]*```[^\n]*$|`[^`\n]+`")
+	mdxTagStart   = regexp.MustCompile(`<(/?)([A-Z][\w.]*)`)
+	mdxAttr       = regexp.MustCompile(`^\s*([\w-]+)(?:\s*=\s*("[^"]*"|'[^']*'|\{))?`)
+	mdxComment    = regexp.MustCompile(`(?s)\{\s*/\*.*?\*/\s*\}`)
+	mdxImportEnd  = regexp.MustCompile(`(from\s+|^import\s+)["'][^"']+["'];?\s*$`)
+	mdxAdmonStart = regexp.MustCompile(`^(:{3,})(\w+)(?:\[(.*)\]|\s+(.*))?$`)
+)
+
+// convertMDX converts an MDX file in a git source: its markdown is kept
+// while imports, exports, and JSX comments are removed and JSX components
+// are rewritten according to the component mapping.
+func convertMDX(data []byte, opts *CrawlOptions) (sourceDoc, error) {
+	doc, err := convertMarkdownSource(data, opts)
+	if err != nil {
+		return doc, err
+	}
+	// Config keys are case-insensitive, so components are matched by
+	// their lowercased name.
+	components := map[string]string{}
+	for name, action := range defaultMDXComponents {
+		components[strings.ToLower(name)] = action
+	}
+	for name, action := range opts.mdx.Components {
+		components[strings.ToLower(name)] = action
+	}
+	body := stripMDXStatements(doc.Body)
+	body = rewriteJSX(body, components)
+	body = rewriteAdmonitions(body)
+	doc.Body = strings.TrimSpace(collapseBlankLines(body)) + "\n"
+	return doc, nil
+}
+
+// stripMDXStatements removes ESM import and export statements and JSX
+// comments outside code blocks.
+func stripMDXStatements(body string) string {
+	lines := strings.Split(body, "\n")
+	var out []string
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		switch {
+		case inFence:
+		case strings.HasPrefix(line, "import "):
+			for i < len(lines)-1 && !mdxImportEnd.MatchString(strings.TrimSpace(lines[i])) {
+				i++
+			}
+			continue
+		case strings.HasPrefix(line, "export "):
+			depth := 0
+			for ; i < len(lines); i++ {
+				depth += strings.Count(lines[i], "{") + strings.Count(lines[i], "(") + strings.Count(lines[i], "[")
+				depth -= strings.Count(lines[i], "}") + strings.Count(lines[i], ")") + strings.Count(lines[i], "]")
+				if depth <= 0 {
+					break
+				}
+			}
+			continue
+		}
+		out = append(out, line)
+	}
+	return mdxComment.ReplaceAllString(strings.Join(out, "\n"), "")
+}
+
+// jsxFrame is a component whose closing tag has not been reached yet.
+type jsxFrame struct {
+	name  string
+	attrs map[string]string
+	body  strings.Builder
+}
+
+// rewriteJSX renders JSX components (capitalized tags) by their mapped
+// action. Code blocks and inline code are left untouched.
+func rewriteJSX(body string, components map[string]string) string {
+	var code []string
+	body = mdxCodeSpan.ReplaceAllStringFunc(body, func(m string) string {
+		// Keep an indented fence's indentation outside the placeholder, so
+		// it is removed along with the component's.
+		indent := m[:len(m)-len(strings.TrimLeft(m, " \t"))]
+		lines := strings.Split(m, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(line, indent)
+		}
+		code = append(code, strings.Join(lines, "\n"))
+		return indent + fmt.Sprintf("\x00%d\x00", len(code)-1)
+	})
+
+	unmask := func(s string) string {
+		for k, span := range code {
+			s = strings.Replace(s, fmt.Sprintf("\x00%d\x00", k), span, 1)
+		}
+		return s
+	}
+
+	stack := []*jsxFrame{{}}
+	write := func(s string) { stack[len(stack)-1].body.WriteString(s) }
+	pop := func() {
+		frame := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		write(renderJSX(frame, components[strings.ToLower(frame.name)], unmask))
+	}
+	for {
+		loc := mdxTagStart.FindStringSubmatchIndex(body)
+		if loc == nil {
+			write(body)
+			break
+		}
+		write(body[:loc[0]])
+		closing := loc[3] > loc[2]
+		name := body[loc[4]:loc[5]]
+		attrs, selfClosing, rest, ok := parseJSXTag(body[loc[1]:])
+		if !ok {
+			// Not a tag after all, such as "a <B" in prose.
+			write(body[loc[0]:loc[1]])
+			body = body[loc[1]:]
+			continue
+		}
+		body = rest
+
+		switch {
+		case closing:
+			// Close up to the matching frame, ignoring stray closing tags.
+			for k := len(stack) - 1; k > 0; k-- {
+				if stack[k].name == name {
+					for len(stack) > k {
+						pop()
+					}
+					break
+				}
+			}
+		case selfClosing:
+			write(renderJSX(&jsxFrame{name: name, attrs: attrs}, components[strings.ToLower(name)], unmask))
+		default:
+			stack = append(stack, &jsxFrame{name: name, attrs: attrs})
+		}
+	}
+	for len(stack) > 1 {
+		pop()
+	}
+
+	return unmask(stack[0].body.String())
+}
+
+// parseJSXTag parses the attributes of a tag up to its closing ">" and
+// returns them with the text after the tag. Expression values ({...}) are
+// kept when they are a plain string literal.
+func parseJSXTag(s string) (map[string]string, bool, string, bool) {
+	attrs := map[string]string{}
+	for {
+		trimmed := strings.TrimLeft(s, " \t\n")
+		switch {
+		case trimmed == "":
+			return nil, false, s, false
+		case strings.HasPrefix(trimmed, "/>"):
+			return attrs, true, trimmed[2:], true
+		case strings.HasPrefix(trimmed, ">"):
+			return attrs, false, trimmed[1:], true
+		case strings.HasPrefix(trimmed, "{"):
+			end := matchBrace(trimmed)
+			if end == -1 {
+				return nil, false, s, false
+			}
+			s = trimmed[end+1:]
+			continue
+		}
+		m := mdxAttr.FindStringSubmatchIndex(trimmed)
+		if m == nil {
+			return nil, false, s, false
+		}
+		name := trimmed[m[2]:m[3]]
+		s = trimmed[m[1]:]
+		switch {
+		case m[4] == -1:
+			attrs[name] = "true"
+		case trimmed[m[4]] == '{':
+			end := matchBrace(trimmed[m[4]:])
+			if end == -1 {
+				return nil, false, s, false
+			}
+			expr := strings.TrimSpace(trimmed[m[4]+1 : m[4]+end])
+			if len(expr) >= 2 && strings.ContainsRune(`"'`+"`", rune(expr[0])) && expr[len(expr)-1] == expr[0] {
+				expr = expr[1 : len(expr)-1]
+			}
+			attrs[name] = expr
+			s = trimmed[m[4]+end+1:]
+		default:
+			attrs[name] = trimmed[m[4]+1 : m[5]-1]
+		}
+	}
+}
+
+// matchBrace returns the index of the brace closing the one at s[0].
+func matchBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// renderJSX renders a closed component as markdown. unmask restores the
+// code spans hidden from the tag scanner.
+func renderJSX(frame *jsxFrame, action string, unmask func(string) string) string {
+	children := strings.TrimSpace(strings.Join(dedent(strings.Split(frame.body.String(), "\n")), "\n"))
+	block := func(s string) string { return "\n\n" + s + "\n\n" }
+	switch action {
+	case mdxDrop:
+		return ""
+	case mdxTab:
+		label := frame.attrs["label"]
+		if label == "" {
+			label = frame.attrs["value"]
+		}
+		if label == "" {
+			return block(children)
+		}
+		return block("**" + label + "**\n\n" + children)
+	case mdxAdmonition:
+		label := frame.attrs["title"]
+		if label == "" {
+			label = frame.attrs["type"]
+		}
+		if label == "" {
+			label = frame.name
+		}
+		return block(admonitionMarkdown(titleCase(label), []string{children}))
+	case mdxCode:
+		lang := frame.attrs["language"]
+		if lang == "" {
+			lang = strings.TrimPrefix(frame.attrs["className"], "language-")
+		}
+		// Code is usually passed as a template literal: {`...`}.
+		code := strings.TrimSpace(unmask(frame.body.String()))
+		if strings.HasPrefix(code, "{") && strings.HasSuffix(code, "}") {
+			code = strings.TrimSpace(code[1 : len(code)-1])
+			if len(code) >= 2 && strings.ContainsRune(`"'`+"`", rune(code[0])) && code[len(code)-1] == code[0] {
+				code = code[1 : len(code)-1]
+			}
+		}
+		code = strings.Trim(code, "\n")
+		if title := frame.attrs["title"]; title != "" {
+			return block("**" + title + "**\n\n" + fence(lang, dedent(strings.Split(code, "\n"))))
+		}
+		return block(fence(lang, dedent(strings.Split(code, "\n"))))
+	case mdxDetails:
+		if summary := frame.attrs["summary"]; summary != "" {
+			return block("**" + summary + "**\n\n" + children)
+		}
+		return block(children)
+	}
+	if children == "" {
+		return ""
+	}
+	if strings.Contains(children, "\n") || strings.Contains(frame.body.String(), "\n") {
+		return block(children)
+	}
+	return children
+}
+
+// rewriteAdmonitions converts Docusaurus ":::note Title" admonitions into
+// blockquotes.
+func rewriteAdmonitions(body string) string {
+	lines := strings.Split(body, "\n")
+	var out []string
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		m := mdxAdmonStart.FindStringSubmatch(strings.TrimSpace(line))
+		if inFence || m == nil {
+			out = append(out, line)
+			continue
+		}
+		label := m[3] + m[4]
+		if label == "" {
+			label = titleCase(m[2])
+		}
+		var inner []string
+		depth := 1
+		for i++; i < len(lines); i++ {
+			t := strings.TrimSpace(lines[i])
+			if mdxAdmonStart.MatchString(t) {
+				depth++
+			} else if strings.HasPrefix(t, ":::") && strings.Trim(t, ":") == "" {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+			inner = append(inner, lines[i])
+		}
+		content := rewriteAdmonitions(strings.Join(inner, "\n"))
+		out = append(out, admonitionMarkdown(label, []string{strings.TrimSpace(content)}))
+	}
+	return strings.Join(out, "\n")
+}
+
+// titleCase upper-cases the first letter of s.
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// collapseBlankLines reduces runs of blank lines to one.
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	var out []string
+	inFence := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		blank := strings.TrimSpace(line) == ""
+		if blank && !inFence && len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			continue
+		}
+		if blank && !inFence {
+			line = ""
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
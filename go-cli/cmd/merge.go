@@ -0,0 +1,473 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// mergeOut holds the output directory for merged skills.
+var mergeOut string
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [skill-dir...]",
+	Short: "Merge each skill into a single markdown file",
+	Long: `Concatenates the markdown under each skill directory (default: every
+directory in the output directory) into <out>/<skill>.md, with a table of
+contents at the top.
+
+Pages follow the navigation of the site: each directory's index page comes
+first, then the pages and subdirectories it links to in link order (or in
+the order: recorded from the site navigation by nav_order), then the rest
+by path. Page titles become H2 headings and the headings inside
+pages move down a level. Links between merged pages point to their
+headings; other relative links are made absolute.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output := configuredOutput()
+		dirs := args
+		if len(dirs) == 0 {
+			entries, err := os.ReadDir(output)
+			if err != nil {
+				fmt.Printf("Error reading output directory: %v\n", err)
+				os.Exit(1)
+			}
+			for _, e := range entries {
+				if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+					dirs = append(dirs, filepath.Join(output, e.Name()))
+				}
+			}
+		}
+
+		out := mergeOut
+		if out == "" {
+			out = strings.TrimSuffix(output, string(filepath.Separator)) + "-merged"
+		}
+		for _, dir := range dirs {
+			dest := filepath.Join(out, filepath.Base(dir)+".md")
+			if err := mergeSkill(dir, dest); err != nil {
+				fmt.Printf("Error merging %s: %v\n", dir, err)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	mergeCmd.Flags().StringVar(&mergeOut, "out", "", "directory for merged files (default: <output>-merged)")
+}
+
+// mergePage is a page included in a merged file.
+type mergePage struct {
+	rel    string // skill-relative, slash-separated
+	meta   pageMeta
+	body   string
+	level  int
+	anchor string
+}
+
+// mergeSkill writes the pages of the skill at dir to dest as one file.
+func mergeSkill(dir, dest string) error {
+	var pages []*mergePage
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		p = pagePath(p)
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".md") || filepath.Base(p) == tocFileName {
+			return err
+		}
+		meta, body, err := readPage(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		pages = append(pages, &mergePage{rel: filepath.ToSlash(rel), meta: meta, body: body})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+
+	pages = navOrder(pages)
+	byURL := map[string]*mergePage{}
+	for _, p := range pages {
+		if p.meta.URL != "" {
+			byURL[normalizeLink(p.meta.URL)] = p
+		}
+	}
+
+	// Anchors are assigned in document order so duplicates get the same
+	// numeric suffixes a markdown renderer would give them.
+	title := mergeTitle(pages[0], dir)
+	anchors := headingAnchors{}
+	anchors.next(title)
+	anchors.next("Contents")
+	for _, p := range pages {
+		p.body = shiftHeadings(pageContent(p), 1)
+		p.anchor = anchors.next(pageTitle(p))
+		for _, h := range markdownHeadings(p.body) {
+			anchors.next(h)
+		}
+	}
+
+	description := pages[0].meta.Description
+	if description == "" {
+		description = noDescription
+	}
+	fm := frontmatter{
+		{Key: "name", Value: sanitizeName(toPathCase(filepath.Base(dir)))},
+		{Key: "description", Value: description},
+		{Key: "metadata", Value: frontmatter{
+			{Key: "url", Value: pages[0].meta.URL},
+			{Key: "pages", Value: len(pages)},
+		}},
+	}
+
+	var b strings.Builder
+	b.WriteString(fm.render())
+	fmt.Fprintf(&b, "\n# %s\n\n## Contents\n\n", title)
+	for _, p := range pages {
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", strings.Repeat("  ", p.level), pageTitle(p), p.anchor)
+	}
+	for _, p := range pages {
+		fmt.Fprintf(&b, "\n## %s\n\n", pageTitle(p))
+		if p.meta.URL != "" {
+			fmt.Fprintf(&b, "Source: %s\n\n", p.meta.URL)
+		}
+		body := rewriteMergedLinks(p, byURL, filepath.Join(dir, filepath.FromSlash(path.Dir(p.rel))), filepath.Dir(dest))
+		if body = strings.TrimSpace(body); body != "" {
+			b.WriteString(body)
+			b.WriteString("\n")
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dest, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Merged %d pages from %s into %s (~%d tokens)\n", len(pages), dir, dest, estimateTokens(b.String()))
+	return nil
+}
+
+// navOrder sorts pages the way the site navigates them: each directory's
+// index page, then its pages and subdirectories in the order the index
+// links to them, then the rest by path. When pages have a recorded nav
+// order, that order replaces the index links. Each page's level is its
+// depth in that tree.
+func navOrder(pages []*mergePage) []*mergePage {
+	navRanks := map[*mergePage]int{}
+	for _, p := range pages {
+		if order := pageOrder(p.meta); order > 0 {
+			navRanks[p] = order
+		}
+	}
+
+	byDir := map[string][]*mergePage{}
+	subdirs := map[string][]string{}
+	for _, p := range pages {
+		dir := path.Dir(p.rel)
+		byDir[dir] = append(byDir[dir], p)
+		for d := dir; d != "."; d = path.Dir(d) {
+			parent := path.Dir(d)
+			if !containsString(subdirs[parent], d) {
+				subdirs[parent] = append(subdirs[parent], d)
+			}
+		}
+	}
+
+	// indexOf returns the index page of a directory, if it has one.
+	indexOf := func(dir string) *mergePage {
+		var index *mergePage
+		for _, p := range byDir[dir] {
+			base := path.Base(p.rel)
+			if isLandingPage(p.meta.URL, nil) || base == "index.md" || base == skillFileName {
+				if index == nil || len(p.meta.URL) < len(index.meta.URL) {
+					index = p
+				}
+			}
+		}
+		return index
+	}
+
+	var ordered []*mergePage
+	var walk func(dir string, level int)
+	walk = func(dir string, level int) {
+		index := indexOf(dir)
+		if index != nil {
+			index.level = level
+			ordered = append(ordered, index)
+			level++
+		}
+
+		// An item is a page or a subdirectory, ranked by the first link
+		// the index page has to it (or to a page below it).
+		type navItem struct {
+			page *mergePage
+			dir  string
+			rank int
+		}
+		ranks := navRanks
+		if len(ranks) == 0 {
+			ranks = linkRanks(index, pages)
+		}
+		var items []navItem
+		for _, p := range byDir[dir] {
+			if p != index {
+				items = append(items, navItem{page: p, rank: rankOf(ranks, p)})
+			}
+		}
+		for _, sub := range subdirs[dir] {
+			rank := -1
+			for _, p := range pages {
+				if strings.HasPrefix(p.rel, sub+"/") {
+					if r := rankOf(ranks, p); r >= 0 && (rank < 0 || r < rank) {
+						rank = r
+					}
+				}
+			}
+			items = append(items, navItem{dir: sub, rank: rank})
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			ri, rj := items[i].rank, items[j].rank
+			if (ri < 0) != (rj < 0) {
+				return ri >= 0
+			}
+			if ri != rj {
+				return ri < rj
+			}
+			return items[i].dir+pageRel(items[i].page) < items[j].dir+pageRel(items[j].page)
+		})
+		for _, item := range items {
+			if item.page != nil {
+				item.page.level = level
+				ordered = append(ordered, item.page)
+			} else {
+				walk(item.dir, level)
+			}
+		}
+	}
+	walk(".", 0)
+	return ordered
+}
+
+// linkRanks maps each page the index page links to to the position of its
+// first link there.
+func linkRanks(index *mergePage, pages []*mergePage) map[*mergePage]int {
+	ranks := map[*mergePage]int{}
+	if index == nil {
+		return ranks
+	}
+	base, err := url.Parse(index.meta.URL)
+	if err != nil || index.meta.URL == "" {
+		return ranks
+	}
+	byURL := map[string]*mergePage{}
+	for _, p := range pages {
+		if p.meta.URL != "" {
+			byURL[normalizeLink(p.meta.URL)] = p
+		}
+	}
+	for i, m := range markdownLinkRe.FindAllStringSubmatch(index.body, -1) {
+		ref, err := url.Parse(m[1])
+		if err != nil {
+			continue
+		}
+		if target, ok := byURL[normalizeLink(base.ResolveReference(ref).String())]; ok {
+			if _, seen := ranks[target]; !seen {
+				ranks[target] = i
+			}
+		}
+	}
+	return ranks
+}
+
+// rankOf returns the link rank of p, or -1 when the index does not link to it.
+func rankOf(ranks map[*mergePage]int, p *mergePage) int {
+	if r, ok := ranks[p]; ok {
+		return r
+	}
+	return -1
+}
+
+func pageRel(p *mergePage) string {
+	if p == nil {
+		return ""
+	}
+	return p.rel
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeTitle returns the H1 of a merged file: the title of its first
+// page, or the directory name.
+func mergeTitle(first *mergePage, dir string) string {
+	if first.meta.Title != "" {
+		return first.meta.Title
+	}
+	return filepath.Base(dir)
+}
+
+// pageTitle returns the heading a page gets in a merged file.
+func pageTitle(p *mergePage) string {
+	if p.meta.Title != "" {
+		return p.meta.Title
+	}
+	if p.meta.Name != "" {
+		return p.meta.Name
+	}
+	return p.rel
+}
+
+// pageContent returns a page body without its title.
+func pageContent(p *mergePage) string {
+	return stripPageTitle(p.body, p.meta.Title)
+}
+
+// stripPageTitle removes the title line from a generated page body, and a
+// leading content heading that repeats it.
+func stripPageTitle(body, title string) string {
+	body = strings.TrimSpace(stripTitle(body))
+	if first, rest, _ := strings.Cut(body, "\n"); strings.TrimSpace(first) == "# "+title {
+		body = strings.TrimSpace(rest)
+	}
+	return body
+}
+
+// shiftHeadings moves every ATX heading outside code fences down by n
+// levels (up when n is negative), keeping them between H1 and H6.
+func shiftHeadings(body string, n int) string {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if level, _, ok := atxHeading(line); ok && !inFence {
+			lines[i] = strings.Repeat("#", max(min(level+n, 6), 1)) + line[level:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownHeadings returns the text of the ATX headings outside code fences.
+func markdownHeadings(body string) []string {
+	var headings []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if _, text, ok := atxHeading(line); ok && !inFence {
+			headings = append(headings, text)
+		}
+	}
+	return headings
+}
+
+// isFence reports whether line opens or closes a code fence.
+func isFence(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+// atxHeading returns the level and text of an ATX heading line.
+func atxHeading(line string) (int, string, bool) {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 || len(line) > level && line[level] != ' ' {
+		return 0, "", false
+	}
+	return level, strings.TrimSpace(line[level:]), true
+}
+
+// headingAnchors assigns GitHub-style heading anchors, numbering repeats.
+type headingAnchors map[string]int
+
+func (a headingAnchors) next(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	slug := b.String()
+	n := a[slug]
+	a[slug] = n + 1
+	if n > 0 {
+		return fmt.Sprintf("%s-%d", slug, n)
+	}
+	return slug
+}
+
+// rewriteMergedLinks points links to other merged pages at their headings,
+// links to files saved next to the page (such as images) at those files
+// from outDir, and other relative links at the absolute URL.
+func rewriteMergedLinks(p *mergePage, byURL map[string]*mergePage, pageDir, outDir string) string {
+	base, err := url.Parse(p.meta.URL)
+	if err != nil {
+		base = nil
+	}
+	return markdownLinkRe.ReplaceAllStringFunc(p.body, func(m string) string {
+		link := strings.TrimPrefix(m, "](")
+		ref, err := url.Parse(link)
+		if err != nil || strings.HasPrefix(link, "#") {
+			return m
+		}
+		var abs *url.URL
+		if base != nil && p.meta.URL != "" {
+			abs = base.ResolveReference(ref)
+			if target, ok := byURL[normalizeLink(abs.String())]; ok {
+				return "](#" + target.anchor
+			}
+		}
+		// Pages are saved with their HTML, which should not be linked
+		// in place of the page.
+		if !ref.IsAbs() && ref.Host == "" && ref.Path != "" && path.Ext(ref.Path) != ".html" {
+			file := filepath.Join(pageDir, filepath.FromSlash(ref.Path))
+			if _, err := statOutputFile(file); err == nil {
+				if rel, err := filepath.Rel(outDir, file); err == nil {
+					return "](" + filepath.ToSlash(rel)
+				}
+			}
+		}
+		if abs == nil {
+			return m
+		}
+		return "](" + abs.String()
+	})
+}
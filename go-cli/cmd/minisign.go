@@ -0,0 +1,226 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// The signing format follows minisign (https://jedisct1.github.io/minisign/)
+// so keys and signatures interoperate with the minisign CLI. Only
+// unencrypted secret keys are supported.
+
+var (
+	minisignAlgEd       = [2]byte{'E', 'd'}
+	minisignAlgHashedEd = [2]byte{'E', 'D'}
+	minisignCksumB2     = [2]byte{'B', '2'}
+)
+
+// minisignPublicKey is a minisign public key.
+type minisignPublicKey struct {
+	KeyID [8]byte
+	Key   ed25519.PublicKey
+}
+
+// minisignSecretKey is an unencrypted minisign secret key.
+type minisignSecretKey struct {
+	KeyID [8]byte
+	Key   ed25519.PrivateKey
+}
+
+// generateMinisignKey creates a new key pair with a random key ID.
+func generateMinisignKey() (*minisignPublicKey, *minisignSecretKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, nil, err
+	}
+	return &minisignPublicKey{KeyID: id, Key: pub}, &minisignSecretKey{KeyID: id, Key: priv}, nil
+}
+
+// keyIDString formats a key ID the way minisign prints it.
+func keyIDString(id [8]byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+// encode returns the public key file contents.
+func (k *minisignPublicKey) encode() []byte {
+	var raw bytes.Buffer
+	raw.Write(minisignAlgEd[:])
+	raw.Write(k.KeyID[:])
+	raw.Write(k.Key)
+	return []byte(fmt.Sprintf("untrusted comment: minisign public key %s\n%s\n",
+		keyIDString(k.KeyID), base64.StdEncoding.EncodeToString(raw.Bytes())))
+}
+
+// encode returns the secret key file contents.
+func (k *minisignSecretKey) encode() []byte {
+	var raw bytes.Buffer
+	raw.Write(minisignAlgEd[:])
+	raw.Write([]byte{0, 0}) // no KDF: the key is stored unencrypted
+	raw.Write(minisignCksumB2[:])
+	raw.Write(make([]byte, 32+8+8)) // salt, opslimit, memlimit
+	raw.Write(k.KeyID[:])
+	raw.Write(k.Key)
+	raw.Write(k.checksum())
+	return []byte(fmt.Sprintf("untrusted comment: minisign unencrypted secret key\n%s\n",
+		base64.StdEncoding.EncodeToString(raw.Bytes())))
+}
+
+func (k *minisignSecretKey) checksum() []byte {
+	h, _ := blake2b.New256(nil)
+	h.Write(minisignAlgEd[:])
+	h.Write(k.KeyID[:])
+	h.Write(k.Key)
+	return h.Sum(nil)
+}
+
+// decodeKeyFile returns the base64 payload line of a minisign key or
+// signature file, skipping the untrusted comment.
+func decodeKeyFile(data []byte) ([]byte, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+		return base64.StdEncoding.DecodeString(line)
+	}
+	return nil, errors.New("no key data found")
+}
+
+// readMinisignPublicKey loads a public key from a file path, or parses
+// the argument directly if it is a base64 key.
+func readMinisignPublicKey(pathOrKey string) (*minisignPublicKey, error) {
+	data, err := os.ReadFile(pathOrKey)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		data = []byte(pathOrKey)
+	}
+	raw, err := decodeKeyFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || !bytes.Equal(raw[:2], minisignAlgEd[:]) {
+		return nil, errors.New("invalid public key: unexpected format")
+	}
+	k := &minisignPublicKey{Key: ed25519.PublicKey(raw[10:])}
+	copy(k.KeyID[:], raw[2:10])
+	return k, nil
+}
+
+// readMinisignSecretKey loads an unencrypted secret key from path.
+func readMinisignSecretKey(path string) (*minisignSecretKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := decodeKeyFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	if len(raw) != 2+2+2+32+8+8+8+ed25519.PrivateKeySize+32 || !bytes.Equal(raw[:2], minisignAlgEd[:]) {
+		return nil, errors.New("invalid secret key: unexpected format")
+	}
+	if raw[2] != 0 || raw[3] != 0 {
+		return nil, errors.New("encrypted secret keys are not supported; generate one with `keygen` or `minisign -G -W`")
+	}
+	keynum := raw[54:]
+	k := &minisignSecretKey{Key: ed25519.PrivateKey(append([]byte(nil), keynum[8:72]...))}
+	copy(k.KeyID[:], keynum[:8])
+	if !bytes.Equal(k.checksum(), keynum[72:]) {
+		return nil, errors.New("invalid secret key: checksum mismatch")
+	}
+	return k, nil
+}
+
+// minisignSign signs message using the pre-hashed (BLAKE2b-512) algorithm
+// and returns the signature file contents.
+func minisignSign(k *minisignSecretKey, message []byte, trustedComment string) []byte {
+	digest := blake2b.Sum512(message)
+	sig := ed25519.Sign(k.Key, digest[:])
+
+	var raw bytes.Buffer
+	raw.Write(minisignAlgHashedEd[:])
+	raw.Write(k.KeyID[:])
+	raw.Write(sig)
+
+	globalSig := ed25519.Sign(k.Key, append(append([]byte(nil), sig...), trustedComment...))
+
+	return []byte(fmt.Sprintf("untrusted comment: signature from %s secret key\n%s\ntrusted comment: %s\n%s\n",
+		toolName,
+		base64.StdEncoding.EncodeToString(raw.Bytes()),
+		trustedComment,
+		base64.StdEncoding.EncodeToString(globalSig)))
+}
+
+// minisignVerify checks sigFile against message and returns the trusted comment.
+func minisignVerify(k *minisignPublicKey, message, sigFile []byte) (string, error) {
+	lines := strings.Split(strings.TrimSpace(string(sigFile)), "\n")
+	if len(lines) < 4 {
+		return "", errors.New("invalid signature file")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return "", errors.New("invalid signature encoding")
+	}
+	trusted, ok := strings.CutPrefix(strings.TrimSpace(lines[2]), "trusted comment: ")
+	if !ok {
+		return "", errors.New("missing trusted comment")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return "", errors.New("invalid global signature encoding")
+	}
+
+	if !bytes.Equal(raw[2:10], k.KeyID[:]) {
+		return "", fmt.Errorf("signature key ID %s does not match public key %s",
+			keyIDString([8]byte(raw[2:10])), keyIDString(k.KeyID))
+	}
+
+	sig := raw[10:]
+	signed := message
+	switch [2]byte(raw[:2]) {
+	case minisignAlgHashedEd:
+		digest := blake2b.Sum512(message)
+		signed = digest[:]
+	case minisignAlgEd:
+	default:
+		return "", errors.New("unsupported signature algorithm")
+	}
+
+	if !ed25519.Verify(k.Key, signed, sig) {
+		return "", errors.New("signature verification failed")
+	}
+	if !ed25519.Verify(k.Key, append(append([]byte(nil), sig...), trusted...), globalSig) {
+		return "", errors.New("trusted comment verification failed")
+	}
+	return trusted, nil
+}
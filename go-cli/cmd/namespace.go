@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/viper"
+)
+
+// namespaceRe matches a valid namespace: a single path segment.
+var namespaceRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// checkNamespace reports whether ns is a valid namespace, or empty.
+func checkNamespace(ns string) error {
+	if ns != "" && !namespaceRe.MatchString(ns) {
+		return fmt.Errorf("invalid namespace %q: use letters, digits, '.', '-', and '_'", ns)
+	}
+	return nil
+}
+
+// namespaced returns dir, or with a namespace, <dir>/<namespace>.
+func namespaced(dir, ns string) string {
+	if ns == "" {
+		return dir
+	}
+	return filepath.Join(dir, ns)
+}
+
+// outputDir returns the output directory of c's tenant: the output
+// directory, or with a namespace, <output>/<namespace>. The manifest,
+// locks, and default crawl state files are kept there, so tenants sharing
+// one installation keep separate skill caches.
+func (c *Config) outputDir() string {
+	return namespaced(c.Output, c.Namespace)
+}
+
+// statePath returns where a crawl state file is kept: path, moved into a
+// directory named after the namespace when there is one, or name in the
+// output directory when path is empty.
+func (c *Config) statePath(path, name string) string {
+	if path == "" {
+		return filepath.Join(c.outputDir(), name)
+	}
+	if c.Namespace == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(path), c.Namespace, filepath.Base(path))
+}
+
+// redisConfig returns the Redis settings of c with the namespace added to
+// the key prefix, so tenants sharing one Redis keep separate keys.
+func (c *Config) redisConfig() RedisConfig {
+	r := c.Redis
+	if c.Namespace == "" {
+		return r
+	}
+	if r.Prefix == "" {
+		r.Prefix = defaultRedisPrefix
+	}
+	r.Prefix += ":" + c.Namespace
+	return r
+}
+
+// configuredOutput returns the output directory set by flags and config,
+// under the namespace, for commands that read the output without a crawl
+// config.
+func configuredOutput() string {
+	return namespaced(viper.GetString("output"), viper.GetString("namespace"))
+}
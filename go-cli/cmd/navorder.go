@@ -0,0 +1,198 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// defaultNavSelector matches the site navigation menus of common
+// documentation themes.
+const defaultNavSelector = `nav, [role="navigation"], aside, .sidebar, .sidenav, .side-nav`
+
+// recordNavOrder writes each page's position in its site's navigation to
+// the order frontmatter field, numbering the pages of each skill from 1.
+// The menus of different pages are merged, so pages that a collapsed
+// sidebar only lists on some pages still fall between their neighbours.
+// Pages missing from the navigation get no order.
+func recordNavOrder(opts *CrawlOptions, m *Manifest, cfg NavOrderConfig) {
+	selector := cfg.Selector
+	if selector == "" {
+		selector = defaultNavSelector
+	}
+
+	// Links are matched to pages the way merge matches them; a section
+	// saved on its own shares its page's link and is left out.
+	skills := map[string]map[string]*ManifestEntry{}
+	for _, entry := range m.Pages {
+		skill := opts.skillDirOf(entry)
+		if skills[skill] == nil {
+			skills[skill] = map[string]*ManifestEntry{}
+		}
+		key := normalizeLink(entry.URL)
+		if other, ok := skills[skill][key]; !ok || len(entry.URL) < len(other.URL) {
+			skills[skill][key] = entry
+		}
+	}
+
+	changed := 0
+	for _, byURL := range skills {
+		var menus [][]string
+		for _, link := range sortedKeys(byURL) {
+			if menu := navLinks(opts, byURL[link], selector, byURL); len(menu) > 0 {
+				menus = append(menus, menu)
+			}
+		}
+		order := mergeNavMenus(menus)
+		for link, entry := range byURL {
+			updated, err := setPageOrder(opts, m, entry, order[link])
+			if err != nil {
+				fmt.Printf("Error recording nav order of %s: %v\n", entry.Path, err)
+				continue
+			}
+			if updated {
+				changed++
+			}
+		}
+	}
+	if changed > 0 {
+		fmt.Printf("Updated the nav order of %d pages\n", changed)
+	}
+}
+
+// navLinks returns the pages of a skill linked from the navigation menus
+// of a page's saved HTML, as normalized URLs in document order. Breadcrumb
+// trails are not menus.
+func navLinks(opts *CrawlOptions, entry *ManifestEntry, selector string, pages map[string]*ManifestEntry) []string {
+	html, err := opts.readSavedHTML(entry)
+	if err != nil {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil
+	}
+	base, err := url.Parse(entry.URL)
+	if err != nil {
+		return nil
+	}
+
+	var links []string
+	seen := map[string]bool{}
+	doc.Find(selector).Not(breadcrumbSelector).Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		if a.Closest(breadcrumbSelector).Length() > 0 {
+			return
+		}
+		ref, err := url.Parse(a.AttrOr("href", ""))
+		if err != nil {
+			return
+		}
+		link := normalizeLink(base.ResolveReference(ref).String())
+		if _, ok := pages[link]; ok && !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	})
+	return links
+}
+
+// mergeNavMenus merges menus into one reading order and returns each
+// link's 1-based position in it. The longest menu sets the order; a link
+// only another menu lists is placed after the link before it there.
+func mergeNavMenus(menus [][]string) map[string]int {
+	sort.SliceStable(menus, func(i, j int) bool {
+		return len(menus[i]) > len(menus[j])
+	})
+	var order []string
+	pos := map[string]int{}
+	for _, menu := range menus {
+		prev := -1
+		for _, link := range menu {
+			if i, ok := pos[link]; ok {
+				prev = i
+				continue
+			}
+			prev++
+			order = append(order, "")
+			copy(order[prev+1:], order[prev:])
+			order[prev] = link
+			for i := prev; i < len(order); i++ {
+				pos[order[i]] = i
+			}
+		}
+	}
+	for link, i := range pos {
+		pos[link] = i + 1
+	}
+	return pos
+}
+
+// setPageOrder sets the order field of a page, removing it when order is
+// 0, and reports whether the file changed.
+func setPageOrder(opts *CrawlOptions, m *Manifest, entry *ManifestEntry, order int) (bool, error) {
+	path := opts.pageFile(entry)
+	data, err := readOutputFile(path)
+	if err != nil {
+		return false, nil
+	}
+	fmText, body := splitFrontmatter(string(data))
+	fm, err := parseFrontmatter(fmText)
+	if err != nil {
+		return false, err
+	}
+	if old, _ := fm.get("order"); old == order || old == nil && order == 0 {
+		return false, nil
+	}
+	if order > 0 {
+		fm.setAfter("description", "order", order)
+	} else {
+		fm.remove("order")
+	}
+	content := fm.render() + body
+	if err := writeFileIfChanged(path, []byte(content)); err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	entry.FileHash = contentHash(content)
+	m.touch(entry.URL)
+	m.mu.Unlock()
+	return true, nil
+}
+
+// pageOrder returns the nav order recorded in a page's frontmatter, or 0.
+func pageOrder(meta pageMeta) int {
+	order, _ := meta.Fields["order"].(int)
+	return order
+}
+
+// orderBefore reports whether a page with nav order a comes before one
+// with nav order b: pages with an order come first, lowest first. ok is
+// false when neither has one, or both the same, and the caller's fallback
+// order decides.
+func orderBefore(a, b int) (before, ok bool) {
+	switch {
+	case a == b:
+		return false, false
+	case a == 0 || b == 0:
+		return a != 0, true
+	default:
+		return a < b, true
+	}
+}
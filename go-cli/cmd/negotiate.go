@@ -0,0 +1,176 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// markdownAccept is the Accept header sent with raw_markdown, so servers
+// that can answer with the page's markdown source prefer it to HTML.
+const markdownAccept = "text/markdown, text/html;q=0.9, */*;q=0.8"
+
+// isMarkdownResponse reports whether a response is markdown: served as
+// text/markdown, or as plain text from a .md or .markdown path. Bodies
+// that start like an HTML document are not, whatever their type says.
+func isMarkdownResponse(u *url.URL, contentType string, body []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/markdown", "text/x-markdown":
+	case "text/plain":
+		if ext := strings.ToLower(path.Ext(u.Path)); ext != ".md" && ext != ".markdown" {
+			return false
+		}
+	default:
+		return false
+	}
+	head := strings.ToLower(strings.TrimSpace(string(body[:min(len(body), 512)])))
+	return !strings.HasPrefix(head, "<!doctype html") && !strings.HasPrefix(head, "<html")
+}
+
+// sourceLinkRe matches the target of an inline link or image, a link
+// reference definition, or an autolink.
+var sourceLinkRe = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)|(?m)^ {0,3}\[[^\]]+\]:\s*<?([^\s>]+)|<(https?://[^\s>]+)>`)
+
+// markdownLinks returns the link targets in a markdown body outside code
+// blocks, so documentation source responses are followed like HTML pages.
+func markdownLinks(body string) []string {
+	var prose []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			prose = append(prose, line)
+		}
+	}
+	var links []string
+	for _, m := range sourceLinkRe.FindAllStringSubmatch(strings.Join(prose, "\n"), -1) {
+		links = append(links, m[1]+m[2]+m[3])
+	}
+	return links
+}
+
+// sourceConverter returns the converter for a response that is a
+// documentation source rather than HTML: a file fetched raw from a forge,
+// or with raw_markdown, a page served as markdown. Raw notebooks are left
+// to saveNotebook.
+func sourceConverter(r *colly.Response, opts *CrawlOptions) (func(data []byte, opts *CrawlOptions) (sourceDoc, error), bool) {
+	if r.Headers.Get(forgeRawHeader) != "" {
+		ext := strings.ToLower(path.Ext(r.Request.URL.Path))
+		convert, ok := sourceConverters[ext]
+		return convert, ok && ext != ".ipynb"
+	}
+	if opts.rawMarkdown && isMarkdownResponse(r.Request.URL, r.Headers.Get("Content-Type"), r.Body) {
+		return convertMarkdownSource, true
+	}
+	return nil, false
+}
+
+// saveSourceResponse writes a documentation source response converted
+// with convert instead of from HTML: markdown keeps its body, its
+// frontmatter is replaced by the generated one, and a leading H1 becomes
+// the title. No raw response is saved, since the source is at hand.
+func saveSourceResponse(r *colly.Response, opts *CrawlOptions, convert func(data []byte, opts *CrawlOptions) (sourceDoc, error)) {
+	u := r.Request.URL
+	doc, err := convert(r.Body, opts)
+	if err != nil {
+		failPage(opts, ErrorConversion, u.String(), 0, fmt.Errorf("converting source: %w", err))
+		return
+	}
+	dirName, fullPath := opts.outputPath(u)
+	mdPath := opts.markdownPath(fullPath)
+	if fragment := u.Fragment; fragment != "" {
+		title, body, ok := markdownSection(doc.Body, fragment)
+		if !ok {
+			fmt.Printf("Warning: no section #%s on %s\n", fragment, u)
+			opts.Events.OnSkip(u.String(), "section not found")
+			return
+		}
+		doc = sourceDoc{Title: title, Body: body}
+		mdPath = opts.sectionPath(mdPath, fragment)
+	}
+	if doc.Title == "" {
+		doc.Title = strings.TrimSuffix(path.Base(u.Path), path.Ext(u.Path))
+	}
+	if doc.Title == "" || doc.Title == "/" {
+		doc.Title = "Untitled"
+	}
+	if doc.Description == "" {
+		doc.Description = firstParagraph(doc.Body)
+	}
+
+	if err := os.MkdirAll(dirName, 0755); err != nil {
+		failPage(opts, ErrorWrite, u.String(), 0, fmt.Errorf("creating dir %s: %w", dirName, err))
+		return
+	}
+	p := convertedPage{
+		URL:          u.String(),
+		Title:        doc.Title,
+		Description:  doc.Description,
+		Body:         doc.Body,
+		LastModified: responseLastModified(r),
+		CrawledAt:    responseCrawledAt(r),
+		Extraction:   opts.extractionFor(u.String()),
+		Restricted:   len(aiDirectives(r.Headers, nil)) > 0,
+	}
+	// Forges and docs hosts negotiating markdown render headings with
+	// GitHub-style anchors; a .md file served as is has none.
+	if ext := strings.ToLower(path.Ext(u.Path)); r.Headers.Get(forgeRawHeader) != "" || ext != ".md" && ext != ".markdown" {
+		p.Anchors = markdownAnchors(p.Body)
+	}
+	writePage(p, opts, dirName, mdPath)
+}
+
+// markdownSection returns the heading and content of the section of a
+// markdown body whose GitHub-style anchor is fragment, up to the next
+// heading of the same or a higher level.
+func markdownSection(body, fragment string) (string, string, bool) {
+	anchors := headingAnchors{}
+	var title string
+	var lines []string
+	level, inFence := 0, false
+	for _, line := range strings.Split(body, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+		}
+		if l, text, ok := atxHeading(line); ok && !inFence {
+			if level > 0 && l <= level {
+				break
+			}
+			if level == 0 && anchors.next(text) == fragment {
+				level, title = l, text
+				continue
+			}
+		}
+		if level > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if level == 0 {
+		return "", "", false
+	}
+	return title, strings.TrimSpace(strings.Join(lines, "\n")) + "\n", true
+}
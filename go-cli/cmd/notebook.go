@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// defaultNotebookOutputLines is how many lines of each cell output are kept.
+const defaultNotebookOutputLines = 20
+
+// notebook is the subset of the Jupyter nbformat 4 document we convert.
+type notebook struct {
+	Cells    []notebookCell `json:"cells"`
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Name       string                  `json:"name"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	EName      string                  `json:"ename"`
+	EValue     string                  `json:"evalue"`
+}
+
+// notebookText is multiline text, stored either as a string or as a list
+// of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = notebookText(s)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		// Non-text outputs (such as application/json) are ignored.
+		return nil
+	}
+	*t = notebookText(strings.Join(lines, ""))
+	return nil
+}
+
+// isNotebook reports whether a response is a Jupyter notebook.
+func isNotebook(u *url.URL, contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "ipynb") || strings.EqualFold(path.Ext(u.Path), ".ipynb")
+}
+
+// convertNotebook converts a notebook to markdown: markdown cells are kept
+// as is, code cells become fenced blocks in the kernel language, and text
+// outputs follow their cell. Images and other rich outputs are dropped.
+// The title is the first markdown heading, if any.
+func convertNotebook(data []byte, cfg NotebookConfig) (string, string, error) {
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", "", fmt.Errorf("parsing notebook: %w", err)
+	}
+	lang := nb.Metadata.LanguageInfo.Name
+	if lang == "" {
+		lang = nb.Metadata.KernelSpec.Language
+	}
+	maxLines := cfg.MaxOutputLines
+	if maxLines <= 0 {
+		maxLines = defaultNotebookOutputLines
+	}
+
+	var title string
+	var b strings.Builder
+	for _, cell := range nb.Cells {
+		source := strings.TrimSpace(string(cell.Source))
+		if source == "" {
+			continue
+		}
+		switch cell.CellType {
+		case "markdown":
+			if title == "" {
+				title, source = splitNotebookTitle(source)
+				if source == "" {
+					continue
+				}
+			}
+			b.WriteString(source + "\n\n")
+		case "code":
+			fmt.Fprintf(&b, "```%s\n%s\n```\n\n", lang, source)
+			if cfg.SkipOutputs {
+				continue
+			}
+			for _, out := range cell.Outputs {
+				if text := notebookOutputText(out); text != "" {
+					fmt.Fprintf(&b, "```text\n%s\n```\n\n", truncateLines(text, maxLines))
+				}
+			}
+		}
+	}
+	return title, strings.TrimSpace(b.String()) + "\n", nil
+}
+
+// splitNotebookTitle removes a leading H1 from a markdown cell and returns
+// it as the title.
+func splitNotebookTitle(source string) (string, string) {
+	first, rest, _ := strings.Cut(source, "\n")
+	if !strings.HasPrefix(first, "# ") {
+		return "", source
+	}
+	return strings.TrimSpace(strings.TrimPrefix(first, "# ")), strings.TrimSpace(rest)
+}
+
+// notebookOutputText returns the text shown for a cell output: streams,
+// plain-text results, and error summaries.
+func notebookOutputText(out notebookOutput) string {
+	var text string
+	switch out.OutputType {
+	case "stream":
+		if out.Name == "stderr" {
+			return ""
+		}
+		text = string(out.Text)
+	case "execute_result", "display_data":
+		for mime := range out.Data {
+			// The plain text of a figure is only its repr.
+			if strings.HasPrefix(mime, "image/") {
+				return ""
+			}
+		}
+		text = string(out.Data["text/plain"])
+	case "error":
+		text = out.EName + ": " + out.EValue
+	}
+	return strings.TrimRight(text, "\n")
+}
+
+// truncateLines keeps the first n lines of s.
+func truncateLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-n)
+}
+
+// saveNotebook converts a crawled notebook and writes it as a page.
+func saveNotebook(r *colly.Response, opts *CrawlOptions, dirName, fullPath string, ext ExtractionConfig) {
+	title, body, err := convertNotebook(r.Body, opts.notebooks)
+	if err != nil {
+		failPage(opts, ErrorConversion, r.Request.URL.String(), 0, fmt.Errorf("converting notebook: %w", err))
+		return
+	}
+	if title == "" {
+		title = strings.TrimSuffix(path.Base(r.Request.URL.Path), path.Ext(r.Request.URL.Path))
+	}
+	writePage(convertedPage{
+		URL:          r.Request.URL.String(),
+		Title:        title,
+		Description:  firstParagraph(body),
+		Body:         body,
+		LastModified: responseLastModified(r),
+		CrawledAt:    responseCrawledAt(r),
+		Extraction:   ext,
+	}, opts, dirName, opts.markdownPath(fullPath))
+}
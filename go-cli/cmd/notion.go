@@ -0,0 +1,640 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// notionVersion is the Notion API version requests are made against.
+const notionVersion = "2022-06-28"
+
+// notionAPI is the Notion API endpoint.
+var notionAPI = "https://api.notion.com/v1"
+
+type notionRichText struct {
+	PlainText   string `json:"plain_text"`
+	Href        string `json:"href"`
+	Annotations struct {
+		Bold          bool `json:"bold"`
+		Italic        bool `json:"italic"`
+		Strikethrough bool `json:"strikethrough"`
+		Code          bool `json:"code"`
+	} `json:"annotations"`
+}
+
+type notionParent struct {
+	Type       string `json:"type"`
+	PageID     string `json:"page_id"`
+	DatabaseID string `json:"database_id"`
+}
+
+// notionObject is a page or database as returned by the API.
+type notionObject struct {
+	Object         string                     `json:"object"`
+	ID             string                     `json:"id"`
+	URL            string                     `json:"url"`
+	LastEditedTime string                     `json:"last_edited_time"`
+	Parent         notionParent               `json:"parent"`
+	Properties     map[string]json.RawMessage `json:"properties"`
+	Title          []notionRichText           `json:"title"`
+	Description    []notionRichText           `json:"description"`
+}
+
+// notionBlock is a content block. Its type-specific fields are decoded
+// from Content, keyed by Type.
+type notionBlock struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	HasChildren bool   `json:"has_children"`
+	Content     map[string]json.RawMessage
+}
+
+func (b *notionBlock) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	json.Unmarshal(raw["id"], &b.ID)
+	json.Unmarshal(raw["type"], &b.Type)
+	json.Unmarshal(raw["has_children"], &b.HasChildren)
+	b.Content = raw
+	return nil
+}
+
+// notionBlockData is the common shape of a block's type-specific object.
+type notionBlockData struct {
+	RichText        []notionRichText   `json:"rich_text"`
+	Caption         []notionRichText   `json:"caption"`
+	Checked         bool               `json:"checked"`
+	Language        string             `json:"language"`
+	Title           string             `json:"title"`
+	URL             string             `json:"url"`
+	Expression      string             `json:"expression"`
+	HasColumnHeader bool               `json:"has_column_header"`
+	Cells           [][]notionRichText `json:"cells"`
+	Icon            struct {
+		Emoji string `json:"emoji"`
+	} `json:"icon"`
+	External struct {
+		URL string `json:"url"`
+	} `json:"external"`
+	File struct {
+		URL string `json:"url"`
+	} `json:"file"`
+}
+
+func (b *notionBlock) data() notionBlockData {
+	var d notionBlockData
+	json.Unmarshal(b.Content[b.Type], &d)
+	return d
+}
+
+// notionSource converts Notion pages and databases through the API.
+type notionSource struct {
+	api     *apiClient
+	visited map[string]bool
+}
+
+// ingestNotion converts the configured pages and databases, or, when none
+// are listed, everything shared with the integration. Child pages are
+// nested below their parent and databases are rendered as tables.
+func ingestNotion(cfg NotionSource, opts *CrawlOptions) error {
+	token, err := sourceToken(cfg.TokenEnv)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	header.Set("Notion-Version", notionVersion)
+	s := &notionSource{api: newAPIClient(header), visited: map[string]bool{}}
+
+	var roots []notionObject
+	for _, id := range cfg.Pages {
+		var page notionObject
+		if err := s.api.getJSON(notionAPI+"/pages/"+url.PathEscape(id), &page); err != nil {
+			return err
+		}
+		roots = append(roots, page)
+	}
+	for _, id := range cfg.Databases {
+		var db notionObject
+		if err := s.api.getJSON(notionAPI+"/databases/"+url.PathEscape(id), &db); err != nil {
+			return err
+		}
+		roots = append(roots, db)
+	}
+	if len(cfg.Pages) == 0 && len(cfg.Databases) == 0 {
+		if roots, err = s.search(); err != nil {
+			return err
+		}
+	}
+
+	// Objects nested in another shared object are written with their
+	// parent; database rows are part of the database's table.
+	shared := map[string]bool{}
+	for _, obj := range roots {
+		shared[normalizeNotionID(obj.ID)] = true
+	}
+	count := 0
+	for _, obj := range roots {
+		parent := normalizeNotionID(obj.Parent.PageID + obj.Parent.DatabaseID)
+		if parent != "" && shared[parent] {
+			continue
+		}
+		count += s.write(obj, nil, opts)
+	}
+	fmt.Printf("Converted %d Notion pages\n", count)
+	return nil
+}
+
+// search lists every page and database shared with the integration.
+func (s *notionSource) search() ([]notionObject, error) {
+	var objects []notionObject
+	body := map[string]interface{}{"page_size": 100}
+	for {
+		var res struct {
+			Results    []notionObject `json:"results"`
+			HasMore    bool           `json:"has_more"`
+			NextCursor string         `json:"next_cursor"`
+		}
+		if err := s.api.postJSON(notionAPI+"/search", body, &res); err != nil {
+			return nil, err
+		}
+		objects = append(objects, res.Results...)
+		if !res.HasMore {
+			return objects, nil
+		}
+		body["start_cursor"] = res.NextCursor
+	}
+}
+
+// write converts a page or database and its child pages, returning how
+// many pages were written. parents are the path segments above it.
+func (s *notionSource) write(obj notionObject, parents []string, opts *CrawlOptions) int {
+	id := normalizeNotionID(obj.ID)
+	if s.visited[id] {
+		return 0
+	}
+	s.visited[id] = true
+
+	title := obj.title()
+	segments := append(append([]string{}, parents...), toPathCase(title))
+	var body string
+	var children []notionObject
+	var err error
+	if obj.Object == "database" {
+		body, err = s.databaseTable(obj)
+	} else {
+		body, children, err = s.pageBody(obj.ID)
+	}
+	if err != nil {
+		fmt.Printf("Error converting Notion page %s: %v\n", title, err)
+		return 0
+	}
+
+	out := &url.URL{Scheme: "https", Host: "notion.so", Path: "/" + path.Join(segments...)}
+	if _, err := writeSourcePage(opts, out, convertedPage{
+		URL:          obj.URL,
+		Title:        title,
+		Description:  notionPlainText(obj.Description),
+		Body:         body,
+		LastModified: obj.LastEditedTime,
+	}); err != nil {
+		fmt.Printf("Error writing Notion page %s: %v\n", title, err)
+		return 0
+	}
+	count := 1
+	for _, child := range children {
+		count += s.write(child, segments, opts)
+	}
+	return count
+}
+
+// title returns the title of a page (its title property) or database.
+func (obj notionObject) title() string {
+	if obj.Object == "database" {
+		if t := notionPlainText(obj.Title); t != "" {
+			return t
+		}
+		return "Untitled"
+	}
+	for _, raw := range obj.Properties {
+		var prop struct {
+			Type  string           `json:"type"`
+			Title []notionRichText `json:"title"`
+		}
+		if json.Unmarshal(raw, &prop) == nil && prop.Type == "title" {
+			if t := notionPlainText(prop.Title); t != "" {
+				return t
+			}
+		}
+	}
+	return "Untitled"
+}
+
+// children lists the child blocks of a page or block.
+func (s *notionSource) children(id string) ([]notionBlock, error) {
+	var blocks []notionBlock
+	cursor := ""
+	for {
+		link := notionAPI + "/blocks/" + url.PathEscape(id) + "/children?page_size=100"
+		if cursor != "" {
+			link += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+		var res struct {
+			Results    []notionBlock `json:"results"`
+			HasMore    bool          `json:"has_more"`
+			NextCursor string        `json:"next_cursor"`
+		}
+		if err := s.api.getJSON(link, &res); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, res.Results...)
+		if !res.HasMore {
+			return blocks, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+// pageBody converts a page's blocks to markdown and returns the child
+// pages and databases it contains.
+func (s *notionSource) pageBody(id string) (string, []notionObject, error) {
+	blocks, err := s.children(id)
+	if err != nil {
+		return "", nil, err
+	}
+	var children []notionObject
+	md, err := s.renderBlocks(blocks, &children)
+	return md + "\n", children, err
+}
+
+// renderBlocks converts blocks to markdown. Consecutive list items are
+// kept together as one list.
+func (s *notionSource) renderBlocks(blocks []notionBlock, children *[]notionObject) (string, error) {
+	var out []string
+	for i, b := range blocks {
+		text, err := s.renderBlock(b, children)
+		if err != nil {
+			return "", err
+		}
+		if text == "" {
+			continue
+		}
+		if i > 0 && len(out) > 0 && isNotionListItem(b.Type) && blocks[i-1].Type == b.Type {
+			out[len(out)-1] += "\n" + text
+			continue
+		}
+		out = append(out, text)
+	}
+	return strings.Join(out, "\n\n"), nil
+}
+
+func isNotionListItem(t string) bool {
+	return t == "bulleted_list_item" || t == "numbered_list_item" || t == "to_do"
+}
+
+// renderBlock converts one block and its children.
+func (s *notionSource) renderBlock(b notionBlock, children *[]notionObject) (string, error) {
+	d := b.data()
+	text := notionMarkdown(d.RichText)
+
+	var nested string
+	if b.HasChildren && b.Type != "child_page" && b.Type != "child_database" {
+		blocks, err := s.children(b.ID)
+		if err != nil {
+			return "", err
+		}
+		if b.Type == "table" {
+			return notionTable(blocks, d.HasColumnHeader), nil
+		}
+		if nested, err = s.renderBlocks(blocks, children); err != nil {
+			return "", err
+		}
+	}
+	withNested := func(s string, indent int) string {
+		if nested == "" {
+			return s
+		}
+		sep := "\n\n"
+		if isNotionListItem(b.Type) {
+			// Keep nested lists tight.
+			sep = "\n"
+		}
+		return s + sep + strings.Repeat(" ", indent) + indentMarkdown(nested, indent)
+	}
+
+	switch b.Type {
+	case "paragraph":
+		return withNested(text, 0), nil
+	case "heading_1", "heading_2", "heading_3":
+		level := int(b.Type[len(b.Type)-1]-'0') + 1
+		return withNested(strings.Repeat("#", level)+" "+text, 0), nil
+	case "bulleted_list_item":
+		return withNested("- "+text, 2), nil
+	case "numbered_list_item":
+		return withNested("1. "+text, 3), nil
+	case "to_do":
+		box := "[ ]"
+		if d.Checked {
+			box = "[x]"
+		}
+		return withNested("- "+box+" "+text, 2), nil
+	case "toggle":
+		return withNested("**"+text+"**", 0), nil
+	case "quote":
+		return quoteMarkdown(withNested(text, 0)), nil
+	case "callout":
+		label := strings.TrimSpace(d.Icon.Emoji + " " + text)
+		return quoteMarkdown(withNested(label, 0)), nil
+	case "code":
+		code := notionPlainText(d.RichText)
+		return fence(d.Language, strings.Split(code, "\n")), nil
+	case "equation":
+		return "$$\n" + d.Expression + "\n$$", nil
+	case "divider":
+		return "---", nil
+	case "image":
+		src := d.File.URL + d.External.URL
+		return "![" + notionPlainText(d.Caption) + "](" + src + ")", nil
+	case "bookmark", "embed", "link_preview", "video", "pdf", "file":
+		link := d.URL + d.File.URL + d.External.URL
+		if link == "" {
+			return "", nil
+		}
+		label := notionPlainText(d.Caption)
+		if label == "" {
+			label = link
+		}
+		return "[" + label + "](" + link + ")", nil
+	case "child_page", "child_database":
+		obj, err := s.object(b)
+		if err != nil {
+			return "", err
+		}
+		*children = append(*children, obj)
+		return "- " + d.Title, nil
+	case "column_list", "column", "synced_block", "template":
+		return nested, nil
+	}
+	// table_of_contents, breadcrumb, and unsupported blocks.
+	return nested, nil
+}
+
+// object fetches the page or database a child block stands for.
+func (s *notionSource) object(b notionBlock) (notionObject, error) {
+	var obj notionObject
+	kind := "pages"
+	if b.Type == "child_database" {
+		kind = "databases"
+	}
+	err := s.api.getJSON(notionAPI+"/"+kind+"/"+url.PathEscape(b.ID), &obj)
+	return obj, err
+}
+
+// notionTable renders a simple table block from its table_row children.
+func notionTable(rows []notionBlock, header bool) string {
+	var cells [][]string
+	for _, row := range rows {
+		var line []string
+		for _, cell := range row.data().Cells {
+			line = append(line, notionMarkdown(cell))
+		}
+		cells = append(cells, line)
+	}
+	if !header && len(cells) > 0 {
+		// Markdown tables need a header row.
+		cells = append([][]string{make([]string, len(cells[0]))}, cells...)
+	}
+	return markdownTable(cells)
+}
+
+// databaseTable renders a database's rows as a table: the title column
+// first, then the other properties by name.
+func (s *notionSource) databaseTable(db notionObject) (string, error) {
+	var rows []notionObject
+	body := map[string]interface{}{"page_size": 100}
+	for {
+		var res struct {
+			Results    []notionObject `json:"results"`
+			HasMore    bool           `json:"has_more"`
+			NextCursor string         `json:"next_cursor"`
+		}
+		if err := s.api.postJSON(notionAPI+"/databases/"+url.PathEscape(db.ID)+"/query", body, &res); err != nil {
+			return "", err
+		}
+		rows = append(rows, res.Results...)
+		if !res.HasMore {
+			break
+		}
+		body["start_cursor"] = res.NextCursor
+	}
+
+	var titleColumn string
+	var columns []string
+	for name, raw := range db.Properties {
+		var prop struct {
+			Type string `json:"type"`
+		}
+		json.Unmarshal(raw, &prop)
+		if prop.Type == "title" {
+			titleColumn = name
+		} else {
+			columns = append(columns, name)
+		}
+	}
+	sort.Strings(columns)
+	if titleColumn != "" {
+		columns = append([]string{titleColumn}, columns...)
+	}
+
+	table := [][]string{columns}
+	for _, row := range rows {
+		var line []string
+		for _, col := range columns {
+			line = append(line, notionPropertyText(row.Properties[col]))
+		}
+		table = append(table, line)
+	}
+	var parts []string
+	if d := notionMarkdown(db.Description); d != "" {
+		parts = append(parts, d)
+	}
+	parts = append(parts, markdownTable(table))
+	return strings.Join(parts, "\n\n") + "\n", nil
+}
+
+// notionPropertyText renders a database property value as text.
+func notionPropertyText(raw json.RawMessage) string {
+	var p struct {
+		Type        string           `json:"type"`
+		Title       []notionRichText `json:"title"`
+		RichText    []notionRichText `json:"rich_text"`
+		Number      *float64         `json:"number"`
+		Checkbox    bool             `json:"checkbox"`
+		URL         string           `json:"url"`
+		Email       string           `json:"email"`
+		PhoneNumber string           `json:"phone_number"`
+		Select      *struct {
+			Name string `json:"name"`
+		} `json:"select"`
+		Status *struct {
+			Name string `json:"name"`
+		} `json:"status"`
+		MultiSelect []struct {
+			Name string `json:"name"`
+		} `json:"multi_select"`
+		People []struct {
+			Name string `json:"name"`
+		} `json:"people"`
+		Files []struct {
+			Name string `json:"name"`
+		} `json:"files"`
+		Date *struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		} `json:"date"`
+		CreatedTime    string          `json:"created_time"`
+		LastEditedTime string          `json:"last_edited_time"`
+		Formula        json.RawMessage `json:"formula"`
+	}
+	if json.Unmarshal(raw, &p) != nil {
+		return ""
+	}
+	var names []string
+	switch p.Type {
+	case "title":
+		return notionMarkdown(p.Title)
+	case "rich_text":
+		return notionMarkdown(p.RichText)
+	case "number":
+		if p.Number != nil {
+			return fmt.Sprint(*p.Number)
+		}
+	case "checkbox":
+		if p.Checkbox {
+			return "Yes"
+		}
+		return "No"
+	case "url":
+		return p.URL
+	case "email":
+		return p.Email
+	case "phone_number":
+		return p.PhoneNumber
+	case "select":
+		if p.Select != nil {
+			return p.Select.Name
+		}
+	case "status":
+		if p.Status != nil {
+			return p.Status.Name
+		}
+	case "multi_select":
+		for _, o := range p.MultiSelect {
+			names = append(names, o.Name)
+		}
+	case "people":
+		for _, o := range p.People {
+			names = append(names, o.Name)
+		}
+	case "files":
+		for _, o := range p.Files {
+			names = append(names, o.Name)
+		}
+	case "date":
+		if p.Date != nil {
+			if p.Date.End != "" {
+				return p.Date.Start + " – " + p.Date.End
+			}
+			return p.Date.Start
+		}
+	case "created_time":
+		return p.CreatedTime
+	case "last_edited_time":
+		return p.LastEditedTime
+	case "formula":
+		var f struct {
+			Type    string   `json:"type"`
+			String  string   `json:"string"`
+			Number  *float64 `json:"number"`
+			Boolean bool     `json:"boolean"`
+		}
+		json.Unmarshal(p.Formula, &f)
+		switch f.Type {
+		case "string":
+			return f.String
+		case "number":
+			if f.Number != nil {
+				return fmt.Sprint(*f.Number)
+			}
+		case "boolean":
+			return fmt.Sprint(f.Boolean)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// notionMarkdown renders rich text with its annotations and links.
+func notionMarkdown(texts []notionRichText) string {
+	var b strings.Builder
+	for _, t := range texts {
+		s := t.PlainText
+		if strings.TrimSpace(s) == "" {
+			b.WriteString(s)
+			continue
+		}
+		a := t.Annotations
+		switch {
+		case a.Code:
+			s = codeSpan(s)
+		case a.Bold && a.Italic:
+			s = "***" + s + "***"
+		case a.Bold:
+			s = "**" + s + "**"
+		case a.Italic:
+			s = "*" + s + "*"
+		}
+		if a.Strikethrough {
+			s = "~~" + s + "~~"
+		}
+		if t.Href != "" {
+			s = "[" + s + "](" + t.Href + ")"
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// notionPlainText joins rich text without formatting.
+func notionPlainText(texts []notionRichText) string {
+	var b strings.Builder
+	for _, t := range texts {
+		b.WriteString(t.PlainText)
+	}
+	return b.String()
+}
+
+// normalizeNotionID strips the dashes Notion IDs may be written with.
+func normalizeNotionID(id string) string {
+	return strings.ReplaceAll(id, "-", "")
+}
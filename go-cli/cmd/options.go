@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CrawlOptions says where and how a crawl writes its output, and holds
+// the state of the crawl. It is built once per crawl and passed down the
+// pipeline, so crawls in one process do not share state.
+type CrawlOptions struct {
+	// Output is the output directory.
+	Output string
+	// Flat saves every page in its own top-level directory.
+	Flat bool
+	// Rename is the file name given to every markdown file, if set.
+	Rename string
+	// Canonical saves pages under their canonical URL.
+	Canonical bool
+	// CategoryDirs saves pages under a directory named after their first
+	// category.
+	CategoryDirs bool
+	// Events receives the crawl's progress.
+	Events CrawlEvents
+
+	// extraction and scopes are the content extraction settings, and
+	// categories the compiled category rules.
+	extraction ExtractionConfig
+	scopes     []compiledScope
+	categories []categoryRule
+	// transcripts fetches video captions when transcripts are enabled.
+	transcripts *transcriptClient
+	notebooks   NotebookConfig
+	mdx         MDXConfig
+	// rawMarkdown saves pages served as markdown from their markdown
+	// instead of skipping them.
+	rawMarkdown bool
+
+	// profile identifies the configuration used, for provenance.
+	profile string
+	// manifest tracks every page written during the crawl, and report
+	// the pages it excluded.
+	manifest *Manifest
+	report   *CrawlReport
+	// texts remembers the shingles of every page kept, so near-duplicates
+	// of an earlier page can be recognised.
+	texts *pageTexts
+	// changes collects the crawl's changes when change summaries are
+	// enabled.
+	changes *changeSet
+	// tracer traces the crawl and log records its events, when configured.
+	tracer *tracer
+	log    *eventLog
+	// screenshots holds screenshots captured by the browser fetcher, keyed
+	// by URL, until saveResponse writes them next to the page's markdown.
+	screenshots *sync.Map
+}
+
+// newCrawlOptions returns the output options of cfg, with an empty
+// manifest and report for the crawl to fill.
+func newCrawlOptions(cfg *Config) *CrawlOptions {
+	return &CrawlOptions{
+		Output:       cfg.outputDir(),
+		Flat:         cfg.Flat,
+		Rename:       cfg.FileRename,
+		Canonical:    cfg.Canonical,
+		CategoryDirs: cfg.CategoryDirs,
+		Events:       NopEvents{},
+		profile:      cfg.profile,
+		manifest:     &Manifest{Pages: map[string]*ManifestEntry{}},
+		report:       &CrawlReport{},
+		texts:        newPageTexts(),
+		screenshots:  &sync.Map{},
+	}
+}
+
+// extractionFor returns the extraction settings for link.
+func (o *CrawlOptions) extractionFor(link string) ExtractionConfig {
+	return resolveExtraction(o.extraction, o.scopes, link)
+}
+
+// outputPath returns the directory and file path the page at u is saved to.
+func (o *CrawlOptions) outputPath(u *url.URL) (string, string) {
+	return getOutputPath(u, o.Output, o.Flat, o.Rename)
+}
+
+// markdownPath returns where the markdown for a page saved at fullPath is
+// written. A page at a .md path is written over its own path, so no raw
+// copy of it is kept.
+func (o *CrawlOptions) markdownPath(fullPath string) string {
+	if o.Rename != "" {
+		return filepath.Join(filepath.Dir(fullPath), o.Rename)
+	}
+	for _, ext := range []string{".html", ".ipynb", ".markdown", ".md"} {
+		if strings.HasSuffix(fullPath, ext) {
+			return strings.TrimSuffix(fullPath, ext) + ".md"
+		}
+	}
+	return fullPath + ".md"
+}
+
+// pageFile returns the markdown file of a page. Inlined pages are read
+// from the copy kept under inlinedDirName until they are saved again.
+func (o *CrawlOptions) pageFile(entry *ManifestEntry) string {
+	path := filepath.Join(o.Output, filepath.FromSlash(entry.Path))
+	if entry.InlinedIn != "" {
+		if _, err := statOutputFile(path); err != nil {
+			return filepath.Join(o.Output, inlinedDirName, filepath.FromSlash(entry.Path))
+		}
+	}
+	return path
+}
+
+// readSavedHTML reads the HTML saved next to a page's markdown.
+func (o *CrawlOptions) readSavedHTML(entry *ManifestEntry) ([]byte, error) {
+	u, err := url.Parse(entry.URL)
+	if err != nil {
+		return nil, err
+	}
+	if isNotebook(u, "") {
+		return nil, fmt.Errorf("%s is a notebook, not HTML", entry.URL)
+	}
+	_, htmlPath := o.outputPath(u)
+	return readOutputFile(htmlPath)
+}
+
+// skillDirOf returns the skill a page belongs to: the top-level output
+// directory of its path, or "." for flat output.
+func (o *CrawlOptions) skillDirOf(entry *ManifestEntry) string {
+	if o.Flat {
+		return "."
+	}
+	return strings.SplitN(entry.Path, "/", 2)[0]
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gobwas/glob"
+	"github.com/gocolly/colly/v2"
+)
+
+// Kinds of pages that are served successfully but are not content.
+const (
+	pageSoftNotFound = "soft_404"
+	pageLoginWall    = "login_wall"
+)
+
+// defaultNotFoundPhrases mark a soft 404 when found in the title or first
+// heading.
+var defaultNotFoundPhrases = []string{
+	"page not found",
+	"404 not found",
+	"error 404",
+	"404 error",
+	"page does not exist",
+	"page doesn't exist",
+	"page could not be found",
+	"page no longer exists",
+}
+
+// defaultLoginPhrases mark a login wall when found in the title or first
+// heading of a page with a password field.
+var defaultLoginPhrases = []string{
+	"sign in",
+	"sign-in",
+	"log in",
+	"login",
+}
+
+// defaultLoginURLs match login pages that protected pages redirect to.
+var defaultLoginURLs = []string{
+	"*/login*",
+	"*/signin*",
+	"*/sign-in*",
+	"*/sign_in*",
+	"*/auth/*",
+	"*/sso/*",
+	"*://accounts.google.com/*",
+	"*://github.com/session*",
+}
+
+// pageChecker classifies fetched pages as content, soft 404s, or login walls.
+type pageChecker struct {
+	notFound          []string
+	canonicalMismatch bool
+	login             []string
+	loginURLs         []glob.Glob
+}
+
+// newPageChecker compiles the status policies from the config.
+func newPageChecker(cfg StatusConfig) (*pageChecker, error) {
+	p := &pageChecker{
+		notFound:          lowerAll(cfg.NotFoundPhrases),
+		canonicalMismatch: cfg.CanonicalMismatch,
+		login:             lowerAll(cfg.LoginPhrases),
+	}
+	if len(p.notFound) == 0 {
+		p.notFound = defaultNotFoundPhrases
+	}
+	if len(p.login) == 0 {
+		p.login = defaultLoginPhrases
+	}
+	patterns := cfg.LoginURLs
+	if len(patterns) == 0 {
+		patterns = defaultLoginURLs
+	}
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid login URL glob %s: %w", pattern, err)
+		}
+		p.loginURLs = append(p.loginURLs, g)
+	}
+	return p, nil
+}
+
+// classify returns the kind of a page fetched from requested that ended up
+// at final, and why. Content pages return an empty kind.
+func (p *pageChecker) classify(requested, final *url.URL, doc *goquery.Document) (string, string) {
+	if requested.String() != final.String() {
+		for _, g := range p.loginURLs {
+			if g.Match(final.String()) {
+				return pageLoginWall, "redirected to " + final.String()
+			}
+		}
+	}
+
+	title := strings.ToLower(squash(doc.Find("title").First().Text()))
+	heading := strings.ToLower(squash(doc.Find("h1").First().Text()))
+
+	if doc.Find(`input[type="password"]`).Length() > 0 {
+		if phrase := findPhrase(p.login, title, heading); phrase != "" {
+			return pageLoginWall, fmt.Sprintf("login form (%q)", phrase)
+		}
+	}
+
+	if title == "404" || title == "not found" || heading == "404" || heading == "not found" {
+		return pageSoftNotFound, fmt.Sprintf("title %q", title)
+	}
+	if phrase := findPhrase(p.notFound, title, heading); phrase != "" {
+		return pageSoftNotFound, fmt.Sprintf("contains %q", phrase)
+	}
+
+	if p.canonicalMismatch {
+		if href, ok := doc.Find(`link[rel="canonical"]`).Attr("href"); ok && href != "" {
+			if canonical, err := final.Parse(href); err == nil && cleanPath(canonical.Path) != cleanPath(final.Path) {
+				return pageSoftNotFound, "canonical URL is " + canonical.String()
+			}
+		}
+	}
+	return "", ""
+}
+
+// checkResponse classifies an HTML response. Other content types are
+// never excluded.
+func (p *pageChecker) checkResponse(requested *url.URL, r *colly.Response) (string, string) {
+	if !strings.Contains(strings.ToLower(r.Headers.Get("Content-Type")), "text/html") {
+		return "", ""
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(r.Body))
+	if err != nil {
+		return "", ""
+	}
+	return p.classify(requested, r.Request.URL, doc)
+}
+
+// excludePage reports a page left out of the output and removes any copy
+// an earlier crawl saved.
+func excludePage(opts *CrawlOptions, kind string, requested, final *url.URL, reason string) {
+	entry := ReportEntry{URL: requested.String(), Reason: reason}
+	if final.String() != requested.String() {
+		entry.FinalURL = final.String()
+	}
+	opts.report.add(kind, entry)
+	opts.Events.OnSkip(requested.String(), reason)
+
+	if old, ok := opts.manifest.lookup(requested.String()); ok {
+		opts.changes.record(pageRemoved, old.URL, old.Title, old.Path, "", "")
+		removeOutputFile(filepath.Join(opts.Output, filepath.FromSlash(old.Path)))
+		_, htmlPath := opts.outputPath(requested)
+		removeOutputFile(htmlPath)
+		opts.manifest.remove(requested.String(), reason)
+	}
+}
+
+// findPhrase returns the first phrase contained in any of the texts.
+func findPhrase(phrases []string, texts ...string) string {
+	for _, phrase := range phrases {
+		for _, text := range texts {
+			if text != "" && strings.Contains(text, phrase) {
+				return phrase
+			}
+		}
+	}
+	return ""
+}
+
+// cleanPath normalizes a URL path so "/a/", "/a" and "/a/index.html" compare
+// equal.
+func cleanPath(p string) string {
+	p = strings.TrimSuffix(p, "index.html")
+	if p == "" {
+		return "/"
+	}
+	return path.Clean(p)
+}
+
+func lowerAll(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		out = append(out, strings.ToLower(s))
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// patchListName is the file in a patch bundle listing its changes.
+const patchListName = "patch.json"
+
+// patchList describes a patch bundle: the generations it goes between,
+// the files it adds and changes (included in the bundle, with their
+// hashes), and the files to delete.
+type patchList struct {
+	From      int               `json:"from"`
+	To        int               `json:"to"`
+	CreatedAt time.Time         `json:"created_at"`
+	Added     []string          `json:"added"`
+	Changed   []string          `json:"changed"`
+	Deleted   []string          `json:"deleted"`
+	Hashes    map[string]string `json:"hashes"`
+}
+
+// diffGenerations lists the changes from the files of base to files.
+func diffGenerations(base *generationSnapshot, to int, files map[string]string) *patchList {
+	p := &patchList{
+		From:      base.Generation,
+		To:        to,
+		CreatedAt: time.Now().UTC(),
+		Added:     []string{},
+		Changed:   []string{},
+		Deleted:   []string{},
+		Hashes:    map[string]string{},
+	}
+	for _, path := range sortedKeys(files) {
+		old, ok := base.Files[path]
+		switch {
+		case !ok:
+			p.Added = append(p.Added, path)
+		case old != files[path]:
+			p.Changed = append(p.Changed, path)
+		default:
+			continue
+		}
+		p.Hashes[path] = files[path]
+	}
+	for _, path := range sortedKeys(base.Files) {
+		if _, ok := files[path]; !ok {
+			p.Deleted = append(p.Deleted, path)
+		}
+	}
+	return p
+}
+
+// writePatch writes a gzipped tar of the added and changed files below
+// outDir, the current manifest, and the patch list to out.
+func writePatch(out, outDir string, p *patchList) error {
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	list, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: patchListName, Mode: 0644, Size: int64(len(list) + 1), ModTime: p.CreatedAt}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(append(list, '\n')); err != nil {
+		return err
+	}
+	files := append(append([]string{}, p.Added...), p.Changed...)
+	for _, path := range append(files, manifestFileName) {
+		data, err := readOutputFile(filepath.Join(outDir, filepath.FromSlash(path)))
+		if err != nil {
+			return err
+		}
+		info, err := statOutputFile(filepath.Join(outDir, filepath.FromSlash(path)))
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: path, Mode: 0644, Size: int64(len(data)), ModTime: info.ModTime()}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// patternFile is a pattern file in a structured format (YAML, JSON, or
+// TOML). Groups keep related patterns together under a name and
+// description; their entries are read like top-level ones.
+type patternFile struct {
+	Patterns []string       `mapstructure:"patterns"`
+	Rules    []RuleConfig   `mapstructure:"rules"`
+	Groups   []patternGroup `mapstructure:"groups"`
+}
+
+// patternGroup is a named set of patterns and rules in a pattern file.
+type patternGroup struct {
+	Name        string       `mapstructure:"name"`
+	Description string       `mapstructure:"description"`
+	Patterns    []string     `mapstructure:"patterns"`
+	Rules       []RuleConfig `mapstructure:"rules"`
+}
+
+// sourcedPattern is a pattern and where in a pattern file it came from.
+// rule is set for patterns written as verbose rules.
+type sourcedPattern struct {
+	pattern string
+	source  string
+	rule    *RuleConfig
+}
+
+// readPatternFile returns the patterns in a pattern file, with ignore
+// patterns prefixed by "!". Files ending in .yaml, .yml, .json, or .toml
+// are read as structured files; anything else has one pattern per line.
+// A missing file has no patterns.
+func readPatternFile(path string) ([]sourcedPattern, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json", ".toml":
+	default:
+		return readPatternLines(path)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var file patternFile
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var patterns []sourcedPattern
+	add := func(prefix string, list []string, rules []RuleConfig) {
+		for i, p := range list {
+			patterns = append(patterns, sourcedPattern{p, fmt.Sprintf("%spatterns[%d]", prefix, i), nil})
+		}
+		for i := range rules {
+			patterns = append(patterns, sourcedPattern{rulePattern(rules[i]), fmt.Sprintf("%srules[%d]", prefix, i), &rules[i]})
+		}
+	}
+	name := path
+	add(name+" ", file.Patterns, file.Rules)
+	for i, g := range file.Groups {
+		group := g.Name
+		if group == "" {
+			group = fmt.Sprint(i)
+		}
+		add(fmt.Sprintf("%s group %q ", name, group), g.Patterns, g.Rules)
+	}
+	return patterns, nil
+}
+
+// readPatternLines reads a line-based pattern file.
+func readPatternLines(path string) ([]sourcedPattern, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var patterns []sourcedPattern
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		patterns = append(patterns, sourcedPattern{scanner.Text(), fmt.Sprintf("%s:%d", path, line), nil})
+	}
+	return patterns, scanner.Err()
+}
+
+// rulePattern returns the glob pattern for a verbose rule, prefixed by
+// "!" for ignore rules.
+func rulePattern(r RuleConfig) string {
+	pat := r.URL
+	if r.Subpaths {
+		if !strings.HasSuffix(pat, "*") {
+			if !strings.HasSuffix(pat, "/") {
+				pat += "/"
+			}
+			pat += "*"
+		}
+	}
+
+	if r.Action == "ignore" {
+		pat = "!" + pat
+	}
+	return pat
+}
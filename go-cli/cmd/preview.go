@@ -0,0 +1,416 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// previewAddr is the address the preview server listens on.
+// previewOpen opens the preview in the default browser.
+var (
+	previewAddr string
+	previewOpen bool
+)
+
+// previewPoll is how often the preview checks the config files for changes.
+const previewPoll = 500 * time.Millisecond
+
+var previewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Serve a side-by-side preview of source pages and their markdown",
+	Long: `Starts a local web server that lists the pages in the output directory and
+shows each source page next to the markdown generated from it.
+
+"Reconvert" converts the page again from its saved HTML (fetching it when
+none was saved) with the current configuration. When skills.yaml or a
+pattern file changes, the configuration is reloaded and the page being
+viewed is reconverted and refreshed, so selectors and cleanup rules can be
+tuned without a full crawl. Steps that look at the whole site, such as
+boilerplate removal and keywords, run on the next crawl.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p := &previewServer{}
+		if err := p.reload(); err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		go p.watch()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", p.handleIndex)
+		mux.HandleFunc("/page", p.handlePage)
+		mux.HandleFunc("/source", p.handleSource)
+		mux.HandleFunc("/markdown", p.handleMarkdown)
+		mux.HandleFunc("/reconvert", p.handleReconvert)
+		mux.HandleFunc("/version", p.handleVersion)
+
+		link := "http://" + previewAddr + "/"
+		fmt.Printf("Previewing %s at %s\n", p.opts.Output, link)
+		if previewOpen {
+			openBrowser(link)
+		}
+		if err := http.ListenAndServe(previewAddr, mux); err != nil {
+			fmt.Printf("Error serving preview: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+	previewCmd.Flags().StringVar(&previewAddr, "addr", "localhost:8080", "address to serve the preview on")
+	previewCmd.Flags().BoolVar(&previewOpen, "open", false, "open the preview in the default browser")
+}
+
+// previewServer serves the preview UI. version counts config reloads so
+// open pages know when to refresh.
+type previewServer struct {
+	mu      sync.Mutex
+	cfg     Config
+	opts    *CrawlOptions
+	version int
+	files   []string
+	mtimes  map[string]time.Time
+}
+
+// previewFiles returns the config files whose changes reload the preview.
+func previewFiles(cfg Config) []string {
+	files := append([]string{}, cfg.ConfigFiles...)
+	if used := viper.ConfigFileUsed(); used != "" {
+		files = append(files, used)
+	}
+	return files
+}
+
+// reload reads the configuration again.
+func (p *previewServer) reload() error {
+	if err := initConfig(); err != nil {
+		return err
+	}
+	var cfg Config
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return err
+	}
+	cfg.profile = viper.ConfigFileUsed()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg = cfg
+	p.opts = newCrawlOptions(&cfg)
+	p.files = previewFiles(cfg)
+	p.version++
+	return nil
+}
+
+// watch reloads the configuration whenever one of its files changes.
+func (p *previewServer) watch() {
+	for range time.Tick(previewPoll) {
+		changed := false
+		mtimes := map[string]time.Time{}
+		p.mu.Lock()
+		for _, file := range p.files {
+			if info, err := os.Stat(file); err == nil {
+				mtimes[file] = info.ModTime()
+			}
+		}
+		if p.mtimes != nil {
+			for file, mtime := range mtimes {
+				if !mtime.Equal(p.mtimes[file]) {
+					changed = true
+				}
+			}
+		}
+		p.mtimes = mtimes
+		p.mu.Unlock()
+		if changed {
+			if err := p.reload(); err != nil {
+				fmt.Printf("Error reloading config: %v\n", err)
+				continue
+			}
+			fmt.Println("Config changed, reloaded")
+		}
+	}
+}
+
+// state returns the current configuration and output options.
+func (p *previewServer) state() (Config, *CrawlOptions, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cfg, p.opts, p.version
+}
+
+// previewEntry returns the manifest entry of the page in the request's
+// url parameter.
+func (p *previewServer) previewEntry(w http.ResponseWriter, r *http.Request) (*ManifestEntry, bool) {
+	_, opts, _ := p.state()
+	m, err := loadManifest(opts.Output)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	entry, ok := m.Pages[r.URL.Query().Get("url")]
+	if !ok {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (p *previewServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	_, opts, _ := p.state()
+	m, err := loadManifest(opts.Output)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	entries := make([]*ManifestEntry, 0, len(m.Pages))
+	for _, entry := range m.Pages {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	previewTemplates.ExecuteTemplate(w, "index", map[string]interface{}{
+		"Output": opts.Output,
+		"Pages":  entries,
+	})
+}
+
+func (p *previewServer) handlePage(w http.ResponseWriter, r *http.Request) {
+	entry, ok := p.previewEntry(w, r)
+	if !ok {
+		return
+	}
+	_, opts, version := p.state()
+	source := "/source?url=" + url.QueryEscape(entry.URL)
+	if _, err := opts.readSavedHTML(entry); err != nil {
+		source = entry.URL
+	}
+	previewTemplates.ExecuteTemplate(w, "page", map[string]interface{}{
+		"Page":    entry,
+		"Source":  source,
+		"Version": version,
+	})
+}
+
+// handleSource serves the saved HTML of a page, with a base URL so its
+// relative links, styles, and images load from the original site.
+func (p *previewServer) handleSource(w http.ResponseWriter, r *http.Request) {
+	entry, ok := p.previewEntry(w, r)
+	if !ok {
+		return
+	}
+	_, opts, _ := p.state()
+	data, err := opts.readSavedHTML(entry)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	base := fmt.Sprintf(`<base href="%s">`, template.HTMLEscapeString(entry.URL))
+	html := string(data)
+	if i := strings.Index(strings.ToLower(html), "<head>"); i >= 0 {
+		html = html[:i+len("<head>")] + base + html[i+len("<head>"):]
+	} else {
+		html = base + html
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, html)
+}
+
+func (p *previewServer) handleMarkdown(w http.ResponseWriter, r *http.Request) {
+	entry, ok := p.previewEntry(w, r)
+	if !ok {
+		return
+	}
+	_, opts, _ := p.state()
+	data, err := readOutputFile(opts.pageFile(entry))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(data)
+}
+
+func (p *previewServer) handleReconvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	entry, ok := p.previewEntry(w, r)
+	if !ok {
+		return
+	}
+	cfg, opts, _ := p.state()
+	if err := reconvertPage(cfg, opts, entry); err != nil {
+		fmt.Printf("Error reconverting %s: %v\n", entry.URL, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Printf("Reconverted %s\n", entry.URL)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *previewServer) handleVersion(w http.ResponseWriter, r *http.Request) {
+	_, _, version := p.state()
+	fmt.Fprint(w, version)
+}
+
+// reconvertMu serialises reconversions, which share the crawl globals.
+var reconvertMu sync.Mutex
+
+// reconvertPage converts a page again with cfg, from its saved HTML or,
+// when none was saved, from a fresh fetch.
+func reconvertPage(cfg Config, opts *CrawlOptions, entry *ManifestEntry) error {
+	reconvertMu.Lock()
+	defer reconvertMu.Unlock()
+
+	u, err := url.Parse(entry.URL)
+	if err != nil {
+		return err
+	}
+	body, err := opts.readSavedHTML(entry)
+	if err != nil {
+		if body, err = fetchPage(entry.URL); err != nil {
+			return err
+		}
+	}
+
+	if err := opts.useConversionConfig(cfg); err != nil {
+		return err
+	}
+	manifest, err := loadManifest(opts.Output)
+	if err != nil {
+		return err
+	}
+	opts.manifest = manifest
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "text/html; charset=utf-8")
+	if entry.LastModified != "" {
+		headers.Set("Last-Modified", entry.LastModified)
+	}
+	if isNotebook(u, "") {
+		headers.Set("Content-Type", "application/x-ipynb+json")
+	}
+	saveResponse(&colly.Response{
+		StatusCode: http.StatusOK,
+		Body:       body,
+		Request:    &colly.Request{URL: u},
+		Headers:    &headers,
+	}, opts)
+	compressOutput(opts, manifest, cfg.Compression)
+	return manifest.save(opts.Output)
+}
+
+// fetchPage fetches the body of a page.
+func fetchPage(link string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// openBrowser opens link in the default browser.
+func openBrowser(link string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Printf("Warning: could not open browser: %v\n", err)
+	}
+}
+
+var previewTemplates = template.Must(template.New("preview").Parse(`
+{{define "style"}}<style>
+body { font-family: system-ui, sans-serif; margin: 0; }
+header { padding: 8px 16px; border-bottom: 1px solid #ddd; display: flex; gap: 16px; align-items: center; }
+header h1 { font-size: 16px; margin: 0; flex: 1; }
+main { display: flex; height: calc(100vh - 50px); }
+main iframe, main pre { flex: 1; margin: 0; border: 0; overflow: auto; }
+main pre { border-left: 1px solid #ddd; padding: 16px; white-space: pre-wrap; font-size: 13px; }
+table { border-collapse: collapse; margin: 16px; }
+td, th { text-align: left; padding: 4px 12px; border-bottom: 1px solid #eee; }
+</style>{{end}}
+
+{{define "index"}}<!doctype html>
+<html><head><meta charset="utf-8"><title>Preview: {{.Output}}</title>{{template "style"}}</head>
+<body><header><h1>{{.Output}}</h1><span>{{len .Pages}} pages</span></header>
+<table><tr><th>Path</th><th>Title</th><th>URL</th></tr>
+{{range .Pages}}<tr><td><a href="/page?url={{.URL}}">{{.Path}}</a></td><td>{{.Title}}</td><td>{{.URL}}</td></tr>
+{{end}}</table></body></html>{{end}}
+
+{{define "page"}}<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Page.Title}}</title>{{template "style"}}</head>
+<body><header><a href="/">All pages</a><h1>{{.Page.Title}}</h1>
+<a href="{{.Page.URL}}" target="_blank">Original</a>
+<button id="reconvert">Reconvert</button></header>
+<main><iframe src="{{.Source}}" sandbox="allow-same-origin"></iframe><pre id="markdown"></pre></main>
+<script>
+const page = {{.Page.URL}};
+let version = {{.Version}};
+async function load() {
+  const res = await fetch("/markdown?url=" + encodeURIComponent(page));
+  document.getElementById("markdown").textContent = await res.text();
+}
+async function reconvert() {
+  const res = await fetch("/reconvert?url=" + encodeURIComponent(page), {method: "POST"});
+  if (!res.ok) alert(await res.text());
+  load();
+}
+document.getElementById("reconvert").onclick = reconvert;
+setInterval(async () => {
+  const current = Number(await (await fetch("/version")).text());
+  if (current !== version) {
+    version = current;
+    reconvert();
+  }
+}, 1000);
+load();
+</script></body></html>{{end}}
+`))
@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/gocolly/colly/v2/queue"
+)
+
+// priorityCtxKey is the request context key holding the fetch priority of
+// the rule that queued a URL. It is stored with the request, so queues
+// kept on disk or in Redis order their requests the same way.
+const priorityCtxKey = "fetch_priority"
+
+// queueWithPriority adds link to q with the given fetch priority.
+func queueWithPriority(q *queue.Queue, link string, priority int) error {
+	u, err := url.Parse(link)
+	if err != nil {
+		return err
+	}
+	ctx := colly.NewContext()
+	ctx.Put(priorityCtxKey, priority)
+	return q.AddRequest(&colly.Request{URL: u, Method: "GET", Ctx: ctx})
+}
+
+// requestPriority returns the fetch priority of a serialized request.
+func requestPriority(r []byte) int {
+	var req struct {
+		Ctx map[string]interface{}
+	}
+	if err := json.Unmarshal(r, &req); err != nil {
+		return 0
+	}
+	p, _ := req.Ctx[priorityCtxKey].(float64)
+	return int(p)
+}
+
+// priorityKey returns the bbolt key of a request: keys sort by descending
+// priority, then by the order requests were added.
+func priorityKey(priority int, seq uint64) []byte {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(-int64(priority))^(1<<63))
+	binary.BigEndian.PutUint64(b[8:], seq)
+	return b[:]
+}
+
+// priorityScore returns the Redis sorted set score of a request, which
+// orders requests like priorityKey.
+func priorityScore(priority int, seq int64) float64 {
+	return float64(-priority)*(1<<32) + float64(seq%(1<<32))
+}
+
+// priorityQueue is an in-memory frontier that hands out the requests of
+// the highest fetch priority first, each priority in the order added.
+type priorityQueue struct {
+	mu     sync.Mutex
+	levels map[int][][]byte
+	// order lists the priorities with pending requests, highest first.
+	order []int
+	size  int
+}
+
+func newPriorityQueue() *priorityQueue {
+	return &priorityQueue{levels: map[int][][]byte{}}
+}
+
+func (q *priorityQueue) add(r []byte) {
+	p := requestPriority(r)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.levels[p]) == 0 {
+		i := sort.Search(len(q.order), func(i int) bool { return q.order[i] <= p })
+		q.order = append(q.order[:i], append([]int{p}, q.order[i:]...)...)
+	}
+	q.levels[p] = append(q.levels[p], r)
+	q.size++
+}
+
+func (q *priorityQueue) next() []byte {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.order) == 0 {
+		return nil
+	}
+	p := q.order[0]
+	r := q.levels[p][0]
+	q.levels[p] = q.levels[p][1:]
+	if len(q.levels[p]) == 0 {
+		delete(q.levels, p)
+		q.order = q.order[1:]
+	}
+	q.size--
+	return r
+}
+
+func (q *priorityQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output profiles choose how much of each page is saved.
+const (
+	// profileMirror saves the whole page.
+	profileMirror = "mirror"
+	// profileDigest saves the lead paragraph and a table of contents
+	// summarizing each section, linked to the source.
+	profileDigest = "digest"
+	// profileSnippets saves only code blocks and tables, under the
+	// headings they appear in.
+	profileSnippets = "snippets"
+)
+
+// checkProfile reports an error for an unknown output profile.
+func checkProfile(profile string) error {
+	switch profile {
+	case "", profileMirror, profileDigest, profileSnippets:
+		return nil
+	}
+	return fmt.Errorf("unknown extraction profile %q (want mirror, digest, or snippets)", profile)
+}
+
+// applyProfile cuts the body of p down to its extraction profile. It
+// reports false when nothing of the page is left to save.
+func applyProfile(p convertedPage) (string, bool) {
+	switch p.Extraction.Profile {
+	case profileDigest:
+		p.Body = withoutTitleHeading(p.Body, p.Title)
+		return digestBody(p), true
+	case profileSnippets:
+		body := snippetsBody(withoutTitleHeading(p.Body, p.Title))
+		return body, body != ""
+	}
+	return p.Body, true
+}
+
+// withoutTitleHeading removes a level 1 heading repeating the page title
+// from the start of body, since the title is written above it.
+func withoutTitleHeading(body, title string) string {
+	trimmed := strings.TrimLeft(body, "\n")
+	line, rest, _ := strings.Cut(trimmed, "\n")
+	if level, heading, ok := atxHeading(line); ok && level == 1 && headingKey(heading) == headingKey(title) {
+		return strings.TrimLeft(rest, "\n")
+	}
+	return body
+}
+
+// digestBody returns the lead paragraph of p followed by a contents list
+// of its top two heading levels. Each entry links to the heading's anchor
+// on the source when it has one and gives the first sentence of its
+// section.
+func digestBody(p convertedPage) string {
+	type section struct {
+		level   int
+		heading string
+		text    []string
+	}
+	var lead []string
+	var sections []*section
+	inFence := false
+	for _, line := range strings.Split(p.Body, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+		} else if level, heading, ok := atxHeading(line); ok && !inFence {
+			sections = append(sections, &section{level: level, heading: heading})
+			continue
+		}
+		if len(sections) == 0 {
+			lead = append(lead, line)
+		} else {
+			last := sections[len(sections)-1]
+			last.text = append(last.text, line)
+		}
+	}
+
+	var b strings.Builder
+	if para := firstParagraph(strings.Join(lead, "\n")); para != "" {
+		b.WriteString(para + "\n\n")
+	}
+	top := 7
+	for _, s := range sections {
+		top = min(top, s.level)
+	}
+	base := stripFragment(p.URL)
+	next := 0
+	var entries []string
+	for _, s := range sections {
+		entry := s.heading
+		key := headingKey(s.heading)
+		for i := next; i < len(p.Anchors); i++ {
+			if headingKey(p.Anchors[i].text) == key {
+				entry = fmt.Sprintf("[%s](%s#%s)", s.heading, base, p.Anchors[i].id)
+				next = i + 1
+				break
+			}
+		}
+		if s.level > top+1 {
+			continue
+		}
+		if summary := firstSentence(firstParagraph(strings.Join(s.text, "\n"))); summary != "" {
+			entry += ": " + summary
+		}
+		entries = append(entries, strings.Repeat("  ", s.level-top)+"- "+entry)
+	}
+	if len(entries) > 0 {
+		b.WriteString("## Contents\n\n" + strings.Join(entries, "\n") + "\n")
+	}
+	return b.String()
+}
+
+// snippetsBody returns the code blocks and tables of body, each under the
+// headings that lead to it. Headings with no snippets below them are
+// left out.
+func snippetsBody(body string) string {
+	type heading struct {
+		level   int
+		line    string
+		written bool
+	}
+	var stack []*heading
+	var blocks []string
+	var block []string
+	inFence, inTable := false, false
+	flush := func() {
+		if len(block) == 0 {
+			return
+		}
+		for _, h := range stack {
+			if !h.written {
+				blocks = append(blocks, h.line)
+				h.written = true
+			}
+		}
+		blocks = append(blocks, strings.Join(block, "\n"))
+		block = nil
+	}
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case isFence(line):
+			block = append(block, line)
+			if inFence {
+				flush()
+			}
+			inFence = !inFence
+			continue
+		case inFence:
+			block = append(block, line)
+			continue
+		case strings.HasPrefix(trimmed, "|"):
+			block = append(block, line)
+			inTable = true
+			continue
+		}
+		if inTable {
+			flush()
+			inTable = false
+		}
+		if level, _, ok := atxHeading(line); ok {
+			for len(stack) > 0 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, &heading{level: level, line: line})
+		}
+	}
+	flush()
+	if len(blocks) == 0 {
+		return ""
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
@@ -0,0 +1,383 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// publishedFileName records in the destination which files publish wrote,
+// so files it did not write are never removed.
+const publishedFileName = ".published.json"
+
+// publishTo is the destination directory; publishGate runs the gate
+// checks first; publishReport is where the gate report is written;
+// publishDryRun only shows what would change; publishSignKey signs the
+// published manifest.
+var (
+	publishTo      string
+	publishGate    bool
+	publishReport  string
+	publishDryRun  bool
+	publishSignKey string
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Copy the skills in the output directory to a destination",
+	Long: `Copies the skills in the output directory to --to (or publish.to), such
+as a checkout of the repository the skills are released from: every
+markdown file and the assets they link to, without the manifest, saved
+page sources, and other crawl files. Files an earlier publish wrote that
+are no longer in the output are removed; other files in the destination
+are left alone.
+
+With --gate, the files are first checked against the publish.gate
+policy: size budgets, a secret scan, a license allowlist, and broken
+links. A machine-readable report is written to --gate-report (default
+<output>/gate-report.json), and nothing is published unless every check
+passes.
+
+With --sign-key (or publish.sign_key), the manifest is signed with the
+secret key and published with its signature, so the destination can be
+checked with verify. --dry-run lists the manifest without signing it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		opts := newCrawlOptions(&cfg)
+		to := publishTo
+		if to == "" {
+			to = cfg.Publish.To
+		}
+		if to == "" {
+			fmt.Println("Error: no destination; pass --to or set publish.to")
+			os.Exit(1)
+		}
+		if within(to, opts.Output) || within(opts.Output, to) {
+			fmt.Printf("Error: %s and the output directory %s must not contain each other\n", to, opts.Output)
+			os.Exit(1)
+		}
+		m, err := loadManifest(opts.Output)
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+		files, err := publishFiles(opts.Output)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", opts.Output, err)
+			os.Exit(1)
+		}
+		// The gate checks the skills; the signed manifest is published
+		// along with them.
+		publishing := files
+		signKey := publishSignKey
+		if signKey == "" {
+			signKey = cfg.Publish.SignKey
+		}
+		if signKey != "" {
+			if !publishDryRun {
+				if err := signManifest(opts.Output, signKey); err != nil {
+					fmt.Printf("Error signing manifest: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			if publishing, err = withManifest(opts.Output, files); err != nil {
+				fmt.Printf("Error reading manifest: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		diff, published, err := planPublish(to, publishing)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", to, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Publishing %s to %s: %d added, %d changed, %d removed, %d unchanged\n",
+			opts.Output, to, len(diff.Added), len(diff.Changed), len(diff.Removed), diff.Unchanged)
+
+		if publishGate {
+			gate := runGate(opts, m, files, cfg.Publish.Gate)
+			gate.Diff = diff
+			reportPath := publishReport
+			if reportPath == "" {
+				reportPath = filepath.Join(opts.Output, gateReportFileName)
+			}
+			if err := gate.save(reportPath); err != nil {
+				fmt.Printf("Error writing gate report: %v\n", err)
+				os.Exit(1)
+			}
+			gate.print()
+			if !gate.Passed {
+				fmt.Printf("Gate failed; not publishing (see %s)\n", reportPath)
+				os.Exit(1)
+			}
+		}
+
+		if publishDryRun {
+			for _, list := range []struct {
+				verb  string
+				paths []string
+			}{{"Add", diff.Added}, {"Update", diff.Changed}, {"Remove", diff.Removed}} {
+				for _, path := range list.paths {
+					fmt.Printf("%s %s\n", list.verb, path)
+				}
+			}
+			return
+		}
+		if err := applyPublish(opts.Output, to, publishing, diff, published); err != nil {
+			fmt.Printf("Error publishing: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+	publishCmd.Flags().StringVar(&publishTo, "to", "", "directory to publish the skills to (default publish.to)")
+	publishCmd.Flags().BoolVar(&publishGate, "gate", false, "run the publish.gate checks and publish only if they all pass")
+	publishCmd.Flags().StringVar(&publishReport, "gate-report", "", "where to write the gate report (default <output>/gate-report.json)")
+	publishCmd.Flags().BoolVar(&publishDryRun, "dry-run", false, "show what would change without publishing")
+	publishCmd.Flags().StringVar(&publishSignKey, "sign-key", "", "sign the manifest with this secret key and publish it with its signature (default publish.sign_key)")
+}
+
+// within reports whether path is dir or below it.
+func within(path, dir string) bool {
+	absPath, err1 := filepath.Abs(path)
+	absDir, err2 := filepath.Abs(dir)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// publishFiles returns the files of the skills in out, by slash-separated
+// path relative to it, with their content hash: every file below a skill
+// directory except those in hidden directories and saved page sources
+// (.html and .ipynb files) that no markdown file links to.
+func publishFiles(out string) (map[string]string, error) {
+	files := map[string]string{}
+	var sources []string
+	linked := map[string]bool{}
+	err := filepath.WalkDir(out, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != out && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Dir(path) == out {
+			return nil // manifest, report, and other crawl files
+		}
+		rel, err := filepath.Rel(out, path)
+		if err != nil {
+			return err
+		}
+		// Compressed pages are published plain.
+		rel = pagePath(filepath.ToSlash(rel))
+		if strings.HasSuffix(rel, ".html") || strings.HasSuffix(rel, ".ipynb") {
+			sources = append(sources, rel)
+			return nil
+		}
+		data, err := readOutputFile(path)
+		if err != nil {
+			return err
+		}
+		files[rel] = contentHash(string(data))
+		if strings.HasSuffix(rel, ".md") {
+			for _, target := range relativeLinks(rel, string(data)) {
+				linked[target.path] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range sources {
+		if linked[rel] {
+			data, err := readOutputFile(filepath.Join(out, filepath.FromSlash(rel)))
+			if err != nil {
+				return nil, err
+			}
+			files[rel] = contentHash(string(data))
+		}
+	}
+	return files, nil
+}
+
+// withManifest returns files with the manifest in out and its signature,
+// when there is one, added.
+func withManifest(out string, files map[string]string) (map[string]string, error) {
+	all := map[string]string{}
+	for rel, hash := range files {
+		all[rel] = hash
+	}
+	for _, name := range []string{manifestFileName, manifestFileName + signatureSuffix} {
+		data, err := os.ReadFile(filepath.Join(out, name))
+		if os.IsNotExist(err) && name != manifestFileName {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		all[name] = contentHash(string(data))
+	}
+	return all, nil
+}
+
+// linkTarget is a relative link in a markdown file.
+type linkTarget struct {
+	path string // slash-separated, relative to the output directory
+	ref  string // as written
+	line int
+}
+
+// relativeLinks returns the relative links in the markdown file at path,
+// resolved against its directory.
+func relativeLinks(path, content string) []linkTarget {
+	var links []linkTarget
+	for i, line := range strings.Split(content, "\n") {
+		for _, m := range markdownLinkRe.FindAllStringSubmatch(line, -1) {
+			ref, err := url.Parse(m[1])
+			if err != nil || ref.IsAbs() || ref.Host != "" || ref.Path == "" || strings.HasPrefix(ref.Path, "/") {
+				continue
+			}
+			target := filepath.ToSlash(filepath.Clean(filepath.Join(filepath.Dir(filepath.FromSlash(path)), filepath.FromSlash(ref.Path))))
+			links = append(links, linkTarget{path: target, ref: m[1], line: i + 1})
+		}
+	}
+	return links
+}
+
+// publishDiff lists how publishing changes the destination.
+type publishDiff struct {
+	Added     []string `json:"added"`
+	Changed   []string `json:"changed"`
+	Removed   []string `json:"removed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// publishedFiles is the record publish keeps in the destination.
+type publishedFiles struct {
+	Files map[string]string `json:"files"`
+}
+
+// planPublish compares files with the destination. Files are removed
+// only when an earlier publish wrote them. It returns the diff and that
+// earlier record.
+func planPublish(to string, files map[string]string) (publishDiff, map[string]string, error) {
+	diff := publishDiff{Added: []string{}, Changed: []string{}, Removed: []string{}}
+	published := map[string]string{}
+	if data, err := os.ReadFile(filepath.Join(to, publishedFileName)); err == nil {
+		var record publishedFiles
+		if err := json.Unmarshal(data, &record); err != nil {
+			return diff, nil, fmt.Errorf("reading %s: %w", publishedFileName, err)
+		}
+		if record.Files != nil {
+			published = record.Files
+		}
+	} else if !os.IsNotExist(err) {
+		return diff, nil, err
+	}
+	for _, rel := range sortedKeys(files) {
+		data, err := os.ReadFile(filepath.Join(to, filepath.FromSlash(rel)))
+		switch {
+		case os.IsNotExist(err):
+			diff.Added = append(diff.Added, rel)
+		case err != nil:
+			return diff, nil, err
+		case contentHash(string(data)) != files[rel]:
+			diff.Changed = append(diff.Changed, rel)
+		default:
+			diff.Unchanged++
+		}
+	}
+	for _, rel := range sortedKeys(published) {
+		if _, ok := files[rel]; !ok {
+			diff.Removed = append(diff.Removed, rel)
+		}
+	}
+	return diff, published, nil
+}
+
+// applyPublish copies the added and changed files from out to to, removes
+// the removed ones, and records what was published.
+func applyPublish(out, to string, files map[string]string, diff publishDiff, published map[string]string) error {
+	for _, rel := range append(append([]string{}, diff.Added...), diff.Changed...) {
+		data, err := readOutputFile(filepath.Join(out, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(to, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return err
+		}
+	}
+	for _, rel := range diff.Removed {
+		dest := filepath.Join(to, filepath.FromSlash(rel))
+		if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		// Remove the directories the file leaves empty, up to the
+		// destination itself.
+		for dir := filepath.Dir(dest); within(dir, to) && !sameDir(dir, to); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+
+	record := publishedFiles{Files: files}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(to, publishedFileName), append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	skills := map[string]bool{}
+	for rel := range files {
+		if skill, _, ok := strings.Cut(rel, "/"); ok {
+			skills[skill] = true
+		}
+	}
+	fmt.Printf("Published %d files of %d skills to %s\n", len(files), len(skills), to)
+	return nil
+}
+
+// sameDir reports whether a and b are the same directory.
+func sameDir(a, b string) bool {
+	absA, err1 := filepath.Abs(a)
+	absB, err2 := filepath.Abs(b)
+	return err1 == nil && err2 == nil && absA == absB
+}
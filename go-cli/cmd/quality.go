@@ -0,0 +1,274 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// qualityReviewFileName is the review list written next to the manifest.
+const qualityReviewFileName = "quality-review.md"
+
+// Defaults for quality scoring.
+const (
+	defaultReviewSize       = 50
+	defaultQualityThreshold = 0.8
+	minTextRatio            = 0.15
+	emptySectionCost        = 0.1
+	brokenTableCost         = 0.15
+	maxQualityPenalty       = 0.3
+	textRatioMaxDeduct      = 0.4
+)
+
+// PageScore rates how well a page converted, from 0 to 1. TextRatio is
+// the share of the content HTML that is visible text; EmptySections and
+// BrokenTables count headings with nothing under them and HTML tables
+// that did not become well-formed markdown tables.
+type PageScore struct {
+	Score         float64 `json:"score"`
+	TextRatio     float64 `json:"text_ratio"`
+	EmptySections int     `json:"empty_sections,omitempty"`
+	BrokenTables  int     `json:"broken_tables,omitempty"`
+}
+
+// issues describes what lowered the score.
+func (s PageScore) issues() []string {
+	var issues []string
+	if s.TextRatio < minTextRatio {
+		issues = append(issues, fmt.Sprintf("text ratio: %.2f", s.TextRatio))
+	}
+	if s.EmptySections > 0 {
+		issues = append(issues, fmt.Sprintf("empty sections: %d", s.EmptySections))
+	}
+	if s.BrokenTables > 0 {
+		issues = append(issues, fmt.Sprintf("broken tables: %d", s.BrokenTables))
+	}
+	return issues
+}
+
+// writeQualityReview scores every crawled page, records the scores in the
+// manifest, and writes the lowest-scoring pages to quality-review.md so
+// selector tuning can start with the worst conversions.
+func writeQualityReview(opts *CrawlOptions, m *Manifest, cfg QualityConfig) {
+	size := cfg.ReviewSize
+	if size <= 0 {
+		size = defaultReviewSize
+	}
+	threshold := cfg.Threshold
+	if threshold <= 0 {
+		threshold = defaultQualityThreshold
+	}
+
+	var review []*ManifestEntry
+	for _, entry := range m.Pages {
+		html, err := opts.readSavedHTML(entry)
+		if err != nil {
+			continue
+		}
+		content, err := extractContent(html, opts.extractionFor(entry.URL))
+		if err != nil {
+			continue
+		}
+		data, err := readOutputFile(filepath.Join(opts.Output, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			continue
+		}
+		_, body := splitFrontmatter(string(data))
+		score, err := scorePage(content, body)
+		if err != nil {
+			continue
+		}
+
+		m.mu.Lock()
+		entry.Quality = &score
+		m.touch(entry.URL)
+		m.mu.Unlock()
+		if score.Score < threshold {
+			review = append(review, entry)
+		}
+	}
+
+	sort.Slice(review, func(i, j int) bool {
+		if review[i].Quality.Score != review[j].Quality.Score {
+			return review[i].Quality.Score < review[j].Quality.Score
+		}
+		return review[i].Path < review[j].Path
+	})
+	total := len(review)
+	if len(review) > size {
+		review = review[:size]
+	}
+
+	path := filepath.Join(opts.Output, qualityReviewFileName)
+	if total == 0 {
+		os.Remove(path)
+		return
+	}
+	if err := writeFileIfChanged(path, []byte(renderQualityReview(review, total))); err != nil {
+		fmt.Printf("Error writing %s: %v\n", qualityReviewFileName, err)
+		return
+	}
+	fmt.Printf("%d pages scored below %.2f (see %s)\n", total, threshold, qualityReviewFileName)
+}
+
+// renderQualityReview lists review entries, worst first, with links to
+// each page's source and output.
+func renderQualityReview(review []*ManifestEntry, total int) string {
+	var b strings.Builder
+	b.WriteString("# Conversion Quality Review\n\n")
+	if total > len(review) {
+		fmt.Fprintf(&b, "The %d lowest-scoring of %d pages below the threshold.\n\n", len(review), total)
+	}
+	b.WriteString("| Score | Page | Source | Issues |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, entry := range review {
+		fmt.Fprintf(&b, "| %.2f | [%s](%s) | [source](%s) | %s |\n",
+			entry.Quality.Score, tableCell(entry.Title), entry.Path, entry.URL,
+			tableCell(strings.Join(entry.Quality.issues(), ", ")))
+	}
+	return b.String()
+}
+
+// tableCell escapes s for a markdown table cell.
+func tableCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+// scorePage scores the conversion of content HTML to a markdown body.
+func scorePage(content, body string) (PageScore, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return PageScore{}, err
+	}
+	var score PageScore
+	if len(content) > 0 {
+		text := strings.Join(strings.Fields(doc.Text()), " ")
+		score.TextRatio = math.Round(float64(len(text))/float64(len(content))*100) / 100
+	}
+	score.EmptySections = countEmptySections(body)
+	score.BrokenTables = countBrokenTables(doc, body)
+
+	penalty := 0.0
+	if score.TextRatio < minTextRatio {
+		penalty += textRatioMaxDeduct * (minTextRatio - score.TextRatio) / minTextRatio
+	}
+	penalty += min(maxQualityPenalty, emptySectionCost*float64(score.EmptySections))
+	penalty += min(maxQualityPenalty, brokenTableCost*float64(score.BrokenTables))
+	score.Score = math.Round(max(0, 1-penalty)*100) / 100
+	return score, nil
+}
+
+var markdownHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+
+// countEmptySections counts headings followed by nothing but another
+// heading of the same or a higher level, or the end of the page. A
+// heading repeated on the next line, such as a title the page also has
+// in its content, counts once.
+func countEmptySections(body string) int {
+	type heading struct {
+		level int
+		text  string
+	}
+	var headings []heading
+	var content []bool
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if trimmed == "" {
+			continue
+		}
+		if match := markdownHeadingRe.FindStringSubmatch(trimmed); match != nil && !inFence {
+			h := heading{level: len(match[1]), text: match[2]}
+			if n := len(headings); n > 0 && !content[n-1] && headings[n-1] == h {
+				continue
+			}
+			headings = append(headings, h)
+			content = append(content, false)
+			continue
+		}
+		if n := len(content); n > 0 {
+			content[n-1] = true
+		}
+	}
+
+	empty := 0
+	for i, h := range headings {
+		if content[i] {
+			continue
+		}
+		if i+1 == len(headings) || headings[i+1].level <= h.level {
+			empty++
+		}
+	}
+	return empty
+}
+
+// countBrokenTables counts HTML data tables (with a row of two or more
+// cells) that have no well-formed markdown table in the body, plus
+// markdown tables whose rows disagree on their column count.
+func countBrokenTables(doc *goquery.Document, body string) int {
+	tables := doc.Find("table").FilterFunction(func(i int, s *goquery.Selection) bool {
+		if s.ParentsFiltered("table").Length() > 0 {
+			return false
+		}
+		wide := false
+		s.Find("tr").EachWithBreak(func(i int, row *goquery.Selection) bool {
+			wide = row.ChildrenFiltered("td, th").Length() > 1
+			return !wide
+		})
+		return wide
+	}).Length()
+
+	good, bad := 0, 0
+	var rows []string
+	flush := func() {
+		if len(rows) >= 2 {
+			cols := strings.Count(strings.Trim(rows[0], "|"), "|")
+			consistent := true
+			for _, row := range rows[1:] {
+				if strings.Count(strings.Trim(row, "|"), "|") != cols {
+					consistent = false
+				}
+			}
+			if consistent {
+				good++
+			} else {
+				bad++
+			}
+		}
+		rows = nil
+	}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "|") {
+			rows = append(rows, strings.ReplaceAll(line, `\|`, ""))
+			continue
+		}
+		flush()
+	}
+	flush()
+	return max(0, tables-good) + bad
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultRedisPrefix namespaces the crawl keys in Redis.
+const defaultRedisPrefix = "agent-skills"
+
+// redisStore shares the crawl frontier, dedup set, visited set, and
+// cookies between workers through Redis, so several machines can crawl
+// one site together. Each worker writes into the shared output directory.
+type redisStore struct {
+	client *redis.Client
+	prefix string
+	ctx    context.Context
+}
+
+func openRedisStore(cfg RedisConfig) (*redisStore, error) {
+	client, prefix, err := connectRedis(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s := &redisStore{client: client, prefix: prefix, ctx: context.Background()}
+	// Register as an active worker here rather than in Init, which both
+	// the collector and the queue call.
+	if err := client.Incr(s.ctx, s.key("workers")).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// connectRedis connects to the server in cfg and returns the client and
+// the key prefix to use.
+func connectRedis(cfg RedisConfig) (*redis.Client, string, error) {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, "", fmt.Errorf("connecting to redis at %s: %w", addr, err)
+	}
+	return client, prefix, nil
+}
+
+func (s *redisStore) key(name string) string {
+	return s.prefix + ":" + name
+}
+
+func (s *redisStore) Init() error {
+	return nil
+}
+
+func (s *redisStore) Visited(requestID uint64) error {
+	return s.client.SAdd(s.ctx, s.key("visited"), strconv.FormatUint(requestID, 10)).Err()
+}
+
+func (s *redisStore) IsVisited(requestID uint64) (bool, error) {
+	return s.client.SIsMember(s.ctx, s.key("visited"), strconv.FormatUint(requestID, 10)).Result()
+}
+
+func (s *redisStore) Cookies(u *url.URL) string {
+	cookies, _ := s.client.HGet(s.ctx, s.key("cookies"), u.Host).Result()
+	return cookies
+}
+
+func (s *redisStore) SetCookies(u *url.URL, cookies string) {
+	s.client.HSet(s.ctx, s.key("cookies"), u.Host, cookies)
+}
+
+// AddRequest adds r to a sorted set scored by fetch priority and a shared
+// sequence number, so every worker takes requests in the same order.
+func (s *redisStore) AddRequest(r []byte) error {
+	seq, err := s.client.Incr(s.ctx, s.key("seq")).Result()
+	if err != nil {
+		return err
+	}
+	return s.client.ZAdd(s.ctx, s.key("frontier"), redis.Z{Score: priorityScore(requestPriority(r), seq), Member: r}).Err()
+}
+
+func (s *redisStore) GetRequest() ([]byte, error) {
+	popped, err := s.client.ZPopMin(s.ctx, s.key("frontier"), 1).Result()
+	if err != nil || len(popped) == 0 {
+		return nil, err
+	}
+	r, _ := popped[0].Member.(string)
+	return []byte(r), nil
+}
+
+func (s *redisStore) QueueSize() (int, error) {
+	n, err := s.client.ZCard(s.ctx, s.key("frontier")).Result()
+	return int(n), err
+}
+
+// MarkQueued uses SADD so exactly one worker enqueues each URL.
+func (s *redisStore) MarkQueued(link string) (bool, error) {
+	added, err := s.client.SAdd(s.ctx, s.key("queued"), link).Result()
+	return added == 1, err
+}
+
+// Finish deregisters this worker. The last worker to leave an empty
+// queue clears the shared state so the next crawl starts fresh.
+func (s *redisStore) Finish() error {
+	defer s.client.Close()
+	remaining, err := s.client.Decr(s.ctx, s.key("workers")).Result()
+	if err != nil {
+		return err
+	}
+	if size, _ := s.QueueSize(); remaining <= 0 && size == 0 {
+		return s.client.Del(s.ctx, s.key("workers"), s.key("frontier"), s.key("seq"), s.key("queued"), s.key("visited"), s.key("cookies")).Err()
+	}
+	return nil
+}
+
+// Close deregisters this worker but keeps the shared state for resuming.
+func (s *redisStore) Close() error {
+	s.client.Decr(s.ctx, s.key("workers"))
+	return s.client.Close()
+}
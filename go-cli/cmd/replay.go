@@ -0,0 +1,542 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/gocolly/colly/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// replayEvents names an event log whose converted pages are replayed.
+// replayMatch limits the diff to URLs matching a glob.
+// replayApply writes the replayed output into the output directory.
+// replayStat lists the changed files instead of printing the diff.
+// replayJSON prints the result as JSON instead.
+var (
+	replayEvents string
+	replayMatch  string
+	replayApply  bool
+	replayStat   bool
+	replayJSON   bool
+)
+
+// crawledAtHeader carries the time a replayed response was crawled to
+// writePage, so the page keeps its recorded crawl time.
+const crawledAtHeader = "X-Agent-Skills-Crawled-At"
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Convert the saved responses again and diff the result against the output",
+	Long: `Runs the conversion stage again on the responses saved in the output
+directory, with the current configuration, and prints a unified diff of the
+result against the current output. Nothing is fetched, so selector, cleanup,
+and template changes can be tried cheaply, and the same responses always
+convert to the same output.
+
+Pages are converted into a scratch directory together with the steps that
+look at the whole site, such as boilerplate removal, keywords, and skill
+entries, and the output directory is left alone unless --apply is given.
+--events replays the pages an event log recorded as converted instead of
+every page in the manifest, and --match limits the diff to page URLs
+matching a glob. Pages with no saved response, such as pages from git
+sources, are carried over unchanged.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.profile = configProfile(cfg)
+
+		var match glob.Glob
+		if replayMatch != "" {
+			var err error
+			if match, err = glob.Compile(replayMatch); err != nil {
+				fmt.Printf("Error parsing --match: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// With --json only the result goes to stdout; progress and
+		// errors go to stderr.
+		stdout := os.Stdout
+		if replayJSON {
+			os.Stdout = os.Stderr
+		}
+		result, err := runReplay(cfg, match)
+		if err != nil {
+			fmt.Printf("Error %v\n", err)
+			os.Exit(1)
+		}
+		if replayJSON {
+			enc := json.NewEncoder(stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(result)
+		}
+	},
+}
+
+// ReplayResult is the outcome of a replay, printed by replay --json: the
+// pages replayed, the files that differ, and whether they were applied.
+type ReplayResult struct {
+	Pages   int          `json:"pages"`
+	Files   []ReplayFile `json:"files"`
+	Applied bool         `json:"applied"`
+}
+
+// ReplayFile is a file a replay changes, with its replay --stat status:
+// A (added), M (modified), D (removed), or R (renamed, as "old -> new").
+type ReplayFile struct {
+	Status string `json:"status"`
+	Path   string `json:"path"`
+}
+
+// runReplay replays the saved responses of the output of cfg, printing
+// the diff (or with --stat, the changed files) of pages matching match,
+// and applies it with --apply.
+func runReplay(cfg Config, match glob.Glob) (*ReplayResult, error) {
+	opts := newCrawlOptions(&cfg)
+	current, err := loadManifest(opts.Output)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	targets, err := replayTargets(opts, current, replayEvents)
+	if err != nil {
+		return nil, fmt.Errorf("reading saved responses: %w", err)
+	}
+	result := &ReplayResult{Pages: len(targets), Files: []ReplayFile{}}
+	if len(targets) == 0 {
+		fmt.Println("No saved responses to replay")
+		return result, nil
+	}
+
+	scratch, err := os.MkdirTemp("", "agent-skills-replay-")
+	if err != nil {
+		return nil, fmt.Errorf("creating scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+	replayed, err := replayPages(cfg, opts, current, targets, scratch)
+	if err != nil {
+		return nil, fmt.Errorf("replaying pages: %w", err)
+	}
+
+	changes := replayChanges(opts, scratch, replayed, current, targets, match)
+	color := !replayStat && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+	for _, c := range changes {
+		result.Files = append(result.Files, ReplayFile{Status: string(c.status()), Path: c.path()})
+		switch {
+		case replayJSON:
+		case replayStat:
+			fmt.Printf("%c %s\n", c.status(), c.path())
+		case color:
+			fmt.Print(colorDiff(c.Diff))
+		default:
+			fmt.Print(c.Diff)
+		}
+	}
+	fmt.Printf("Replayed %d pages: %d files changed\n", len(targets), len(changes))
+	var summaries []skillSummary
+	if cfg.ChangeSummary.Enabled {
+		keepDiff := cfg.ChangeSummary.Method == "llm"
+		summaries = summarizeChanges(opts, cfg.ChangeSummary, cfg.LLM, replayPageChanges(opts, scratch, replayed, current, changes, keepDiff))
+		printChangeSummaries(summaries)
+	}
+
+	if replayApply && len(changes) > 0 {
+		if err := applyReplay(opts, scratch, replayed, current, changes, cfg, summaries); err != nil {
+			return nil, fmt.Errorf("applying replay: %w", err)
+		}
+		result.Applied = true
+		fmt.Printf("Applied %d changes to %s\n", len(changes), opts.Output)
+	}
+	return result, nil
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().StringVar(&replayEvents, "events", "", "replay the pages recorded as converted in this event log")
+	replayCmd.Flags().StringVar(&replayMatch, "match", "", "only diff pages whose URL matches this glob")
+	replayCmd.Flags().BoolVar(&replayApply, "apply", false, "write the replayed output into the output directory")
+	replayCmd.Flags().BoolVar(&replayStat, "stat", false, "list the files that differ instead of printing the diff")
+	replayCmd.Flags().BoolVar(&replayJSON, "json", false, "print the pages replayed and the files that differ as JSON, with progress on stderr")
+}
+
+// replayTargets returns the saved response of each page to replay, keyed
+// by URL. Pages come from the converted events of eventsPath when it is
+// set and from the manifest otherwise; pages no longer in the manifest
+// or with no saved response are left out.
+func replayTargets(opts *CrawlOptions, current *Manifest, eventsPath string) (map[string]string, error) {
+	targets := map[string]string{}
+	if eventsPath != "" {
+		f, err := os.Open(eventsPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var e LogEvent
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				return nil, fmt.Errorf("%s: %w", eventsPath, err)
+			}
+			if e.Event == logConverted && e.Raw != "" && current.Pages[e.URL] != nil {
+				targets[e.URL] = filepath.Join(opts.Output, filepath.FromSlash(e.Raw))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		for link, entry := range current.Pages {
+			u, err := url.Parse(link)
+			if err != nil || entry.InlinedIn != "" {
+				continue
+			}
+			_, raw := opts.outputPath(u)
+			if raw != opts.markdownPath(raw) {
+				targets[link] = raw
+			}
+		}
+	}
+	for link, raw := range targets {
+		if _, err := statOutputFile(raw); err != nil {
+			delete(targets, link)
+		}
+	}
+	return targets, nil
+}
+
+// replayPages converts the saved responses of targets into scratch with
+// cfg, carrying the other pages of current over, then runs the site-wide
+// steps. It returns the scratch manifest.
+func replayPages(cfg Config, opts *CrawlOptions, current *Manifest, targets map[string]string, scratch string) (*Manifest, error) {
+	scratchOpts := *opts
+	if err := scratchOpts.useConversionConfig(cfg); err != nil {
+		return nil, err
+	}
+	scratchOpts.Output = scratch
+	scratchOpts.Events = NopEvents{}
+	manifest := &Manifest{Pages: map[string]*ManifestEntry{}}
+	scratchOpts.manifest = manifest
+	scratchOpts.report = &CrawlReport{}
+	scratchOpts.texts = newPageTexts()
+
+	for link, entry := range current.Pages {
+		if _, ok := targets[link]; ok {
+			continue
+		}
+		src := opts.pageFile(entry)
+		rel, err := filepath.Rel(opts.Output, src)
+		if err != nil {
+			continue
+		}
+		if data, err := readOutputFile(src); err == nil {
+			dst := filepath.Join(scratch, rel)
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(dst, data, 0644); err != nil {
+				return nil, err
+			}
+		}
+		carried := *entry
+		manifest.Pages[link] = &carried
+	}
+
+	for _, link := range sortedKeys(targets) {
+		entry := current.Pages[link]
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		body, err := readOutputFile(targets[link])
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", targets[link], err)
+			continue
+		}
+		headers := http.Header{}
+		headers.Set("Content-Type", "text/html; charset=utf-8")
+		if isNotebook(u, "") {
+			headers.Set("Content-Type", "application/x-ipynb+json")
+		}
+		if entry.LastModified != "" {
+			headers.Set("Last-Modified", entry.LastModified)
+		}
+		if !entry.Provenance.CrawledAt.IsZero() {
+			headers.Set(crawledAtHeader, entry.Provenance.CrawledAt.Format(time.RFC3339))
+		}
+		if archived := archivedURL(opts.pageFile(entry)); archived != "" {
+			headers.Set(archivedHeader, archived)
+		}
+		saveResponse(&colly.Response{
+			StatusCode: http.StatusOK,
+			Body:       body,
+			Request:    &colly.Request{URL: u},
+			Headers:    &headers,
+		}, &scratchOpts)
+	}
+
+	processSite(cfg, &scratchOpts, manifest)
+	return manifest, nil
+}
+
+// responseCrawledAt returns the recorded crawl time of a replayed
+// response, or zero for a response fetched now.
+func responseCrawledAt(r *colly.Response) time.Time {
+	t, _ := time.Parse(time.RFC3339, r.Headers.Get(crawledAtHeader))
+	return t
+}
+
+// archivedURL returns the Wayback Machine snapshot a saved page was
+// converted from, if any.
+func archivedURL(path string) string {
+	meta, _, err := readPage(path)
+	if err != nil {
+		return ""
+	}
+	metadata, _ := meta.Fields["metadata"].(map[string]interface{})
+	archived, _ := metadata["archived_url"].(string)
+	return archived
+}
+
+// replayChange is a file whose replayed output differs from the output
+// directory. Old and New are relative to the output and scratch
+// directories and empty when the file was added or dropped. URL is empty
+// for generated files, such as section SKILL.md files.
+type replayChange struct {
+	URL  string
+	Old  string
+	New  string
+	Diff string
+}
+
+// status returns the change's letter in --stat output.
+func (c replayChange) status() byte {
+	switch {
+	case c.Old == "":
+		return 'A'
+	case c.New == "":
+		return 'D'
+	case c.Old != c.New:
+		return 'R'
+	}
+	return 'M'
+}
+
+// path returns the file the change is listed under in --stat output.
+func (c replayChange) path() string {
+	switch {
+	case c.New == "":
+		return c.Old
+	case c.Old != "" && c.Old != c.New:
+		return c.Old + " -> " + c.New
+	}
+	return c.New
+}
+
+// replayChanges compares the replayed pages and generated files with the
+// output directory. Generated files are only compared when match is nil.
+func replayChanges(opts *CrawlOptions, scratch string, replayed, current *Manifest, targets map[string]string, match glob.Glob) []replayChange {
+	var links []string
+	for link := range targets {
+		links = append(links, link)
+	}
+	for link := range replayed.Pages {
+		if current.Pages[link] == nil {
+			links = append(links, link)
+		}
+	}
+	sort.Strings(links)
+
+	var changes []replayChange
+	for _, link := range links {
+		if match != nil && !match.Match(link) {
+			continue
+		}
+		c := replayChange{URL: link}
+		var before, after string
+		if old := current.Pages[link]; old != nil {
+			c.Old = old.Path
+			data, _ := readOutputFile(opts.pageFile(old))
+			before = string(data)
+		}
+		if entry := replayed.Pages[link]; entry != nil {
+			c.New = entry.Path
+			data, _ := os.ReadFile(filepath.Join(scratch, filepath.FromSlash(entry.Path)))
+			after = string(data)
+		}
+		if c.Old == c.New && before == after {
+			continue
+		}
+		c.Diff = unifiedDiff(diffName("a/", c.Old), diffName("b/", c.New), before, after)
+		changes = append(changes, c)
+	}
+
+	if match != nil {
+		return changes
+	}
+	for _, path := range sortedKeys(replayed.Generated) {
+		if current.Generated[path] == replayed.Generated[path] {
+			continue
+		}
+		before, _ := os.ReadFile(filepath.Join(opts.Output, filepath.FromSlash(path)))
+		after, _ := os.ReadFile(filepath.Join(scratch, filepath.FromSlash(path)))
+		if string(before) == string(after) {
+			continue
+		}
+		c := replayChange{New: path, Old: path}
+		if _, ok := current.Generated[path]; !ok {
+			c.Old = ""
+		}
+		c.Diff = unifiedDiff(diffName("a/", c.Old), diffName("b/", c.New), string(before), string(after))
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// replayPageChanges describes the pages a replay adds, updates, and
+// drops, for change summaries.
+func replayPageChanges(opts *CrawlOptions, scratch string, replayed, current *Manifest, changes []replayChange, keepDiff bool) []pageChange {
+	var pages []pageChange
+	for _, c := range changes {
+		if c.URL == "" {
+			continue
+		}
+		old, entry := current.Pages[c.URL], replayed.Pages[c.URL]
+		switch {
+		case old == nil:
+			pages = append(pages, newPageChange(pageAdded, c.URL, entry.Title, entry.Path, "", "", false))
+		case entry == nil:
+			pages = append(pages, newPageChange(pageRemoved, c.URL, old.Title, old.Path, "", "", false))
+		default:
+			before, _ := readOutputFile(opts.pageFile(old))
+			after, _ := os.ReadFile(filepath.Join(scratch, filepath.FromSlash(entry.Path)))
+			pages = append(pages, newPageChange(pageUpdated, c.URL, entry.Title, entry.Path, string(before), string(after), keepDiff))
+		}
+	}
+	return pages
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffName returns the name of one side of a diff: path under prefix, or
+// /dev/null when the file does not exist on that side.
+func diffName(prefix, path string) string {
+	if path == "" {
+		return "/dev/null"
+	}
+	return prefix + path
+}
+
+// colorDiff colors the removed and added lines of a unified diff.
+func colorDiff(diff string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			b.WriteString(ansiBold + line + ansiReset)
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString(ansiCyan + line + ansiReset)
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(ansiRed + line + ansiReset)
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(ansiGreen + line + ansiReset)
+		default:
+			b.WriteString(line)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// applyReplay copies the changed files from scratch into the output
+// directory, removes dropped and renamed pages, and updates the manifest,
+// recording tombstones of dropped pages and writing change summaries as
+// the crawl would.
+func applyReplay(opts *CrawlOptions, scratch string, replayed, current *Manifest, changes []replayChange, cfg Config, summaries []skillSummary) error {
+	if err := current.keepTombstones(cfg.Tombstones); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if c.New != "" {
+			data, err := os.ReadFile(filepath.Join(scratch, filepath.FromSlash(c.New)))
+			if err != nil {
+				return err
+			}
+			dst := filepath.Join(opts.Output, filepath.FromSlash(c.New))
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			if err := writeFileIfChanged(dst, data); err != nil {
+				return err
+			}
+			if c.URL == "" {
+				current.recordGenerated(c.New, string(data))
+			}
+		}
+		if c.Old != "" && c.Old != c.New {
+			removeOutputFile(filepath.Join(opts.Output, filepath.FromSlash(c.Old)))
+		}
+		if c.URL == "" {
+			continue
+		}
+		if entry := replayed.Pages[c.URL]; entry != nil {
+			if c.Old == "" {
+				// A page saved under a new canonical URL brings its
+				// saved response along.
+				if u, err := url.Parse(c.URL); err == nil {
+					_, raw := opts.outputPath(u)
+					if rel, err := filepath.Rel(opts.Output, raw); err == nil {
+						if data, err := os.ReadFile(filepath.Join(scratch, rel)); err == nil {
+							writeFileIfChanged(raw, data)
+						}
+					}
+				}
+			}
+			current.record(entry)
+		} else {
+			current.remove(c.URL, "dropped by the replayed config")
+		}
+	}
+	compressOutput(opts, current, cfg.Compression)
+	removeEmptyDirs(opts.Output)
+	syncTombstoneFiles(opts, current, cfg.Tombstones)
+	if cfg.ChangeSummary.Enabled {
+		writeChangeSummaries(opts, current, cfg.ChangeSummary, summaries)
+	}
+	return current.save(opts.Output)
+}
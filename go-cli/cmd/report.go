@@ -0,0 +1,176 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reportFileName is the crawl report written next to the manifest.
+const reportFileName = "crawl-report.json"
+
+// ReportEntry is a page left out of the output. Errors have a Kind, and
+// the HTTP Status when the fetch failed with one.
+type ReportEntry struct {
+	URL      string    `json:"url"`
+	FinalURL string    `json:"final_url,omitempty"`
+	Kind     ErrorKind `json:"kind,omitempty"`
+	Status   int       `json:"status,omitempty"`
+	Reason   string    `json:"reason"`
+}
+
+// CrawlReport lists the pages a crawl excluded, by kind.
+type CrawlReport struct {
+	GeneratedAt  time.Time     `json:"generated_at"`
+	SoftNotFound []ReportEntry `json:"soft_404"`
+	LoginWalls   []ReportEntry `json:"login_walls"`
+	Noindex      []ReportEntry `json:"noindex"`
+	Thin         []ReportEntry `json:"thin"`
+	Restricted   []ReportEntry `json:"restricted"`
+	Traps        []ReportEntry `json:"traps"`
+	Errors       []ReportEntry `json:"errors"`
+	// Warnings are what the conversion of kept pages lost, one entry per
+	// warning.
+	Warnings []ReportEntry `json:"warnings"`
+
+	mu sync.Mutex
+}
+
+// add records an excluded page under kind.
+func (r *CrawlReport) add(kind string, entry ReportEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch kind {
+	case pageSoftNotFound:
+		r.SoftNotFound = append(r.SoftNotFound, entry)
+	case pageLoginWall:
+		r.LoginWalls = append(r.LoginWalls, entry)
+	case pageNoindex:
+		r.Noindex = append(r.Noindex, entry)
+	case pageThin:
+		r.Thin = append(r.Thin, entry)
+	case pageRestricted:
+		r.Restricted = append(r.Restricted, entry)
+	case pageTrap:
+		r.Traps = append(r.Traps, entry)
+	default:
+		r.Errors = append(r.Errors, entry)
+	}
+}
+
+// warn records the conversion warnings of a kept page.
+func (r *CrawlReport) warn(link string, warnings []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, warning := range warnings {
+		r.Warnings = append(r.Warnings, ReportEntry{URL: link, Reason: warning})
+	}
+}
+
+// save writes the report to outDir and prints a summary.
+func (r *CrawlReport) save(outDir string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.GeneratedAt = time.Now().UTC()
+	for _, list := range []*[]ReportEntry{&r.SoftNotFound, &r.LoginWalls, &r.Noindex, &r.Thin, &r.Restricted, &r.Traps, &r.Errors, &r.Warnings} {
+		if *list == nil {
+			*list = []ReportEntry{}
+		}
+	}
+	if n := len(r.SoftNotFound) + len(r.LoginWalls) + len(r.Noindex) + len(r.Thin) + len(r.Restricted) + len(r.Traps) + len(r.Errors); n > 0 {
+		fmt.Printf("Excluded %d pages: %d soft 404s, %d login walls, %d noindex, %d thin, %d restricted, %d traps, %d errors%s; see %s\n",
+			n, len(r.SoftNotFound), len(r.LoginWalls), len(r.Noindex), len(r.Thin), len(r.Restricted), len(r.Traps), len(r.Errors), errorCounts(r.Errors), reportFileName)
+	}
+	if len(r.Warnings) > 0 {
+		fmt.Printf("Conversion warnings on %d pages:%s; see %s\n", warnedPages(r.Warnings), warningCounts(r.Warnings), reportFileName)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, reportFileName), append(data, '\n'), 0644)
+}
+
+// errorCounts describes how many errors of each kind there are, as
+// " (3 network, 1 write)".
+func errorCounts(entries []ReportEntry) string {
+	counts := map[string]int{}
+	for _, entry := range entries {
+		counts[string(entry.Kind)]++
+	}
+	var parts []string
+	for _, kind := range sortedKeys(counts) {
+		if kind != "" {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// warnedPages counts the pages with warnings.
+func warnedPages(entries []ReportEntry) int {
+	pages := map[string]bool{}
+	for _, entry := range entries {
+		pages[entry.URL] = true
+	}
+	return len(pages)
+}
+
+// warningCounts describes how many warnings of each kind there are, as
+// " 3 dropped elements, 1 truncated table".
+func warningCounts(entries []ReportEntry) string {
+	counts := map[string]int{}
+	for _, entry := range entries {
+		counts[warningKind(entry.Reason)]++
+	}
+	var parts []string
+	for _, kind := range sortedKeys(counts) {
+		label := kind
+		if counts[kind] != 1 {
+			label += "s"
+		}
+		parts = append(parts, fmt.Sprintf(" %d %s", counts[kind], label))
+	}
+	return strings.Join(parts, ",")
+}
+
+// loadReport reads the report of the last crawl from outDir. A missing
+// report yields an empty one.
+func loadReport(outDir string) (*CrawlReport, error) {
+	r := &CrawlReport{}
+	data, err := os.ReadFile(filepath.Join(outDir, reportFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// hostOverride sends connections to host:port to fixed addresses instead
+// of the ones DNS returns, like curl's --resolve.
+type hostOverride struct {
+	host  string
+	port  string
+	addrs []string
+}
+
+// parseResolve parses --resolve entries of the form
+// host:port:address[,address...]. IPv6 addresses may be bracketed.
+func parseResolve(entries []string) ([]hostOverride, error) {
+	var overrides []hostOverride
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid resolve entry %q: want host:port:address", entry)
+		}
+		o := hostOverride{host: strings.ToLower(parts[0]), port: parts[1]}
+		for _, addr := range strings.Split(parts[2], ",") {
+			addr = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(addr), "["), "]")
+			if addr == "" {
+				return nil, fmt.Errorf("invalid resolve entry %q: empty address", entry)
+			}
+			o.addrs = append(o.addrs, addr)
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// dialFunc dials network connections, as http.Transport.DialContext does.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newDialer returns a dialer that applies overrides and looks other hosts
+// up with the DNS servers in cfg, or the system resolver when there are
+// none. It returns nil when neither is configured.
+func newDialer(overrides []hostOverride, cfg DNSConfig) dialFunc {
+	if len(overrides) == 0 && len(cfg.Servers) == 0 {
+		return nil
+	}
+	d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if len(cfg.Servers) > 0 {
+		servers := make([]string, len(cfg.Servers))
+		for i, s := range cfg.Servers {
+			if _, _, err := net.SplitHostPort(s); err != nil {
+				s = net.JoinHostPort(strings.Trim(s, "[]"), "53")
+			}
+			servers[i] = s
+		}
+		d.Resolver = &net.Resolver{
+			PreferGo: true,
+			// Each lookup tries the servers in order.
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var err error
+				for _, server := range servers {
+					var conn net.Conn
+					if conn, err = (&net.Dialer{Timeout: 5 * time.Second}).DialContext(ctx, network, server); err == nil {
+						return conn, nil
+					}
+				}
+				return nil, err
+			},
+		}
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return d.DialContext(ctx, network, addr)
+		}
+		for _, o := range overrides {
+			if o.host != strings.ToLower(host) || o.port != port {
+				continue
+			}
+			for _, a := range o.addrs {
+				var conn net.Conn
+				if conn, err = d.DialContext(ctx, network, net.JoinHostPort(a, port)); err == nil {
+					return conn, nil
+				}
+			}
+			return nil, err
+		}
+		return d.DialContext(ctx, network, addr)
+	}
+}
+
+// browserHostRules returns the overrides as a Chrome --host-resolver-rules
+// value, which maps only the first address of each.
+func browserHostRules(overrides []hostOverride) string {
+	var rules []string
+	for _, o := range overrides {
+		rules = append(rules, fmt.Sprintf("MAP %s:%s %s", o.host, o.port, net.JoinHostPort(o.addrs[0], o.port)))
+	}
+	return strings.Join(rules, ", ")
+}
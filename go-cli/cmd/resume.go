@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/gocolly/colly/v2/queue"
+)
+
+// resumeFileName holds the frontier of a crawl stopped by --max-duration.
+const resumeFileName = ".crawl-resume.json"
+
+// resumeState is the pending frontier saved when a crawl runs out of time.
+// Stores that persist their own queue (disk, redis) keep it in place and
+// save no URLs here.
+type resumeState struct {
+	Token   string    `json:"token"`
+	SavedAt time.Time `json:"saved_at"`
+	Queue   string    `json:"queue"`
+	Pending []string  `json:"pending,omitempty"`
+}
+
+// stoppableQueue wraps the frontier so a crawl can stop early. Once
+// stopped it reports an empty queue, so colly's queue runner finishes the
+// requests in flight and returns while pending ones stay in the store.
+// With a schedule it also holds back requests to hosts outside their
+// crawl windows.
+type stoppableQueue struct {
+	queue.Storage
+	stopped  atomic.Bool
+	schedule *crawlSchedule
+	paused   map[string]bool
+}
+
+func (s *stoppableQueue) QueueSize() (int, error) {
+	if s.stopped.Load() {
+		return 0, nil
+	}
+	return s.Storage.QueueSize()
+}
+
+// GetRequest returns the next request whose host may be crawled now.
+// Requests to other hosts go back into the queue once one is found, so
+// they keep their priority; when every pending request has to wait, it
+// sleeps until the first window opens.
+func (s *stoppableQueue) GetRequest() ([]byte, error) {
+	if s.schedule == nil {
+		return s.Storage.GetRequest()
+	}
+	for !s.stopped.Load() {
+		size, err := s.Storage.QueueSize()
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		var wait time.Duration = -1
+		var held [][]byte
+		requeue := func() error {
+			for _, r := range held {
+				if err := s.Storage.AddRequest(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for i := 0; i < size; i++ {
+			r, err := s.Storage.GetRequest()
+			if err != nil || r == nil {
+				if err := requeue(); err != nil {
+					return nil, err
+				}
+				return r, err
+			}
+			host := requestHost(r)
+			d, loc := s.schedule.wait(host, time.Now())
+			if d == 0 {
+				if s.paused[host] {
+					fmt.Printf("Crawl window open, resuming %s\n", host)
+					delete(s.paused, host)
+				}
+				return r, requeue()
+			}
+			if !s.paused[host] {
+				if s.paused == nil {
+					s.paused = map[string]bool{}
+				}
+				s.paused[host] = true
+				fmt.Printf("Outside crawl window, pausing %s until %s\n", host, time.Now().Add(d).In(loc).Format("15:04 MST"))
+			}
+			if wait < 0 || d < wait {
+				wait = d
+			}
+			held = append(held, r)
+		}
+		if err := requeue(); err != nil {
+			return nil, err
+		}
+		for deadline := time.Now().Add(wait); time.Now().Before(deadline) && !s.stopped.Load(); {
+			time.Sleep(min(time.Until(deadline), time.Second))
+		}
+	}
+	return nil, fmt.Errorf("crawl stopped")
+}
+
+// stop stops handing out requests.
+func (s *stoppableQueue) stop() {
+	s.stopped.Store(true)
+}
+
+// stopMargin returns how long before the deadline a crawl limited to d
+// stops taking new requests, leaving time for in-flight work to finish.
+func stopMargin(d time.Duration) time.Duration {
+	return min(d/10, time.Minute)
+}
+
+// newResumeToken returns a random token identifying a partial crawl.
+func newResumeToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// drainQueue removes every pending request from the store and returns
+// their URLs.
+func drainQueue(c *colly.Collector, store crawlStore) ([]string, error) {
+	var pending []string
+	for {
+		size, err := store.QueueSize()
+		if err != nil {
+			return pending, err
+		}
+		if size == 0 {
+			return pending, nil
+		}
+		buf, err := store.GetRequest()
+		if err != nil {
+			return pending, err
+		}
+		r, err := c.UnmarshalRequest(buf)
+		if err != nil {
+			continue
+		}
+		pending = append(pending, r.URL.String())
+	}
+}
+
+// saveResumeState writes the resume file to outDir.
+func saveResumeState(outDir string, state *resumeState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, resumeFileName), append(data, '\n'), 0644)
+}
+
+// loadResumeState reads the resume file from outDir and checks that it
+// belongs to the partial crawl identified by token.
+func loadResumeState(outDir, token string) (*resumeState, error) {
+	data, err := os.ReadFile(filepath.Join(outDir, resumeFileName))
+	if err != nil {
+		return nil, err
+	}
+	var state resumeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state.Token != token {
+		return nil, fmt.Errorf("resume token %q does not match the saved crawl (%q)", token, state.Token)
+	}
+	return &state, nil
+}
+
+// clearResumeState removes the resume file after a crawl completes.
+func clearResumeState(outDir string) {
+	os.Remove(filepath.Join(outDir, resumeFileName))
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// pageNoindex is the report kind for pages excluded by robots directives.
+const pageNoindex = "noindex"
+
+// pageRestricted is the report kind for pages excluded by noai directives.
+const pageRestricted = "restricted"
+
+// isNoindex reports whether a page asks not to be indexed, through an
+// X-Robots-Tag header or a robots meta tag. Directives scoped to another
+// crawler (e.g. "googlebot: noindex") are ignored.
+func isNoindex(headers *http.Header, body []byte) bool {
+	for _, value := range headerDirectives(headers) {
+		if hasNoindex(value) {
+			return true
+		}
+	}
+	doc := htmlDocument(headers, body)
+	return doc != nil && metaNoindex(doc)
+}
+
+// metaNoindex reports whether a robots meta tag in doc asks not to index
+// the page.
+func metaNoindex(doc *goquery.Document) bool {
+	for _, value := range metaDirectives(doc) {
+		if hasNoindex(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// aiDirectives returns the noai and noimageai directives, which ask not
+// to use the page or its images for AI, given to this crawler by the
+// page's X-Robots-Tag headers or, when doc is not nil, its robots meta
+// tags.
+func aiDirectives(headers *http.Header, doc *goquery.Document) []string {
+	values := headerDirectives(headers)
+	if doc != nil {
+		values = append(values, metaDirectives(doc)...)
+	}
+	var found []string
+	for _, value := range values {
+		for _, d := range strings.Split(value, ",") {
+			d = strings.ToLower(strings.TrimSpace(d))
+			if (d == "noai" || d == "noimageai") && !slices.Contains(found, d) {
+				found = append(found, d)
+			}
+		}
+	}
+	return found
+}
+
+// restrictedDirectives is aiDirectives for a response that has not been
+// parsed.
+func restrictedDirectives(headers *http.Header, body []byte) []string {
+	return aiDirectives(headers, htmlDocument(headers, body))
+}
+
+// htmlDocument parses body when headers say it is HTML, or returns nil.
+func htmlDocument(headers *http.Header, body []byte) *goquery.Document {
+	if headers == nil || !strings.Contains(strings.ToLower(headers.Get("Content-Type")), "text/html") {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	return doc
+}
+
+// headerDirectives returns the X-Robots-Tag directive lists that apply to
+// this crawler.
+func headerDirectives(headers *http.Header) []string {
+	if headers == nil {
+		return nil
+	}
+	var lists []string
+	for _, value := range headers.Values("X-Robots-Tag") {
+		if agent, directives, ok := strings.Cut(value, ":"); ok && !strings.Contains(agent, ",") {
+			if !robotsAgentApplies(agent) {
+				continue
+			}
+			value = directives
+		}
+		lists = append(lists, value)
+	}
+	return lists
+}
+
+// metaDirectives returns the contents of the robots meta tags in doc that
+// apply to this crawler.
+func metaDirectives(doc *goquery.Document) []string {
+	var lists []string
+	doc.Find("meta[name]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		if robotsAgentApplies(name) || strings.EqualFold(name, "robots") {
+			lists = append(lists, s.AttrOr("content", ""))
+		}
+	})
+	return lists
+}
+
+// robotsAgentApplies reports whether robots directives for agent apply to
+// this crawler.
+func robotsAgentApplies(agent string) bool {
+	agent = strings.ToLower(strings.TrimSpace(agent))
+	return agent == "*" || agent == toolName
+}
+
+// hasNoindex reports whether a comma-separated directive list contains
+// noindex or none.
+func hasNoindex(directives string) bool {
+	for _, d := range strings.Split(directives, ",") {
+		switch strings.ToLower(strings.TrimSpace(d)) {
+		case "noindex", "none":
+			return true
+		}
+	}
+	return false
+}
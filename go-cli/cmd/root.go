@@ -32,6 +32,10 @@ Markdown files suitable for agentic skills and knowledge bases.`,
 		if err := initConfig(); err != nil {
 			fmt.Printf("Config error: %v\n", err)
 		}
+		if err := checkNamespace(viper.GetString("namespace")); err != nil {
+			fmt.Printf("Config error: %v\n", err)
+			os.Exit(1)
+		}
 	},
 	// Run the crawl command by default if no subcommand is specified
 	Run: func(cmd *cobra.Command, args []string) {
@@ -48,16 +52,48 @@ func Execute() {
 
 func init() {
 	// Global persistent flags
-	rootCmd.PersistentFlags().StringVar(&configFile, "config", ".skillscontext", "config file path")
-	rootCmd.PersistentFlags().StringVar(&outputDir, "output", ".skillscache", "output directory")
-	rootCmd.PersistentFlags().BoolVar(&flatOutput, "flat", false, "save files in a flat directory structure")
-	rootCmd.PersistentFlags().StringVar(&fileRename, "rename", "", "rename output markdown file (e.g. SKILL.md)")
+	rootCmd.PersistentFlags().StringSlice("config", []string{".skillscontext"}, "pattern file path: line-based, or .yaml, .json, or .toml (repeatable, read in order)")
+	rootCmd.PersistentFlags().String("output", ".skillscache", "output directory")
+	rootCmd.PersistentFlags().String("namespace", "", "keep output, manifests, locks, and crawl state under <output>/<namespace>")
+	rootCmd.PersistentFlags().Bool("flat", false, "save files in a flat directory structure")
+	rootCmd.PersistentFlags().String("rename", "", "rename output markdown file (e.g. SKILL.md)")
 
 	// Bind viper to these persistent flags
 	viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
 	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	viper.BindPFlag("namespace", rootCmd.PersistentFlags().Lookup("namespace"))
 	viper.BindPFlag("flat", rootCmd.PersistentFlags().Lookup("flat"))
 	viper.BindPFlag("file_rename", rootCmd.PersistentFlags().Lookup("rename"))
+
+	// Crawl-only flags are shared by the root command and the crawl subcommand
+	rootCmd.Flags().AddFlagSet(crawlFlags)
+	viper.BindPFlag("queue", crawlFlags.Lookup("queue"))
+	viper.BindPFlag("state_file", crawlFlags.Lookup("state-file"))
+	viper.BindPFlag("storage.backend", crawlFlags.Lookup("storage"))
+	viper.BindPFlag("distributed", crawlFlags.Lookup("distributed"))
+	viper.BindPFlag("redis.addr", crawlFlags.Lookup("redis"))
+	viper.BindPFlag("boilerplate.enabled", crawlFlags.Lookup("dedup-boilerplate"))
+	viper.BindPFlag("compression", crawlFlags.Lookup("compression"))
+	viper.BindPFlag("sign_key", crawlFlags.Lookup("sign-key"))
+	viper.BindPFlag("fetcher", crawlFlags.Lookup("fetcher"))
+	viper.BindPFlag("as_of", crawlFlags.Lookup("as-of"))
+	viper.BindPFlag("local_root", crawlFlags.Lookup("local-root"))
+	viper.BindPFlag("warc_files", crawlFlags.Lookup("warc-file"))
+	viper.BindPFlag("warc_out", crawlFlags.Lookup("warc-out"))
+	viper.BindPFlag("ignore_noindex", crawlFlags.Lookup("ignore-noindex"))
+	viper.BindPFlag("exclude_noai", crawlFlags.Lookup("exclude-noai"))
+	viper.BindPFlag("raw_markdown", crawlFlags.Lookup("raw-markdown"))
+	viper.BindPFlag("fetch_errors.non_interactive", crawlFlags.Lookup("non-interactive"))
+	viper.BindPFlag("resolve", crawlFlags.Lookup("resolve"))
+	viper.BindPFlag("dns.servers", crawlFlags.Lookup("dns-server"))
+	viper.BindPFlag("event_log", crawlFlags.Lookup("event-log"))
+	viper.BindPFlag("errors_out", crawlFlags.Lookup("errors-out"))
+	viper.BindPFlag("max_duration", crawlFlags.Lookup("max-duration"))
+	viper.BindPFlag("resume", crawlFlags.Lookup("resume"))
+	viper.BindPFlag("urls", crawlFlags.Lookup("urls"))
+	viper.BindPFlag("extraction.profile", crawlFlags.Lookup("profile"))
+	viper.BindPFlag("extraction.html_fallback", crawlFlags.Lookup("html-fallback"))
+	viper.BindPFlag("extraction.stop_sections", crawlFlags.Lookup("stop-sections"))
 }
 
 func initConfig() error {
@@ -73,6 +109,13 @@ func initConfig() error {
 			// Config file was found but another error was produced
 			return err
 		}
+		return nil
+	}
+
+	// Resolve extends/include into the final settings
+	settings, err := loadComposedConfig(viper.ConfigFileUsed())
+	if err != nil {
+		return err
 	}
-	return nil
+	return viper.MergeConfigMap(settings)
 }
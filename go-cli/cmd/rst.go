@@ -0,0 +1,616 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// rstConverter converts reStructuredText to markdown. It covers the
+// constructs Sphinx projects use most: sections, literal and code blocks,
+// admonitions, lists, field lists, tables, and the Python domain roles
+// and directives. Directives it does not know keep their content.
+type rstConverter struct {
+	// styles lists section adornments in the order they appear, which is
+	// how reStructuredText assigns heading levels.
+	styles      []string
+	title       string
+	description string
+}
+
+var (
+	rstDirective   = regexp.MustCompile(`^\.\.\s+([\w:.-]+)::\s*(.*)$`)
+	rstOption      = regexp.MustCompile(`^:([\w -]+):\s*(.*)$`)
+	rstListItem    = regexp.MustCompile(`^([-*+•]|\d+[.)]|#[.)]|\(\d+\))\s+`)
+	rstField       = regexp.MustCompile("^:([^:`]+):(?:\\s+|$)(.*)$")
+	rstSimpleTable = regexp.MustCompile(`^=+( +=+)+$`)
+	rstLiteral     = regexp.MustCompile("``(.+?)``")
+	rstRole        = regexp.MustCompile(":((?:[\\w-]+:)?[\\w-]+):`([^`]+)`")
+	rstLink        = regexp.MustCompile("`([^`<]*?)\\s*<([^`>]+)>`__?")
+	rstReference   = regexp.MustCompile("`([^`]+)`__?")
+	rstFootnote    = regexp.MustCompile(`\[(\d+|#\w*|\*)\]_`)
+	rstTitleTarget = regexp.MustCompile(`^(.*?)\s*<([^<>]+)>$`)
+)
+
+// rstAdmonitions maps admonition directives to their label.
+var rstAdmonitions = map[string]string{
+	"note":      "Note",
+	"warning":   "Warning",
+	"tip":       "Tip",
+	"hint":      "Hint",
+	"important": "Important",
+	"caution":   "Caution",
+	"danger":    "Danger",
+	"attention": "Attention",
+	"error":     "Error",
+	"seealso":   "See also",
+	"todo":      "To do",
+}
+
+// rstVersionNotes maps version directives to the sentence Sphinx renders.
+var rstVersionNotes = map[string]string{
+	"versionadded":   "New in version",
+	"versionchanged": "Changed in version",
+	"deprecated":     "Deprecated since version",
+	"versionremoved": "Removed in version",
+}
+
+// rstSignatures are the object description directives of the Sphinx
+// domains, rendered as their signature.
+var rstSignatures = map[string]string{
+	"function": "", "method": "", "staticmethod": "", "classmethod": "",
+	"attribute": "", "property": "", "data": "", "member": "", "var": "",
+	"macro": "", "type": "", "struct": "", "enum": "", "describe": "",
+	"object": "", "envvar": "", "option": "", "module": "",
+	"class": "class ", "exception": "exception ", "decorator": "@",
+}
+
+// rstSkipped are directives with no useful markdown output.
+var rstSkipped = map[string]bool{
+	"toctree": true, "contents": true, "index": true, "currentmodule": true,
+	"highlight": true, "include": true, "literalinclude": true, "raw": true,
+	"tabularcolumns": true, "sectionauthor": true, "codeauthor": true,
+	"autosummary": true, "target": true, "meta": true, "sectnum": true,
+}
+
+// convertRST converts a reStructuredText file in a git source.
+func convertRST(data []byte, opts *CrawlOptions) (sourceDoc, error) {
+	c := &rstConverter{}
+	text := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\t", "        ")
+	body := strings.Join(c.blocks(strings.Split(text, "\n")), "\n\n")
+	return sourceDoc{Title: c.title, Description: c.description, Body: strings.TrimSpace(body) + "\n"}, nil
+}
+
+// blocks converts a run of lines at the same indentation to markdown
+// blocks.
+func (c *rstConverter) blocks(lines []string) []string {
+	var out []string
+	for i := 0; i < len(lines); {
+		line := strings.TrimRight(lines[i], " ")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+
+		case indentOf(line) > 0:
+			block, next := takeIndented(lines, i)
+			for _, b := range c.blocks(dedent(block)) {
+				out = append(out, quoteMarkdown(b))
+			}
+			i = next
+
+		case strings.HasPrefix(line, ".. ") || line == "..":
+			out = append(out, c.explicit(lines, &i)...)
+
+		case isAdornment(line) && i+2 < len(lines) && isAdornment(lines[i+2]) && strings.TrimSpace(lines[i+1]) != "":
+			if h := c.heading(strings.TrimSpace(lines[i+1]), "over"+line[:1]); h != "" {
+				out = append(out, h)
+			}
+			i += 3
+
+		case i+1 < len(lines) && isAdornment(lines[i+1]) && !isAdornment(line):
+			if h := c.heading(trimmed, strings.TrimSpace(lines[i+1])[:1]); h != "" {
+				out = append(out, h)
+			}
+			i += 2
+
+		case isAdornment(line):
+			// A transition between sections.
+			out = append(out, "---")
+			i++
+
+		case strings.HasPrefix(line, ">>>"):
+			j := i
+			for j < len(lines) && strings.TrimSpace(lines[j]) != "" {
+				j++
+			}
+			out = append(out, fence("pycon", lines[i:j]))
+			i = j
+
+		case strings.HasPrefix(line, "+-") || strings.HasPrefix(line, "+="):
+			// Grid tables keep their layout as preformatted text.
+			j := i
+			for j < len(lines) && strings.TrimSpace(lines[j]) != "" {
+				j++
+			}
+			out = append(out, fence("text", lines[i:j]))
+			i = j
+
+		case rstSimpleTable.MatchString(line):
+			table, next := c.simpleTable(lines, i)
+			out = append(out, table)
+			i = next
+
+		case rstListItem.MatchString(line):
+			out = append(out, c.list(lines, &i))
+
+		case rstField.MatchString(line):
+			out = append(out, c.fields(lines, &i))
+
+		default:
+			out = append(out, c.paragraph(lines, &i)...)
+		}
+	}
+	return out
+}
+
+// heading records a section title. The first top-level title becomes the
+// document title; the others become markdown headings.
+func (c *rstConverter) heading(text, style string) string {
+	level := 0
+	for level < len(c.styles) && c.styles[level] != style {
+		level++
+	}
+	if level == len(c.styles) {
+		c.styles = append(c.styles, style)
+	}
+	text = rstInline(text)
+	if level == 0 && c.title == "" {
+		c.title = text
+		return ""
+	}
+	return strings.Repeat("#", min(level+1, 6)) + " " + text
+}
+
+// paragraph converts a paragraph, a definition list item, or a paragraph
+// introducing a literal block ("::").
+func (c *rstConverter) paragraph(lines []string, i *int) []string {
+	start := *i
+	j := start
+	for j < len(lines) && strings.TrimSpace(lines[j]) != "" && indentOf(lines[j]) == 0 {
+		j++
+	}
+	para := lines[start:j]
+	*i = j
+
+	if len(para) == 1 && j < len(lines) && strings.TrimSpace(lines[j]) != "" && indentOf(lines[j]) > 0 {
+		// A definition list item: the term, then its indented definition.
+		def, next := takeIndented(lines, j)
+		*i = next
+		term, _, _ := strings.Cut(para[0], " : ")
+		return append([]string{"**" + rstInline(strings.TrimSpace(term)) + "**"}, c.blocks(dedent(def))...)
+	}
+
+	text := strings.Join(para, "\n")
+	literal := strings.HasSuffix(text, "::")
+	if literal {
+		switch {
+		case strings.TrimSpace(text) == "::":
+			text = ""
+		case strings.HasSuffix(text, " ::"):
+			text = strings.TrimSuffix(text, " ::")
+		default:
+			text = strings.TrimSuffix(text, ":")
+		}
+	}
+	var out []string
+	if text != "" {
+		out = append(out, rstInline(text))
+	}
+	if literal {
+		for *i < len(lines) && strings.TrimSpace(lines[*i]) == "" {
+			*i++
+		}
+		if *i < len(lines) && indentOf(lines[*i]) > 0 {
+			block, next := takeIndented(lines, *i)
+			*i = next
+			out = append(out, fence("", dedent(block)))
+		}
+	}
+	return out
+}
+
+// list converts consecutive list items into one markdown list.
+func (c *rstConverter) list(lines []string, i *int) string {
+	var items []string
+	kind := ""
+	for *i < len(lines) {
+		marker := rstListItem.FindString(lines[*i])
+		if marker == "" {
+			break
+		}
+		bullet := "- "
+		if m := strings.TrimSpace(marker); m[0] >= '0' && m[0] <= '9' || m[0] == '#' || m[0] == '(' {
+			bullet = "1. "
+		}
+		if kind != "" && bullet != kind {
+			break
+		}
+		kind = bullet
+		width := len(marker)
+		item := []string{strings.Repeat(" ", width) + lines[*i][width:]}
+		j := *i + 1
+		for j < len(lines) && (strings.TrimSpace(lines[j]) == "" || indentOf(lines[j]) >= width) {
+			j++
+		}
+		// Trailing blank lines separate this item from the next.
+		for j > *i+1 && strings.TrimSpace(lines[j-1]) == "" {
+			j--
+		}
+		item = append(item, lines[*i+1:j]...)
+		*i = j
+		for *i < len(lines) && strings.TrimSpace(lines[*i]) == "" {
+			*i++
+		}
+
+		inner := strings.Join(c.blocks(dedent(item)), "\n\n")
+		items = append(items, bullet+indentMarkdown(inner, len(bullet)))
+	}
+	return strings.Join(items, "\n")
+}
+
+// fields converts a field list, such as a Sphinx docstring's :param x:
+// entries, to a bulleted list.
+func (c *rstConverter) fields(lines []string, i *int) string {
+	var items []string
+	for *i < len(lines) {
+		m := rstField.FindStringSubmatch(lines[*i])
+		if m == nil {
+			break
+		}
+		text := []string{m[2]}
+		*i++
+		for *i < len(lines) && strings.TrimSpace(lines[*i]) != "" && indentOf(lines[*i]) > 0 {
+			text = append(text, strings.TrimSpace(lines[*i]))
+			*i++
+		}
+		items = append(items, "- **"+rstInline(m[1])+"**: "+rstInline(strings.TrimSpace(strings.Join(text, " "))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// simpleTable converts a table drawn with "=" column borders. Rows whose
+// first column is empty continue the row above.
+func (c *rstConverter) simpleTable(lines []string, i int) (string, int) {
+	border := lines[i]
+	var starts []int
+	for k := 0; k < len(border); k++ {
+		if border[k] == '=' && (k == 0 || border[k-1] == ' ') {
+			starts = append(starts, k)
+		}
+	}
+	var rows [][]string
+	header := 0
+	borders := 1
+	j := i + 1
+	for ; j < len(lines); j++ {
+		line := strings.TrimRight(lines[j], " ")
+		if rstSimpleTable.MatchString(line) {
+			borders++
+			if borders == 2 {
+				header = len(rows)
+			}
+			if j+1 >= len(lines) || strings.TrimSpace(lines[j+1]) == "" {
+				j++
+				break
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		cells := make([]string, len(starts))
+		for k, s := range starts {
+			if s >= len(line) {
+				break
+			}
+			end := len(line)
+			if k+1 < len(starts) && starts[k+1] < end {
+				end = starts[k+1]
+			}
+			cells[k] = strings.TrimSpace(line[s:end])
+		}
+		if cells[0] == "" && len(rows) > 0 {
+			prev := rows[len(rows)-1]
+			for k, cell := range cells {
+				prev[k] = strings.TrimSpace(prev[k] + " " + cell)
+			}
+			continue
+		}
+		rows = append(rows, cells)
+	}
+	if borders < 3 {
+		header = 0
+	}
+	for _, row := range rows {
+		for k := range row {
+			row[k] = rstInline(row[k])
+		}
+	}
+	if header > 1 {
+		rows = rows[header-1:]
+	}
+	return markdownTable(rows), j
+}
+
+// explicit converts an explicit markup block starting at lines[*i]: a
+// directive, or a comment, target, footnote, or substitution, which are
+// dropped.
+func (c *rstConverter) explicit(lines []string, i *int) []string {
+	first := strings.TrimSpace(lines[*i])
+	body, next := takeIndented(lines, *i+1)
+	*i = next
+
+	m := rstDirective.FindStringSubmatch(first)
+	if m == nil {
+		if rest, ok := strings.CutPrefix(first, ".. ["); ok {
+			label, text, _ := strings.Cut(rest, "]")
+			note := append([]string{strings.TrimSpace(text)}, dedent(body)...)
+			return []string{"[" + label + "] " + rstInline(strings.TrimSpace(strings.Join(note, " ")))}
+		}
+		return nil
+	}
+	name := strings.ToLower(m[1])
+	if k := strings.LastIndex(name, ":"); k >= 0 {
+		name = name[k+1:]
+	}
+	arg := m[2]
+
+	content := dedent(body)
+	options := map[string]string{}
+	for len(content) > 0 {
+		opt := rstOption.FindStringSubmatch(content[0])
+		if opt == nil {
+			break
+		}
+		options[opt[1]] = opt[2]
+		content = content[1:]
+	}
+
+	switch {
+	case name == "code-block" || name == "code" || name == "sourcecode":
+		return []string{fence(arg, trimBlank(content))}
+	case name == "doctest":
+		return []string{fence("pycon", trimBlank(content))}
+	case name == "math":
+		if arg != "" {
+			content = append([]string{arg}, content...)
+		}
+		return []string{fence("math", trimBlank(content))}
+	case name == "meta":
+		if d := options["description"]; d != "" {
+			c.description = d
+		}
+		return nil
+	case rstSkipped[name] || strings.HasPrefix(name, "auto"):
+		return nil
+	case name == "image" || name == "figure":
+		alt := options["alt"]
+		out := []string{"![" + alt + "](" + arg + ")"}
+		return append(out, c.blocks(content)...)
+	case name == "list-table":
+		return []string{c.listTable(content)}
+	case name == "csv-table":
+		return []string{c.csvTable(content, options)}
+	case name == "rubric":
+		return []string{"**" + rstInline(arg) + "**"}
+	case name == "admonition" || rstAdmonitions[name] != "":
+		label := rstAdmonitions[name]
+		if name == "admonition" {
+			label, arg = arg, ""
+		}
+		if arg != "" {
+			content = append([]string{arg, ""}, content...)
+		}
+		return []string{admonitionMarkdown(label, c.blocks(content))}
+	case rstVersionNotes[name] != "":
+		version, rest, _ := strings.Cut(arg, " ")
+		note := "*" + rstVersionNotes[name] + " " + version
+		if rest == "" && len(content) == 0 {
+			return []string{note + ".*"}
+		}
+		inner := c.blocks(content)
+		if rest != "" {
+			inner = append([]string{rstInline(rest)}, inner...)
+		}
+		inner[0] = note + ":* " + inner[0]
+		return inner
+	}
+	if prefix, ok := rstSignatures[name]; ok && arg != "" {
+		return append([]string{"**`" + prefix + arg + "`**"}, c.blocks(content)...)
+	}
+	var out []string
+	if arg != "" && (name == "topic" || name == "sidebar" || name == "tab" || name == "group-tab") {
+		out = append(out, "**"+rstInline(arg)+"**")
+	}
+	return append(out, c.blocks(content)...)
+}
+
+// listTable converts a list-table directive, a bullet list of rows each
+// holding a bullet list of cells.
+func (c *rstConverter) listTable(content []string) string {
+	var rows [][]string
+	for _, line := range content {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case indentOf(line) == 0 && (strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "- ")):
+			rows = append(rows, nil)
+			if cell := strings.TrimSpace(trimmed[2:]); strings.HasPrefix(cell, "- ") || strings.HasPrefix(cell, "* ") {
+				rows[len(rows)-1] = append(rows[len(rows)-1], rstInline(strings.TrimSpace(cell[2:])))
+			}
+		case len(rows) > 0 && (strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ")):
+			rows[len(rows)-1] = append(rows[len(rows)-1], rstInline(strings.TrimSpace(trimmed[2:])))
+		case len(rows) > 0 && len(rows[len(rows)-1]) > 0:
+			row := rows[len(rows)-1]
+			row[len(row)-1] += " " + rstInline(trimmed)
+		}
+	}
+	return markdownTable(rows)
+}
+
+// csvTable converts a csv-table directive with inline content.
+func (c *rstConverter) csvTable(content []string, options map[string]string) string {
+	var rows [][]string
+	if h := options["header"]; h != "" {
+		if header, err := csv.NewReader(strings.NewReader(h)).Read(); err == nil {
+			rows = append(rows, header)
+		}
+	}
+	r := csv.NewReader(strings.NewReader(strings.Join(content, "\n")))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, _ := r.ReadAll()
+	rows = append(rows, records...)
+	for _, row := range rows {
+		for k := range row {
+			row[k] = rstInline(row[k])
+		}
+	}
+	return markdownTable(rows)
+}
+
+// rstInline converts inline markup: literals, roles, hyperlinks, and
+// footnote references. Emphasis and strong emphasis are already markdown.
+func rstInline(s string) string {
+	var literals []string
+	s = rstLiteral.ReplaceAllStringFunc(s, func(m string) string {
+		literals = append(literals, rstLiteral.FindStringSubmatch(m)[1])
+		return fmt.Sprintf("\x00%d\x00", len(literals)-1)
+	})
+	s = rstRole.ReplaceAllStringFunc(s, func(m string) string {
+		sub := rstRole.FindStringSubmatch(m)
+		return rstRoleText(sub[1], sub[2])
+	})
+	s = rstLink.ReplaceAllStringFunc(s, func(m string) string {
+		sub := rstLink.FindStringSubmatch(m)
+		text, target := sub[1], sub[2]
+		if strings.HasSuffix(target, "_") {
+			// A named reference to a target defined elsewhere.
+			return text
+		}
+		if text == "" {
+			text = target
+		}
+		return "[" + text + "](" + target + ")"
+	})
+	s = rstReference.ReplaceAllString(s, "$1")
+	s = rstFootnote.ReplaceAllString(s, "[$1]")
+	for k, lit := range literals {
+		s = strings.Replace(s, fmt.Sprintf("\x00%d\x00", k), codeSpan(lit), 1)
+	}
+	return s
+}
+
+// rstRoleText renders an interpreted text role the way Sphinx displays it.
+func rstRoleText(role, text string) string {
+	if k := strings.LastIndex(role, ":"); k >= 0 {
+		role = role[k+1:]
+	}
+	title, target := text, text
+	explicit := false
+	if m := rstTitleTarget.FindStringSubmatch(text); m != nil && m[1] != "" {
+		title, target, explicit = m[1], m[2], true
+	}
+	target = strings.TrimPrefix(target, "!")
+	if !explicit {
+		if short, ok := strings.CutPrefix(target, "~"); ok {
+			short = short[strings.LastIndex(short, ".")+1:]
+			target = short
+		}
+		title = target
+	}
+
+	switch role {
+	case "func", "meth", "function", "method":
+		if !explicit && !strings.HasSuffix(title, ")") {
+			title += "()"
+		}
+		return codeSpan(title)
+	case "class", "mod", "attr", "exc", "data", "obj", "const", "type",
+		"member", "var", "macro", "struct", "enum", "envvar", "option",
+		"code", "file", "samp", "command", "program", "kbd", "makevar",
+		"mimetype", "regexp", "any", "attribute", "exception", "literal":
+		return codeSpan(title)
+	case "math":
+		return "$" + text + "$"
+	case "pep":
+		return "PEP " + target
+	case "rfc":
+		return "RFC " + target
+	case "doc":
+		if !explicit {
+			return path.Base(target)
+		}
+	case "guilabel":
+		return strings.ReplaceAll(title, "&", "")
+	case "menuselection":
+		return strings.ReplaceAll(title, "-->", ">")
+	case "emphasis":
+		return "*" + title + "*"
+	case "strong":
+		return "**" + title + "**"
+	}
+	return title
+}
+
+// isAdornment reports whether line is a section underline or overline: a
+// run of one punctuation character.
+func isAdornment(line string) bool {
+	line = strings.TrimRight(line, " ")
+	if len(line) < 3 || !strings.ContainsRune("=-~^\"'`*+#:.<>_", rune(line[0])) {
+		return false
+	}
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// takeIndented returns the indented block starting at lines[i], up to the
+// next non-blank line without indentation, and the index after it.
+func takeIndented(lines []string, i int) ([]string, int) {
+	j := i
+	for j < len(lines) && (strings.TrimSpace(lines[j]) == "" || indentOf(lines[j]) > 0) {
+		j++
+	}
+	end := j
+	for end > i && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return lines[i:end], j
+}
+
+// trimBlank removes leading and trailing blank lines.
+func trimBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// rulesTestFile holds an optional file of URLs to test, one per line.
+var rulesTestFile string
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Inspect crawl rules",
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test [url...]",
+	Short: "Show which rules match each URL",
+	Long: `Reports, for each URL, every allow and ignore rule that matches it and
+where the rule is defined, and whether the crawl would visit and save the
+page. The rule that decides the outcome is marked with "*", following
+rule priorities and rule_precedence.
+
+Only the rules are checked; pages may still be excluded at crawl time by
+their status, a noindex tag, or robots.txt.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		allowed, ignored, err := loadRules(&cfg)
+		if err != nil {
+			fmt.Printf("Error processing rules: %v\n", err)
+			os.Exit(1)
+		}
+
+		links := args
+		if rulesTestFile != "" {
+			f, err := os.Open(rulesTestFile)
+			if err != nil {
+				fmt.Printf("Error reading %s: %v\n", rulesTestFile, err)
+				os.Exit(1)
+			}
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+					links = append(links, line)
+				}
+			}
+			f.Close()
+		}
+		if len(links) == 0 {
+			fmt.Println("Error: no URLs to test")
+			os.Exit(1)
+		}
+
+		seeds := map[string]bool{}
+		for _, g := range allowed {
+			seeds[getSeedURL(g.pattern)] = true
+		}
+		for i, link := range links {
+			if i > 0 {
+				fmt.Println()
+			}
+			printRuleMatches(link, allowed, ignored, cfg.Precedence, seeds[link])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesTestCmd)
+	rulesTestCmd.Flags().StringVar(&rulesTestFile, "file", "", "read URLs to test from a file, one per line")
+}
+
+// printRuleMatches prints the rules matching link, in the order they are
+// defined, and the outcome.
+func printRuleMatches(link string, allowed, ignored []globRule, precedence string, seed bool) {
+	fmt.Println(link)
+	winner := matchRule(link, allowed, ignored, precedence)
+	var matches []globRule
+	for _, rule := range append(append([]globRule{}, allowed...), ignored...) {
+		if rule.g.Match(link) {
+			matches = append(matches, rule)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].order < matches[j].order })
+	for _, rule := range matches {
+		marker, kind := " ", "allow"
+		if rule.order == winner.order {
+			marker = "*"
+		}
+		if rule.ignore {
+			kind = "ignore"
+		}
+		kind = fmt.Sprintf("%-6s", kind)
+		if rule.priority != 0 {
+			kind += fmt.Sprintf(" (priority %d)", rule.priority)
+		}
+		if rule.fetchPriority != 0 {
+			kind += fmt.Sprintf(" (fetch priority %d)", rule.fetchPriority)
+		}
+		fmt.Printf("  %s %s %s  (%s)\n", marker, kind, rule.pattern, rule.source)
+	}
+
+	switch {
+	case winner != nil && !winner.ignore:
+		outcome := "visited and saved"
+		if winner.noConvert {
+			outcome = "visited for links only, not saved"
+		}
+		if winner.noFollow {
+			outcome += ", links not followed"
+		}
+		if winner.render {
+			outcome += ", rendered in the browser"
+		}
+		fmt.Println("  => " + outcome)
+	case seed:
+		// Seeds are fetched for their links even when a rule skips them.
+		fmt.Println("  => visited as a seed, not saved")
+	case winner != nil:
+		fmt.Println("  => skipped: ignored")
+	default:
+		fmt.Println("  => skipped: no allow rule matches")
+	}
+}
+
+// matchRule returns the rule that decides whether link is crawled, or nil
+// when no rule matches. The rule with the highest priority wins. Among
+// rules of equal priority, precedence (the rule_precedence setting)
+// decides: "ignore" (the default)
+// lets any ignore rule win, "specific" picks the rule with the most
+// literal characters (an ignore rule on a tie), and "order" picks the
+// rule defined last.
+func matchRule(link string, allowed, ignored []globRule, precedence string) *globRule {
+	var winner *globRule
+	for _, rules := range [][]globRule{ignored, allowed} {
+		for i := range rules {
+			if rules[i].g.Match(link) && (winner == nil || ruleBeats(&rules[i], winner, precedence)) {
+				winner = &rules[i]
+			}
+		}
+	}
+	return winner
+}
+
+// fetchPriority returns the fetch priority of link: that of the most
+// specific matching allow rule which sets one (the later rule on a tie),
+// or 0. Rules that only set a fetch priority need not decide the URL.
+func fetchPriority(link string, allowed []globRule) int {
+	var best *globRule
+	for i := range allowed {
+		r := &allowed[i]
+		if r.fetchPriority == 0 || !r.g.Match(link) {
+			continue
+		}
+		if best == nil || ruleSpecificity(r.pattern) >= ruleSpecificity(best.pattern) {
+			best = r
+		}
+	}
+	if best == nil {
+		return 0
+	}
+	return best.fetchPriority
+}
+
+// ruleBeats reports whether rule a takes precedence over rule b under
+// precedence.
+func ruleBeats(a, b *globRule, precedence string) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	switch precedence {
+	case "specific":
+		if sa, sb := ruleSpecificity(a.pattern), ruleSpecificity(b.pattern); sa != sb {
+			return sa > sb
+		}
+	case "order":
+		return a.order > b.order
+	}
+	return a.ignore && !b.ignore
+}
+
+// ruleSpecificity counts the literal characters of a glob pattern. A
+// character class or alternation counts as one character and wildcards
+// count as none.
+func ruleSpecificity(pattern string) int {
+	n, depth := 0, 0
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\':
+			i++
+			n++
+		case c == '[' || c == '{':
+			if depth == 0 {
+				n++
+			}
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == '*' || c == '?':
+		case depth == 0:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+)
+
+// crawlSchedule limits when the hosts matched by each entry may be
+// crawled. Hosts no entry matches are crawled at any time.
+type crawlSchedule struct {
+	entries []scheduleEntry
+}
+
+type scheduleEntry struct {
+	hosts   []glob.Glob
+	windows []timeWindow
+	loc     *time.Location
+}
+
+// timeWindow is a time of day range in minutes after midnight. A window
+// whose end is not after its start runs past midnight.
+type timeWindow struct {
+	start, end int
+}
+
+// compileSchedule parses the crawl windows in cfg. It returns nil when
+// there are none.
+func compileSchedule(cfg []CrawlWindow) (*crawlSchedule, error) {
+	if len(cfg) == 0 {
+		return nil, nil
+	}
+	s := &crawlSchedule{}
+	for i, w := range cfg {
+		entry := scheduleEntry{loc: time.Local}
+		if w.Timezone != "" {
+			loc, err := time.LoadLocation(w.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("schedule[%d]: %w", i, err)
+			}
+			entry.loc = loc
+		}
+		for _, h := range w.Hosts {
+			g, err := glob.Compile(strings.ToLower(h))
+			if err != nil {
+				return nil, fmt.Errorf("schedule[%d]: invalid host %q: %w", i, h, err)
+			}
+			entry.hosts = append(entry.hosts, g)
+		}
+		for _, spec := range w.Windows {
+			window, err := parseTimeWindow(spec)
+			if err != nil {
+				return nil, fmt.Errorf("schedule[%d]: %w", i, err)
+			}
+			entry.windows = append(entry.windows, window)
+		}
+		if len(entry.hosts) == 0 || len(entry.windows) == 0 {
+			return nil, fmt.Errorf("schedule[%d]: needs hosts and windows", i)
+		}
+		s.entries = append(s.entries, entry)
+	}
+	return s, nil
+}
+
+// parseTimeWindow parses a window such as "01:00-05:00".
+func parseTimeWindow(spec string) (timeWindow, error) {
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return timeWindow{}, fmt.Errorf("invalid window %q, want HH:MM-HH:MM", spec)
+	}
+	var w timeWindow
+	for _, part := range []struct {
+		text string
+		min  *int
+	}{{from, &w.start}, {to, &w.end}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(part.text))
+		if err != nil {
+			return timeWindow{}, fmt.Errorf("invalid window %q, want HH:MM-HH:MM", spec)
+		}
+		*part.min = t.Hour()*60 + t.Minute()
+	}
+	return w, nil
+}
+
+// wait returns how long requests to host must wait for a crawl window to
+// open, or 0 when one is open now, and the entry's time zone.
+func (s *crawlSchedule) wait(host string, now time.Time) (time.Duration, *time.Location) {
+	host = strings.ToLower(host)
+	for _, entry := range s.entries {
+		if !entry.matches(host) {
+			continue
+		}
+		local := now.In(entry.loc)
+		minute := local.Hour()*60 + local.Minute()
+		var wait time.Duration = -1
+		for _, w := range entry.windows {
+			if w.contains(minute) {
+				return 0, entry.loc
+			}
+			for day := 0; day <= 1; day++ {
+				opens := time.Date(local.Year(), local.Month(), local.Day()+day, w.start/60, w.start%60, 0, 0, entry.loc)
+				if opens.After(now) {
+					if d := opens.Sub(now); wait < 0 || d < wait {
+						wait = d
+					}
+					break
+				}
+			}
+		}
+		return wait, entry.loc
+	}
+	return 0, nil
+}
+
+func (e scheduleEntry) matches(host string) bool {
+	for _, g := range e.hosts {
+		if g.Match(host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w timeWindow) contains(minute int) bool {
+	if w.end > w.start {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
+
+// requestHost returns the host of a serialized colly request.
+func requestHost(r []byte) string {
+	var req struct{ URL string }
+	if err := json.Unmarshal(r, &req); err != nil {
+		return ""
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaID is the $id advertised in the generated JSON Schema.
+const schemaID = "https://github.com/rodydavis/agent-skills-generator/skills.schema.json"
+
+// schemaOut holds the optional file to write the schema to.
+var schemaOut string
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate configuration",
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print a JSON Schema for skills.yaml",
+	Long: `Prints a JSON Schema describing skills.yaml, generated from the config
+structs. Point your editor's YAML language server at it for completion and
+validation, e.g. with a "# yaml-language-server: $schema=skills.schema.json"
+comment at the top of skills.yaml.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := json.MarshalIndent(configSchema(), "", "  ")
+		if err != nil {
+			fmt.Printf("Error generating schema: %v\n", err)
+			os.Exit(1)
+		}
+		data = append(data, '\n')
+
+		if schemaOut == "" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(schemaOut, data, 0644); err != nil {
+			fmt.Printf("Error writing schema: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote schema to %s\n", schemaOut)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configSchemaCmd.Flags().StringVar(&schemaOut, "out", "", "write the schema to a file instead of stdout")
+}
+
+// configSchema returns the JSON Schema for the Config struct.
+func configSchema() map[string]interface{} {
+	s := schemaFor(reflect.TypeOf(Config{}))
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["$id"] = schemaID
+	s["title"] = "agent-skills-generator configuration"
+	s["$comment"] = fmt.Sprintf("config schema version %d", configSchemaVersion)
+	return s
+}
+
+// schemaFor builds a schema for t from its mapstructure tags.
+// Struct fields may add a `schema` tag with ";"-separated options:
+// "desc=..." for a description and "enum=a|b" for allowed values.
+func schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		props := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			tag := f.Tag.Get("mapstructure")
+			if strings.Contains(tag, ",squash") {
+				embedded := schemaFor(f.Type)
+				for k, v := range embedded["properties"].(map[string]interface{}) {
+					props[k] = v
+				}
+				continue
+			}
+			name := strings.Split(tag, ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			prop := schemaFor(f.Type)
+			for _, opt := range strings.Split(f.Tag.Get("schema"), ";") {
+				key, value, ok := strings.Cut(opt, "=")
+				if !ok {
+					continue
+				}
+				switch key {
+				case "desc":
+					prop["description"] = value
+				case "enum":
+					prop["enum"] = strings.Split(value, "|")
+				}
+			}
+			props[name] = prop
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]interface{}{}
+	}
+}
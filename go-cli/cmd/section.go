@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// headingSelectors selects the headings of each level and above, so
+// headingSelectors[2] is "h1, h2".
+var headingSelectors = []string{"", "h1", "h1, h2", "h1, h2, h3", "h1, h2, h3, h4", "h1, h2, h3, h4, h5", "h1, h2, h3, h4, h5, h6"}
+
+// headingLevel returns the level of a heading element, or 0.
+func headingLevel(s *goquery.Selection) int {
+	name := goquery.NodeName(s)
+	if len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6' {
+		return int(name[1] - '0')
+	}
+	return 0
+}
+
+// targetsSection reports whether an allow rule naming a page fragment
+// matches link, so the link keeps its fragment and saves only that
+// section.
+func targetsSection(link string, allowed []globRule) bool {
+	for i := range allowed {
+		if strings.Contains(allowed[i].pattern, "#") && allowed[i].g.Match(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripFragment returns link without its fragment.
+func stripFragment(link string) string {
+	if i := strings.Index(link, "#"); i != -1 {
+		return link[:i]
+	}
+	return link
+}
+
+// sectionDocument returns a document holding only the section of doc
+// named by fragment: the content under the heading with that id (or
+// named by an anchor with that id or name) up to the next heading of the
+// same or a higher level, or the element with that id when it is not a
+// heading. The section's heading becomes the document title.
+func sectionDocument(doc *goquery.Document, fragment string) (*goquery.Document, bool) {
+	target := doc.Find("[id], a[name]").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return s.AttrOr("id", "") == fragment || goquery.NodeName(s) == "a" && s.AttrOr("name", "") == fragment
+	}).First()
+	if target.Length() == 0 {
+		return nil, false
+	}
+	if headingLevel(target) == 0 {
+		// An anchor in or just before a heading names that heading.
+		if h := target.Closest(headingSelectors[6]); h.Length() > 0 {
+			target = h
+		} else if next := target.Next(); strings.TrimSpace(target.Text()) == "" && headingLevel(next) > 0 {
+			target = next
+		}
+	}
+
+	var body strings.Builder
+	title := ""
+	if level := headingLevel(target); level > 0 {
+		title = squash(target.Text())
+		// Headings wrapped with their permalink in an element of their
+		// own end where the wrapper's siblings begin.
+		start := target
+		for parent := start.Parent(); parent.Length() > 0 && !parent.Is("body, main, article, section") &&
+			parent.Children().Length() <= 2 && parent.Find("p, ul, ol, pre, table").Length() == 0; parent = start.Parent() {
+			start = parent
+		}
+		for s := start.Next(); s.Length() > 0; s = s.Next() {
+			if headingLevel(s) > 0 && headingLevel(s) <= level || s.Find(headingSelectors[level]).Length() > 0 {
+				break
+			}
+			if h, err := goquery.OuterHtml(s); err == nil {
+				body.WriteString(h)
+			}
+		}
+	} else {
+		title = squash(target.Find(headingSelectors[6]).First().Text())
+		if h, err := goquery.OuterHtml(target); err == nil {
+			body.WriteString(h)
+		}
+	}
+	if title == "" {
+		title, _ = documentMetadata(doc)
+	}
+
+	lang := doc.Find("html").First().AttrOr("lang", "")
+	page := `<html lang="` + html.EscapeString(lang) + `"><head><title>` + html.EscapeString(title) +
+		"</title></head><body><article>" + body.String() + "</article></body></html>"
+	section, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return nil, false
+	}
+	if description := squash(section.Find("article p").First().Text()); description != "" {
+		section.Find("head").AppendHtml(`<meta name="description" content="` + html.EscapeString(description) + `">`)
+	}
+	return section, true
+}
+
+// sectionPath returns where the markdown for the section of a page named
+// by fragment is written, next to the page's own markdown at mdPath.
+func (o *CrawlOptions) sectionPath(mdPath, fragment string) string {
+	name := sanitizeName(fragment)
+	if name == "" {
+		name = "section"
+	}
+	if o.Rename != "" {
+		return filepath.Join(filepath.Dir(mdPath), name, o.Rename)
+	}
+	return strings.TrimSuffix(mdPath, ".md") + "-" + name + ".md"
+}
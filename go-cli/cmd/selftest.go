@@ -0,0 +1,317 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// selftestVerbose shows the crawl output; selftestKeep keeps the output
+// directories.
+var (
+	selftestVerbose bool
+	selftestKeep    bool
+)
+
+// selftestLastModified is served for every fixture page so the output
+// does not depend on when the test runs.
+const selftestLastModified = "Mon, 05 Jan 2026 10:00:00 GMT"
+
+// selftestSite is the fixture documentation site, keyed by path.
+var selftestSite = map[string]string{
+	"/docs/": `<!DOCTYPE html>
+<html><head>
+<title>Fixture Docs</title>
+<meta name="description" content="Documentation for the fixture project.">
+</head><body>
+<nav><a href="/docs/">Home</a> <a href="/blog/">Blog</a></nav>
+<article>
+<h1>Fixture Docs</h1>
+<p>The fixture project has a <strong>small</strong> API.</p>
+<ul>
+<li><a href="/docs/guide/install.html">Install the CLI</a></li>
+<li><a href="/docs/guide/draft.html">Draft guide</a></li>
+<li><a href="/blog/news.html">Latest news</a></li>
+</ul>
+</article>
+<footer>Copyright Fixture Authors</footer>
+</body></html>`,
+	"/docs/guide/install.html": `<!DOCTYPE html>
+<html><head>
+<title>Install the CLI</title>
+<meta property="og:description" content="How to install the fixture CLI.">
+</head><body>
+<article>
+<h1>Install the CLI</h1>
+<p>Install with Go:</p>
+<pre><code class="language-sh">go install example.com/fixture@latest</code></pre>
+<h2>Verify</h2>
+<ol>
+<li>Run <code>fixture version</code>.</li>
+<li>Check the output.</li>
+</ol>
+<p>Back to <a href="/docs/">the docs</a>.</p>
+</article>
+</body></html>`,
+	"/docs/guide/draft.html": `<!DOCTYPE html>
+<html><head><title>Draft</title></head><body><article><h1>Draft</h1></article></body></html>`,
+	"/blog/news.html": `<!DOCTYPE html>
+<html><head><title>News</title></head><body><article><h1>News</h1></article></body></html>`,
+}
+
+// selftestPages are the pages in the fixture that the crawl should save,
+// with their expected output. The blog is outside the crawl patterns and
+// the draft is ignored.
+var selftestPages = map[string]string{
+	"/docs/": `description: Documentation for the fixture project.
+url: {{base}}/docs/
+
+# Fixture Docs
+
+# Fixture Docs
+
+The fixture project has a **small** API.
+
+- [Install the CLI](/docs/guide/install.html)
+- [Draft guide](/docs/guide/draft.html)
+- [Latest news](/blog/news.html)`,
+	"/docs/guide/install.html": `description: How to install the fixture CLI.
+url: {{base}}/docs/guide/install.html
+
+# Install the CLI
+
+# Install the CLI
+
+Install with Go:
+
+` + "```sh" + `
+go install example.com/fixture@latest
+` + "```" + `
+
+## Verify
+
+1. Run ` + "`fixture version`" + `.
+2. Check the output.
+
+Back to [the docs](/docs/).`,
+}
+
+// selftestCase is one crawl of the fixture site and the output path and
+// skill name it should produce for each page in selftestPages.
+type selftestCase struct {
+	name   string
+	flat   bool
+	rename string
+	paths  map[string]string
+	names  map[string]string
+}
+
+var selftestCases = []selftestCase{
+	{
+		name: "nested layout",
+		paths: map[string]string{
+			"/docs/":                   "127.0.0.1/docs/index.md",
+			"/docs/guide/install.html": "127.0.0.1/docs/guide/install.md",
+		},
+		names: map[string]string{
+			"/docs/":                   "fixture-docs",
+			"/docs/guide/install.html": "install-the-cli",
+		},
+	},
+	{
+		name:   "flat layout with rename",
+		flat:   true,
+		rename: "SKILL.md",
+		paths: map[string]string{
+			"/docs/":                   "127_0_0_1_docs/SKILL.md",
+			"/docs/guide/install.html": "127_0_0_1_docs_guide_install/SKILL.md",
+		},
+		names: map[string]string{
+			"/docs/":                   "127-0-0-1-docs",
+			"/docs/guide/install.html": "127-0-0-1-docs-guide-install",
+		},
+	},
+}
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Crawl a built-in fixture site and check the output",
+	Long: `Starts a local web server with a small fixture documentation site,
+crawls it with the nested and flat output layouts, and compares the
+generated markdown and output paths with the expected results. Use it to
+check an installation or a change to extraction or output paths.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !runSelftest() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().BoolVar(&selftestVerbose, "verbose", false, "show the crawl output")
+	selftestCmd.Flags().BoolVar(&selftestKeep, "keep", false, "keep the output directories")
+}
+
+// runSelftest runs every case against the fixture server and reports
+// whether all of them passed.
+func runSelftest() bool {
+	server := httptest.NewServer(http.HandlerFunc(serveSelftestSite))
+	defer server.Close()
+
+	root, err := os.MkdirTemp("", "agent-skills-selftest")
+	if err != nil {
+		fmt.Printf("Error creating output directory: %v\n", err)
+		return false
+	}
+	if selftestKeep {
+		fmt.Printf("Writing output to %s\n", root)
+	} else {
+		defer os.RemoveAll(root)
+	}
+
+	passed := true
+	for i, tc := range selftestCases {
+		outDir := filepath.Join(root, fmt.Sprintf("case%d", i+1))
+		problems := runSelftestCase(tc, server.URL, outDir)
+		if len(problems) == 0 {
+			fmt.Printf("PASS %s\n", tc.name)
+			continue
+		}
+		passed = false
+		fmt.Printf("FAIL %s\n", tc.name)
+		for _, p := range problems {
+			fmt.Printf("  %s\n", strings.ReplaceAll(p, "\n", "\n  "))
+		}
+	}
+	return passed
+}
+
+// serveSelftestSite serves the fixture site.
+func serveSelftestSite(w http.ResponseWriter, r *http.Request) {
+	page, ok := selftestSite[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Last-Modified", selftestLastModified)
+	fmt.Fprint(w, page)
+}
+
+// runSelftestCase crawls the fixture into outDir and returns what did not
+// match the expected output.
+func runSelftestCase(tc selftestCase, base, outDir string) []string {
+	cfg := Config{
+		Output:     outDir,
+		Flat:       tc.flat,
+		FileRename: tc.rename,
+		Patterns: []string{
+			base + "/docs/**",
+			"!" + base + "/docs/guide/draft*",
+		},
+	}
+	cfg.profile = "selftest"
+
+	restore := func() {}
+	if !selftestVerbose {
+		restore = quietStdout()
+	}
+	crawlSite(cfg, "", nil)
+	restore()
+
+	var pages []string
+	for page := range tc.paths {
+		pages = append(pages, page)
+	}
+	sort.Strings(pages)
+
+	var problems []string
+	want := map[string]bool{}
+	for _, page := range pages {
+		rel := tc.paths[page]
+		want[rel] = true
+		data, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(rel)))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: missing %s", page, rel))
+			continue
+		}
+		name, got, err := selftestSummary(string(data), base)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+		if name != tc.names[page] {
+			problems = append(problems, fmt.Sprintf("%s: name is %q, expected %q", rel, name, tc.names[page]))
+		}
+		if expected := selftestPages[page]; got != expected {
+			problems = append(problems, fmt.Sprintf("%s: output differs\n--- expected\n%s\n--- got\n%s", rel, expected, got))
+		}
+	}
+
+	var extra []string
+	filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".md") {
+			return err
+		}
+		rel, _ := filepath.Rel(outDir, path)
+		if rel = filepath.ToSlash(rel); !want[rel] {
+			extra = append(extra, rel)
+		}
+		return nil
+	})
+	sort.Strings(extra)
+	for _, rel := range extra {
+		problems = append(problems, "unexpected file "+rel)
+	}
+	return problems
+}
+
+// quietStdout sends standard output to the null device until the
+// returned function is called, hiding the progress lines of a crawl.
+func quietStdout() func() {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return func() {}
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	return func() {
+		os.Stdout = stdout
+		devNull.Close()
+	}
+}
+
+// selftestSummary returns the name of a generated page and the parts of
+// it that do not vary between runs: its description and URL (with the
+// server address replaced by {{base}}), and its body.
+func selftestSummary(content, base string) (string, string, error) {
+	meta, body, err := parsePage(content)
+	if err != nil {
+		return "", "", err
+	}
+	summary := fmt.Sprintf("description: %s\nurl: %s\n\n%s",
+		meta.Description, strings.Replace(meta.URL, base, "{{base}}", 1), strings.TrimSpace(body))
+	return meta.Name, summary, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"flag"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// updateGolden rewrites the golden files from the current output.
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenVolatile matches the frontmatter lines that change between runs
+// and builds.
+var goldenVolatile = regexp.MustCompile(`(?m)^(\s*(?:crawled_at|version):).*$`)
+
+// TestCrawlGolden crawls the fixture site with each selftest layout and
+// compares every markdown file written with its golden copy under
+// testdata/crawl. Run with -update to accept new output.
+func TestCrawlGolden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(serveSelftestSite))
+	defer server.Close()
+
+	for _, tc := range selftestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outDir := t.TempDir()
+			restore := quietStdout()
+			crawlSite(goldenConfig(tc, server.URL, outDir), "", nil)
+			restore()
+			checkGolden(t, tc, server.URL, outDir)
+		})
+	}
+}
+
+// TestConcurrentCrawls crawls the fixture site with every selftest layout
+// at once, as embedders running several profiles do, and checks that each
+// output still matches its golden files. Run with -race to catch state the
+// crawls share.
+func TestConcurrentCrawls(t *testing.T) {
+	if *updateGolden {
+		t.Skip("golden files are written by TestCrawlGolden")
+	}
+	server := httptest.NewServer(http.HandlerFunc(serveSelftestSite))
+	defer server.Close()
+
+	outDirs := make([]string, len(selftestCases))
+	var wg sync.WaitGroup
+	restore := quietStdout()
+	for i, tc := range selftestCases {
+		outDirs[i] = t.TempDir()
+		wg.Add(1)
+		go func(tc selftestCase, outDir string) {
+			defer wg.Done()
+			crawlSite(goldenConfig(tc, server.URL, outDir), "", nil)
+		}(tc, outDirs[i])
+	}
+	wg.Wait()
+	restore()
+
+	for i, tc := range selftestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkGolden(t, tc, server.URL, outDirs[i])
+		})
+	}
+}
+
+// goldenConfig returns the config crawling the fixture site at base into
+// outDir with the layout of tc.
+func goldenConfig(tc selftestCase, base, outDir string) Config {
+	cfg := Config{
+		Output:     outDir,
+		Flat:       tc.flat,
+		FileRename: tc.rename,
+		Patterns: []string{
+			base + "/docs/**",
+			"!" + base + "/docs/guide/draft*",
+		},
+	}
+	cfg.profile = "selftest"
+	return cfg
+}
+
+// checkGolden compares the markdown files in outDir with the golden files
+// of tc, or rewrites them with -update.
+func checkGolden(t *testing.T, tc selftestCase, base, outDir string) {
+	t.Helper()
+	goldenDir := filepath.Join("testdata", "crawl", strings.ReplaceAll(tc.name, " ", "-"))
+	got := goldenFiles(t, outDir, func(data []byte) string {
+		content := strings.ReplaceAll(string(data), base, "{{base}}")
+		return goldenVolatile.ReplaceAllString(content, "$1 <volatile>")
+	})
+	if *updateGolden {
+		os.RemoveAll(goldenDir)
+		for rel, content := range got {
+			path := filepath.Join(goldenDir, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	want := goldenFiles(t, goldenDir, func(data []byte) string { return string(data) })
+	for _, rel := range sortedKeys(want) {
+		content, ok := got[rel]
+		if !ok {
+			t.Errorf("missing %s", rel)
+			continue
+		}
+		if content != want[rel] {
+			t.Errorf("%s differs from its golden file\n--- want\n%s\n--- got\n%s", rel, want[rel], content)
+		}
+	}
+	for _, rel := range sortedKeys(got) {
+		if _, ok := want[rel]; !ok {
+			t.Errorf("unexpected file %s", rel)
+		}
+	}
+}
+
+// goldenFiles reads the markdown files under dir, keyed by slash-separated
+// relative path, through normalize.
+func goldenFiles(t *testing.T, dir string, normalize func([]byte) string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".md") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = normalize(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
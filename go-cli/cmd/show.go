@@ -0,0 +1,232 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// showRaw prints the markdown without styling it.
+var showRaw bool
+
+var showCmd = &cobra.Command{
+	Use:   "show <skill|url|path>",
+	Short: "Print a generated skill or page in the terminal",
+	Long: `Finds the markdown generated for a skill or page and prints it with
+terminal styling for headings, lists, code, and links.
+
+The argument may be a source URL from the manifest, a skill name (its
+SKILL.md is shown), a page's path or name, or any markdown file. When stdout is not a
+terminal, NO_COLOR is set, or --raw is given, the markdown is printed
+unchanged.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		outputDir := configuredOutput()
+		opts := &CrawlOptions{Output: outputDir, Flat: viper.GetBool("flat"), Rename: viper.GetString("rename")}
+		m, err := loadManifest(outputDir)
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+		path, err := resolveShowTarget(opts, m, args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		data, err := readOutputFile(path)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		if showRaw || os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout) {
+			os.Stdout.Write(data)
+			return
+		}
+		fmt.Print(renderMarkdown(string(data)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().BoolVar(&showRaw, "raw", false, "print the markdown without styling")
+}
+
+// resolveShowTarget returns the file to show for target: the page with
+// that source URL, the SKILL.md of the skill with that name, or the page
+// with that path or name, falling back to target as a file path.
+func resolveShowTarget(opts *CrawlOptions, m *Manifest, target string) (string, error) {
+	if u, err := url.Parse(target); err == nil && u.Scheme != "" && u.Host != "" {
+		u.Fragment = ""
+		link := u.String()
+		for _, candidate := range []string{link, strings.TrimSuffix(link, "/"), link + "/"} {
+			if entry, ok := m.Pages[candidate]; ok {
+				return opts.pageFile(entry), nil
+			}
+		}
+		return "", fmt.Errorf("%s is not in the manifest", link)
+	}
+
+	rel := strings.Trim(filepath.ToSlash(target), "/")
+	skillFile := filepath.Join(opts.Output, filepath.FromSlash(rel), "SKILL.md")
+	if _, err := os.Stat(skillFile); err == nil {
+		return skillFile, nil
+	}
+	var matches []*ManifestEntry
+	for _, entry := range m.Pages {
+		if entry.Path == rel || entry.Path == rel+".md" || entry.Name == rel {
+			matches = append(matches, entry)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		for _, path := range []string{filepath.Join(opts.Output, filepath.FromSlash(rel)), target} {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+		return "", fmt.Errorf("no skill or page named %q in %s", target, opts.Output)
+	case 1:
+		return opts.pageFile(matches[0]), nil
+	}
+	paths := make([]string, len(matches))
+	for i, entry := range matches {
+		paths[i] = entry.Path
+	}
+	sort.Strings(paths)
+	return "", fmt.Errorf("%q matches several pages: %s", target, strings.Join(paths, ", "))
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ANSI escape sequences used for terminal output.
+const (
+	ansiReset     = "\033[0m"
+	ansiBold      = "\033[1m"
+	ansiDim       = "\033[2m"
+	ansiItalic    = "\033[3m"
+	ansiUnderline = "\033[4m"
+	ansiRed       = "\033[31m"
+	ansiGreen     = "\033[32m"
+	ansiMagenta   = "\033[35m"
+	ansiCyan      = "\033[36m"
+	ansiYellow    = "\033[33m"
+)
+
+var (
+	listItemRe   = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	ruleRe       = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdLinkRe     = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	mdBoldRe     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalicRe   = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*|(^|[^\w_])_([^_\s][^_]*)_`)
+	inlineCodeRe = regexp.MustCompile("`[^`]+`")
+)
+
+// renderMarkdown styles markdown for a terminal: frontmatter is dimmed,
+// headings are bold and coloured without their "#" markers, code is
+// coloured, and list bullets, quotes, and rules are drawn with box
+// characters. Everything else is printed as written.
+func renderMarkdown(content string) string {
+	var b strings.Builder
+	fm, body := splitFrontmatter(content)
+	if fm != "" {
+		b.WriteString(ansiDim + strings.TrimSuffix(fm, "\n") + ansiReset + "\n")
+	}
+
+	fence := ""
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				continue
+			}
+			b.WriteString("  " + ansiYellow + line + ansiReset + "\n")
+			continue
+		}
+		if isFence(line) {
+			fence = trimmed[:3]
+			continue
+		}
+		if level, text, ok := atxHeading(line); ok {
+			style := ansiBold
+			switch level {
+			case 1:
+				style += ansiMagenta + ansiUnderline
+			case 2:
+				style += ansiMagenta
+			case 3:
+				style += ansiCyan
+			}
+			b.WriteString(style + renderInline(text, style) + ansiReset + "\n")
+			continue
+		}
+		switch {
+		case ruleRe.MatchString(line):
+			line = ansiDim + strings.Repeat("─", 40) + ansiReset
+		case strings.HasPrefix(trimmed, ">"):
+			quote := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			line = ansiDim + "│ " + ansiReset + ansiItalic + renderInline(quote, ansiItalic) + ansiReset
+		case listItemRe.MatchString(line):
+			indent := listItemRe.FindStringSubmatch(line)[1]
+			line = indent + ansiCyan + "• " + ansiReset + renderInline(line[len(listItemRe.FindString(line)):], "")
+		default:
+			line = renderInline(line, "")
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// renderInline styles code spans, bold and italic text, and links within
+// a line. base is the style to restore after each span.
+func renderInline(line, base string) string {
+	reset := ansiReset + base
+	var b strings.Builder
+	last := 0
+	for _, loc := range inlineCodeRe.FindAllStringIndex(line, -1) {
+		b.WriteString(renderEmphasis(line[last:loc[0]], reset))
+		b.WriteString(ansiYellow + line[loc[0]+1:loc[1]-1] + reset)
+		last = loc[1]
+	}
+	b.WriteString(renderEmphasis(line[last:], reset))
+	return b.String()
+}
+
+// renderEmphasis styles bold and italic text and links outside code spans.
+func renderEmphasis(s, reset string) string {
+	s = mdLinkRe.ReplaceAllStringFunc(s, func(link string) string {
+		m := mdLinkRe.FindStringSubmatch(link)
+		text := m[1]
+		if text == "" {
+			text = m[2]
+		}
+		return ansiUnderline + ansiCyan + text + reset + ansiDim + " (" + m[2] + ")" + reset
+	})
+	s = mdBoldRe.ReplaceAllString(s, ansiBold+"$1$2"+reset)
+	return mdItalicRe.ReplaceAllString(s, "$1$3"+ansiItalic+"$2$4"+reset)
+}
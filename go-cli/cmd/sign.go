@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// signatureSuffix is appended to the manifest path for its signature file.
+const signatureSuffix = ".minisig"
+
+// keygenOut holds the base path for generated key files.
+// keygenForce allows overwriting existing key files.
+// signKey holds the path to the secret key used for signing.
+// verifyKey holds the public key (path or base64) used for verification.
+var (
+	keygenOut   string
+	keygenForce bool
+	signKey     string
+	verifyKey   string
+)
+
+var keygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate a key pair for signing manifests",
+	Long: `Generates a minisign-compatible Ed25519 key pair. The secret key is
+written to <out>.key and the public key to <out>.pub.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		secPath, pubPath := keygenOut+".key", keygenOut+".pub"
+		if !keygenForce {
+			for _, p := range []string{secPath, pubPath} {
+				if _, err := os.Stat(p); err == nil {
+					fmt.Printf("Refusing to overwrite %s (use --force)\n", p)
+					os.Exit(1)
+				}
+			}
+		}
+
+		pub, sec, err := generateMinisignKey()
+		if err != nil {
+			fmt.Printf("Error generating key: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(secPath, sec.encode(), 0600); err != nil {
+			fmt.Printf("Error writing secret key: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(pubPath, pub.encode(), 0644); err != nil {
+			fmt.Printf("Error writing public key: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated key %s\n  secret: %s\n  public: %s\n", keyIDString(pub.KeyID), secPath, pubPath)
+	},
+}
+
+var signCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign the output manifest",
+	Long:  `Signs manifest.json in the output directory, writing manifest.json.minisig next to it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := signManifest(configuredOutput(), signKey); err != nil {
+			fmt.Printf("Error signing manifest: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the manifest signature and file hashes",
+	Long: `Checks manifest.json against its signature and then confirms every listed
+file exists and matches the recorded hash.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !verifyManifest(configuredOutput(), verifyKey) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(keygenCmd)
+	rootCmd.AddCommand(signCmd)
+	rootCmd.AddCommand(verifyCmd)
+
+	keygenCmd.Flags().StringVar(&keygenOut, "out", "skills", "base path for the generated key files")
+	keygenCmd.Flags().BoolVar(&keygenForce, "force", false, "overwrite existing key files")
+
+	signCmd.Flags().StringVar(&signKey, "key", "skills.key", "secret key file")
+	verifyCmd.Flags().StringVar(&verifyKey, "pub", "skills.pub", "public key file or base64 public key")
+}
+
+// signManifest signs the manifest in outDir with the secret key at keyPath.
+func signManifest(outDir, keyPath string) error {
+	key, err := readMinisignSecretKey(keyPath)
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(outDir, manifestFileName)
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	trusted := fmt.Sprintf("timestamp:%d\tfile:%s\ttool:%s %s",
+		time.Now().Unix(), manifestFileName, toolName, buildInfo().Version)
+	if err := os.WriteFile(manifestPath+signatureSuffix, minisignSign(key, data, trusted), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Signed %s with key %s\n", manifestPath, keyIDString(key.KeyID))
+	return nil
+}
+
+// verifyManifest checks the manifest signature and every file hash,
+// printing each problem found. It returns true if everything verified.
+func verifyManifest(outDir, pubKey string) bool {
+	key, err := readMinisignPublicKey(pubKey)
+	if err != nil {
+		fmt.Printf("Error reading public key: %v\n", err)
+		return false
+	}
+
+	manifestPath := filepath.Join(outDir, manifestFileName)
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		fmt.Printf("Error reading manifest: %v\n", err)
+		return false
+	}
+	sig, err := os.ReadFile(manifestPath + signatureSuffix)
+	if err != nil {
+		fmt.Printf("Error reading signature: %v\n", err)
+		return false
+	}
+	trusted, err := minisignVerify(key, data, sig)
+	if err != nil {
+		fmt.Printf("Manifest signature invalid: %v\n", err)
+		return false
+	}
+	fmt.Printf("Manifest signature OK (%s)\n", trusted)
+
+	m, err := loadManifest(outDir)
+	if err != nil {
+		fmt.Printf("Error parsing manifest: %v\n", err)
+		return false
+	}
+
+	ok := true
+	checked, inlined := 0, 0
+	for _, entry := range m.Pages {
+		if entry.InlinedIn != "" {
+			// Checked as part of the SKILL.md it was inlined into.
+			inlined++
+			continue
+		}
+		if entry.FileHash == "" {
+			fmt.Printf("No file hash recorded: %s\n", entry.Path)
+			ok = false
+			continue
+		}
+		content, err := readOutputFile(filepath.Join(outDir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			fmt.Printf("Missing: %s\n", entry.Path)
+			ok = false
+			continue
+		}
+		if contentHash(string(content)) != entry.FileHash {
+			fmt.Printf("Modified: %s\n", entry.Path)
+			ok = false
+			continue
+		}
+		checked++
+	}
+	for path, hash := range m.Generated {
+		content, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(path)))
+		if err != nil {
+			fmt.Printf("Missing: %s\n", path)
+			ok = false
+			continue
+		}
+		if contentHash(string(content)) != hash {
+			fmt.Printf("Modified: %s\n", path)
+			ok = false
+			continue
+		}
+		checked++
+	}
+
+	fmt.Printf("Verified %d of %d files\n", checked, len(m.Pages)-inlined+len(m.Generated))
+	return ok
+}
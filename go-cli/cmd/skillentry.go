@@ -0,0 +1,461 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// skillFileName is the entry point written for each section.
+const skillFileName = "SKILL.md"
+
+// inlinedDirName is the directory under the output directory that keeps
+// the files of inlined pages, so later runs can inline them again without
+// fetching them.
+const inlinedDirName = ".inlined"
+
+// skillSection is a site section rooted at a landing page.
+type skillSection struct {
+	landing  *ManifestEntry
+	dir      string // output-relative, slash-separated
+	pages    []*ManifestEntry
+	children []*skillSection
+}
+
+// writeSkillEntries writes a SKILL.md into the directory of each section
+// landing page. The landing page's content becomes the skill body, and the
+// pages below it (nested sections by their own SKILL.md) are listed as
+// references. keywordField names the frontmatter field keywords were
+// written to, if any.
+func writeSkillEntries(opts *CrawlOptions, m *Manifest, cfg SkillEntryConfig, keywordField string, llmCfg LLMConfig) {
+	if opts.Flat {
+		fmt.Println("Warning: skill entries need the nested output layout, skipping")
+		return
+	}
+
+	uw := &useWhenWriter{mode: cfg.UseWhen, keywordField: keywordField}
+	switch cfg.UseWhen {
+	case "", "titles", "none":
+	case "llm":
+		var err error
+		if uw.llm, err = newLLMClient(llmCfg); err != nil {
+			fmt.Printf("Error configuring LLM for use_when, using page titles: %v\n", err)
+			uw.mode = "titles"
+		}
+	default:
+		fmt.Printf("Error: unknown use_when method %q\n", cfg.UseWhen)
+		return
+	}
+
+	var globs []glob.Glob
+	for _, pattern := range cfg.Sections {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			fmt.Printf("Warning: invalid section glob %s: %v\n", pattern, err)
+			continue
+		}
+		globs = append(globs, g)
+	}
+
+	sections := map[string]*skillSection{}
+	for _, entry := range m.Pages {
+		if isLandingPage(entry.URL, globs) {
+			dir := path.Dir(entry.Path)
+			// Prefer the shorter URL when two landing pages share a directory.
+			if s, ok := sections[dir]; !ok || len(entry.URL) < len(s.landing.URL) {
+				sections[dir] = &skillSection{landing: entry, dir: dir}
+			}
+		}
+	}
+
+	// nearest returns the closest section containing dir.
+	nearest := func(dir string) *skillSection {
+		for {
+			if s, ok := sections[dir]; ok {
+				return s
+			}
+			if dir == "." || dir == "/" {
+				return nil
+			}
+			dir = path.Dir(dir)
+		}
+	}
+	for _, entry := range m.Pages {
+		if s, ok := sections[path.Dir(entry.Path)]; ok && s.landing == entry {
+			continue
+		}
+		if s := nearest(path.Dir(entry.Path)); s != nil {
+			s.pages = append(s.pages, entry)
+		}
+	}
+	for _, s := range sections {
+		if s.dir == "." {
+			continue
+		}
+		if parent := nearest(path.Dir(s.dir)); parent != nil {
+			parent.children = append(parent.children, s)
+		}
+	}
+
+	written := 0
+	for _, s := range sections {
+		if len(s.pages) == 0 && len(s.children) == 0 {
+			continue
+		}
+		if err := writeSkillEntry(opts, m, s, uw, cfg.Inline); err != nil {
+			fmt.Printf("Error writing skill entry for %s: %v\n", s.landing.URL, err)
+			continue
+		}
+		written++
+	}
+	if written > 0 {
+		fmt.Printf("Wrote %d skill entry points\n", written)
+	}
+}
+
+// isLandingPage reports whether link is a section landing page: a match
+// for one of the section globs, or by default a directory index.
+func isLandingPage(link string, globs []glob.Glob) bool {
+	if len(globs) > 0 {
+		for _, g := range globs {
+			if g.Match(link) {
+				return true
+			}
+		}
+		return false
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	base := path.Base(u.Path)
+	return u.Path == "" || strings.HasSuffix(u.Path, "/") || base == "index.html" || base == "index.htm"
+}
+
+// skillRef is a line of a SKILL.md's references, listed in nav order and
+// then alphabetically.
+type skillRef struct {
+	line  string
+	order int
+}
+
+// inlinedPage is a small page written as a section of its SKILL.md.
+type inlinedPage struct {
+	entry *ManifestEntry
+	meta  pageMeta
+	body  string
+}
+
+// writeSkillEntry renders the SKILL.md for one section. Pages under
+// inline tokens are written into it as sections and their own files
+// removed.
+func writeSkillEntry(opts *CrawlOptions, m *Manifest, s *skillSection, uw *useWhenWriter, inline int) error {
+	meta, body, err := readPage(filepath.Join(opts.Output, filepath.FromSlash(s.landing.Path)))
+	if err != nil {
+		return err
+	}
+	title := meta.Title
+	if title == "" {
+		title = meta.Name
+	}
+
+	var refs []skillRef
+	related := []pageMeta{meta}
+	for _, child := range s.children {
+		childMeta, _, err := readPage(filepath.Join(opts.Output, filepath.FromSlash(child.landing.Path)))
+		if err != nil {
+			continue
+		}
+		rel := strings.TrimPrefix(child.dir, s.dir+"/") + "/" + skillFileName
+		refs = append(refs, skillRef{line: referenceLine(childMeta, rel), order: pageOrder(childMeta)})
+		related = append(related, childMeta)
+	}
+	var inlined []*inlinedPage
+	for _, page := range s.pages {
+		data, err := readOutputFile(opts.pageFile(page))
+		if err != nil {
+			continue
+		}
+		pageMeta, pageBody, err := parsePage(string(data))
+		if err != nil {
+			continue
+		}
+		related = append(related, pageMeta)
+		if inline > 0 && estimateTokens(pageBody) < inline {
+			inlined = append(inlined, &inlinedPage{entry: page, meta: pageMeta, body: pageBody})
+			continue
+		}
+		refs = append(refs, skillRef{line: referenceLine(pageMeta, strings.TrimPrefix(page.Path, s.dir+"/")), order: pageOrder(pageMeta)})
+	}
+	sort.Slice(inlined, func(i, j int) bool {
+		if before, ok := orderBefore(pageOrder(inlined[i].meta), pageOrder(inlined[j].meta)); ok {
+			return before
+		}
+		return inlined[i].meta.Title < inlined[j].meta.Title
+	})
+
+	// Inlined pages follow the fixed sections, so their anchors are
+	// numbered after every heading above them.
+	overview := strings.TrimSpace(stripTitle(body))
+	anchors := headingAnchors{}
+	anchors.next(title)
+	for _, h := range markdownHeadings(overview) {
+		anchors.next(h)
+	}
+	anchors.next("When to Use")
+	anchors.next("References")
+	for _, p := range inlined {
+		p.body = shiftHeadings(stripPageTitle(p.body, p.meta.Title), 1)
+		refs = append(refs, skillRef{line: referenceLine(p.meta, "#"+anchors.next(inlinedTitle(p))), order: pageOrder(p.meta)})
+		for _, h := range markdownHeadings(p.body) {
+			anchors.next(h)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if before, ok := orderBefore(refs[i].order, refs[j].order); ok {
+			return before
+		}
+		return refs[i].line < refs[j].line
+	})
+
+	fm := frontmatter{
+		{Key: "name", Value: meta.Name},
+		{Key: "description", Value: meta.Description},
+	}
+	if useWhen := uw.write(title, meta.Description, body, related); useWhen != "" {
+		fm = append(fm, fmField{Key: "use_when", Value: useWhen})
+	}
+	fm = append(fm, frontmatter{
+		{Key: "metadata", Value: frontmatter{
+			{Key: "url", Value: s.landing.URL},
+			{Key: "pages", Value: len(s.pages) + len(s.children)},
+		}},
+	}...)
+
+	var b strings.Builder
+	b.WriteString(fm.render())
+	fmt.Fprintf(&b, "\n# %s\n\n", title)
+	if overview != "" {
+		b.WriteString(overview)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("## When to Use\n\n")
+	fmt.Fprintf(&b, "Use this skill for questions and tasks about %s.", title)
+	if meta.Description != "" && meta.Description != noDescription {
+		fmt.Fprintf(&b, " %s", meta.Description)
+	}
+	b.WriteString("\n\n## References\n\n")
+	for _, ref := range refs {
+		b.WriteString(ref.line)
+		b.WriteString("\n")
+	}
+	for _, p := range inlined {
+		fmt.Fprintf(&b, "\n## %s\n\n", inlinedTitle(p))
+		if p.meta.URL != "" {
+			fmt.Fprintf(&b, "Source: %s\n\n", p.meta.URL)
+		}
+		if p.body != "" {
+			b.WriteString(p.body)
+			b.WriteString("\n")
+		}
+	}
+	content := b.String()
+
+	rel := path.Join(s.dir, skillFileName)
+	if err := writeFileIfChanged(filepath.Join(opts.Output, filepath.FromSlash(rel)), []byte(content)); err != nil {
+		return err
+	}
+	for _, p := range inlined {
+		pagePath := filepath.Join(opts.Output, filepath.FromSlash(p.entry.Path))
+		if pagePath != opts.pageFile(p.entry) {
+			continue // already inlined by an earlier run
+		}
+		kept := filepath.Join(opts.Output, inlinedDirName, filepath.FromSlash(p.entry.Path))
+		if err := os.MkdirAll(filepath.Dir(kept), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(pagePath, kept); err != nil {
+			fmt.Printf("Error moving inlined page %s: %v\n", p.entry.Path, err)
+			continue
+		}
+		if opts.Rename != "" {
+			// Renamed pages have a directory of their own, which is
+			// removed when nothing else is in it.
+			os.Remove(filepath.Dir(pagePath))
+		}
+		m.mu.Lock()
+		p.entry.InlinedIn = rel
+		m.touch(p.entry.URL)
+		m.mu.Unlock()
+	}
+	if rel == s.landing.Path {
+		// With --rename SKILL.md the landing page is itself the entry point.
+		m.mu.Lock()
+		s.landing.FileHash = contentHash(content)
+		m.touch(s.landing.URL)
+		m.mu.Unlock()
+		return nil
+	}
+	m.recordGenerated(rel, content)
+	return nil
+}
+
+// inlinedTitle returns the section heading of an inlined page.
+func inlinedTitle(p *inlinedPage) string {
+	if p.meta.Title != "" {
+		return p.meta.Title
+	}
+	return p.meta.Name
+}
+
+// referenceLine renders a reference list item linking to a page.
+func referenceLine(meta pageMeta, link string) string {
+	title := meta.Title
+	if title == "" {
+		title = meta.Name
+	}
+	line := fmt.Sprintf("- [%s](%s)", title, link)
+	if meta.Description != "" && meta.Description != noDescription {
+		line += ": " + meta.Description
+	}
+	return line
+}
+
+// stripTitle removes the first H1 line from a page body.
+func stripTitle(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "# ") {
+			return strings.Join(append(lines[:i], lines[i+1:]...), "\n")
+		}
+	}
+	return body
+}
+
+// useWhenTopics and useWhenKeywords cap how many page titles and keywords
+// a derived use_when field lists.
+const (
+	useWhenTopics   = 5
+	useWhenKeywords = 5
+)
+
+// useWhenWriter writes the use_when field of skill entries, which tells
+// an agent when to load the skill.
+type useWhenWriter struct {
+	mode         string // titles (or ""), llm, or none
+	keywordField string
+	llm          *llmClient
+}
+
+// write returns the use_when text for a section titled title. related
+// holds the landing page followed by the pages and sections below it.
+func (w *useWhenWriter) write(title, description, body string, related []pageMeta) string {
+	if w.mode == "none" {
+		return ""
+	}
+	var topics []string
+	for _, meta := range related[1:] {
+		if meta.Title != "" && meta.Title != title {
+			topics = append(topics, meta.Title)
+		}
+	}
+	if w.llm != nil {
+		useWhen, err := llmUseWhen(w.llm, title, description, body, topics)
+		if err == nil && useWhen != "" {
+			return useWhen
+		}
+		if err != nil {
+			fmt.Printf("Error writing use_when for %s, using page titles: %v\n", title, err)
+		}
+	}
+	return deriveUseWhen(title, topics, w.keywords(title, related))
+}
+
+// keywords returns the keywords most common across the related pages.
+func (w *useWhenWriter) keywords(title string, related []pageMeta) []string {
+	if w.keywordField == "" {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, meta := range related {
+		list, _ := meta.Fields[w.keywordField].([]interface{})
+		for _, k := range list {
+			if s, ok := k.(string); ok && !strings.EqualFold(s, title) {
+				counts[s]++
+			}
+		}
+	}
+	keywords := make([]string, 0, len(counts))
+	for k := range counts {
+		keywords = append(keywords, k)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if counts[keywords[i]] != counts[keywords[j]] {
+			return counts[keywords[i]] > counts[keywords[j]]
+		}
+		return keywords[i] < keywords[j]
+	})
+	return keywords[:min(len(keywords), useWhenKeywords)]
+}
+
+// deriveUseWhen builds a use_when sentence from the section title, the
+// titles of its pages, and its keywords.
+func deriveUseWhen(title string, topics, keywords []string) string {
+	text := "Use when a task or question involves " + title
+	if len(topics) > 0 {
+		text += ", such as " + joinOr(topics[:min(len(topics), useWhenTopics)])
+	}
+	if len(keywords) > 0 {
+		text += ", or mentions " + joinOr(keywords)
+	}
+	return text + "."
+}
+
+// joinOr joins items as "a, b, or c".
+func joinOr(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " or " + items[1]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", or " + items[len(items)-1]
+}
+
+// llmUseWhen asks the LLM when the skill should be used.
+func llmUseWhen(llm *llmClient, title, description, body string, topics []string) (string, error) {
+	if len(body) > llmKeywordChars {
+		body = body[:llmKeywordChars]
+	}
+	prompt := fmt.Sprintf("Skill: %s\nDescription: %s\nPages: %s\n\n%s", title, description, strings.Join(topics, "; "), body)
+	reply, err := llm.complete(
+		`You write the use_when field of agent skills. Reply with one sentence starting with "Use when" that names the tasks and questions the skill should be loaded for, and nothing else.`,
+		prompt,
+	)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(reply), " "), nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// snippetsFileName is the code snippet collection written into each skill
+// directory.
+const snippetsFileName = "SNIPPETS.md"
+
+// codeSnippet is a code block found on a page, with the heading it
+// appears under and the sentence introducing it, if any.
+type codeSnippet struct {
+	Language string
+	Code     []string
+	Heading  string
+	Caption  string
+	// URL is the source page, at the anchor of the snippet's section
+	// when it has one.
+	URL string
+}
+
+// writeSnippets writes a SNIPPETS.md into each skill directory (each
+// top-level directory of the output) collecting the code blocks of its
+// pages, grouped by page, each under its nearest heading and linked to
+// its source.
+func writeSnippets(opts *CrawlOptions, m *Manifest, cfg SnippetsConfig) {
+	languages := map[string]bool{}
+	for _, lang := range cfg.Languages {
+		languages[strings.ToLower(lang)] = true
+	}
+
+	entries := make([]*ManifestEntry, 0, len(m.Pages))
+	for _, entry := range m.Pages {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	bySkill := map[string][]pageSnippets{}
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		data, err := readOutputFile(opts.pageFile(entry))
+		if err != nil {
+			continue
+		}
+		_, body := splitFrontmatter(string(data))
+		skill := opts.skillDirOf(entry)
+		var kept []codeSnippet
+		for _, s := range extractSnippets(body, entry) {
+			if len(s.Code) < max(cfg.MinLines, 1) || len(languages) > 0 && !languages[s.Language] {
+				continue
+			}
+			key := skill + "\x00" + strings.Join(s.Code, "\n")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			kept = append(kept, s)
+		}
+		if len(kept) > 0 {
+			bySkill[skill] = append(bySkill[skill], pageSnippets{Page: entry, Snippets: kept})
+		}
+	}
+
+	for skill, pages := range bySkill {
+		rel := path.Join(skill, snippetsFileName)
+		content := renderSnippets(pages, skill)
+		if err := writeFileIfChanged(filepath.Join(opts.Output, filepath.FromSlash(rel)), []byte(content)); err != nil {
+			fmt.Printf("Error writing %s: %v\n", rel, err)
+			continue
+		}
+		m.recordGenerated(rel, content)
+		count := 0
+		for _, p := range pages {
+			count += len(p.Snippets)
+		}
+		fmt.Printf("Wrote %d code snippets to %s\n", count, rel)
+	}
+}
+
+// pageSnippets are the snippets kept from one page.
+type pageSnippets struct {
+	Page     *ManifestEntry
+	Snippets []codeSnippet
+}
+
+// extractSnippets returns the fenced code blocks of a saved page body.
+// A paragraph ending in a colon just before a block is its caption.
+// Source HTML embedded by the HTML fallback is not a snippet.
+func extractSnippets(body string, entry *ManifestEntry) []codeSnippet {
+	var snippets []codeSnippet
+	var heading, section string
+	var para []string
+	var current *codeSnippet
+	var marker string
+	skip := false
+	previous := ""
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current != nil {
+			if strings.HasPrefix(trimmed, marker) && strings.TrimLeft(trimmed, marker[:1]) == "" {
+				for len(current.Code) > 0 && strings.TrimSpace(current.Code[len(current.Code)-1]) == "" {
+					current.Code = current.Code[:len(current.Code)-1]
+				}
+				if current.Language == "" {
+					current.Language = guessLanguage(current.Code)
+				}
+				if !skip {
+					snippets = append(snippets, *current)
+				}
+				current = nil
+			} else {
+				current.Code = append(current.Code, line)
+			}
+			continue
+		}
+		switch {
+		case isFence(line):
+			marker = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+			info := strings.Fields(strings.TrimPrefix(trimmed, marker))
+			current = &codeSnippet{Heading: heading, URL: sectionURL(entry, section)}
+			if len(info) > 0 {
+				current.Language = strings.ToLower(info[0])
+			}
+			if caption := strings.Join(para, " "); strings.HasSuffix(caption, ":") {
+				current.Caption = caption
+			}
+			skip = previous == "<summary>Source HTML</summary>"
+			para = nil
+		case trimmed == "":
+		default:
+			if level, text, ok := atxHeading(trimmed); ok {
+				if level > 1 || text != entry.Title {
+					heading = text
+				}
+				if level == 2 {
+					section = text
+				}
+				para = nil
+			} else if previous == "" {
+				para = []string{trimmed}
+			} else {
+				para = append(para, trimmed)
+			}
+		}
+		if trimmed != "" {
+			previous = trimmed
+		}
+	}
+	return snippets
+}
+
+// sectionURL returns the source link of the section of entry with the
+// given level 2 heading, or of the page when it has no anchor.
+func sectionURL(entry *ManifestEntry, heading string) string {
+	if heading != "" {
+		for _, c := range entry.Citations {
+			if c.Heading == heading && c.Anchor != "" {
+				return c.URL
+			}
+		}
+	}
+	return entry.URL
+}
+
+// guessLanguage names the language of an untagged code block when it is
+// recognizable: shell sessions, JSON, and HTML or XML.
+func guessLanguage(code []string) string {
+	text := strings.TrimSpace(strings.Join(code, "\n"))
+	switch {
+	case text == "":
+		return ""
+	case strings.HasPrefix(text, "$ "):
+		return "sh"
+	case json.Valid([]byte(text)) && strings.ContainsAny(text[:1], "{["):
+		return "json"
+	case strings.HasPrefix(text, "<") && strings.HasSuffix(text, ">"):
+		return "html"
+	}
+	return ""
+}
+
+// renderSnippets renders the snippets of a skill's pages, one section per
+// page, linking each snippet to its source and the page to its file.
+func renderSnippets(pages []pageSnippets, skill string) string {
+	var b strings.Builder
+	b.WriteString("# Snippets\n\n")
+	for _, p := range pages {
+		link := p.Page.Path
+		if p.Page.InlinedIn != "" {
+			link = p.Page.InlinedIn
+		}
+		if skill != "." {
+			link = strings.TrimPrefix(link, skill+"/")
+		}
+		fmt.Fprintf(&b, "## [%s](%s)\n\n", p.Page.Title, link)
+		heading := ""
+		for _, s := range p.Snippets {
+			if s.Heading != "" && s.Heading != heading {
+				fmt.Fprintf(&b, "### %s\n\n", s.Heading)
+			}
+			heading = s.Heading
+			if s.Caption != "" {
+				b.WriteString(s.Caption + "\n\n")
+			}
+			fmt.Fprintf(&b, "%s\n\n[source](%s)\n\n", fence(s.Language, s.Code), s.URL)
+		}
+	}
+	return strings.TrimSpace(b.String()) + "\n"
+}
@@ -0,0 +1,428 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// sourceDoc is a documentation file converted to markdown. An empty title
+// falls back to the file name.
+type sourceDoc struct {
+	Title       string
+	Description string
+	Body        string
+}
+
+// sourceConverters convert documentation files in git sources, keyed by
+// file extension. Files with other extensions are skipped.
+var sourceConverters = map[string]func(data []byte, opts *CrawlOptions) (sourceDoc, error){
+	".md":       convertMarkdownSource,
+	".mdx":      convertMDX,
+	".ipynb":    convertNotebookSource,
+	".rst":      convertRST,
+	".adoc":     convertAsciiDoc,
+	".asciidoc": convertAsciiDoc,
+}
+
+// ingestSources converts every configured source into the output
+// directory.
+func ingestSources(sources []SourceConfig, opts *CrawlOptions) {
+	for _, src := range sources {
+		var name string
+		var err error
+		switch {
+		case src.Git != "":
+			name, err = src.Git, ingestGitSource(src, opts)
+		case src.Confluence.URL != "":
+			name, err = src.Confluence.URL, ingestConfluence(src.Confluence, opts)
+		case src.Notion.TokenEnv != "":
+			name, err = "notion", ingestNotion(src.Notion, opts)
+		case src.Drive.FolderID != "":
+			name, err = "drive:"+src.Drive.FolderID, ingestDrive(src.Drive, opts)
+		case src.Zendesk.URL != "":
+			name, err = src.Zendesk.URL, ingestZendesk(src.Zendesk, opts)
+		case src.Intercom.TokenEnv != "":
+			name, err = "intercom", ingestIntercom(src.Intercom, opts)
+		case src.Discourse.URL != "":
+			name, err = src.Discourse.URL, ingestDiscourse(src.Discourse, opts)
+		case src.Mbox.Path != "":
+			name, err = src.Mbox.Path, ingestMbox(src.Mbox, opts)
+		default:
+			continue
+		}
+		if err != nil {
+			kind := errorKind(err)
+			if kind == "" {
+				kind = ErrorSource
+			}
+			failPage(opts, kind, name, 0, err)
+		}
+	}
+}
+
+func ingestGitSource(src SourceConfig, opts *CrawlOptions) error {
+	repoDir, err := syncGitSource(src)
+	if err != nil {
+		return err
+	}
+	lastModified, _ := gitOutput(repoDir, "log", "-1", "--format=%cD")
+
+	var includes []glob.Glob
+	for _, pattern := range src.Include {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		includes = append(includes, g)
+	}
+
+	root := filepath.Join(repoDir, filepath.FromSlash(src.Path))
+	count := 0
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		convert, ok := sourceConverters[strings.ToLower(filepath.Ext(p))]
+		if !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		included := len(includes) == 0
+		for _, g := range includes {
+			included = included || g.Match(rel)
+		}
+		if !included {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		doc, err := convert(data, opts)
+		if err != nil {
+			fmt.Printf("Error converting %s: %v\n", rel, err)
+			return nil
+		}
+		if doc.Title == "" {
+			doc.Title = strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+		}
+
+		page := convertedPage{
+			URL:          sourceFileURL(src, path.Join(src.Path, rel)),
+			Title:        doc.Title,
+			Description:  doc.Description,
+			Body:         doc.Body,
+			LastModified: lastModified,
+		}
+		// Forges render markdown with GitHub-style heading anchors.
+		if strings.HasPrefix(page.URL, "http") && strings.HasSuffix(strings.ToLower(rel), ".md") {
+			page.Anchors = markdownAnchors(doc.Body)
+		}
+		if _, err := writeSourcePage(opts, sourceOutputURL(src, rel), page); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Converted %d files from %s\n", count, src.Git)
+	return nil
+}
+
+// writeSourcePage writes a page from a source at the output path derived
+// from out, the same way a crawled page at that URL would be saved, and
+// returns the page's directory.
+func writeSourcePage(opts *CrawlOptions, out *url.URL, p convertedPage) (string, error) {
+	dirName, fullPath := opts.outputPath(out)
+	if err := os.MkdirAll(dirName, 0755); err != nil {
+		return "", err
+	}
+	p.Extraction = opts.extractionFor(p.URL)
+	if p.Description == "" {
+		p.Description = firstParagraph(p.Body)
+	}
+	writePage(p, opts, dirName, opts.markdownPath(fullPath))
+	return dirName, nil
+}
+
+// syncGitSource fetches the source's ref into a cached checkout and
+// returns its directory. Only the latest commit is downloaded.
+func syncGitSource(src SourceConfig) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(src.Git))
+	dir := filepath.Join(cache, toolName, "git", hex.EncodeToString(sum[:8]))
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		if _, err := gitOutput(dir, "init", "-q"); err != nil {
+			return "", err
+		}
+	}
+	remote := src.Git
+	if info, err := os.Stat(remote); err == nil && info.IsDir() {
+		// Shallow fetches need a URL rather than a plain path.
+		abs, _ := filepath.Abs(remote)
+		remote = "file://" + filepath.ToSlash(abs)
+	}
+	ref := src.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	fmt.Printf("Fetching %s (%s)\n", src.Git, ref)
+	if _, err := gitOutput(dir, "fetch", "-q", "--depth", "1", remote, ref); err != nil {
+		return "", err
+	}
+	if _, err := gitOutput(dir, "checkout", "-q", "--force", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// gitOutput runs git in dir and returns its trimmed output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// sourceFileURL returns the URL a source file is recorded under: its page
+// on the forge for http(s) remotes, or a file URL for local repositories.
+func sourceFileURL(src SourceConfig, repoPath string) string {
+	if u, err := url.Parse(src.Git); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		ref := src.Ref
+		if ref == "" {
+			ref = "HEAD"
+		}
+		return strings.TrimSuffix(src.Git, ".git") + "/blob/" + ref + "/" + repoPath
+	}
+	abs, err := filepath.Abs(src.Git)
+	if err != nil {
+		abs = src.Git
+	}
+	return "file://" + filepath.ToSlash(abs) + "/" + repoPath
+}
+
+// sourceOutputURL maps a source file to the URL its output path is derived
+// from: <host>/<repository>/<file without extension>. README and index
+// files stand for their directory.
+func sourceOutputURL(src SourceConfig, rel string) *url.URL {
+	host, repo := "local", filepath.Base(strings.TrimSuffix(src.Git, ".git"))
+	if u, err := url.Parse(src.Git); err == nil && u.Host != "" {
+		host, repo = u.Hostname(), strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	}
+	p := strings.TrimSuffix(rel, path.Ext(rel))
+	switch strings.ToLower(path.Base(p)) {
+	case "readme", "index":
+		p = path.Dir(p)
+	}
+	return &url.URL{Scheme: "https", Host: host, Path: "/" + path.Join(repo, p)}
+}
+
+// convertMarkdownSource strips a markdown file's frontmatter, taking the
+// title and description from it, and moves a leading H1 into the title.
+func convertMarkdownSource(data []byte, opts *CrawlOptions) (sourceDoc, error) {
+	var doc sourceDoc
+	fm, body := splitFrontmatter(strings.ReplaceAll(string(data), "\r\n", "\n"))
+	if fm != "" {
+		if fields, err := parseFrontmatter(fm); err == nil {
+			if v, ok := fields.get("title"); ok {
+				doc.Title, _ = v.(string)
+			}
+			if v, ok := fields.get("description"); ok {
+				doc.Description, _ = v.(string)
+			}
+		}
+	}
+	body = strings.TrimSpace(body)
+	if first, rest, _ := strings.Cut(body, "\n"); strings.HasPrefix(first, "# ") {
+		if doc.Title == "" {
+			doc.Title = strings.TrimSpace(strings.TrimPrefix(first, "# "))
+		}
+		body = strings.TrimSpace(rest)
+	}
+	doc.Body = body + "\n"
+	return doc, nil
+}
+
+// convertNotebookSource converts a Jupyter notebook in a git source.
+func convertNotebookSource(data []byte, opts *CrawlOptions) (sourceDoc, error) {
+	title, body, err := convertNotebook(data, opts.notebooks)
+	return sourceDoc{Title: title, Body: body}, err
+}
+
+// firstParagraph returns the first prose paragraph of a markdown document,
+// skipping headings, code blocks, lists, tables, and images, for use as a
+// description.
+func firstParagraph(markdown string) string {
+	var para []string
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if line == "" {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		if len(para) == 0 && (strings.ContainsAny(line[:1], "#-*|>!<[") || (line[0] >= '0' && line[0] <= '9')) {
+			continue
+		}
+		para = append(para, line)
+	}
+	return strings.Join(para, " ")
+}
+
+// markdownTable renders rows as a markdown table. The first row is the
+// header, since markdown tables require one.
+func markdownTable(rows [][]string) string {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	if width == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, row := range rows {
+		cells := make([]string, width)
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = strings.ReplaceAll(strings.Join(strings.Fields(row[j]), " "), "|", `\|`)
+			}
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// indentOf returns the number of leading spaces on line.
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// dedent removes the indentation shared by the non-blank lines.
+func dedent(lines []string) []string {
+	common := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := indentOf(line); common == -1 || n < common {
+			common = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= common && common > 0 {
+			line = line[common:]
+		}
+		out[i] = strings.TrimRight(line, " ")
+	}
+	return out
+}
+
+// fence renders lines as a fenced code block, using a fence longer than
+// any backtick run in the code.
+func fence(lang string, lines []string) string {
+	marker := "```"
+	for strings.Contains(strings.Join(lines, "\n"), marker) {
+		marker += "`"
+	}
+	return marker + lang + "\n" + strings.Join(lines, "\n") + "\n" + marker
+}
+
+// codeSpan renders s as inline code.
+func codeSpan(s string) string {
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+// quoteMarkdown prefixes every line of a markdown block with "> ".
+func quoteMarkdown(block string) string {
+	lines := strings.Split(block, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indentMarkdown indents every line after the first by n spaces, so a
+// block can continue a list item.
+func indentMarkdown(block string, n int) string {
+	lines := strings.Split(block, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = strings.Repeat(" ", n) + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// admonitionMarkdown renders a note or warning as a blockquote headed by
+// its label.
+func admonitionMarkdown(label string, blocks []string) string {
+	return quoteMarkdown(strings.Join(append([]string{"**" + label + "**"}, blocks...), "\n\n"))
+}
+
+// htmlToMarkdown converts an HTML fragment from a source API to markdown.
+func htmlToMarkdown(html string, opts *CrawlOptions) (string, error) {
+	converter := newMarkdownConverter(opts.extraction.Converter, nil)
+	return converter.ConvertString(html)
+}
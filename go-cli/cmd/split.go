@@ -0,0 +1,338 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// splitOut holds the directory split skills are written to.
+// splitLevel holds the deepest heading level a file is split at.
+var (
+	splitOut   string
+	splitLevel int
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split <file.md...>",
+	Short: "Split large markdown files into a skill per heading",
+	Long: `Splits each markdown file at its H1 and H2 headings (--level 1 splits
+at H1 only) into <out>/<file>/, one file per section plus a SKILL.md that
+keeps the text before the first section and lists the sections. A single
+leading H1 is taken as the document title rather than a section.
+
+Each section inherits the file's frontmatter with its own name and
+description, and its headings move up so the section heading is the H1.
+Links to headings in the file point to the section files, and relative
+links are adjusted for the new location. Tables of contents made only of
+links to headings are dropped.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if splitLevel < 1 || splitLevel > 2 {
+			fmt.Println("Error: --level must be 1 or 2")
+			os.Exit(1)
+		}
+		out := splitOut
+		if out == "" {
+			out = configuredOutput()
+		}
+		failed := false
+		for _, file := range args {
+			dest := filepath.Join(out, strings.TrimSuffix(filepath.Base(pagePath(file)), filepath.Ext(pagePath(file))))
+			if err := splitFile(file, dest, splitLevel); err != nil {
+				fmt.Printf("Error splitting %s: %v\n", file, err)
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+	splitCmd.Flags().StringVar(&splitOut, "out", "", "directory the split skills are written to (default: the output directory)")
+	splitCmd.Flags().IntVar(&splitLevel, "level", 2, "deepest heading level to split at (1 or 2)")
+}
+
+// splitHeading is a heading in a file being split.
+type splitHeading struct {
+	line   int
+	level  int
+	text   string
+	anchor string // in the source file
+}
+
+// splitSection is one output file of a split.
+type splitSection struct {
+	heading  splitHeading
+	file     string
+	lines    []string
+	headings []splitHeading
+}
+
+// sourceLineRe matches the source line merge writes under each page.
+var sourceLineRe = regexp.MustCompile(`^Source: (\S+)$`)
+
+// tocLineRe matches a list item that only links to a heading.
+var tocLineRe = regexp.MustCompile(`^\s*[-*+] \[[^\]]*\]\(#[^)]*\)\s*$`)
+
+// splitFile writes the sections of the markdown file at path to dest.
+func splitFile(path, dest string, level int) error {
+	data, err := readOutputFile(path)
+	if err != nil {
+		return err
+	}
+	fm, body := splitFrontmatter(string(data))
+	var fields frontmatter
+	if fm != "" {
+		if fields, err = parseFrontmatter(fm); err != nil {
+			return fmt.Errorf("parsing frontmatter: %w", err)
+		}
+	}
+	meta, _, _ := parsePage(string(data))
+
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	var headings []splitHeading
+	anchors := headingAnchors{}
+	inFence := false
+	for i, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if l, text, ok := atxHeading(line); ok && !inFence {
+			headings = append(headings, splitHeading{line: i, level: l, text: text, anchor: anchors.next(text)})
+		}
+	}
+
+	// A lone H1 at the top titles the document.
+	title := ""
+	titleLine := -1
+	h1s := 0
+	for _, h := range headings {
+		if h.level == 1 {
+			h1s++
+		}
+	}
+	if level == 2 && h1s == 1 && len(headings) > 0 && headings[0].level == 1 {
+		title = headings[0].text
+		titleLine = headings[0].line
+		headings = headings[1:]
+	}
+	if title == "" {
+		title = meta.Name
+	}
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(pagePath(path)), filepath.Ext(pagePath(path)))
+	}
+
+	var preamble []string
+	var sections []*splitSection
+	names := map[string]int{}
+	var current *splitSection
+	next := 0
+	for i, line := range lines {
+		if next < len(headings) && headings[next].line == i {
+			h := headings[next]
+			next++
+			if h.level <= level {
+				name := sanitizeName(toPathCase(h.text))
+				if name == "" {
+					name = "section"
+				}
+				if n := names[name]; n > 0 {
+					names[name] = n + 1
+					name = fmt.Sprintf("%s-%d", name, n)
+				} else {
+					names[name] = 1
+				}
+				current = &splitSection{heading: h, file: name + ".md"}
+				sections = append(sections, current)
+				continue
+			}
+			if current != nil {
+				current.headings = append(current.headings, h)
+			}
+		}
+		if i == titleLine {
+			continue
+		}
+		if current == nil {
+			preamble = append(preamble, line)
+		} else {
+			current.lines = append(current.lines, line)
+		}
+	}
+
+	// Drop tables of contents, which the section files replace.
+	kept := sections[:0]
+	for _, s := range sections {
+		if !isTOC(s.lines) {
+			kept = append(kept, s)
+		}
+	}
+	sections = kept
+	if len(sections) == 0 {
+		return fmt.Errorf("no headings to split at")
+	}
+
+	// Source anchors resolve to the section holding the heading, under
+	// the anchor the heading gets in that file.
+	targets := map[string]string{}
+	for _, s := range sections {
+		fileAnchors := headingAnchors{}
+		fileAnchors.next(s.heading.text)
+		targets[s.heading.anchor] = s.file
+		for _, h := range s.headings {
+			targets[h.anchor] = s.file + "#" + fileAnchors.next(h.text)
+		}
+	}
+	rewrite := func(text string) string {
+		return rewriteSplitLinks(text, targets, filepath.Dir(path), dest)
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	description := meta.Description
+	var refs []string
+	for _, s := range sections {
+		content := strings.TrimSpace(shiftHeadings(strings.Join(s.lines, "\n"), 1-s.heading.level))
+		sourceURL := ""
+		if meta.URL != "" {
+			sourceURL = meta.URL + "#" + s.heading.anchor
+		}
+		// Sections of a merged file keep the URL of the page they came from.
+		if first, rest, _ := strings.Cut(content, "\n"); sourceLineRe.MatchString(first) {
+			sourceURL = sourceLineRe.FindStringSubmatch(first)[1]
+			content = strings.TrimSpace(rest)
+		}
+		content = rewrite(content)
+		sectionDescription := firstParagraph(content)
+		if sectionDescription == "" {
+			sectionDescription = description
+		}
+		sectionFields := inheritFrontmatter(fields, sanitizeName(toPathCase(s.heading.text)), sanitizeDescription(sectionDescription))
+		if sourceURL != "" {
+			sectionFields = withMetadata(sectionFields, "url", sourceURL)
+		}
+		if metadata, ok := sectionFields.get("metadata"); ok {
+			nested := metadata.(frontmatter)
+			nested.remove("pages")
+			sectionFields.set("metadata", nested)
+		}
+		page := sectionFields.render() + fmt.Sprintf("\n# %s\n\n", s.heading.text) + content + "\n"
+		if err := os.WriteFile(filepath.Join(dest, s.file), []byte(page), 0644); err != nil {
+			return err
+		}
+		refs = append(refs, referenceLine(pageMeta{Title: s.heading.text, Description: sanitizeDescription(sectionDescription)}, s.file))
+	}
+
+	indexFields := inheritFrontmatter(fields, sanitizeName(toPathCase(title)), sanitizeDescription(description))
+	indexFields = withMetadata(indexFields, "pages", len(sections))
+	var b strings.Builder
+	b.WriteString(indexFields.render())
+	fmt.Fprintf(&b, "\n# %s\n\n", title)
+	if overview := strings.TrimSpace(rewrite(strings.Join(preamble, "\n"))); overview != "" {
+		b.WriteString(overview)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("## References\n\n")
+	for _, ref := range refs {
+		b.WriteString(ref)
+		b.WriteString("\n")
+	}
+	if err := os.WriteFile(filepath.Join(dest, skillFileName), []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Split %s into %d sections in %s\n", path, len(sections), dest)
+	return nil
+}
+
+// isTOC reports whether lines hold only a list of links to headings.
+func isTOC(lines []string) bool {
+	links := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !tocLineRe.MatchString(line) {
+			return false
+		}
+		links++
+	}
+	return links > 0
+}
+
+// inheritFrontmatter returns a copy of fields with the given name and
+// description, so edits do not leak between sections.
+func inheritFrontmatter(fields frontmatter, name, description string) frontmatter {
+	out := frontmatter{
+		{Key: "name", Value: name},
+		{Key: "description", Value: description},
+	}
+	for _, field := range fields {
+		if field.Key == "name" || field.Key == "description" {
+			continue
+		}
+		if nested, ok := field.Value.(frontmatter); ok {
+			field.Value = append(frontmatter{}, nested...)
+		}
+		out = append(out, field)
+	}
+	return out
+}
+
+// withMetadata sets key in the metadata block of fields.
+func withMetadata(fields frontmatter, key string, value interface{}) frontmatter {
+	metadata, _ := fields.get("metadata")
+	nested, _ := metadata.(frontmatter)
+	nested.set(key, value)
+	fields.set("metadata", nested)
+	return fields
+}
+
+// rewriteSplitLinks points links to headings at the section files in
+// targets and moves relative links from srcDir to destDir.
+func rewriteSplitLinks(text string, targets map[string]string, srcDir, destDir string) string {
+	return markdownLinkRe.ReplaceAllStringFunc(text, func(m string) string {
+		link := strings.TrimPrefix(m, "](")
+		if strings.HasPrefix(link, "#") {
+			if target, ok := targets[strings.TrimPrefix(link, "#")]; ok {
+				return "](" + target
+			}
+			return m
+		}
+		ref, err := url.Parse(link)
+		if err != nil || ref.IsAbs() || ref.Host != "" || ref.Path == "" || strings.HasPrefix(ref.Path, "/") {
+			return m
+		}
+		rel, err := filepath.Rel(destDir, filepath.Join(srcDir, filepath.FromSlash(ref.Path)))
+		if err != nil {
+			return m
+		}
+		ref.Path = filepath.ToSlash(rel)
+		return "](" + ref.String()
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gobwas/glob"
+)
+
+// defaultStopSections are headings of sections that only point elsewhere
+// or ask for feedback, per page language. They are compared with the
+// heading lowercased and without trailing punctuation.
+var defaultStopSections = map[string][]string{
+	"en": {"see also", "related articles", "related pages", "related topics", "related content", "feedback", "was this page helpful", "was this helpful", "on this page", "in this article", "table of contents"},
+	"de": {"siehe auch", "verwandte artikel", "verwandte themen", "feedback", "war diese seite hilfreich", "auf dieser seite", "in diesem artikel", "inhaltsverzeichnis"},
+	"fr": {"voir aussi", "articles connexes", "sujets connexes", "commentaires", "cette page vous a-t-elle été utile", "sur cette page", "dans cet article", "table des matières"},
+	"es": {"véase también", "ver también", "artículos relacionados", "temas relacionados", "comentarios", "en esta página", "en este artículo", "tabla de contenidos"},
+	"pt": {"veja também", "consulte também", "artigos relacionados", "tópicos relacionados", "comentários", "nesta página", "neste artigo", "índice"},
+	"it": {"vedi anche", "articoli correlati", "argomenti correlati", "feedback", "in questa pagina", "in questo articolo", "sommario"},
+	"ja": {"関連項目", "関連記事", "関連トピック", "フィードバック", "このページの内容", "この記事の内容", "目次"},
+	"zh": {"另请参阅", "相关文章", "相关主题", "反馈", "本页内容", "本文内容", "目录"},
+	"ko": {"참고 항목", "관련 문서", "관련 항목", "피드백", "이 페이지의 내용", "이 문서의 내용", "목차"},
+}
+
+// stopGlobs caches compiled stop_headings patterns. Invalid patterns are
+// cached as nil so they are reported once.
+var (
+	stopGlobsMu sync.Mutex
+	stopGlobs   = map[string]glob.Glob{}
+)
+
+// documentLanguage returns the primary language subtag of the page, such
+// as "fr" for lang="fr-CA", or "" when it is not declared.
+func documentLanguage(doc *goquery.Document) string {
+	lang, _ := doc.Find("html").First().Attr("lang")
+	lang, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	return lang
+}
+
+// removeStopSections removes sections whose heading is a stop heading: a
+// default one for lang (and English, which localized sites often keep)
+// when ext.StopSections is set, or one of ext.StopHeadings. Without a
+// language the defaults of every language apply. A section runs to the
+// next heading of the same or a higher level.
+func removeStopSections(body string, ext ExtractionConfig, lang string) string {
+	if !ext.StopSections && len(ext.StopHeadings) == 0 {
+		return body
+	}
+	stop := map[string]bool{}
+	if ext.StopSections {
+		for l, headings := range defaultStopSections {
+			if lang == "" || l == lang || l == "en" {
+				for _, h := range headings {
+					stop[h] = true
+				}
+			}
+		}
+	}
+	globs := compileStopHeadings(ext.StopHeadings)
+
+	isStop := func(heading string) bool {
+		heading = normalizeStopHeading(heading)
+		if stop[heading] {
+			return true
+		}
+		for _, g := range globs {
+			if g.Match(heading) {
+				return true
+			}
+		}
+		return false
+	}
+
+	lines := strings.Split(body, "\n")
+	out := lines[:0]
+	inFence := false
+	removing := 0 // level of the section being removed, or 0
+	for _, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+		} else if level, text, ok := atxHeading(line); ok && !inFence {
+			if removing > 0 && level <= removing {
+				removing = 0
+			}
+			if removing == 0 && isStop(text) {
+				removing = level
+			}
+		}
+		if removing == 0 {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// normalizeStopHeading lowercases a heading and removes trailing
+// punctuation and permalink markers.
+func normalizeStopHeading(heading string) string {
+	heading = strings.ToLower(strings.TrimSpace(heading))
+	return strings.TrimSpace(strings.TrimRight(heading, "?:.!？：¶# \u00a0"))
+}
+
+// compileStopHeadings returns the compiled stop_headings patterns,
+// lowercased so they match case-insensitively.
+func compileStopHeadings(patterns []string) []glob.Glob {
+	stopGlobsMu.Lock()
+	defer stopGlobsMu.Unlock()
+	var globs []glob.Glob
+	for _, pattern := range patterns {
+		g, ok := stopGlobs[pattern]
+		if !ok {
+			var err error
+			if g, err = glob.Compile(normalizeStopHeading(pattern)); err != nil {
+				fmt.Printf("Warning: invalid stop heading %s: %v\n", pattern, err)
+				g = nil
+			}
+			stopGlobs[pattern] = g
+		}
+		if g != nil {
+			globs = append(globs, g)
+		}
+	}
+	return globs
+}
@@ -0,0 +1,495 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// suggestDepth is how many links deep the sample crawl follows.
+// suggestMaxPages caps the pages the sample crawl fetches.
+// suggestYes accepts every suggestion without asking.
+var (
+	suggestDepth    int
+	suggestMaxPages int
+	suggestYes      bool
+)
+
+// suggestTreeDepth is how many directories below the seed are searched
+// for sections to exclude.
+const suggestTreeDepth = 3
+
+// lowValueDirs maps directory names that rarely hold documentation to
+// why they are excluded.
+var lowValueDirs = map[string]string{}
+
+func init() {
+	for reason, names := range map[string][]string{
+		"blog and news":            {"blog", "blogs", "news", "newsroom", "press", "events", "webinars", "podcast", "podcasts"},
+		"company pages":            {"about", "careers", "jobs", "company", "team", "contact", "legal", "privacy", "terms", "pricing", "customers", "partners", "investors", "brand"},
+		"sign-in pages":            {"login", "logout", "signin", "sign-in", "signup", "sign-up", "register", "account", "auth"},
+		"tag and archive listings": {"tag", "tags", "category", "categories", "author", "authors", "archive", "archives", "search"},
+		"store pages":              {"shop", "store", "cart", "checkout"},
+	} {
+		for _, name := range names {
+			lowValueDirs[name] = reason
+		}
+	}
+}
+
+// localeCodes are the language codes recognized as locale directories,
+// alone or with a region (en-us, pt_BR, zh-hans).
+var localeCodes = map[string]bool{
+	"ar": true, "bg": true, "bn": true, "ca": true, "cs": true, "da": true, "de": true, "el": true,
+	"en": true, "es": true, "et": true, "fa": true, "fi": true, "fr": true, "he": true, "hi": true,
+	"hr": true, "hu": true, "id": true, "it": true, "ja": true, "ko": true, "lt": true, "lv": true,
+	"ms": true, "nl": true, "no": true, "pl": true, "pt": true, "ro": true, "ru": true, "sk": true,
+	"sl": true, "sr": true, "sv": true, "th": true, "tr": true, "uk": true, "vi": true, "zh": true,
+}
+
+var (
+	localeRe  = regexp.MustCompile(`^([a-z]{2})(?:[-_][A-Za-z]{2,4})?$`)
+	versionRe = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)(?:\.x)?$`)
+	// assetExts are file types the sample crawl does not count as pages.
+	assetExts = map[string]bool{".css": true, ".js": true, ".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true, ".pdf": true, ".zip": true, ".xml": true, ".json": true}
+)
+
+var suggestRulesCmd = &cobra.Command{
+	Use:   "suggest-rules <url>",
+	Short: "Propose crawl rules for a site from a sample crawl",
+	Long: `Crawls a few levels of the site below <url>, groups the pages found by
+directory, and proposes rules: an allow rule for everything below <url>
+and ignore rules for sections that rarely hold documentation, such as
+blogs, careers pages, and tag listings, for every language but one when
+the site has locale directories (/en/, /de/, ...), and for every version
+but the latest when it has version directories (/v1/, /v2/, ...).
+
+Each suggestion is shown with how many sampled pages it covers and asked
+about in turn; the accepted rules are appended to the first --config
+file (default .skillscontext). With --yes every suggestion is accepted.
+When stdin is not a terminal the rules are printed in pattern file form
+instead, with nothing written.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		seed, err := url.Parse(args[0])
+		if err != nil || (seed.Scheme != "http" && seed.Scheme != "https") || seed.Host == "" {
+			fmt.Printf("Error: %q is not an http or https URL\n", args[0])
+			os.Exit(1)
+		}
+		seed.Fragment, seed.RawQuery = "", ""
+
+		fmt.Printf("Sampling %s (depth %d, up to %d pages)...\n", seed, suggestDepth, suggestMaxPages)
+		links, err := sampleCrawl(&cfg, seed, suggestDepth, suggestMaxPages)
+		if err != nil {
+			fmt.Printf("Error crawling %s: %v\n", seed, err)
+			os.Exit(1)
+		}
+		if len(links) == 0 {
+			fmt.Printf("Error: no pages found below %s\n", seed)
+			os.Exit(1)
+		}
+		suggestions := suggestRules(seed, links)
+
+		interactive := !suggestYes && isTerminal(os.Stdin)
+		if !interactive && !suggestYes {
+			fmt.Printf("# Suggested from %d pages below %s\n", len(links), seed)
+			for _, s := range suggestions {
+				fmt.Printf("# %s\n%s\n", s.describe(), s.pattern)
+			}
+			return
+		}
+
+		fmt.Printf("Found %d pages below %s\n\n", len(links), seed)
+		var accepted []string
+		in := bufio.NewReader(os.Stdin)
+		for _, s := range suggestions {
+			fmt.Printf("%s\n  %s\n", s.pattern, s.describe())
+			if interactive && !askYesNo(in, "  Add this rule?", true) {
+				continue
+			}
+			accepted = append(accepted, s.pattern)
+		}
+		if len(accepted) == 0 {
+			fmt.Println("No rules added")
+			return
+		}
+		target := ".skillscontext"
+		if len(cfg.ConfigFiles) > 0 {
+			target = cfg.ConfigFiles[0]
+		}
+		added, err := appendPatterns(target, accepted)
+		if err != nil {
+			fmt.Printf("Error writing %s: %v\n", target, err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nAdded %d rules to %s\n", added, target)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suggestRulesCmd)
+	suggestRulesCmd.Flags().IntVar(&suggestDepth, "depth", 2, "how many links deep to sample")
+	suggestRulesCmd.Flags().IntVar(&suggestMaxPages, "max-pages", 200, "most pages to fetch while sampling")
+	suggestRulesCmd.Flags().BoolVar(&suggestYes, "yes", false, "accept every suggestion without asking")
+}
+
+// ruleSuggestion is a proposed rule, with the number of sampled pages it
+// covers and an example of one.
+type ruleSuggestion struct {
+	pattern string
+	reason  string
+	pages   int
+	example string
+}
+
+func (s ruleSuggestion) describe() string {
+	noun := "pages"
+	if s.pages == 1 {
+		noun = "page"
+	}
+	text := fmt.Sprintf("%s: %d sampled %s", s.reason, s.pages, noun)
+	if s.example != "" {
+		text += ", e.g. " + s.example
+	}
+	return text
+}
+
+// sampleCrawl fetches up to maxPages pages below seed, following links up
+// to depth deep, and returns every page URL found below seed, without
+// queries or fragments.
+func sampleCrawl(cfg *Config, seed *url.URL, depth, maxPages int) ([]string, error) {
+	base := seedBase(seed)
+	fetcher, err := newFetcher(cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if closer, ok := fetcher.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	c := colly.NewCollector(
+		colly.UserAgent(userAgent()),
+		colly.AllowedDomains(seed.Hostname()),
+		colly.MaxDepth(depth+1),
+		colly.Async(true),
+	)
+	c.WithTransport(fetcherTransport{fetcher: fetcher})
+	if err := c.Limit(&colly.LimitRule{DomainGlob: "*", Parallelism: 4}); err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	found := map[string]bool{}
+	visits := 1
+	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		u, err := url.Parse(e.Request.AbsoluteURL(e.Attr("href")))
+		if err != nil || u.Host != seed.Host {
+			return
+		}
+		u.Fragment, u.RawQuery = "", ""
+		link := u.String()
+		if !strings.HasPrefix(link, base) || assetExts[strings.ToLower(path.Ext(u.Path))] {
+			return
+		}
+		mu.Lock()
+		seen := found[link]
+		found[link] = true
+		visit := !seen && visits < maxPages
+		if visit {
+			visits++
+		}
+		mu.Unlock()
+		if visit {
+			e.Request.Visit(link)
+		}
+	})
+	found[seed.String()] = true
+	if err := c.Visit(seed.String()); err != nil {
+		return nil, err
+	}
+	c.Wait()
+
+	links := make([]string, 0, len(found))
+	for link := range found {
+		links = append(links, link)
+	}
+	sort.Strings(links)
+	return links, nil
+}
+
+// seedBase returns the URL prefix of the pages below seed: seed up to
+// and including the last "/" of its path.
+func seedBase(seed *url.URL) string {
+	dir := seed.Path
+	if i := strings.LastIndex(dir, "/"); i != -1 {
+		dir = dir[:i+1]
+	} else {
+		dir = "/"
+	}
+	return seed.Scheme + "://" + seed.Host + dir
+}
+
+// pathNode is a directory of the sampled site.
+type pathNode struct {
+	children map[string]*pathNode
+	pages    int
+	example  string
+}
+
+func (n *pathNode) child(name string) *pathNode {
+	if n.children == nil {
+		n.children = map[string]*pathNode{}
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &pathNode{}
+		n.children[name] = c
+	}
+	return c
+}
+
+// suggestRules proposes an allow rule for the pages below seed and ignore
+// rules for the directories of links that look like sections to skip.
+func suggestRules(seed *url.URL, links []string) []ruleSuggestion {
+	base := seedBase(seed)
+	root := &pathNode{}
+	for _, link := range links {
+		rel := strings.TrimPrefix(link, base)
+		node := root
+		node.pages++
+		dirs := strings.Split(rel, "/")
+		for _, name := range dirs[:len(dirs)-1] {
+			if name == "" {
+				continue
+			}
+			node = node.child(name)
+			node.pages++
+			if node.example == "" {
+				node.example = link
+			}
+		}
+	}
+
+	suggestions := []ruleSuggestion{{pattern: base + "**", reason: "everything below " + base, pages: root.pages}}
+	var walk func(prefix string, node *pathNode, depth int)
+	walk = func(prefix string, node *pathNode, depth int) {
+		names := make([]string, 0, len(node.children))
+		for name := range node.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		ignored := map[string]string{}
+		for name, reason := range siblingExclusions(names, strings.TrimPrefix(seed.Path, "/")) {
+			ignored[name] = reason
+		}
+		for _, name := range names {
+			if reason, ok := lowValueDirs[strings.ToLower(name)]; ok {
+				ignored[name] = reason
+			}
+		}
+		for _, name := range names {
+			child := node.children[name]
+			if reason, ok := ignored[name]; ok {
+				suggestions = append(suggestions, ruleSuggestion{pattern: "!" + prefix + name + "/**", reason: reason, pages: child.pages, example: child.example})
+			} else if depth+1 < suggestTreeDepth {
+				walk(prefix+name+"/", child, depth+1)
+			}
+		}
+	}
+	walk(base, root, 0)
+	return suggestions
+}
+
+// siblingExclusions returns the directories among names to exclude
+// because they are other languages or versions of the same docs: all
+// locale directories but the one in seedPath, or else English, and all
+// version directories but "latest" (or "stable", "current") or else the
+// highest version.
+func siblingExclusions(names []string, seedPath string) map[string]string {
+	excluded := map[string]string{}
+	seedDirs := map[string]bool{}
+	for _, dir := range strings.Split(seedPath, "/") {
+		seedDirs[dir] = true
+	}
+
+	var locales, versions []string
+	hasLatest := false
+	for _, name := range names {
+		if m := localeRe.FindStringSubmatch(name); m != nil && localeCodes[strings.ToLower(m[1])] {
+			locales = append(locales, name)
+		} else if versionRe.MatchString(name) {
+			versions = append(versions, name)
+		}
+		switch strings.ToLower(name) {
+		case "latest", "stable", "current":
+			hasLatest = true
+		}
+	}
+
+	if len(locales) >= 2 {
+		keep := ""
+		for _, name := range locales {
+			if seedDirs[name] {
+				keep = name
+			}
+		}
+		for _, name := range locales {
+			if keep == "" && strings.HasPrefix(strings.ToLower(name), "en") {
+				keep = name
+			}
+		}
+		if keep == "" {
+			keep = locales[0]
+		}
+		for _, name := range locales {
+			if name != keep {
+				excluded[name] = "another language (keeping " + keep + ")"
+			}
+		}
+	}
+
+	if len(versions) >= 2 || len(versions) >= 1 && hasLatest {
+		keep := ""
+		for _, name := range versions {
+			if seedDirs[name] {
+				keep = name
+			}
+		}
+		if keep == "" && !hasLatest {
+			keep = versions[0]
+			for _, name := range versions[1:] {
+				if compareVersions(name, keep) > 0 {
+					keep = name
+				}
+			}
+		}
+		reason := "an older version"
+		if keep != "" {
+			reason += " (keeping " + keep + ")"
+		}
+		for _, name := range versions {
+			if name != keep {
+				excluded[name] = reason
+			}
+		}
+	}
+	return excluded
+}
+
+// compareVersions compares two version directory names numerically.
+func compareVersions(a, b string) int {
+	pa := strings.Split(versionRe.FindStringSubmatch(a)[1], ".")
+	pb := strings.Split(versionRe.FindStringSubmatch(b)[1], ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// askYesNo asks a yes/no question on stdout, returning def for an empty
+// answer and false once input ends.
+func askYesNo(in *bufio.Reader, prompt string, def bool) bool {
+	choices := "[y/N]"
+	if def {
+		choices = "[Y/n]"
+	}
+	fmt.Printf("%s %s ", prompt, choices)
+	line, err := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	case "":
+		if err != nil {
+			fmt.Println()
+			return false
+		}
+	}
+	return def
+}
+
+// appendPatterns appends the patterns not already in the line-based
+// pattern file at target, creating it if needed, and returns how many it
+// added. Structured pattern files are left for the user to edit.
+func appendPatterns(target string, patterns []string) (int, error) {
+	switch strings.ToLower(filepath.Ext(target)) {
+	case ".yaml", ".yml", ".json", ".toml":
+		return 0, fmt.Errorf("%s is a structured pattern file; add the rules to its patterns list by hand", target)
+	}
+	existing, err := readPatternLines(target)
+	if err != nil {
+		return 0, err
+	}
+	have := map[string]bool{}
+	for _, p := range existing {
+		have[strings.TrimSpace(p.pattern)] = true
+	}
+	var lines []string
+	for _, p := range patterns {
+		if !have[p] {
+			lines = append(lines, p)
+			have[p] = true
+		}
+	}
+	if len(lines) == 0 {
+		return 0, nil
+	}
+
+	data, _ := os.ReadFile(target)
+	prefix := ""
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		prefix = "\n"
+	}
+	f, err := os.OpenFile(target, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(prefix + strings.Join(lines, "\n") + "\n"); err != nil {
+		return 0, err
+	}
+	return len(lines), nil
+}
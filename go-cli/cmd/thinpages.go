@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// pageThin marks pages excluded for too little or duplicated content.
+const pageThin = "thin"
+
+// pageTexts maps page URLs to the set of their word shingles.
+type pageTexts struct {
+	mu     sync.Mutex
+	seeded bool
+	pages  map[string]map[uint64]bool
+}
+
+func newPageTexts() *pageTexts {
+	return &pageTexts{pages: map[string]map[uint64]bool{}}
+}
+
+// thinReason returns why a converted page should be dropped: too few
+// words or tokens, or text at least ext.MaxSimilarity percent identical
+// to a page already kept. Kept pages are remembered for later checks, so
+// of two near-duplicates the first one converted stays.
+func thinReason(p convertedPage, opts *CrawlOptions) string {
+	ext := p.Extraction
+	if ext.MinWords > 0 {
+		if n := len(textWords(p.Body)); n < ext.MinWords {
+			return fmt.Sprintf("%d words, fewer than min_words %d", n, ext.MinWords)
+		}
+	}
+	if ext.MinTokens > 0 {
+		if n := estimateTokens(p.Body); n < ext.MinTokens {
+			return fmt.Sprintf("%d tokens, fewer than min_tokens %d", n, ext.MinTokens)
+		}
+	}
+	if ext.MaxSimilarity <= 0 {
+		return ""
+	}
+
+	set := map[uint64]bool{}
+	for _, s := range shingles(p.Body) {
+		set[s] = true
+	}
+	if len(set) == 0 {
+		return ""
+	}
+	texts := opts.texts
+	texts.mu.Lock()
+	defer texts.mu.Unlock()
+	if !texts.seeded {
+		texts.seed(opts)
+	}
+	for link, other := range texts.pages {
+		if link == p.URL {
+			continue
+		}
+		if similarity := shingleSimilarity(set, other); similarity >= ext.MaxSimilarity {
+			return fmt.Sprintf("%.0f%% identical to %s", similarity, link)
+		}
+	}
+	texts.pages[p.URL] = set
+	return ""
+}
+
+// seed adds the pages kept by earlier crawls, which unchanged pages skip
+// converting again. Callers hold t.mu.
+func (t *pageTexts) seed(opts *CrawlOptions) {
+	t.seeded = true
+	opts.manifest.mu.Lock()
+	pages := make(map[string]*ManifestEntry, len(opts.manifest.Pages))
+	for link, entry := range opts.manifest.Pages {
+		pages[link] = entry
+	}
+	opts.manifest.mu.Unlock()
+	for link, entry := range pages {
+		_, body, err := readPage(opts.pageFile(entry))
+		if err != nil {
+			continue
+		}
+		set := map[uint64]bool{}
+		for _, s := range shingles(body) {
+			set[s] = true
+		}
+		t.pages[link] = set
+	}
+}
+
+// shingleSimilarity returns the percentage of shingles two pages share,
+// relative to the larger page.
+func shingleSimilarity(a, b map[uint64]bool) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(b)) * 100
+}
+
+// excludeThinPage drops a page thinReason rejected, removing any output
+// an earlier crawl wrote for it.
+func excludeThinPage(opts *CrawlOptions, link, reason string) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return
+	}
+	fmt.Printf("Excluding %s: %s\n", link, reason)
+	excludePage(opts, pageThin, u, u, reason)
+}
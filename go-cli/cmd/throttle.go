@@ -0,0 +1,204 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Throttle defaults, used when the config leaves a setting empty.
+const (
+	defaultThrottleMaxDelay = time.Minute
+	defaultThrottleCooldown = 30 * time.Second
+	defaultThrottleRetries  = 3
+	// minThrottleStep is the smallest delay a slowed host gets.
+	minThrottleStep = 500 * time.Millisecond
+)
+
+// Request context keys used by the throttle.
+const (
+	throttleStartKey    = "throttle_start"
+	throttleAttemptsKey = "throttle_attempts"
+)
+
+// throttle spaces out requests to each host and slows a host down when it
+// answers 429 Too Many Requests or 503 Service Unavailable: the request
+// is retried after Retry-After (or the new delay), and the host's delay
+// doubles. Once a host has answered normally for a cool-down period, its
+// delay halves again, back to the configured one. Requests wait before
+// they are sent, so waiting does not count against the request timeout.
+type throttle struct {
+	delay    time.Duration
+	maxDelay time.Duration
+	cooldown time.Duration
+	retries  int
+
+	mu    sync.Mutex
+	hosts map[string]*hostThrottle
+}
+
+// hostThrottle is the request pacing of one host.
+type hostThrottle struct {
+	delay   time.Duration
+	next    time.Time // when the next request may start
+	changed time.Time // when delay last changed
+}
+
+// newThrottle returns the pacing configured by cfg.
+func newThrottle(cfg ThrottleConfig) (*throttle, error) {
+	t := &throttle{
+		maxDelay: defaultThrottleMaxDelay,
+		cooldown: defaultThrottleCooldown,
+		retries:  defaultThrottleRetries,
+		hosts:    map[string]*hostThrottle{},
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"delay", cfg.Delay, &t.delay},
+		{"max_delay", cfg.MaxDelay, &t.maxDelay},
+		{"cooldown", cfg.Cooldown, &t.cooldown},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid throttle.%s: %w", d.name, err)
+		}
+		*d.dest = v
+	}
+	if cfg.Retries != 0 {
+		t.retries = max(cfg.Retries, 0)
+	}
+	t.maxDelay = max(t.maxDelay, t.delay)
+	return t, nil
+}
+
+// host returns the pacing of host. Callers hold t.mu.
+func (t *throttle) host(host string) *hostThrottle {
+	h, ok := t.hosts[host]
+	if !ok {
+		h = &hostThrottle{delay: t.delay}
+		t.hosts[host] = h
+	}
+	return h
+}
+
+// wait blocks until r may be sent to its host and reserves its slot.
+func (t *throttle) wait(r *colly.Request) {
+	t.mu.Lock()
+	h := t.host(r.URL.Host)
+	start := time.Now()
+	if h.next.After(start) {
+		start = h.next
+	}
+	h.next = start.Add(h.delay)
+	t.mu.Unlock()
+	time.Sleep(time.Until(start))
+	r.Ctx.Put(throttleStartKey, start)
+}
+
+// retry slows the host of a 429 or 503 response down and sends the
+// request again, unless it has been retried too often. It reports whether
+// the request was retried.
+func (t *throttle) retry(r *colly.Response) bool {
+	if r.StatusCode != http.StatusTooManyRequests && r.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+	var after time.Duration
+	if r.Headers != nil {
+		after = retryAfter(r.Headers.Get("Retry-After"), time.Now())
+	}
+	started, _ := r.Ctx.GetAny(throttleStartKey).(time.Time)
+	wait := t.slowDown(r.Request.URL.Host, started, after)
+
+	attempts, _ := r.Ctx.GetAny(throttleAttemptsKey).(int)
+	if attempts >= t.retries {
+		return false
+	}
+	r.Ctx.Put(throttleAttemptsKey, attempts+1)
+	fmt.Printf("Throttled by %s (HTTP %d), retrying %s in %s\n", r.Request.URL.Host, r.StatusCode, r.Request.URL, wait.Round(time.Millisecond))
+	// Failed retries are reported by their own OnError call; only a
+	// request that could not be sent again is left to the caller.
+	return r.Request.Retry() != colly.ErrRetryBodyUnseekable
+}
+
+// slowDown holds the next request to host back by its delay, or by
+// retryAfter when the server asked for longer, up to the maximum delay,
+// and returns how long that is. The delay doubles unless the throttled
+// request was sent before the last change, since requests already in
+// flight answer for the old rate.
+func (t *throttle) slowDown(host string, started time.Time, retryAfter time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.host(host)
+	now := time.Now()
+	if !started.Before(h.changed) {
+		if delay := min(max(2*h.delay, minThrottleStep), t.maxDelay); delay != h.delay {
+			fmt.Printf("Slowing down %s: %s between requests\n", host, delay)
+			h.delay = delay
+		}
+		h.changed = now
+	}
+	wait := min(max(retryAfter, h.delay), t.maxDelay)
+	if next := now.Add(wait); next.After(h.next) {
+		h.next = next
+	}
+	return wait
+}
+
+// succeeded halves the delay of host once it has gone a cool-down period
+// without being throttled.
+func (t *throttle) succeeded(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.host(host)
+	if h.delay <= t.delay || time.Since(h.changed) < t.cooldown {
+		return
+	}
+	h.delay /= 2
+	if h.delay < minThrottleStep {
+		h.delay = t.delay
+	}
+	h.changed = time.Now()
+	fmt.Printf("Speeding up %s: %s between requests\n", host, h.delay)
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP
+// date, into how long to wait from now. It returns 0 when the header is
+// missing or invalid.
+func retryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,303 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// breadcrumbSelector matches common breadcrumb trails.
+const breadcrumbSelector = `nav[aria-label*="readcrumb"], [itemtype$="schema.org/BreadcrumbList"], .breadcrumb, .breadcrumbs`
+
+// disambiguateTitles qualifies the titles of pages in a skill that share
+// a title with the section they belong to, e.g. "Installation (Android)".
+// Sections come from the page's breadcrumbs, or else its URL path, named
+// by their landing page's title when it was crawled. Each page gets the
+// fewest nearest sections that tell it apart from the others. The H1, the manifest title, and the
+// name (when it was derived from the title) are updated.
+func disambiguateTitles(opts *CrawlOptions, m *Manifest) {
+	titles := map[string]string{}
+	for _, entry := range m.Pages {
+		titles[strings.TrimSuffix(entry.URL, "/")] = entry.Title
+	}
+
+	// Pages are grouped by their unqualified title, so pages qualified by
+	// an earlier crawl still group with ones fetched again since.
+	base := map[*ManifestEntry]string{}
+	sections := map[*ManifestEntry][]string{}
+	groups := map[string][]*ManifestEntry{}
+	for _, entry := range m.Pages {
+		if entry.Title == "" {
+			continue
+		}
+		base[entry] = entry.Title
+		if i := strings.LastIndex(entry.Title, " ("); i > 0 && strings.HasSuffix(entry.Title, ")") {
+			names := pageSections(opts, entry, entry.Title[:i], titles)
+			if isQualifier(entry.Title[i+2:len(entry.Title)-1], names) {
+				base[entry] = entry.Title[:i]
+				sections[entry] = names
+			}
+		}
+		if sections[entry] == nil {
+			sections[entry] = pageSections(opts, entry, base[entry], titles)
+		}
+		key := opts.skillDirOf(entry) + "\x00" + strings.ToLower(base[entry])
+		groups[key] = append(groups[key], entry)
+	}
+
+	count := 0
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		trails := make([][]string, len(group))
+		for i, entry := range group {
+			trails[i] = sections[entry]
+		}
+		for i, entry := range group {
+			n := qualifierDepth(trails, i)
+			if n == 0 {
+				continue
+			}
+			title := fmt.Sprintf("%s (%s)", base[entry], qualifier(trails[i][:n]))
+			if title == entry.Title {
+				continue
+			}
+			if err := retitlePage(opts, m, entry, base[entry], title); err != nil {
+				fmt.Printf("Error retitling %s: %v\n", entry.Path, err)
+				continue
+			}
+			count++
+		}
+	}
+	if count > 0 {
+		fmt.Printf("Disambiguated %d duplicate titles\n", count)
+	}
+}
+
+// qualifierDepth returns how many of page i's sections, nearest first,
+// tell it apart from every other page in the group, or all of them if
+// none suffice.
+func qualifierDepth(sections [][]string, i int) int {
+	prefix := func(s []string, depth int) string {
+		return strings.ToLower(strings.Join(s[:min(depth, len(s))], "\x00"))
+	}
+	for depth := 1; depth < len(sections[i]); depth++ {
+		unique := true
+		for j, other := range sections {
+			if j != i && prefix(other, depth) == prefix(sections[i], depth) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return depth
+		}
+	}
+	return len(sections[i])
+}
+
+// qualifier joins section names, given nearest first, outermost first.
+func qualifier(names []string) string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[len(names)-1-i] = name
+	}
+	return strings.Join(out, " / ")
+}
+
+// isQualifier reports whether q is a qualifier built from the nearest
+// sections in names.
+func isQualifier(q string, names []string) bool {
+	for n := 1; n <= len(names); n++ {
+		if qualifier(names[:n]) == q {
+			return true
+		}
+	}
+	return false
+}
+
+// pageSections returns the names of the sections a page is in, nearest
+// first, leaving out any named like the page itself.
+func pageSections(opts *CrawlOptions, entry *ManifestEntry, title string, titles map[string]string) []string {
+	names := breadcrumbNames(opts, entry)
+	if len(names) == 0 {
+		names = urlSectionNames(entry.URL, titles)
+	}
+	var out []string
+	for i := len(names) - 1; i >= 0; i-- {
+		if !strings.EqualFold(names[i], title) && names[i] != "" {
+			out = append(out, names[i])
+		}
+	}
+	return out
+}
+
+// breadcrumbNames reads the breadcrumb trail of a crawled page, outermost
+// first, from BreadcrumbList JSON-LD or breadcrumb markup.
+func breadcrumbNames(opts *CrawlOptions, entry *ManifestEntry) []string {
+	html, err := opts.readSavedHTML(entry)
+	if err != nil {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err == nil {
+			names = jsonLDBreadcrumbs(data)
+		}
+		return len(names) == 0
+	})
+	if len(names) > 0 {
+		return names
+	}
+
+	trail := doc.Find(breadcrumbSelector).First()
+	items := trail.Find("li")
+	if items.Length() == 0 {
+		items = trail.Find("a")
+	}
+	items.Each(func(i int, s *goquery.Selection) {
+		names = append(names, squash(s.Text()))
+	})
+	return names
+}
+
+// jsonLDBreadcrumbs returns the item names of the first BreadcrumbList in
+// a JSON-LD document, ordered by position.
+func jsonLDBreadcrumbs(data interface{}) []string {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if names := jsonLDBreadcrumbs(item); len(names) > 0 {
+				return names
+			}
+		}
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok {
+			if names := jsonLDBreadcrumbs(graph); len(names) > 0 {
+				return names
+			}
+		}
+		if !jsonLDHasType(v, "BreadcrumbList") {
+			return nil
+		}
+		items, _ := v["itemListElement"].([]interface{})
+		type crumb struct {
+			position float64
+			name     string
+		}
+		var crumbs []crumb
+		for _, raw := range items {
+			item, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := item["name"].(string)
+			if inner, ok := item["item"].(map[string]interface{}); ok && name == "" {
+				name, _ = inner["name"].(string)
+			}
+			position, _ := item["position"].(float64)
+			crumbs = append(crumbs, crumb{position, squash(name)})
+		}
+		sort.SliceStable(crumbs, func(i, j int) bool { return crumbs[i].position < crumbs[j].position })
+		var names []string
+		for _, c := range crumbs {
+			names = append(names, c.name)
+		}
+		return names
+	}
+	return nil
+}
+
+// urlSectionNames returns the names of the directories above a page in
+// its URL, outermost first. A directory is named by the title of its
+// landing page when that was crawled, and otherwise by its path segment.
+func urlSectionNames(link string, titles map[string]string) []string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if last := segments[len(segments)-1]; strings.HasPrefix(last, "index.") {
+		segments = segments[:len(segments)-1]
+	}
+	if len(segments) > 0 {
+		segments = segments[:len(segments)-1]
+	}
+
+	var names []string
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		dir := u.Scheme + "://" + u.Host + "/" + strings.Join(segments[:i+1], "/")
+		title := titles[dir]
+		if title == "" {
+			title = titles[dir+"/index.html"]
+		}
+		if title == "" {
+			title = titleCase(strings.NewReplacer("-", " ", "_", " ").Replace(segment))
+		}
+		names = append(names, title)
+	}
+	return names
+}
+
+// retitlePage renames a page's H1 and manifest title, and its name when
+// that was derived from its title, unqualified or not.
+func retitlePage(opts *CrawlOptions, m *Manifest, entry *ManifestEntry, original, title string) error {
+	path := filepath.Join(opts.Output, filepath.FromSlash(entry.Path))
+	data, err := readOutputFile(path)
+	if err != nil {
+		return err
+	}
+	fmText, body := splitFrontmatter(string(data))
+	fm, err := parseFrontmatter(fmText)
+	if err != nil {
+		return err
+	}
+	name := entry.Name
+	if name == sanitizeName(toPathCase(entry.Title)) || name == sanitizeName(toPathCase(original)) {
+		name = sanitizeName(toPathCase(title))
+		fm.set("name", name)
+	}
+	body = strings.Replace(body, "# "+entry.Title+"\n", "# "+title+"\n", 1)
+
+	content := fm.render() + body
+	if err := writeFileIfChanged(path, []byte(content)); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	entry.Title = title
+	entry.Name = name
+	entry.FileHash = contentHash(content)
+	m.touch(entry.URL)
+	m.mu.Unlock()
+	return nil
+}
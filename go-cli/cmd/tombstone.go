@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultTombstoneRetention is how long tombstones are kept when the
+// config does not say.
+const defaultTombstoneRetention = 30 * 24 * time.Hour
+
+// tombstoneSuffix ends the names of tombstone files.
+const tombstoneSuffix = ".tombstone.json"
+
+// Tombstone records a page removed from the output, so tools syncing the
+// output can delete their copy instead of inferring it from the page's
+// absence. It is kept until ExpiresAt, or until the page comes back.
+type Tombstone struct {
+	URL         string    `json:"url"`
+	Path        string    `json:"path"`
+	Title       string    `json:"title,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	FileHash    string    `json:"file_hash,omitempty"`
+	InlinedIn   string    `json:"inlined_in,omitempty"`
+	RemovedAt   time.Time `json:"removed_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// keepTombstones makes m record a tombstone for each page removed from
+// now on, when cfg enables them.
+func (m *Manifest) keepTombstones(cfg TombstoneConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	retention := defaultTombstoneRetention
+	if cfg.Retention != "" {
+		d, err := time.ParseDuration(cfg.Retention)
+		if err != nil {
+			return fmt.Errorf("invalid retention %q: %w", cfg.Retention, err)
+		}
+		retention = d
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tombstoneTTL = retention
+	return nil
+}
+
+// bury records a tombstone for the page at url, which is being removed
+// for reason. Callers hold m.mu.
+func (m *Manifest) bury(url, reason string) {
+	entry, ok := m.Pages[url]
+	if !ok || m.tombstoneTTL <= 0 {
+		return
+	}
+	if m.Tombstones == nil {
+		m.Tombstones = map[string]*Tombstone{}
+	}
+	now := time.Now().UTC()
+	m.Tombstones[url] = &Tombstone{
+		URL:         url,
+		Path:        entry.Path,
+		Title:       entry.Title,
+		Reason:      reason,
+		ContentHash: entry.Provenance.ContentHash,
+		FileHash:    entry.FileHash,
+		InlinedIn:   entry.InlinedIn,
+		RemovedAt:   now,
+		ExpiresAt:   now.Add(m.tombstoneTTL),
+	}
+}
+
+// pruneTombstones drops expired tombstones and those of pages that were
+// saved again. Callers hold m.mu.
+func (m *Manifest) pruneTombstones(now time.Time) {
+	for url, t := range m.Tombstones {
+		if _, ok := m.Pages[url]; ok || now.After(t.ExpiresAt) {
+			delete(m.Tombstones, url)
+		}
+	}
+}
+
+// tombstonePath returns the tombstone file of the page saved at path
+// (relative to the output directory).
+func tombstonePath(path string) string {
+	return strings.TrimSuffix(path, ".md") + tombstoneSuffix
+}
+
+// syncTombstoneFiles writes each tombstone next to where its page was
+// when cfg.Files is set, and deletes tombstone files whose tombstone
+// expired or whose page came back.
+func syncTombstoneFiles(opts *CrawlOptions, m *Manifest, cfg TombstoneConfig) {
+	m.mu.Lock()
+	m.pruneTombstones(time.Now())
+	want := map[string]*Tombstone{}
+	if cfg.Enabled && cfg.Files {
+		for _, t := range m.Tombstones {
+			if t.Path != "" && t.InlinedIn == "" {
+				want[tombstonePath(t.Path)] = t
+			}
+		}
+	}
+	var stale []string
+	for path := range m.Generated {
+		if strings.HasSuffix(path, tombstoneSuffix) && want[path] == nil {
+			stale = append(stale, path)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, path := range stale {
+		os.Remove(filepath.Join(opts.Output, filepath.FromSlash(path)))
+		m.removeGenerated(path)
+	}
+	for path, t := range want {
+		data, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			continue
+		}
+		data = append(data, '\n')
+		full := filepath.Join(opts.Output, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			fmt.Printf("Warning: could not write tombstone %s: %v\n", path, err)
+			continue
+		}
+		if err := writeFileIfChanged(full, data); err != nil {
+			fmt.Printf("Warning: could not write tombstone %s: %v\n", path, err)
+			continue
+		}
+		m.recordGenerated(path, string(data))
+	}
+}
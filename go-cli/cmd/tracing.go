@@ -0,0 +1,411 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// traceBatchSize is how many ended spans are buffered before they are
+// exported.
+const traceBatchSize = 256
+
+// Request context keys of a page's span and its fetch span.
+const (
+	pageSpanKey  = "trace_span"
+	fetchSpanKey = "trace_fetch"
+)
+
+// tracer records spans of the crawl pipeline and exports them to an
+// OpenTelemetry collector with OTLP over HTTP (JSON encoding), so a crawl
+// shows up in the traces of the pipeline that runs it. A nil tracer
+// records nothing.
+type tracer struct {
+	endpoint string
+	headers  map[string]string
+	service  string
+	client   *http.Client
+	root     *span
+
+	mu      sync.Mutex
+	pending []otlpSpan
+	warned  bool
+}
+
+// newTracer returns a tracer for cfg, falling back to the standard
+// OTEL_* environment variables, or nil when no endpoint is configured. A
+// W3C TRACEPARENT in the environment makes the crawl part of that trace.
+func newTracer(cfg TracingConfig) *tracer {
+	// A base endpoint gets the traces path; the traces variable is a
+	// full URL already.
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint != "" && !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+	if full := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); cfg.Endpoint == "" && full != "" {
+		endpoint = full
+	}
+	if endpoint == "" {
+		return nil
+	}
+
+	headers := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
+	service := cfg.ServiceName
+	if service == "" {
+		service = os.Getenv("OTEL_SERVICE_NAME")
+	}
+	if service == "" {
+		service = toolName
+	}
+	return &tracer{endpoint: endpoint, headers: headers, service: service, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// start begins the root span of the crawl.
+func (t *tracer) start(name string, attrs ...attr) *span {
+	if t == nil {
+		return nil
+	}
+	s := &span{t: t, name: name, start: time.Now(), attrs: attrs}
+	if traceID, parentID, ok := parseTraceparent(os.Getenv("TRACEPARENT")); ok {
+		s.traceID, s.parentID = traceID, parentID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	t.root = s
+	return s
+}
+
+// Close ends the root span and exports every span not yet exported.
+func (t *tracer) Close() error {
+	if t == nil {
+		return nil
+	}
+	t.root.end()
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	t.export(batch)
+	return nil
+}
+
+// finished queues an ended span for export.
+func (t *tracer) finished(s otlpSpan) {
+	t.mu.Lock()
+	t.pending = append(t.pending, s)
+	var batch []otlpSpan
+	if len(t.pending) >= traceBatchSize {
+		batch = t.pending
+		t.pending = nil
+	}
+	t.mu.Unlock()
+	if batch != nil {
+		t.export(batch)
+	}
+}
+
+// export sends spans to the collector. Failures are reported once, so an
+// unreachable collector does not flood the crawl output.
+func (t *tracer) export(spans []otlpSpan) {
+	if len(spans) == 0 {
+		return
+	}
+	body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttr{stringAttr("service.name", t.service).otlp(), stringAttr("service.version", buildInfo().Version).otlp()}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: toolName, Version: buildInfo().Version},
+			Spans: spans,
+		}},
+	}}})
+	if err == nil {
+		err = t.post(body)
+	}
+	if err != nil {
+		t.mu.Lock()
+		warn := !t.warned
+		t.warned = true
+		t.mu.Unlock()
+		if warn {
+			fmt.Printf("Warning: exporting traces to %s: %v\n", t.endpoint, err)
+		}
+	}
+}
+
+func (t *tracer) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// span is one timed stage of the pipeline. A nil span records nothing, so
+// callers need not check whether tracing is on.
+type span struct {
+	t        *tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	client   bool
+	start    time.Time
+	attrs    []attr
+
+	mu    sync.Mutex
+	err   error
+	ended bool
+}
+
+// attr is a span attribute: a string or an int.
+type attr struct {
+	key string
+	str string
+	num int64
+	isN bool
+}
+
+func stringAttr(key, value string) attr {
+	return attr{key: key, str: value}
+}
+
+func intAttr(key string, value int) attr {
+	return attr{key: key, num: int64(value), isN: true}
+}
+
+// child starts a span below s.
+func (s *span) child(name string, attrs ...attr) *span {
+	if s == nil {
+		return nil
+	}
+	c := &span{t: s.t, traceID: s.traceID, parentID: s.spanID, name: name, start: time.Now(), attrs: attrs}
+	rand.Read(c.spanID[:])
+	return c
+}
+
+// set adds attributes to s.
+func (s *span) set(attrs ...attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// fail marks s as failed with err.
+func (s *span) fail(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// end finishes s and queues it for export. Ending a span again does
+// nothing.
+func (s *span) end() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	o := otlpSpan{
+		TraceID:   hex.EncodeToString(s.traceID[:]),
+		SpanID:    hex.EncodeToString(s.spanID[:]),
+		Name:      s.name,
+		Kind:      1, // internal
+		StartTime: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTime:   strconv.FormatInt(time.Now().UnixNano(), 10),
+	}
+	if s.parentID != [8]byte{} {
+		o.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.client {
+		o.Kind = 3 // client
+	}
+	for _, a := range s.attrs {
+		o.Attributes = append(o.Attributes, a.otlp())
+	}
+	if s.err != nil {
+		o.Status = &otlpStatus{Code: 2, Message: s.err.Error()}
+	}
+	s.mu.Unlock()
+	s.t.finished(o)
+}
+
+// pageSpan returns the span of the page r fetches, starting it on the
+// first attempt as a child of the crawl's root span.
+func (t *tracer) pageSpan(r *colly.Request) *span {
+	if t == nil || r == nil || r.Ctx == nil {
+		return nil
+	}
+	if s, ok := r.Ctx.GetAny(pageSpanKey).(*span); ok {
+		return s
+	}
+	s := t.root.child("page", stringAttr("url.full", r.URL.String()), stringAttr("server.address", r.URL.Hostname()))
+	r.Ctx.Put(pageSpanKey, s)
+	return s
+}
+
+// stageSpan starts a span for a pipeline stage of the page r fetched,
+// tagged with its host.
+func (t *tracer) stageSpan(r *colly.Request, name string, attrs ...attr) *span {
+	s := t.pageSpan(r).child(name, append([]attr{stringAttr("server.address", r.URL.Hostname())}, attrs...)...)
+	if s != nil && name == "fetch" {
+		s.client = true
+	}
+	return s
+}
+
+// fetch starts the fetch span of r.
+func (t *tracer) fetch(r *colly.Request) {
+	if s := t.stageSpan(r, "fetch", stringAttr("http.request.method", r.Method), stringAttr("url.full", r.URL.String())); s != nil {
+		r.Ctx.Put(fetchSpanKey, s)
+	}
+}
+
+// fetched ends the fetch span of r with its status. Statuses below
+// 400, such as 304 Not Modified, are not failures.
+func (t *tracer) fetched(r *colly.Response, err error) {
+	if t == nil || r.Ctx == nil {
+		return
+	}
+	s, _ := r.Ctx.GetAny(fetchSpanKey).(*span)
+	if r.StatusCode != 0 {
+		s.set(intAttr("http.response.status_code", r.StatusCode))
+	}
+	if r.StatusCode == 0 || r.StatusCode >= 400 {
+		s.fail(err)
+	}
+	s.end()
+}
+
+// parseTraceparent parses a W3C traceparent header value.
+func parseTraceparent(value string) (traceID [16]byte, spanID [8]byte, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return traceID, spanID, false
+	}
+	if _, err := hex.Decode(traceID[:], []byte(parts[1])); err != nil {
+		return traceID, spanID, false
+	}
+	if _, err := hex.Decode(spanID[:], []byte(parts[2])); err != nil {
+		return traceID, spanID, false
+	}
+	return traceID, spanID, traceID != [16]byte{} && spanID != [8]byte{}
+}
+
+// OTLP/JSON export payload.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttr `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID      string      `json:"traceId"`
+	SpanID       string      `json:"spanId"`
+	ParentSpanID string      `json:"parentSpanId,omitempty"`
+	Name         string      `json:"name"`
+	Kind         int         `json:"kind"`
+	StartTime    string      `json:"startTimeUnixNano"`
+	EndTime      string      `json:"endTimeUnixNano"`
+	Attributes   []otlpAttr  `json:"attributes,omitempty"`
+	Status       *otlpStatus `json:"status,omitempty"`
+}
+
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func (a attr) otlp() otlpAttr {
+	if a.isN {
+		n := strconv.FormatInt(a.num, 10)
+		return otlpAttr{Key: a.key, Value: otlpValue{IntValue: &n}}
+	}
+	s := a.str
+	return otlpAttr{Key: a.key, Value: otlpValue{StringValue: &s}}
+}
@@ -0,0 +1,305 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Endpoints used to find and download captions.
+var (
+	youtubeWatchURL = "https://www.youtube.com/watch?v="
+	vimeoConfigURL  = "https://player.vimeo.com/video/%s/config"
+)
+
+// transcriptParagraph is how much video time each transcript paragraph
+// covers.
+const transcriptParagraph = 30 * time.Second
+
+var (
+	youtubeEmbedRe = regexp.MustCompile(`(?:youtube(?:-nocookie)?\.com/(?:embed/|watch\?v=)|youtu\.be/)([\w-]{11})`)
+	vimeoEmbedRe   = regexp.MustCompile(`(?:player\.)?vimeo\.com/(?:video/)?(\d+)`)
+	vttTimestampRe = regexp.MustCompile(`^(?:(\d+):)?(\d{2}):(\d{2})\.(\d{3}) -->`)
+)
+
+// transcriptCue is a caption shown from Start.
+type transcriptCue struct {
+	Start time.Duration
+	Text  string
+}
+
+// transcriptClient fetches captions for embedded videos.
+type transcriptClient struct {
+	languages []string
+	client    *http.Client
+}
+
+func newTranscriptClient(cfg TranscriptConfig) *transcriptClient {
+	languages := cfg.Languages
+	if len(languages) == 0 {
+		languages = []string{"en"}
+	}
+	return &transcriptClient{languages: languages, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// addTranscriptRule makes the converter render YouTube and Vimeo iframes as
+// a link to the video followed by its transcript in a collapsible section.
+func addTranscriptRule(converter *md.Converter, t *transcriptClient) {
+	converter.AddRules(md.Rule{
+		Filter: []string{"iframe"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			out := t.embed(selec.AttrOr("src", ""), selec.AttrOr("title", ""))
+			if out == "" {
+				return nil
+			}
+			return &out
+		},
+	})
+}
+
+// embed returns the markdown of a video iframe: a link to the video and
+// its transcript. Iframes of other sites return "".
+func (t *transcriptClient) embed(src, title string) string {
+	if title == "" {
+		title = "Video"
+	}
+	var cues []transcriptCue
+	var err error
+	var watch string
+	if m := youtubeEmbedRe.FindStringSubmatch(src); m != nil {
+		watch = "https://www.youtube.com/watch?v=" + m[1]
+		cues, err = t.youtube(m[1])
+	} else if m := vimeoEmbedRe.FindStringSubmatch(src); m != nil {
+		watch = "https://vimeo.com/" + m[1]
+		cues, err = t.vimeo(m[1])
+	} else {
+		return ""
+	}
+	if err != nil {
+		fmt.Printf("Warning: no transcript for %s: %v\n", watch, err)
+	}
+	out := fmt.Sprintf("\n\n[%s](%s)\n\n", title, watch)
+	if len(cues) > 0 {
+		out += renderTranscript(title, cues)
+	}
+	return out
+}
+
+// renderTranscript renders cues as a collapsible section of timestamped
+// paragraphs.
+func renderTranscript(title string, cues []transcriptCue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<details>\n<summary>Transcript: %s</summary>\n\n", html.EscapeString(title))
+	var para []string
+	var start time.Duration
+	flush := func() {
+		if len(para) > 0 {
+			fmt.Fprintf(&b, "[%s] %s\n\n", formatTimestamp(start), strings.Join(para, " "))
+		}
+		para = nil
+	}
+	for _, cue := range cues {
+		if len(para) > 0 && cue.Start-start >= transcriptParagraph {
+			flush()
+		}
+		if len(para) == 0 {
+			start = cue.Start
+		}
+		para = append(para, cue.Text)
+	}
+	flush()
+	b.WriteString("</details>\n\n")
+	return b.String()
+}
+
+// formatTimestamp formats d as m:ss or h:mm:ss.
+func formatTimestamp(d time.Duration) string {
+	s := int(d.Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+func (t *transcriptClient) get(link string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("Accept-Language", strings.Join(t.languages, ","))
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", link, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// youtube fetches the captions of a YouTube video, preferring manual
+// captions over automatic ones in the configured languages.
+func (t *transcriptClient) youtube(id string) ([]transcriptCue, error) {
+	page, err := t.get(youtubeWatchURL + id)
+	if err != nil {
+		return nil, err
+	}
+	i := bytes.Index(page, []byte(`"captionTracks":`))
+	if i < 0 {
+		return nil, fmt.Errorf("no captions")
+	}
+	var tracks []struct {
+		BaseURL      string `json:"baseUrl"`
+		LanguageCode string `json:"languageCode"`
+		Kind         string `json:"kind"`
+	}
+	// The tracks are embedded in the player's JSON; decode just the array.
+	if err := json.NewDecoder(bytes.NewReader(page[i+len(`"captionTracks":`):])).Decode(&tracks); err != nil {
+		return nil, err
+	}
+
+	best, bestScore := "", -1
+	for _, track := range tracks {
+		for i, lang := range t.languages {
+			if track.LanguageCode == lang || strings.HasPrefix(track.LanguageCode, lang+"-") {
+				score := (len(t.languages) - i) * 2
+				if track.Kind != "asr" {
+					score++
+				}
+				if score > bestScore {
+					best, bestScore = track.BaseURL, score
+				}
+			}
+		}
+	}
+	if best == "" {
+		return nil, fmt.Errorf("no captions in %s", strings.Join(t.languages, ", "))
+	}
+
+	data, err := t.get(best)
+	if err != nil {
+		return nil, err
+	}
+	var transcript struct {
+		Texts []struct {
+			Start string `xml:"start,attr"`
+			Text  string `xml:",chardata"`
+		} `xml:"text"`
+	}
+	if err := xml.Unmarshal(data, &transcript); err != nil {
+		return nil, err
+	}
+	var cues []transcriptCue
+	for _, text := range transcript.Texts {
+		start, _ := strconv.ParseFloat(text.Start, 64)
+		cues = append(cues, transcriptCue{
+			Start: time.Duration(start * float64(time.Second)),
+			Text:  squash(html.UnescapeString(text.Text)),
+		})
+	}
+	return cues, nil
+}
+
+// vimeo fetches the text tracks of a Vimeo video from its player config.
+func (t *transcriptClient) vimeo(id string) ([]transcriptCue, error) {
+	configURL := fmt.Sprintf(vimeoConfigURL, id)
+	data, err := t.get(configURL)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Request struct {
+			TextTracks []struct {
+				Lang string `json:"lang"`
+				URL  string `json:"url"`
+			} `json:"text_tracks"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	for _, lang := range t.languages {
+		for _, track := range config.Request.TextTracks {
+			if track.Lang != lang && !strings.HasPrefix(track.Lang, lang+"-") {
+				continue
+			}
+			base, _ := url.Parse(configURL)
+			trackURL, err := base.Parse(track.URL)
+			if err != nil {
+				return nil, err
+			}
+			vtt, err := t.get(trackURL.String())
+			if err != nil {
+				return nil, err
+			}
+			return parseWebVTT(string(vtt)), nil
+		}
+	}
+	return nil, fmt.Errorf("no captions in %s", strings.Join(t.languages, ", "))
+}
+
+// parseWebVTT returns the cues of a WebVTT file.
+func parseWebVTT(vtt string) []transcriptCue {
+	var cues []transcriptCue
+	var cur *transcriptCue
+	for _, line := range strings.Split(strings.ReplaceAll(vtt, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if m := vttTimestampRe.FindStringSubmatch(line); m != nil {
+			h, _ := strconv.Atoi(m[1])
+			mins, _ := strconv.Atoi(m[2])
+			sec, _ := strconv.Atoi(m[3])
+			ms, _ := strconv.Atoi(m[4])
+			cues = append(cues, transcriptCue{
+				Start: time.Duration(h)*time.Hour + time.Duration(mins)*time.Minute +
+					time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond,
+			})
+			cur = &cues[len(cues)-1]
+			continue
+		}
+		if line == "" {
+			cur = nil
+			continue
+		}
+		if cur != nil {
+			cur.Text = strings.TrimSpace(cur.Text + " " + stripTags(line))
+		}
+	}
+	return cues
+}
+
+// stripTags removes inline markup such as <v Speaker> from caption text.
+func stripTags(s string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+	return doc.Text()
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gobwas/glob"
+	"github.com/gocolly/colly/v2"
+)
+
+// pageTrap is the report kind for search results, filtered listings, and
+// calendars: URL spaces a crawl could follow forever.
+const pageTrap = "trap"
+
+// Default limits of the trap heuristics.
+const (
+	defaultTrapMaxParams     = 3
+	defaultTrapMaxVariants   = 50
+	defaultTrapMaxSimilarity = 90
+)
+
+// defaultTrapParams maps query parameters that mark a trap to the kind of
+// page they make.
+var defaultTrapParams = map[string]string{
+	"q":          "search results",
+	"query":      "search results",
+	"search":     "search results",
+	"s":          "search results",
+	"keyword":    "search results",
+	"keywords":   "search results",
+	"term":       "search results",
+	"filter":     "filtered listing",
+	"filters":    "filtered listing",
+	"facet":      "filtered listing",
+	"facets":     "filtered listing",
+	"refine":     "filtered listing",
+	"sort":       "filtered listing",
+	"sortby":     "filtered listing",
+	"sort_by":    "filtered listing",
+	"order":      "filtered listing",
+	"orderby":    "filtered listing",
+	"order_by":   "filtered listing",
+	"date":       "calendar",
+	"day":        "calendar",
+	"week":       "calendar",
+	"month":      "calendar",
+	"year":       "calendar",
+	"calendar":   "calendar",
+	"start_date": "calendar",
+	"end_date":   "calendar",
+}
+
+// trapDetector recognises links into infinite URL spaces: by their query
+// parameters and paths, by how many variants of one URL shape a crawl
+// meets, and by fetched variants whose content is nearly the same.
+type trapDetector struct {
+	disabled      bool
+	params        map[string]string
+	allow         []glob.Glob
+	maxParams     int
+	maxVariants   int
+	maxSimilarity float64
+
+	mu       sync.Mutex
+	variants map[string]map[string]bool
+	texts    map[string]map[string]map[uint64]bool
+	trapped  map[string]string
+	reported map[string]bool
+}
+
+// newTrapDetector compiles the trap heuristics from the config.
+func newTrapDetector(cfg TrapConfig) (*trapDetector, error) {
+	t := &trapDetector{
+		disabled:      cfg.Disabled,
+		params:        map[string]string{},
+		maxParams:     cfg.MaxParams,
+		maxVariants:   cfg.MaxVariants,
+		maxSimilarity: cfg.MaxSimilarity,
+		variants:      map[string]map[string]bool{},
+		texts:         map[string]map[string]map[uint64]bool{},
+		trapped:       map[string]string{},
+		reported:      map[string]bool{},
+	}
+	for name, kind := range defaultTrapParams {
+		t.params[name] = kind
+	}
+	for _, name := range cfg.Params {
+		t.params[strings.ToLower(name)] = "search or filter page"
+	}
+	if t.maxParams == 0 {
+		t.maxParams = defaultTrapMaxParams
+	}
+	if t.maxVariants == 0 {
+		t.maxVariants = defaultTrapMaxVariants
+	}
+	if t.maxSimilarity == 0 {
+		t.maxSimilarity = defaultTrapMaxSimilarity
+	}
+	for _, pattern := range cfg.Allow {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trap allow glob %s: %w", pattern, err)
+		}
+		t.allow = append(t.allow, g)
+	}
+	return t, nil
+}
+
+// allowed reports whether link is never treated as a trap.
+func (t *trapDetector) allowed(link string) bool {
+	if t.disabled {
+		return true
+	}
+	for _, g := range t.allow {
+		if g.Match(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns why a link found on a page leads into a trap, or "" to
+// follow it. Every link checked counts as a variant of its shape.
+func (t *trapDetector) check(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || t.allowed(link) {
+		return ""
+	}
+	if reason := trapURLReason(u, t.params, t.maxParams); reason != "" {
+		return reason
+	}
+
+	shape := urlShape(u)
+	if shape == "" {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if reason, ok := t.trapped[shape]; ok {
+		return reason
+	}
+	seen := t.variants[shape]
+	if seen == nil {
+		seen = map[string]bool{}
+		t.variants[shape] = seen
+	}
+	if !seen[link] && t.maxVariants > 0 && len(seen) >= t.maxVariants {
+		reason := fmt.Sprintf("more than max_variants %d URLs like %s", t.maxVariants, shape)
+		t.trapped[shape] = reason
+		return reason
+	}
+	seen[link] = true
+	return ""
+}
+
+// trapURLReason returns why u looks like a search, filter, or calendar
+// URL by its query parameters and path alone.
+func trapURLReason(u *url.URL, params map[string]string, maxParams int) string {
+	query := u.Query()
+	for _, name := range sortedKeys(query) {
+		lower := strings.ToLower(name)
+		if i := strings.IndexByte(lower, '['); i > 0 {
+			lower = lower[:i]
+		}
+		if kind, ok := params[lower]; ok {
+			return fmt.Sprintf("%s (?%s=)", kind, name)
+		}
+	}
+	if maxParams > 0 && len(query) > maxParams {
+		return fmt.Sprintf("%d query parameters, more than max_params %d", len(query), maxParams)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	counts := map[string]int{}
+	for i, segment := range segments {
+		lower := strings.ToLower(segment)
+		switch {
+		case lower == "search" && u.RawQuery != "":
+			return fmt.Sprintf("search results (/%s?)", segment)
+		case lower == "calendar" && i+1 < len(segments) && numericSegment(segments[i+1]):
+			return fmt.Sprintf("calendar (/%s/%s/)", segment, segments[i+1])
+		}
+		if segment != "" {
+			counts[segment]++
+			if counts[segment] >= 3 {
+				return fmt.Sprintf("path repeats /%s/", segment)
+			}
+		}
+	}
+	return ""
+}
+
+// urlShape returns u with its query values dropped and its numeric path
+// segments replaced by *, so /events/2024/05?page=2 and
+// /events/2023/11?page=7 share a shape. URLs with neither a query nor a
+// numeric segment have no shape: they have no variants.
+func urlShape(u *url.URL) string {
+	segments := strings.Split(u.Path, "/")
+	variable := u.RawQuery != ""
+	for i, segment := range segments {
+		if numericSegment(segment) {
+			segments[i] = "*"
+			variable = true
+		}
+	}
+	if !variable {
+		return ""
+	}
+	shape := u.Host + strings.Join(segments, "/")
+	if names := sortedKeys(u.Query()); len(names) > 0 {
+		shape += "?" + strings.Join(names, "=*&") + "=*"
+	}
+	return shape
+}
+
+// numericSegment reports whether a path segment is a number or a date,
+// such as 2024, 05, or 2024-05-01.
+func numericSegment(segment string) bool {
+	if segment == "" || strings.Trim(segment, "-") == "" {
+		return false
+	}
+	return strings.Trim(segment, "0123456789-") == ""
+}
+
+// checkResponse returns why a fetched page is a trap: its shape was
+// found to be one, or its content is at least max_similarity percent
+// identical to a variant fetched before it. Such a shape becomes a trap,
+// so its other links are no longer followed. The first variant is kept.
+func (t *trapDetector) checkResponse(requested *url.URL, r *colly.Response, doc *goquery.Document, opts *CrawlOptions) string {
+	link := requested.String()
+	if t.maxSimilarity < 0 || t.allowed(link) {
+		return ""
+	}
+	shape := urlShape(requested)
+	if shape == "" {
+		return ""
+	}
+	t.mu.Lock()
+	reason, ok := t.trapped[shape]
+	t.mu.Unlock()
+	if ok {
+		return reason
+	}
+
+	ext := opts.extractionFor(link)
+	var content string
+	var err error
+	switch {
+	case doc != nil:
+		content, err = documentContent(goquery.CloneDocument(doc), ext)
+	case strings.Contains(strings.ToLower(r.Headers.Get("Content-Type")), "text/html"):
+		content, err = extractContent(r.Body, ext)
+	default:
+		return ""
+	}
+	if err != nil {
+		return ""
+	}
+	text, err := goquery.NewDocumentFromReader(bytes.NewReader([]byte(content)))
+	if err != nil {
+		return ""
+	}
+	set := map[uint64]bool{}
+	for _, s := range shingles(text.Text()) {
+		set[s] = true
+	}
+	if len(set) == 0 {
+		return ""
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pages := t.texts[shape]
+	if pages == nil {
+		pages = map[string]map[uint64]bool{}
+		t.texts[shape] = pages
+	}
+	earlier := make([]string, 0, len(pages))
+	for other := range pages {
+		earlier = append(earlier, other)
+	}
+	sort.Strings(earlier)
+	for _, other := range earlier {
+		if other == link {
+			continue
+		}
+		if similarity := shingleSimilarity(set, pages[other]); similarity >= t.maxSimilarity {
+			reason := fmt.Sprintf("same results as %s (%.0f%% identical)", other, similarity)
+			t.trapped[shape] = reason
+			return reason
+		}
+	}
+	pages[link] = set
+	return ""
+}
+
+// exclude reports a trap link once and removes any output an earlier
+// crawl wrote for it.
+func (t *trapDetector) exclude(opts *CrawlOptions, requested, final *url.URL, reason string) {
+	t.mu.Lock()
+	seen := t.reported[requested.String()]
+	t.reported[requested.String()] = true
+	t.mu.Unlock()
+	if seen {
+		return
+	}
+	fmt.Printf("Excluding %s: %s\n", requested, reason)
+	excludePage(opts, pageTrap, requested, final, reason)
+}
@@ -0,0 +1,508 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Severities of validation findings. Rules set to off do not run.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+	severityOff     = "off"
+)
+
+// defaultRuleset is the ruleset validate applies unless told otherwise.
+const defaultRuleset = "anthropic"
+
+// validateRuleset names the ruleset to apply.
+// validateJSON prints the findings as JSON.
+// validateListRules lists the ruleset's rules instead of validating.
+var (
+	validateRuleset   string
+	validateJSON      bool
+	validateListRules bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [skill-dir...]",
+	Short: "Check skills against a ruleset for the agent platform they target",
+	Long: `Checks each skill directory (default: every directory in the output
+directory) against a validation ruleset and lists what it finds. Every
+SKILL.md below a directory is checked as a skill of its own.
+
+The built-in rulesets are agentskills, the open Agent Skills format, and
+anthropic (the default), which adds the limits of Anthropic's platforms.
+Rulesets defined under validate.rulesets in skills.yaml can extend either
+and change the severity or limit of any rule, for house rules. The exit
+status is non-zero when a rule at severity error fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error reading config: %v\n", err)
+			os.Exit(1)
+		}
+		name := validateRuleset
+		if name == "" {
+			name = cfg.Validate.Ruleset
+		}
+		if name == "" {
+			name = defaultRuleset
+		}
+		ruleset, err := resolveRuleset(name, cfg.Validate.Rulesets)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if validateListRules {
+			printRuleset(name, ruleset)
+			return
+		}
+
+		dirs := args
+		if len(dirs) == 0 {
+			output := configuredOutput()
+			entries, err := os.ReadDir(output)
+			if err != nil {
+				fmt.Printf("Error reading output directory: %v\n", err)
+				os.Exit(1)
+			}
+			for _, e := range entries {
+				if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+					dirs = append(dirs, filepath.Join(output, e.Name()))
+				}
+			}
+		}
+		var skills []*skillDoc
+		for _, dir := range dirs {
+			found, err := findSkills(dir)
+			if err != nil {
+				fmt.Printf("Error reading %s: %v\n", dir, err)
+				os.Exit(1)
+			}
+			skills = append(skills, found...)
+		}
+
+		findings := validateSkills(skills, ruleset)
+		if validateJSON {
+			data, _ := json.MarshalIndent(validateReport{Ruleset: name, Skills: len(skills), Findings: findings}, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printFindings(name, len(skills), findings)
+		}
+		for _, f := range findings {
+			if f.Severity == severityError {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVar(&validateRuleset, "ruleset", "", "ruleset to apply: agentskills, anthropic, or one from validate.rulesets (default validate.ruleset, or anthropic)")
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "print the findings as JSON")
+	validateCmd.Flags().BoolVar(&validateListRules, "list-rules", false, "list the rules of the ruleset with their severities and exit")
+}
+
+// skillDoc is a skill being validated. A skill directory without a
+// SKILL.md has only dir set.
+type skillDoc struct {
+	dir    string
+	path   string
+	lines  []string
+	fields map[string]interface{}
+	body   string
+	// bodyLine is the line the body starts on.
+	bodyLine int
+	// err is set when the frontmatter is missing or does not parse;
+	// only the skill-file and frontmatter rules run then.
+	err error
+}
+
+// Finding is a rule a skill failed.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+// validateReport is what validate --json prints.
+type validateReport struct {
+	Ruleset  string    `json:"ruleset"`
+	Skills   int       `json:"skills"`
+	Findings []Finding `json:"findings"`
+}
+
+// validationRule is a check rulesets can enable. Limit is the default for
+// rules that compare against one.
+type validationRule struct {
+	id          string
+	description string
+	limit       int
+	check       func(s *skillDoc, setting RuleSetting) []Finding
+}
+
+// skillNameRe matches a valid skill name: lowercase letters and digits in
+// hyphen-separated words.
+var skillNameRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// xmlTagRe matches an XML or HTML tag.
+var xmlTagRe = regexp.MustCompile(`</?[A-Za-z][\w:.-]*(\s[^<>]*)?/?>`)
+
+// agentSkillsFields are the frontmatter fields the Agent Skills format
+// defines; anything else belongs under metadata.
+var agentSkillsFields = []string{"name", "description", "license", "compatibility", "allowed-tools", "metadata"}
+
+// reservedSkillWords may not appear in skill names on Anthropic's
+// platforms.
+var reservedSkillWords = []string{"anthropic", "claude"}
+
+// validationRules are the checks rulesets choose from, in reporting order.
+var validationRules = []validationRule{
+	{id: "skill-file", description: "Each skill directory has a SKILL.md", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		if s.path != "" {
+			return nil
+		}
+		return []Finding{{Path: s.dir, Message: "no " + skillFileName}}
+	}},
+	{id: "frontmatter", description: "SKILL.md starts with YAML frontmatter that parses", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		if s.path == "" || s.err == nil {
+			return nil
+		}
+		return []Finding{{Path: s.path, Line: 1, Message: s.err.Error()}}
+	}},
+	{id: "name", description: "name is set to lowercase letters, digits, and single hyphens between them", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		name, _ := s.fields["name"].(string)
+		switch {
+		case name == "":
+			return s.finding("name", "no name")
+		case !skillNameRe.MatchString(name):
+			return s.finding("name", fmt.Sprintf("name %q may only use lowercase letters, digits, and single hyphens between them", name))
+		}
+		return nil
+	}},
+	{id: "name-length", description: "name is at most limit characters", limit: 64, check: func(s *skillDoc, r RuleSetting) []Finding {
+		if name, _ := s.fields["name"].(string); len([]rune(name)) > r.Limit {
+			return s.finding("name", fmt.Sprintf("name is %d characters, over %d", len([]rune(name)), r.Limit))
+		}
+		return nil
+	}},
+	{id: "name-matches-dir", description: "name is the name of the skill directory", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		if name, _ := s.fields["name"].(string); name != "" && name != filepath.Base(s.dir) {
+			return s.finding("name", fmt.Sprintf("name %q differs from the directory %q", name, filepath.Base(s.dir)))
+		}
+		return nil
+	}},
+	{id: "name-reserved", description: "name contains no reserved word (anthropic, claude)", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		name, _ := s.fields["name"].(string)
+		for _, word := range reservedSkillWords {
+			if strings.Contains(strings.ToLower(name), word) {
+				return s.finding("name", fmt.Sprintf("name contains the reserved word %q", word))
+			}
+		}
+		return nil
+	}},
+	{id: "description", description: "description is set", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		if description, _ := s.fields["description"].(string); strings.TrimSpace(description) == "" {
+			return s.finding("description", "no description")
+		}
+		return nil
+	}},
+	{id: "description-length", description: "description is at most limit characters", limit: 1024, check: func(s *skillDoc, r RuleSetting) []Finding {
+		if description, _ := s.fields["description"].(string); len([]rune(description)) > r.Limit {
+			return s.finding("description", fmt.Sprintf("description is %d characters, over %d", len([]rune(description)), r.Limit))
+		}
+		return nil
+	}},
+	{id: "no-xml-tags", description: "name and description contain no XML tags", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		var findings []Finding
+		for _, key := range []string{"name", "description"} {
+			if value, _ := s.fields[key].(string); xmlTagRe.MatchString(value) {
+				findings = append(findings, s.finding(key, fmt.Sprintf("%s contains the tag %s", key, xmlTagRe.FindString(value)))...)
+			}
+		}
+		return findings
+	}},
+	{id: "compatibility-length", description: "compatibility is at most limit characters", limit: 500, check: func(s *skillDoc, r RuleSetting) []Finding {
+		if compatibility, _ := s.fields["compatibility"].(string); len([]rune(compatibility)) > r.Limit {
+			return s.finding("compatibility", fmt.Sprintf("compatibility is %d characters, over %d", len([]rune(compatibility)), r.Limit))
+		}
+		return nil
+	}},
+	{id: "allowed-fields", description: "The frontmatter has only the fields of the Agent Skills format", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		var findings []Finding
+		for _, key := range sortedKeys(s.fields) {
+			if !slices.Contains(agentSkillsFields, key) {
+				findings = append(findings, s.finding(key, fmt.Sprintf("field %q is not part of the format; move it under metadata", key))...)
+			}
+		}
+		return findings
+	}},
+	{id: "required-fields", description: "The frontmatter sets every field in fields", check: func(s *skillDoc, r RuleSetting) []Finding {
+		var findings []Finding
+		for _, key := range r.Fields {
+			if value, ok := s.fields[key]; !ok || strings.TrimSpace(fmt.Sprint(value)) == "" {
+				findings = append(findings, Finding{Path: s.path, Line: 1, Message: fmt.Sprintf("no %s", key)})
+			}
+		}
+		return findings
+	}},
+	{id: "body-lines", description: "The body of SKILL.md is at most limit lines", limit: 500, check: func(s *skillDoc, r RuleSetting) []Finding {
+		if n := len(strings.Split(strings.TrimRight(s.body, "\n"), "\n")); n > r.Limit {
+			return []Finding{{Path: s.path, Line: s.bodyLine + r.Limit, Message: fmt.Sprintf("body is %d lines, over %d; move detail into referenced files", n, r.Limit)}}
+		}
+		return nil
+	}},
+	{id: "body-tokens", description: "The body of SKILL.md is at most limit estimated tokens", limit: 5000, check: func(s *skillDoc, r RuleSetting) []Finding {
+		if n := estimateTokens(s.body); n > r.Limit {
+			return []Finding{{Path: s.path, Message: fmt.Sprintf("body is about %d tokens, over %d", n, r.Limit)}}
+		}
+		return nil
+	}},
+	{id: "links", description: "Relative links in SKILL.md point to files that exist", check: func(s *skillDoc, _ RuleSetting) []Finding {
+		var findings []Finding
+		for _, link := range relativeLinks(filepath.ToSlash(s.path), s.body) {
+			if _, err := statOutputFile(filepath.FromSlash(link.path)); err != nil {
+				findings = append(findings, Finding{Path: s.path, Line: s.bodyLine + link.line - 1, Message: fmt.Sprintf("broken link to %s", link.ref)})
+			}
+		}
+		return findings
+	}},
+}
+
+// builtinRulesets are the rulesets available without configuration, by
+// rule ID. anthropic extends agentskills.
+var builtinRulesets = map[string]map[string]RuleSetting{
+	"agentskills": {
+		"skill-file":           {Severity: severityError},
+		"frontmatter":          {Severity: severityError},
+		"name":                 {Severity: severityError},
+		"name-length":          {Severity: severityError},
+		"name-matches-dir":     {Severity: severityError},
+		"description":          {Severity: severityError},
+		"description-length":   {Severity: severityError},
+		"compatibility-length": {Severity: severityError},
+		"allowed-fields":       {Severity: severityWarning},
+	},
+	"anthropic": {
+		"name-reserved": {Severity: severityError},
+		"no-xml-tags":   {Severity: severityError},
+		"body-lines":    {Severity: severityWarning},
+		"body-tokens":   {Severity: severityInfo},
+		"links":         {Severity: severityWarning},
+	},
+}
+
+// builtinExtends names the ruleset each built-in ruleset builds on.
+var builtinExtends = map[string]string{"anthropic": "agentskills"}
+
+// resolveRuleset returns the settings of every rule the named ruleset
+// enables, following extends. Custom rulesets may shadow built-in ones.
+func resolveRuleset(name string, custom map[string]RulesetConfig) (map[string]RuleSetting, error) {
+	return resolveRulesetChain(strings.ToLower(name), custom, nil)
+}
+
+func resolveRulesetChain(name string, custom map[string]RulesetConfig, seen []string) (map[string]RuleSetting, error) {
+	if slices.Contains(seen, name) {
+		return nil, fmt.Errorf("ruleset %s extends itself through %s", name, strings.Join(seen, " -> "))
+	}
+	seen = append(seen, name)
+
+	var parent string
+	var rules map[string]RuleSetting
+	if rs, ok := custom[name]; ok {
+		parent, rules = strings.ToLower(rs.Extends), rs.Rules
+	} else if rs, ok := builtinRulesets[name]; ok {
+		parent, rules = builtinExtends[name], rs
+	} else {
+		return nil, fmt.Errorf("unknown ruleset %q", name)
+	}
+
+	resolved := map[string]RuleSetting{}
+	if parent != "" {
+		var err error
+		if resolved, err = resolveRulesetChain(parent, custom, seen); err != nil {
+			return nil, err
+		}
+	}
+	for id, setting := range rules {
+		rule, ok := findValidationRule(id)
+		if !ok {
+			return nil, fmt.Errorf("ruleset %s: unknown rule %q", name, id)
+		}
+		inherited := resolved[id]
+		if setting.Severity == "" {
+			setting.Severity = inherited.Severity
+		}
+		switch setting.Severity {
+		case "":
+			setting.Severity = severityError
+		case severityError, severityWarning, severityInfo, severityOff:
+		default:
+			return nil, fmt.Errorf("ruleset %s: rule %s has unknown severity %q", name, id, setting.Severity)
+		}
+		if setting.Limit <= 0 {
+			setting.Limit = max(inherited.Limit, rule.limit)
+		}
+		if setting.Fields == nil {
+			setting.Fields = inherited.Fields
+		}
+		resolved[id] = setting
+	}
+	return resolved, nil
+}
+
+// findValidationRule returns the rule with the given ID.
+func findValidationRule(id string) (validationRule, bool) {
+	for _, rule := range validationRules {
+		if rule.id == id {
+			return rule, true
+		}
+	}
+	return validationRule{}, false
+}
+
+// findSkills returns the skills below dir: every directory with a
+// SKILL.md, or dir itself, without one, when there are none.
+func findSkills(dir string) ([]*skillDoc, error) {
+	var skills []*skillDoc
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		p = pagePath(p)
+		if filepath.Base(p) != skillFileName {
+			return nil
+		}
+		data, err := readOutputFile(p)
+		if err != nil {
+			return err
+		}
+		skills = append(skills, parseSkillDoc(filepath.Dir(p), p, string(data)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(skills) == 0 {
+		skills = append(skills, &skillDoc{dir: dir})
+	}
+	return skills, nil
+}
+
+// parseSkillDoc splits a SKILL.md into its frontmatter fields and body.
+func parseSkillDoc(dir, path, content string) *skillDoc {
+	s := &skillDoc{dir: dir, path: path, lines: strings.Split(content, "\n"), bodyLine: 1}
+	fm, _ := splitFrontmatter(content)
+	if fm == "" {
+		s.err = fmt.Errorf("no frontmatter")
+		s.body = content
+		return s
+	}
+	meta, body, err := parsePage(content)
+	if err != nil {
+		s.err = fmt.Errorf("frontmatter does not parse: %v", err)
+		return s
+	}
+	s.fields, s.body = meta.Fields, body
+	s.bodyLine = strings.Count(fm, "\n") + 1
+	return s
+}
+
+// finding reports a problem with a frontmatter field, at the field's line.
+func (s *skillDoc) finding(key, message string) []Finding {
+	line := 1
+	for i, l := range s.lines {
+		if strings.HasPrefix(l, key+":") {
+			line = i + 1
+			break
+		}
+	}
+	return []Finding{{Path: s.path, Line: line, Message: message}}
+}
+
+// validateSkills runs the enabled rules of ruleset against each skill.
+func validateSkills(skills []*skillDoc, ruleset map[string]RuleSetting) []Finding {
+	findings := []Finding{}
+	for _, s := range skills {
+		for _, rule := range validationRules {
+			setting, ok := ruleset[rule.id]
+			if !ok || setting.Severity == severityOff {
+				continue
+			}
+			if s.fields == nil && rule.id != "skill-file" && rule.id != "frontmatter" {
+				continue
+			}
+			for _, f := range rule.check(s, setting) {
+				f.Rule, f.Severity = rule.id, setting.Severity
+				f.Path = filepath.ToSlash(f.Path)
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// printFindings lists findings like compiler messages, then a summary.
+func printFindings(ruleset string, skills int, findings []Finding) {
+	counts := map[string]int{}
+	for _, f := range findings {
+		if f.Line > 0 {
+			fmt.Printf("%s:%d: %s %s: %s\n", f.Path, f.Line, f.Severity, f.Rule, f.Message)
+		} else {
+			fmt.Printf("%s: %s %s: %s\n", f.Path, f.Severity, f.Rule, f.Message)
+		}
+		counts[f.Severity]++
+	}
+	fmt.Printf("Validated %d skills with the %s ruleset: %d errors, %d warnings, %d notes\n",
+		skills, ruleset, counts[severityError], counts[severityWarning], counts[severityInfo])
+}
+
+// printRuleset lists the rules a ruleset enables, in reporting order.
+func printRuleset(name string, ruleset map[string]RuleSetting) {
+	fmt.Printf("Ruleset %s:\n", name)
+	for _, rule := range validationRules {
+		setting, ok := ruleset[rule.id]
+		if !ok {
+			continue
+		}
+		description := strings.Replace(rule.description, "limit", fmt.Sprint(setting.Limit), 1)
+		if rule.id == "required-fields" {
+			description += ": " + strings.Join(setting.Fields, ", ")
+		}
+		fmt.Printf("  %-22s %-8s %s\n", rule.id, setting.Severity, description)
+	}
+}
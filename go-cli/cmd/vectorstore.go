@@ -0,0 +1,346 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Defaults for the vector_store config section.
+const (
+	defaultOpenAIEndpoint = "https://api.openai.com/v1"
+	vectorStateFileName   = ".vector-store.json"
+	// vectorBatchSize is the most files added to the store in one batch.
+	vectorBatchSize = 500
+	// vectorBatchPoll is how often a file batch is checked, and
+	// vectorBatchTimeout how long one may take to be processed.
+	vectorBatchPoll    = 2 * time.Second
+	vectorBatchTimeout = 30 * time.Minute
+)
+
+// vectorDryRun lists what a sync would upload and remove.
+var vectorDryRun bool
+
+var vectorStoreCmd = &cobra.Command{
+	Use:   "vector-store",
+	Short: "Sync the output to an OpenAI vector store",
+}
+
+var vectorStoreSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Upload new and changed pages to the vector store and remove deleted ones",
+	Long: `Uploads the pages and generated markdown files in the output directory
+to the OpenAI vector store vector_store.id, in file batches. Only files
+whose manifest hash changed since the last sync are uploaded; their old
+copies, and the copies of files no longer in the output, are removed from
+the store and deleted. What was uploaded is recorded in
+<output>/.vector-store.json.
+
+With vector_store.enabled set, every crawl syncs when it finishes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		opts := newCrawlOptions(&cfg)
+		m, err := loadManifest(opts.Output)
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+		if err := syncVectorStore(opts, m, cfg.VectorStore, vectorDryRun); err != nil {
+			fmt.Printf("Error syncing vector store: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vectorStoreCmd)
+	vectorStoreCmd.AddCommand(vectorStoreSyncCmd)
+	vectorStoreSyncCmd.Flags().BoolVar(&vectorDryRun, "dry-run", false, "list what would be uploaded and removed without changing the store")
+}
+
+// vectorState records the files uploaded to a vector store, by path
+// relative to the output directory.
+type vectorState struct {
+	VectorStoreID string                     `json:"vector_store_id"`
+	Files         map[string]vectorStateFile `json:"files"`
+}
+
+type vectorStateFile struct {
+	FileID string `json:"file_id"`
+	Hash   string `json:"hash"`
+}
+
+// loadVectorState reads the sync state for the store id from outDir. The
+// state of another store, or none, yields an empty one.
+func loadVectorState(outDir, id string) (*vectorState, error) {
+	state := &vectorState{VectorStoreID: id, Files: map[string]vectorStateFile{}}
+	data, err := os.ReadFile(filepath.Join(outDir, vectorStateFileName))
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var saved vectorState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	if saved.VectorStoreID != id {
+		fmt.Printf("Warning: %s recorded vector store %s; syncing %s from scratch\n", vectorStateFileName, saved.VectorStoreID, id)
+		return state, nil
+	}
+	if saved.Files != nil {
+		state.Files = saved.Files
+	}
+	return state, nil
+}
+
+func (s *vectorState) save(outDir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, vectorStateFileName), append(data, '\n'), 0644)
+}
+
+// vectorFiles returns the markdown files in the output with their
+// manifest hash: every page with a file of its own and every generated
+// markdown file, such as SKILL.md entry points.
+func vectorFiles(opts *CrawlOptions, m *Manifest) map[string]string {
+	files := manifestFiles(opts.Output, m)
+	for path := range files {
+		if !strings.HasSuffix(path, ".md") {
+			delete(files, path)
+		}
+	}
+	return files
+}
+
+// openAIClient calls the OpenAI files and vector store APIs.
+type openAIClient struct {
+	api      *apiClient
+	endpoint string
+}
+
+func newOpenAIClient(cfg VectorStoreConfig) (*openAIClient, error) {
+	keyEnv := cfg.APIKeyEnv
+	if keyEnv == "" {
+		keyEnv = defaultLLMAPIKeyEnv
+	}
+	key := os.Getenv(keyEnv)
+	if key == "" {
+		return nil, fmt.Errorf("%s is not set", keyEnv)
+	}
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = defaultOpenAIEndpoint
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+key)
+	return &openAIClient{api: newAPIClient(header), endpoint: endpoint}, nil
+}
+
+// uploadFile uploads content as a file for the vector store and returns
+// its ID.
+func (c *openAIClient) uploadFile(name string, content []byte) (string, error) {
+	var form bytes.Buffer
+	w := multipart.NewWriter(&form)
+	w.WriteField("purpose", "assistants")
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		return "", err
+	}
+	part.Write(content)
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := c.api.postForm(c.endpoint+"/files", form.Bytes(), w.FormDataContentType(), &file); err != nil {
+		return "", err
+	}
+	return file.ID, nil
+}
+
+// fileBatch is the status of a vector store file batch.
+type fileBatch struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	FileCounts struct {
+		Completed int `json:"completed"`
+		Failed    int `json:"failed"`
+	} `json:"file_counts"`
+}
+
+// addFiles adds uploaded files to the store in one batch and waits until
+// the store has processed them.
+func (c *openAIClient) addFiles(store string, fileIDs []string) (*fileBatch, error) {
+	var batch fileBatch
+	link := c.endpoint + "/vector_stores/" + store + "/file_batches"
+	if err := c.api.postJSON(link, map[string]interface{}{"file_ids": fileIDs}, &batch); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(vectorBatchTimeout)
+	for batch.Status == "in_progress" {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("file batch %s still processing after %s", batch.ID, vectorBatchTimeout)
+		}
+		time.Sleep(vectorBatchPoll)
+		if err := c.api.getJSON(link+"/"+batch.ID, &batch); err != nil {
+			return nil, err
+		}
+	}
+	if batch.Status != "completed" {
+		return &batch, fmt.Errorf("file batch %s %s", batch.ID, batch.Status)
+	}
+	return &batch, nil
+}
+
+// removeFile removes a file from the store and deletes it.
+func (c *openAIClient) removeFile(store, fileID string) error {
+	var deleted struct{}
+	if err := c.api.deleteJSON(c.endpoint+"/vector_stores/"+store+"/files/"+fileID, &deleted); err != nil && !isNotFound(err) {
+		return err
+	}
+	if err := c.api.deleteJSON(c.endpoint+"/files/"+fileID, &deleted); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// syncVectorStore uploads the files whose hash changed since the last
+// sync to the vector store and removes the replaced and deleted ones.
+// With dryRun it only lists what it would do.
+func syncVectorStore(opts *CrawlOptions, m *Manifest, cfg VectorStoreConfig, dryRun bool) error {
+	if cfg.ID == "" {
+		return fmt.Errorf("vector_store.id is not set")
+	}
+	state, err := loadVectorState(opts.Output, cfg.ID)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", vectorStateFileName, err)
+	}
+	files := vectorFiles(opts, m)
+	var upload, remove []string
+	for _, path := range sortedKeys(files) {
+		if state.Files[path].Hash != files[path] {
+			upload = append(upload, path)
+		}
+	}
+	for _, path := range sortedKeys(state.Files) {
+		if _, ok := files[path]; !ok {
+			remove = append(remove, path)
+		}
+	}
+	fmt.Printf("Vector store %s: %d files to upload, %d to remove, %d unchanged\n", cfg.ID, len(upload), len(remove), len(files)-len(upload))
+	if dryRun {
+		for _, path := range upload {
+			fmt.Printf("Upload %s\n", path)
+		}
+		for _, path := range remove {
+			fmt.Printf("Remove %s\n", path)
+		}
+		return nil
+	}
+	if len(upload) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	client, err := newOpenAIClient(cfg)
+	if err != nil {
+		return err
+	}
+	// The state is saved whatever happens, so the next sync retries only
+	// what did not go through.
+	defer func() {
+		if err := state.save(opts.Output); err != nil {
+			fmt.Printf("Error writing %s: %v\n", vectorStateFileName, err)
+		}
+	}()
+
+	uploaded := map[string]string{}
+	for _, path := range upload {
+		data, err := readOutputFile(filepath.Join(opts.Output, filepath.FromSlash(path)))
+		if err != nil {
+			fmt.Printf("Warning: could not read %s: %v\n", path, err)
+			continue
+		}
+		id, err := client.uploadFile(strings.ReplaceAll(path, "/", "_"), data)
+		if err != nil {
+			fmt.Printf("Warning: could not upload %s: %v\n", path, err)
+			continue
+		}
+		uploaded[path] = id
+	}
+
+	paths := sortedKeys(uploaded)
+	var failed []error
+	for start := 0; start < len(paths); start += vectorBatchSize {
+		batch := paths[start:min(start+vectorBatchSize, len(paths))]
+		ids := make([]string, len(batch))
+		for i, path := range batch {
+			ids[i] = uploaded[path]
+		}
+		result, err := client.addFiles(cfg.ID, ids)
+		if err != nil && result == nil {
+			// Nothing is known of the batch; drop its uploads.
+			failed = append(failed, err)
+			for _, id := range ids {
+				client.removeFile(cfg.ID, id)
+			}
+			continue
+		}
+		if err != nil {
+			failed = append(failed, fmt.Errorf("%w (%d files failed)", err, result.FileCounts.Failed))
+		}
+		for _, path := range batch {
+			if old, ok := state.Files[path]; ok {
+				if err := client.removeFile(cfg.ID, old.FileID); err != nil {
+					fmt.Printf("Warning: could not remove the old copy of %s: %v\n", path, err)
+				}
+			}
+			state.Files[path] = vectorStateFile{FileID: uploaded[path], Hash: files[path]}
+		}
+	}
+
+	removed := 0
+	for _, path := range remove {
+		if err := client.removeFile(cfg.ID, state.Files[path].FileID); err != nil {
+			fmt.Printf("Warning: could not remove %s: %v\n", path, err)
+			continue
+		}
+		delete(state.Files, path)
+		removed++
+	}
+	fmt.Printf("Uploaded %d files and removed %d from vector store %s\n", len(paths), removed, cfg.ID)
+	if len(failed) > 0 {
+		return failed[0]
+	}
+	return nil
+}
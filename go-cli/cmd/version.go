@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// toolName is the name recorded in frontmatter provenance and manifests.
+const toolName = "agent-skills-generator"
+
+// configSchemaVersion is bumped whenever skills.yaml changes incompatibly.
+const configSchemaVersion = 1
+
+// Build metadata, set at build time with:
+//
+//	go build -ldflags "-X github.com/rodydavis/agent-skills-generator/cmd.version=1.2.3 \
+//	  -X github.com/rodydavis/agent-skills-generator/cmd.commit=$(git rev-parse HEAD) \
+//	  -X github.com/rodydavis/agent-skills-generator/cmd.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// versionJSON prints the version information as JSON.
+var versionJSON bool
+
+// BuildInfo describes the build that produced the binary.
+type BuildInfo struct {
+	Version             string `json:"version"`
+	Commit              string `json:"commit,omitempty"`
+	BuildDate           string `json:"build_date,omitempty"`
+	ConfigSchemaVersion int    `json:"config_schema_version"`
+	GoVersion           string `json:"go_version,omitempty"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Run: func(cmd *cobra.Command, args []string) {
+		info := buildInfo()
+		if versionJSON {
+			data, _ := json.MarshalIndent(info, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+		fmt.Printf("%s %s\n", toolName, info.Version)
+		if info.Commit != "" {
+			fmt.Printf("  commit:         %s\n", info.Commit)
+		}
+		if info.BuildDate != "" {
+			fmt.Printf("  built:          %s\n", info.BuildDate)
+		}
+		fmt.Printf("  config schema:  v%d\n", info.ConfigSchemaVersion)
+		fmt.Printf("  go:             %s %s/%s\n", info.GoVersion, runtime.GOOS, runtime.GOARCH)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "print as JSON")
+}
+
+// buildInfo returns the build metadata, falling back to the VCS details
+// embedded by the Go toolchain when ldflags were not set.
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:             version,
+		Commit:              commit,
+		BuildDate:           buildDate,
+		ConfigSchemaVersion: configSchemaVersion,
+		GoVersion:           runtime.Version(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			}
+		}
+	}
+	return info
+}
+
+// userAgent returns the User-Agent sent with every request.
+func userAgent() string {
+	info := buildInfo()
+	comment := "+https://github.com/rodydavis/agent-skills-generator"
+	if len(info.Commit) >= 7 {
+		comment += "; commit " + info.Commit[:7]
+	}
+	return fmt.Sprintf("%s/%s (%s)", toolName, info.Version, comment)
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/redis/go-redis/v9"
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultVisitFile is the disk storage location inside the output directory.
+const defaultVisitFile = ".crawl-visited.db"
+
+// visitBackend persists colly's visited set, with the time of each
+// visit, and its cookies.
+type visitBackend interface {
+	visitedAt(id uint64) (time.Time, bool, error)
+	markVisited(id uint64, at time.Time) error
+	forget(id uint64) error
+	Cookies(u *url.URL) string
+	SetCookies(u *url.URL, cookies string)
+	Close() error
+}
+
+// visitStore is the colly storage used when storage.backend is set. Unlike
+// the crawl store, which is discarded when a crawl completes, it keeps the
+// visited set and cookies between runs: pages visited by an earlier run
+// are skipped until revisitAfter has passed (never, when it is zero).
+type visitStore struct {
+	backend      visitBackend
+	revisitAfter time.Duration
+
+	mu        sync.Mutex
+	capturing bool
+	captured  uint64
+}
+
+// openVisitStore opens the storage selected by cfg.Storage, or returns nil
+// when the visited set stays with the crawl store.
+func openVisitStore(cfg *Config) (*visitStore, error) {
+	var revisitAfter time.Duration
+	if cfg.Storage.RevisitAfter != "" {
+		d, err := time.ParseDuration(cfg.Storage.RevisitAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid storage.revisit_after: %w", err)
+		}
+		revisitAfter = d
+	}
+
+	var backend visitBackend
+	switch cfg.Storage.Backend {
+	case "", "memory":
+		return nil, nil
+	case "disk":
+		b, err := openBoltVisits(cfg.statePath(cfg.Storage.Path, defaultVisitFile))
+		if err != nil {
+			return nil, err
+		}
+		backend = b
+	case "redis":
+		client, prefix, err := connectRedis(cfg.redisConfig())
+		if err != nil {
+			return nil, err
+		}
+		backend = &redisVisits{client: client, prefix: prefix + ":storage", ctx: context.Background()}
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+	return &visitStore{backend: backend, revisitAfter: revisitAfter}, nil
+}
+
+func (s *visitStore) Init() error {
+	return nil
+}
+
+func (s *visitStore) Visited(requestID uint64) error {
+	return s.backend.markVisited(requestID, time.Now())
+}
+
+func (s *visitStore) IsVisited(requestID uint64) (bool, error) {
+	s.mu.Lock()
+	if s.capturing {
+		s.captured = requestID
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.mu.Unlock()
+
+	at, ok, err := s.backend.visitedAt(requestID)
+	if err != nil || !ok {
+		return false, err
+	}
+	return s.revisitAfter == 0 || time.Since(at) < s.revisitAfter, nil
+}
+
+func (s *visitStore) Cookies(u *url.URL) string {
+	return s.backend.Cookies(u)
+}
+
+func (s *visitStore) SetCookies(u *url.URL, cookies string) {
+	s.backend.SetCookies(u, cookies)
+}
+
+func (s *visitStore) Close() error {
+	return s.backend.Close()
+}
+
+// forgetURL removes link from the visited set, so seeds are fetched on
+// every run and new pages linked from them are found. colly does not
+// export its request hash, but HasVisited passes it to IsVisited, where it
+// is captured.
+func (s *visitStore) forgetURL(c *colly.Collector, link string) error {
+	s.mu.Lock()
+	s.capturing = true
+	s.mu.Unlock()
+	_, err := c.HasVisited(link)
+	s.mu.Lock()
+	s.capturing = false
+	id := s.captured
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.backend.forget(id)
+}
+
+// boltVisits keeps the visited set and cookies in a bbolt database.
+type boltVisits struct {
+	db *bolt.DB
+}
+
+func openBoltVisits(path string) (*boltVisits, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltVisited, boltCookies} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltVisits{db: db}, nil
+}
+
+func (b *boltVisits) visitedAt(id uint64) (time.Time, bool, error) {
+	var at time.Time
+	var ok bool
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(boltVisited).Get(u64Key(id)); len(v) == 8 {
+			at, ok = time.Unix(int64(binary.BigEndian.Uint64(v)), 0), true
+		}
+		return nil
+	})
+	return at, ok, err
+}
+
+func (b *boltVisits) markVisited(id uint64, at time.Time) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltVisited).Put(u64Key(id), u64Key(uint64(at.Unix())))
+	})
+}
+
+func (b *boltVisits) forget(id uint64) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltVisited).Delete(u64Key(id))
+	})
+}
+
+func (b *boltVisits) Cookies(u *url.URL) string {
+	var cookies string
+	b.db.View(func(tx *bolt.Tx) error {
+		cookies = string(tx.Bucket(boltCookies).Get([]byte(u.Host)))
+		return nil
+	})
+	return cookies
+}
+
+func (b *boltVisits) SetCookies(u *url.URL, cookies string) {
+	b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCookies).Put([]byte(u.Host), []byte(cookies))
+	})
+}
+
+func (b *boltVisits) Close() error {
+	return b.db.Close()
+}
+
+// redisVisits keeps the visited set and cookies in Redis hashes, shared by
+// every worker and run using the same prefix.
+type redisVisits struct {
+	client *redis.Client
+	prefix string
+	ctx    context.Context
+}
+
+func (r *redisVisits) key(name string) string {
+	return r.prefix + ":" + name
+}
+
+func (r *redisVisits) visitedAt(id uint64) (time.Time, bool, error) {
+	v, err := r.client.HGet(r.ctx, r.key("visited"), strconv.FormatUint(id, 10)).Int64()
+	if err == redis.Nil {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return time.Unix(v, 0), true, nil
+}
+
+func (r *redisVisits) markVisited(id uint64, at time.Time) error {
+	return r.client.HSet(r.ctx, r.key("visited"), strconv.FormatUint(id, 10), at.Unix()).Err()
+}
+
+func (r *redisVisits) forget(id uint64) error {
+	return r.client.HDel(r.ctx, r.key("visited"), strconv.FormatUint(id, 10)).Err()
+}
+
+func (r *redisVisits) Cookies(u *url.URL) string {
+	cookies, _ := r.client.HGet(r.ctx, r.key("cookies"), u.Host).Result()
+	return cookies
+}
+
+func (r *redisVisits) SetCookies(u *url.URL, cookies string) {
+	r.client.HSet(r.ctx, r.key("cookies"), u.Host, cookies)
+}
+
+func (r *redisVisits) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,438 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// warcVersion is the WARC format version written by warcWriter.
+const warcVersion = "WARC/1.0"
+
+// warcRecord is a parsed WARC record header with its content block.
+type warcRecord struct {
+	Header textproto.MIMEHeader
+	Block  []byte
+}
+
+// readWARCRecord reads the next record from r. It returns io.EOF when
+// there are no more records.
+func readWARCRecord(r *bufio.Reader) (*warcRecord, error) {
+	var line string
+	for line == "" {
+		l, err := r.ReadString('\n')
+		line = strings.TrimSpace(l)
+		if err == io.EOF && line == "" {
+			return nil, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+	if !strings.HasPrefix(line, "WARC/") {
+		return nil, fmt.Errorf("invalid WARC record: unexpected %q", line)
+	}
+
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("invalid WARC header: %w", err)
+	}
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WARC Content-Length: %w", err)
+	}
+	block := make([]byte, length)
+	if _, err := io.ReadFull(r, block); err != nil {
+		return nil, fmt.Errorf("truncated WARC record: %w", err)
+	}
+
+	// Records end with two CRLFs
+	for {
+		b, err := r.Peek(1)
+		if err != nil || (b[0] != '\r' && b[0] != '\n') {
+			break
+		}
+		r.ReadByte()
+	}
+	return &warcRecord{Header: header, Block: block}, nil
+}
+
+// warcLocation points at a record inside a WARC file.
+type warcLocation struct {
+	file       string
+	offset     int64
+	compressed bool
+}
+
+// countingReader counts bytes consumed through it. It implements
+// io.ByteReader so gzip reads exactly one member at a time.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// warcFetcher serves responses recorded in WARC files (for example from
+// Browsertrix or wget --warc-file), so existing captures can be converted
+// without refetching.
+type warcFetcher struct {
+	index map[string]warcLocation
+}
+
+// newWARCFetcher indexes the response records in the given files.
+// Patterns may be globs. Later records for the same URL win.
+func newWARCFetcher(patterns []string) (*warcFetcher, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("fetcher %q requires warc_files", "warc")
+	}
+	f := &warcFetcher{index: map[string]warcLocation{}}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no WARC files match %s", pattern)
+		}
+		for _, path := range matches {
+			if err := f.indexFile(path); err != nil {
+				return nil, fmt.Errorf("indexing %s: %w", path, err)
+			}
+		}
+	}
+	fmt.Printf("Indexed %d WARC responses\n", len(f.index))
+	return f, nil
+}
+
+// add records the location of rec if it is a response.
+func (f *warcFetcher) add(rec *warcRecord, loc warcLocation) {
+	if rec.Header.Get("WARC-Type") != "response" {
+		return
+	}
+	if target := strings.Trim(rec.Header.Get("WARC-Target-URI"), "<>"); target != "" {
+		f.index[target] = loc
+	}
+}
+
+func (f *warcFetcher) indexFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if strings.HasSuffix(path, ".gz") {
+		// Each record is its own gzip member, so member offsets are
+		// record offsets.
+		cr := &countingReader{r: bufio.NewReader(file)}
+		zr, err := gzip.NewReader(cr)
+		if err != nil {
+			return err
+		}
+		var start int64
+		for {
+			zr.Multistream(false)
+			rec, err := readWARCRecord(bufio.NewReader(zr))
+			if err != nil && err != io.EOF {
+				return err
+			}
+			if rec != nil {
+				f.add(rec, warcLocation{file: path, offset: start, compressed: true})
+			}
+			io.Copy(io.Discard, zr)
+
+			start = cr.n
+			if err := zr.Reset(cr); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+
+	br := bufio.NewReader(file)
+	for {
+		pos, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		start := pos - int64(br.Buffered())
+		rec, err := readWARCRecord(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f.add(rec, warcLocation{file: path, offset: start})
+	}
+}
+
+func (f *warcFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	loc, ok := f.index[req.URL.String()]
+	if !ok {
+		return localResponse(req, http.StatusNotFound, nil, "", time.Time{}), nil
+	}
+
+	file, err := os.Open(loc.file)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(loc.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = file
+	if loc.compressed {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		zr.Multistream(false)
+		r = zr
+	}
+	rec, err := readWARCRecord(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(rec.Block)), req)
+}
+
+// warcWriter appends records to a WARC file, gzipping each record as
+// its own member when the path ends in .gz.
+type warcWriter struct {
+	mu       sync.Mutex
+	file     *os.File
+	dir      string
+	compress bool
+}
+
+// newWARCWriter creates path and writes the warcinfo record.
+func newWARCWriter(path string) (*warcWriter, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &warcWriter{file: file, dir: filepath.Dir(path), compress: strings.HasSuffix(path, ".gz")}
+
+	info := fmt.Sprintf("software: %s\r\nformat: WARC File Format 1.0\r\n", userAgent())
+	err = w.write(textproto.MIMEHeader{
+		"Warc-Type":     {"warcinfo"},
+		"Warc-Filename": {filepath.Base(path)},
+		"Content-Type":  {"application/warc-fields"},
+	}, strings.NewReader(info), int64(len(info)))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// writeResponse records an HTTP response for targetURI. Its header is
+// resp's, and its body the size bytes read from body.
+func (w *warcWriter) writeResponse(targetURI string, resp *http.Response, body io.Reader, size int64) error {
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "HTTP/%d.%d %s\r\n", resp.ProtoMajor, resp.ProtoMinor, resp.Status)
+	header := resp.Header.Clone()
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.FormatInt(size, 10))
+	header.Write(&head)
+	head.WriteString("\r\n")
+
+	return w.write(textproto.MIMEHeader{
+		"Warc-Type":       {"response"},
+		"Warc-Target-Uri": {targetURI},
+		"Content-Type":    {"application/http;msgtype=response"},
+	}, io.MultiReader(&head, body), int64(head.Len())+size)
+}
+
+// write appends a record with header and the size bytes of block. The block
+// is copied to the file, not buffered, so large responses are recorded in
+// constant memory.
+func (w *warcWriter) write(header textproto.MIMEHeader, block io.Reader, size int64) error {
+	var rec bytes.Buffer
+	rec.WriteString(warcVersion + "\r\n")
+	fmt.Fprintf(&rec, "WARC-Record-ID: <urn:uuid:%s>\r\n", newUUID())
+	fmt.Fprintf(&rec, "WARC-Date: %s\r\n", time.Now().UTC().Format(time.RFC3339))
+	for _, key := range []string{"Warc-Type", "Warc-Target-Uri", "Warc-Filename", "Content-Type"} {
+		if v := header.Get(key); v != "" {
+			fmt.Fprintf(&rec, "%s: %s\r\n", warcHeaderName(key), v)
+		}
+	}
+	fmt.Fprintf(&rec, "Content-Length: %d\r\n\r\n", size)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var out io.Writer = w.file
+	var zw *gzip.Writer
+	if w.compress {
+		zw = gzip.NewWriter(w.file)
+		out = zw
+	}
+	if _, err := rec.WriteTo(out); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(out, block, size); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(out, "\r\n\r\n"); err != nil {
+		return err
+	}
+	if zw != nil {
+		return zw.Close()
+	}
+	return nil
+}
+
+// Close flushes and closes the WARC file.
+func (w *warcWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// warcHeaderName restores the conventional WARC capitalization of a
+// canonicalized header key.
+func warcHeaderName(key string) string {
+	switch key {
+	case "Warc-Type":
+		return "WARC-Type"
+	case "Warc-Target-Uri":
+		return "WARC-Target-URI"
+	case "Warc-Filename":
+		return "WARC-Filename"
+	}
+	return key
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// warcRecordingFetcher writes every response it fetches to a WARC file.
+// The body is copied to a temporary file as the crawl reads it, and the
+// record is written from that file once the body is closed, so bodies
+// streamed to the spool are never held in memory.
+type warcRecordingFetcher struct {
+	next Fetcher
+	w    *warcWriter
+}
+
+func (f *warcRecordingFetcher) Fetch(req *http.Request) (*http.Response, error) {
+	resp, err := f.next.Fetch(req)
+	if err != nil || resp.StatusCode == http.StatusNotModified {
+		return resp, err
+	}
+	file, err := os.CreateTemp(f.w.dir, ".warc-body-*")
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	// The header is recorded as fetched, before later fetchers change it.
+	recorded := *resp
+	recorded.Header = resp.Header.Clone()
+	resp.Body = &warcTee{body: resp.Body, file: file, link: req.URL.String(), resp: &recorded, w: f.w}
+	return resp, nil
+}
+
+// warcTee copies a response body to file while it is read, and records
+// the response when the body is closed.
+type warcTee struct {
+	body io.ReadCloser
+	file *os.File
+	link string
+	resp *http.Response
+	w    *warcWriter
+	err  error
+	once sync.Once
+}
+
+func (t *warcTee) Read(p []byte) (int, error) {
+	n, err := t.body.Read(p)
+	if n > 0 && t.err == nil {
+		_, t.err = t.file.Write(p[:n])
+	}
+	return n, err
+}
+
+// Close reads what is left of the body, so the record is complete, and
+// writes the record.
+func (t *warcTee) Close() error {
+	var err error
+	t.once.Do(func() {
+		defer os.Remove(t.file.Name())
+		defer t.file.Close()
+		if t.err == nil {
+			_, t.err = io.Copy(t.file, t.body)
+		}
+		err = t.body.Close()
+		if t.err == nil {
+			t.err = t.record()
+		}
+		if t.err != nil {
+			fmt.Printf("Error writing WARC record for %s: %v\n", t.link, t.err)
+		}
+	})
+	return err
+}
+
+// record writes the copied body to the WARC file.
+func (t *warcTee) record() error {
+	size, err := t.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return t.w.writeResponse(t.link, t.resp, t.file, size)
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Conversion warning kinds. Each warning starts with its kind, as in
+// "dropped element: <iframe>".
+const (
+	warningDropped   = "dropped element"
+	warningUnhandled = "unhandled tag"
+	warningTable     = "truncated table"
+	warningPage      = "truncated page"
+)
+
+// droppedElements are left out of the markdown by both engines, apart
+// from any fallback text they hold.
+const droppedElements = "iframe, video, audio, canvas, svg, object, embed, input:not([type=hidden]), select, textarea"
+
+// conversionWarnings lists what converting content HTML with ext loses:
+// dropped elements, tags markdown has no syntax for, and tables that lose
+// structure. Repeated warnings are counted, as in "dropped element:
+// <iframe> (3)".
+func conversionWarnings(content string, ext ExtractionConfig, transcripts *transcriptClient) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil
+	}
+	var order []string
+	counts := map[string]int{}
+	add := func(warning string) {
+		if counts[warning] == 0 {
+			order = append(order, warning)
+		}
+		counts[warning]++
+	}
+
+	plugins := converterPlugins(ext.Converter)
+	doc.Find(droppedElements).Each(func(i int, s *goquery.Selection) {
+		tag := goquery.NodeName(s)
+		if s.ParentsFiltered(droppedElements).Length() > 0 {
+			return
+		}
+		if tag == "iframe" && transcripts != nil {
+			src := s.AttrOr("src", "")
+			if youtubeEmbedRe.MatchString(src) || vimeoEmbedRe.MatchString(src) {
+				return
+			}
+		}
+		if tag == "input" && plugins[pluginTaskLists] && s.AttrOr("type", "") == "checkbox" && s.ParentsFiltered("li").Length() > 0 {
+			return
+		}
+		add(fmt.Sprintf("%s: <%s>", warningDropped, tag))
+	})
+
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		reason := lowConfidenceReason(s)
+		tag := goquery.NodeName(s)
+		switch {
+		case tag == "table" && !plugins[pluginTables]:
+			add(warningTable + ": flattened to text without the tables plugin")
+		case reason == "":
+		case tag == "table":
+			add(fmt.Sprintf("%s: %s%s", warningTable, reason, fallbackNote(s, ext)))
+		default:
+			add(fmt.Sprintf("%s: <%s>%s", warningUnhandled, tag, fallbackNote(s, ext)))
+		}
+	})
+
+	warnings := make([]string, len(order))
+	for i, warning := range order {
+		warnings[i] = warning
+		if counts[warning] > 1 {
+			warnings[i] += fmt.Sprintf(" (%d)", counts[warning])
+		}
+	}
+	return warnings
+}
+
+// fallbackNote notes that the source HTML of s is kept after its markdown
+// by html_fallback.
+func fallbackNote(s *goquery.Selection, ext ExtractionConfig) string {
+	if !ext.HTMLFallback {
+		return ""
+	}
+	if html, err := goquery.OuterHtml(s); err != nil || len(html) > htmlFallbackLimit {
+		return ""
+	}
+	return ", source HTML kept"
+}
+
+// warningKind returns the kind a warning starts with.
+func warningKind(warning string) string {
+	kind, _, _ := strings.Cut(warning, ":")
+	return kind
+}
@@ -0,0 +1,313 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// watchBaseURL is the address the watched directory is published at, and
+// watchInterval how often it is checked for changes.
+var (
+	watchBaseURL  string
+	watchInterval time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <dir>",
+	Short: "Convert a local directory of docs and reconvert files as they change",
+	Long: `Converts the HTML, markdown, MDX, reStructuredText, AsciiDoc, and notebook
+files below a directory into the output directory, then watches it and
+converts files again as they are saved, removing the pages of deleted
+files. After each change the site-wide steps, such as boilerplate removal
+and SKILL.md entries, run again, so the output always matches the docs.
+
+Pages are recorded under --base-url plus their path in the directory, the
+address the docs are published at, or their file:// URL without one.
+Hidden directories, node_modules, and the output directory are skipped.`,
+	Example: `  agent-skills-generator watch docs --base-url https://docs.example.com/
+  agent-skills-generator watch site/_build/html --interval 2s`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			fmt.Printf("Error unmarshalling config: %v\n", err)
+			os.Exit(1)
+		}
+		w, err := newDirWatcher(cfg, args[0], watchBaseURL)
+		if err != nil {
+			fmt.Printf("Error watching %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		w.sync()
+		fmt.Printf("Watching %s for changes (%d files)\n", args[0], len(w.stamps))
+		for range time.Tick(watchInterval) {
+			w.sync()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVar(&watchBaseURL, "base-url", "", "URL the directory is published at (default: its file:// URL)")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 500*time.Millisecond, "how often to check the directory for changes")
+}
+
+// fileStamp identifies a version of a watched file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// dirWatcher converts the files of a local directory into the output as
+// they change. pages maps each converted file, relative to root, to the
+// URL of its manifest entry.
+type dirWatcher struct {
+	cfg    Config
+	opts   *CrawlOptions
+	root   string
+	base   *url.URL
+	events *convertEvents
+	stamps map[string]fileStamp
+	pages  map[string]string
+}
+
+func newDirWatcher(cfg Config, dir, baseURL string) (*dirWatcher, error) {
+	if err := checkNamespace(cfg.Namespace); err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	if baseURL == "" {
+		baseURL = "file://" + filepath.ToSlash(root) + "/"
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Scheme == "" {
+		return nil, fmt.Errorf("invalid base URL %q", baseURL)
+	}
+	w := &dirWatcher{
+		cfg:    cfg,
+		opts:   newCrawlOptions(&cfg),
+		root:   root,
+		base:   base,
+		events: &convertEvents{},
+		stamps: map[string]fileStamp{},
+		pages:  map[string]string{},
+	}
+	if err := w.opts.useConversionConfig(cfg); err != nil {
+		return nil, err
+	}
+	w.opts.Events = w.events
+	manifest, err := loadManifest(w.opts.Output)
+	if err != nil {
+		fmt.Printf("Warning: could not read manifest, starting fresh: %v\n", err)
+		manifest = &Manifest{Pages: map[string]*ManifestEntry{}}
+	}
+	w.opts.manifest = manifest
+	manifest.Config = w.opts.profile
+	manifest.Namespace = cfg.Namespace
+	// Pages of files deleted since the last run are removed on the first
+	// scan.
+	prefix := base.String()
+	for link := range manifest.Pages {
+		if rel, err := url.PathUnescape(strings.TrimPrefix(link, prefix)); err == nil && strings.HasPrefix(link, prefix) && watchable(rel) {
+			w.pages[rel] = link
+		}
+	}
+	w.opts.changes = newChangeSet(cfg.ChangeSummary)
+	return w, nil
+}
+
+// watchable reports whether a file with this name is converted.
+func watchable(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	_, ok := sourceConverters[ext]
+	return ok || ext == ".html"
+}
+
+// scan returns the stamps of the convertible files below the root.
+func (w *dirWatcher) scan() (map[string]fileStamp, error) {
+	output, _ := filepath.Abs(w.opts.Output)
+	stamps := map[string]fileStamp{}
+	err := filepath.WalkDir(w.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != w.root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || p == output) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !watchable(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(w.root, p)
+		if err != nil {
+			return err
+		}
+		stamps[filepath.ToSlash(rel)] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return stamps, err
+}
+
+// sync converts the files added or changed since the last scan, removes
+// the pages of deleted files, and then reruns the site-wide steps and
+// saves the manifest.
+func (w *dirWatcher) sync() {
+	stamps, err := w.scan()
+	if err != nil {
+		fmt.Printf("Error scanning %s: %v\n", w.root, err)
+		return
+	}
+	changed := 0
+	for _, rel := range sortedKeys(stamps) {
+		if old, ok := w.stamps[rel]; ok && old == stamps[rel] {
+			continue
+		}
+		if err := w.convert(rel, stamps[rel].modTime); err != nil {
+			fmt.Printf("Error converting %s: %v\n", rel, err)
+			continue
+		}
+		changed++
+	}
+	for _, rel := range sortedKeys(w.pages) {
+		if _, ok := stamps[rel]; !ok {
+			w.remove(rel)
+			changed++
+		}
+	}
+	initial := len(w.stamps) == 0
+	w.stamps = stamps
+	if changed == 0 {
+		return
+	}
+
+	processSite(w.cfg, w.opts, w.opts.manifest)
+	compressOutput(w.opts, w.opts.manifest, w.cfg.Compression)
+	if err := w.opts.manifest.save(w.opts.Output); err != nil {
+		fmt.Printf("Error writing manifest: %v\n", err)
+		return
+	}
+	if !initial {
+		fmt.Printf("Updated %s: %d changed\n", w.opts.Output, changed)
+	}
+}
+
+// pageURL returns the URL of the file at rel below the root.
+func (w *dirWatcher) pageURL(rel string) *url.URL {
+	return w.base.JoinPath(rel)
+}
+
+// convert converts the file at rel: HTML as a crawled page at its URL, and
+// other formats with the source converters.
+func (w *dirWatcher) convert(rel string, modTime time.Time) error {
+	data, err := os.ReadFile(filepath.Join(w.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	u := w.pageURL(rel)
+	w.events.entry, w.events.url, w.events.reason = nil, "", ""
+
+	ext := strings.ToLower(path.Ext(rel))
+	if ext == ".html" {
+		headers := http.Header{}
+		headers.Set("Content-Type", "text/html; charset=utf-8")
+		headers.Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+		saveResponse(&colly.Response{
+			StatusCode: http.StatusOK,
+			Body:       data,
+			Request:    &colly.Request{URL: u},
+			Headers:    &headers,
+		}, w.opts)
+	} else {
+		doc, err := sourceConverters[ext](data, w.opts)
+		if err != nil {
+			return err
+		}
+		if doc.Title == "" {
+			doc.Title = strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+		}
+		// Other formats are written where the page's HTML build would be,
+		// with README and index files standing for their directory.
+		p := strings.TrimSuffix(rel, path.Ext(rel))
+		switch strings.ToLower(path.Base(p)) {
+		case "readme", "index":
+			p = strings.TrimSuffix(path.Dir(p), ".") + "/"
+		default:
+			p += ".html"
+		}
+		if _, err := writeSourcePage(w.opts, w.pageURL(p), convertedPage{
+			URL:          u.String(),
+			Title:        doc.Title,
+			Description:  doc.Description,
+			Body:         doc.Body,
+			LastModified: modTime.UTC().Format(http.TimeFormat),
+		}); err != nil {
+			return err
+		}
+	}
+	switch {
+	case w.events.entry != nil:
+		w.pages[rel] = w.events.entry.URL
+	case w.events.reason == "unchanged":
+		w.pages[rel] = w.events.url
+	case w.events.reason != "":
+		return fmt.Errorf("page left out: %s", w.events.reason)
+	}
+	return nil
+}
+
+// remove deletes the page of a file that was deleted.
+func (w *dirWatcher) remove(rel string) {
+	link := w.pages[rel]
+	delete(w.pages, rel)
+	entry, ok := w.opts.manifest.lookup(link)
+	if !ok {
+		return
+	}
+	w.opts.changes.record(pageRemoved, entry.URL, entry.Title, entry.Path, "", "")
+	removeOutputFile(w.opts.pageFile(entry))
+	if u, err := url.Parse(link); err == nil && strings.HasSuffix(u.Path, ".html") {
+		_, raw := w.opts.outputPath(u)
+		removeOutputFile(raw)
+	}
+	w.opts.manifest.remove(link, "file removed")
+	fmt.Printf("Removed %s\n", entry.Path)
+}
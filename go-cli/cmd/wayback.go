@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// defaultWaybackEndpoint is the Wayback Machine availability API host.
+const defaultWaybackEndpoint = "https://archive.org"
+
+// archivedHeader carries the snapshot URL of a page converted from the
+// Wayback Machine to writePage, which marks it in the frontmatter.
+const archivedHeader = "X-Agent-Skills-Archived"
+
+// waybackSnapshot is the closest snapshot reported by the availability API.
+type waybackSnapshot struct {
+	Available bool   `json:"available"`
+	URL       string `json:"url"`
+	Timestamp string `json:"timestamp"`
+	Status    string `json:"status"`
+}
+
+// waybackFallback converts the latest Wayback Machine snapshot of a page
+// that has disappeared upstream, so pages an earlier crawl saved keep
+// their coverage. It reports whether a snapshot was converted.
+func waybackFallback(cfg WaybackConfig, opts *CrawlOptions, u *url.URL) bool {
+	if !cfg.Enabled {
+		return false
+	}
+	if _, ok := opts.manifest.lookup(u.String()); !ok {
+		return false
+	}
+	snapshot, err := latestSnapshot(cfg, u.String())
+	if err != nil {
+		fmt.Printf("Warning: no archived copy of %s: %v\n", u, err)
+		return false
+	}
+	// The id_ flag serves the page as archived, without the Wayback
+	// Machine's toolbar and rewritten links.
+	raw := strings.Replace(snapshot.URL, "/"+snapshot.Timestamp+"/", "/"+snapshot.Timestamp+"id_/", 1)
+	body, err := fetchPage(raw)
+	if err != nil {
+		fmt.Printf("Warning: could not fetch archived copy of %s: %v\n", u, err)
+		return false
+	}
+
+	archivedAt := snapshot.Timestamp
+	if t, err := time.Parse("20060102150405", snapshot.Timestamp); err == nil {
+		archivedAt = t.UTC().Format(http.TimeFormat)
+	}
+	headers := http.Header{}
+	headers.Set("Content-Type", "text/html; charset=utf-8")
+	headers.Set("Last-Modified", archivedAt)
+	headers.Set(archivedHeader, snapshot.URL)
+	fmt.Printf("Using archived copy of %s from %s\n", u, archivedAt)
+	saveResponse(&colly.Response{
+		StatusCode: http.StatusOK,
+		Body:       body,
+		Request:    &colly.Request{URL: u},
+		Headers:    &headers,
+	}, opts)
+	return true
+}
+
+// latestSnapshot asks the availability API for the latest snapshot of link.
+func latestSnapshot(cfg WaybackConfig, link string) (waybackSnapshot, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultWaybackEndpoint
+	}
+	data, err := fetchPage(strings.TrimSuffix(endpoint, "/") + "/wayback/available?url=" + url.QueryEscape(link))
+	if err != nil {
+		return waybackSnapshot{}, err
+	}
+	var result struct {
+		Snapshots struct {
+			Closest waybackSnapshot `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return waybackSnapshot{}, err
+	}
+	snapshot := result.Snapshots.Closest
+	if !snapshot.Available || snapshot.URL == "" || snapshot.Status != "" && snapshot.Status != "200" {
+		return waybackSnapshot{}, fmt.Errorf("not archived")
+	}
+	return snapshot, nil
+}